@@ -0,0 +1,192 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// GET /api/access-control/roles
+func GetRoles(c *models.ReqContext) Response {
+	query := models.GetRolesQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to list roles", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// GET /api/access-control/roles/:roleId
+func GetRoleByID(c *models.ReqContext) Response {
+	query := models.GetRoleByIdQuery{Id: c.ParamsInt64(":roleId"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == models.ErrRoleNotFound {
+			return Error(404, "Role not found", err)
+		}
+		return Error(500, "Failed to get role", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/access-control/roles
+func CreateRole(c *models.ReqContext, cmd models.CreateRoleCommand) Response {
+	if resp := validateRolePermissions(cmd.Permissions); resp != nil {
+		return resp
+	}
+	cmd.OrgId = c.OrgId
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrRoleNameTaken {
+			return Error(409, "Role name taken", err)
+		}
+		return Error(500, "Failed to create role", err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+// PUT /api/access-control/roles/:roleId
+func UpdateRole(c *models.ReqContext, cmd models.UpdateRoleCommand) Response {
+	if resp := validateRolePermissions(cmd.Permissions); resp != nil {
+		return resp
+	}
+	cmd.Id = c.ParamsInt64(":roleId")
+	cmd.OrgId = c.OrgId
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		switch err {
+		case models.ErrRoleNotFound:
+			return Error(404, "Role not found", err)
+		case models.ErrRoleNameTaken:
+			return Error(409, "Role name taken", err)
+		}
+		return Error(500, "Failed to update role", err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+// DELETE /api/access-control/roles/:roleId
+func DeleteRole(c *models.ReqContext) Response {
+	cmd := models.DeleteRoleCommand{Id: c.ParamsInt64(":roleId"), OrgId: c.OrgId}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrRoleNotFound {
+			return Error(404, "Role not found", err)
+		}
+		return Error(500, "Failed to delete role", err)
+	}
+
+	return Success("Role deleted")
+}
+
+// GET /api/access-control/users/:userId/roles
+func GetUserRoles(c *models.ReqContext) Response {
+	query := models.GetUserRolesQuery{UserId: c.ParamsInt64(":userId"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to list roles of user", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/access-control/users/:userId/roles
+func AddUserRole(c *models.ReqContext, cmd models.AddUserRoleCommand) Response {
+	cmd.UserId = c.ParamsInt64(":userId")
+	cmd.OrgId = c.OrgId
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		switch err {
+		case models.ErrRoleNotFound:
+			return Error(404, "Role not found", err)
+		case models.ErrOrgUserNotFound:
+			return Error(404, "User not found", err)
+		case models.ErrRoleAlreadyAdded:
+			return Error(400, "Role is already added to user", err)
+		}
+		return Error(500, "Failed to add role to user", err)
+	}
+
+	return Success("Role added to user")
+}
+
+// DELETE /api/access-control/users/:userId/roles/:roleId
+func RemoveUserRole(c *models.ReqContext) Response {
+	cmd := models.RemoveUserRoleCommand{
+		RoleId: c.ParamsInt64(":roleId"),
+		UserId: c.ParamsInt64(":userId"),
+		OrgId:  c.OrgId,
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrRoleAssignmentMissing {
+			return Error(404, "Role is not added to user", err)
+		}
+		return Error(500, "Failed to remove role from user", err)
+	}
+
+	return Success("Role removed from user")
+}
+
+// GET /api/access-control/teams/:teamId/roles
+func GetTeamRoles(c *models.ReqContext) Response {
+	query := models.GetTeamRolesQuery{TeamId: c.ParamsInt64(":teamId"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to list roles of team", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/access-control/teams/:teamId/roles
+func AddTeamRole(c *models.ReqContext, cmd models.AddTeamRoleCommand) Response {
+	cmd.TeamId = c.ParamsInt64(":teamId")
+	cmd.OrgId = c.OrgId
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		switch err {
+		case models.ErrRoleNotFound:
+			return Error(404, "Role not found", err)
+		case models.ErrTeamNotFound:
+			return Error(404, "Team not found", err)
+		case models.ErrRoleAlreadyAdded:
+			return Error(400, "Role is already added to team", err)
+		}
+		return Error(500, "Failed to add role to team", err)
+	}
+
+	return Success("Role added to team")
+}
+
+// DELETE /api/access-control/teams/:teamId/roles/:roleId
+func RemoveTeamRole(c *models.ReqContext) Response {
+	cmd := models.RemoveTeamRoleCommand{
+		RoleId: c.ParamsInt64(":roleId"),
+		TeamId: c.ParamsInt64(":teamId"),
+		OrgId:  c.OrgId,
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrRoleAssignmentMissing {
+			return Error(404, "Role is not added to team", err)
+		}
+		return Error(500, "Failed to remove role from team", err)
+	}
+
+	return Success("Role removed from team")
+}
+
+func validateRolePermissions(permissions []*models.PermissionDTO) Response {
+	for _, p := range permissions {
+		if !accesscontrol.IsValidAction(p.Action) {
+			return Error(400, fmt.Sprintf("Unknown permission action %q", p.Action), nil)
+		}
+		if !accesscontrol.IsValidScope(p.Scope) {
+			return Error(400, fmt.Sprintf("Unknown permission scope %q", p.Scope), nil)
+		}
+	}
+
+	return nil
+}
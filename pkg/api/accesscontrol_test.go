@@ -0,0 +1,22 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolePermissionValidation(t *testing.T) {
+	c := &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_ADMIN}}
+
+	for _, scope := range []string{"", "dashboards:*", "datasources:"} {
+		permissions := []*models.PermissionDTO{{Action: "datasources:query", Scope: scope}}
+
+		rsp := CreateRole(c, models.CreateRoleCommand{Name: "queriers", Permissions: permissions})
+		assert.Equal(t, 400, responseStatus(t, rsp), scope)
+
+		rsp = UpdateRole(c, models.UpdateRoleCommand{Name: "queriers", Permissions: permissions})
+		assert.Equal(t, 400, responseStatus(t, rsp), scope)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/admin/audit
+func AdminSearchAuditEntries(c *models.ReqContext) Response {
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 100
+	}
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	query := models.SearchAuditEntriesQuery{
+		OrgId:        c.QueryInt64("orgId"),
+		ActorId:      c.QueryInt64("actorId"),
+		ActorLogin:   c.Query("actorLogin"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resourceType"),
+		ResourceId:   c.Query("resourceId"),
+		Page:         page,
+		Limit:        perPage,
+	}
+
+	// from and to are epoch milliseconds
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to search audit entries", err)
+	}
+
+	query.Result.Page = page
+	query.Result.PerPage = perPage
+
+	return JSON(200, query.Result)
+}
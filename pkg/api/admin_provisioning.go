@@ -37,3 +37,11 @@ func (server *HTTPServer) AdminProvisioningReloadNotifications(c *models.ReqCont
 	}
 	return Success("Notifications config reloaded")
 }
+
+func (server *HTTPServer) AdminProvisioningReloadAlerts(c *models.ReqContext) Response {
+	err := server.ProvisioningService.ProvisionAlerts()
+	if err != nil {
+		return Error(500, "", err)
+	}
+	return Success("Alert rules config reloaded")
+}
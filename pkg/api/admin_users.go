@@ -30,7 +30,7 @@ func AdminCreateUser(c *models.ReqContext, form dtos.AdminCreateUserForm) {
 		return
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrOrgNotFound {
 			c.JsonApiErr(400, models.ErrOrgNotFound.Error(), nil)
 			return
@@ -78,7 +78,7 @@ func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPass
 		NewPassword: passwordHashed,
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		c.JsonApiErr(500, "Failed to update user password", err)
 		return
 	}
@@ -95,7 +95,7 @@ func AdminUpdateUserPermissions(c *models.ReqContext, form dtos.AdminUpdateUserP
 		IsGrafanaAdmin: form.IsGrafanaAdmin,
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrLastGrafanaAdmin {
 			c.JsonApiErr(400, models.ErrLastGrafanaAdmin.Error(), nil)
 			return
@@ -113,7 +113,7 @@ func AdminDeleteUser(c *models.ReqContext) {
 
 	cmd := models.DeleteUserCommand{UserId: userID}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrUserNotFound {
 			c.JsonApiErr(404, models.ErrUserNotFound.Error(), nil)
 			return
@@ -136,7 +136,7 @@ func (server *HTTPServer) AdminDisableUser(c *models.ReqContext) Response {
 	}
 
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: true}
-	if err := bus.Dispatch(&disableCmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &disableCmd); err != nil {
 		if err == models.ErrUserNotFound {
 			return Error(404, models.ErrUserNotFound.Error(), nil)
 		}
@@ -162,7 +162,7 @@ func AdminEnableUser(c *models.ReqContext) Response {
 	}
 
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: false}
-	if err := bus.Dispatch(&disableCmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &disableCmd); err != nil {
 		if err == models.ErrUserNotFound {
 			return Error(404, models.ErrUserNotFound.Error(), nil)
 		}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+// GET /api/alert-notifications/policy
+func GetAlertNotificationPolicy(c *models.ReqContext) Response {
+	query := &models.GetAlertNotificationPolicyQuery{OrgId: c.OrgId}
+
+	if err := bus.Dispatch(query); err != nil {
+		if err == models.ErrAlertNotificationPolicyNotFound {
+			return Error(404, "Alert notification policy not found", nil)
+		}
+		return Error(500, "Failed to get alert notification policy", err)
+	}
+
+	return JSON(200, dtos.NewAlertNotificationPolicy(query.Result))
+}
+
+// PUT /api/alert-notifications/policy
+func SaveAlertNotificationPolicy(c *models.ReqContext, cmd models.SaveAlertNotificationPolicyCommand) Response {
+	cmd.OrgId = c.OrgId
+
+	if err := alerting.ValidateNotificationPolicy(c.OrgId, cmd.Policy); err != nil {
+		return Error(400, err.Error(), err)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return Error(500, "Failed to save alert notification policy", err)
+	}
+
+	return JSON(200, dtos.NewAlertNotificationPolicy(cmd.Result))
+}
+
+// DELETE /api/alert-notifications/policy
+func DeleteAlertNotificationPolicy(c *models.ReqContext) Response {
+	cmd := &models.DeleteAlertNotificationPolicyCommand{OrgId: c.OrgId}
+
+	if err := bus.Dispatch(cmd); err != nil {
+		return Error(500, "Failed to delete alert notification policy", err)
+	}
+
+	return Success("Alert notification policy deleted")
+}
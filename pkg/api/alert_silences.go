@@ -0,0 +1,95 @@
+package api
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/alerts/silences
+func GetAlertSilences(c *models.ReqContext) Response {
+	query := &models.GetAlertSilencesQuery{OrgId: c.OrgId}
+	if c.QueryBool("active") {
+		query.ActiveAt = time.Now()
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		return Error(500, "Failed to get alert silences", err)
+	}
+
+	result := make([]*dtos.AlertSilence, 0, len(query.Result))
+	for _, silence := range query.Result {
+		result = append(result, dtos.NewAlertSilence(silence))
+	}
+
+	return JSON(200, result)
+}
+
+// GET /api/alerts/silences/:silenceId
+func GetAlertSilenceByID(c *models.ReqContext) Response {
+	query := &models.GetAlertSilenceByIdQuery{
+		Id:    c.ParamsInt64(":silenceId"),
+		OrgId: c.OrgId,
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		if err == models.ErrAlertSilenceNotFound {
+			return Error(404, "Alert silence not found", nil)
+		}
+		return Error(500, "Failed to get alert silence", err)
+	}
+
+	return JSON(200, dtos.NewAlertSilence(query.Result))
+}
+
+// POST /api/alerts/silences
+func CreateAlertSilence(c *models.ReqContext, cmd models.CreateAlertSilenceCommand) Response {
+	cmd.OrgId = c.OrgId
+	cmd.CreatedBy = c.UserId
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return alertSilenceError(err, "Failed to create alert silence")
+	}
+
+	return JSON(200, dtos.NewAlertSilence(cmd.Result))
+}
+
+// PUT /api/alerts/silences/:silenceId
+func UpdateAlertSilence(c *models.ReqContext, cmd models.UpdateAlertSilenceCommand) Response {
+	cmd.Id = c.ParamsInt64(":silenceId")
+	cmd.OrgId = c.OrgId
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrAlertSilenceNotFound {
+			return Error(404, "Alert silence not found", nil)
+		}
+		return alertSilenceError(err, "Failed to update alert silence")
+	}
+
+	return JSON(200, dtos.NewAlertSilence(cmd.Result))
+}
+
+// DELETE /api/alerts/silences/:silenceId
+func DeleteAlertSilence(c *models.ReqContext) Response {
+	cmd := &models.DeleteAlertSilenceCommand{
+		Id:    c.ParamsInt64(":silenceId"),
+		OrgId: c.OrgId,
+	}
+
+	if err := bus.Dispatch(cmd); err != nil {
+		return Error(500, "Failed to delete alert silence", err)
+	}
+
+	return Success("Alert silence deleted")
+}
+
+func alertSilenceError(err error, message string) Response {
+	if err == models.ErrAlertSilenceInvalidPeriod || err == models.ErrAlertSilenceMissingRepeat || err == models.ErrAlertSilenceInvalidCron ||
+		err == models.ErrAlertSilenceMissingMatcher {
+		return Error(400, err.Error(), err)
+	}
+
+	return Error(500, message, err)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/alerts/history
+func GetAlertStateHistory(c *models.ReqContext) Response {
+	return getAlertStateHistory(c, c.QueryInt64("alertId"))
+}
+
+// GET /api/alerts/:alertId/history
+func GetAlertStateHistoryByAlertID(c *models.ReqContext) Response {
+	return getAlertStateHistory(c, c.ParamsInt64(":alertId"))
+}
+
+func getAlertStateHistory(c *models.ReqContext, alertID int64) Response {
+	query := &models.GetAlertStateHistoryQuery{
+		OrgId:   c.OrgId,
+		AlertId: alertID,
+		From:    c.QueryInt64("from"),
+		To:      c.QueryInt64("to"),
+		State:   models.AlertStateType(c.Query("state")),
+		Page:    c.QueryInt("page"),
+		PerPage: c.QueryInt("perpage"),
+		User:    c.SignedInUser,
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		return Error(500, "Failed to get alert state history", err)
+	}
+
+	result := dtos.AlertStateHistoryResult{
+		TotalCount: query.Result.TotalCount,
+		History:    make([]*dtos.AlertStateHistory, 0, len(query.Result.History)),
+		Page:       query.Result.Page,
+		PerPage:    query.Result.PerPage,
+	}
+	for _, history := range query.Result.History {
+		result.History = append(result.History, dtos.NewAlertStateHistory(history))
+	}
+
+	return JSON(200, result)
+}
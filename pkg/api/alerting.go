@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func ValidateOrgAlert(c *models.ReqContext) {
@@ -271,7 +272,11 @@ func GetAlertNotificationByUID(c *models.ReqContext) Response {
 func CreateAlertNotification(c *models.ReqContext, cmd models.CreateAlertNotificationCommand) Response {
 	cmd.OrgId = c.OrgId
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := alerting.ValidateNotificationTemplates(cmd.Settings); err != nil {
+		return Error(400, err.Error(), err)
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return Error(500, "Failed to create alert notification", err)
 	}
 
@@ -281,12 +286,16 @@ func CreateAlertNotification(c *models.ReqContext, cmd models.CreateAlertNotific
 func UpdateAlertNotification(c *models.ReqContext, cmd models.UpdateAlertNotificationCommand) Response {
 	cmd.OrgId = c.OrgId
 
+	if err := alerting.ValidateNotificationTemplates(cmd.Settings); err != nil {
+		return Error(400, err.Error(), err)
+	}
+
 	err := fillWithSecureSettingsData(&cmd)
 	if err != nil {
 		return Error(500, "Failed to update alert notification", err)
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return Error(500, "Failed to update alert notification", err)
 	}
 
@@ -310,12 +319,16 @@ func UpdateAlertNotificationByUID(c *models.ReqContext, cmd models.UpdateAlertNo
 	cmd.OrgId = c.OrgId
 	cmd.Uid = c.Params("uid")
 
+	if err := alerting.ValidateNotificationTemplates(cmd.Settings); err != nil {
+		return Error(400, err.Error(), err)
+	}
+
 	err := fillWithSecureSettingsDataByUID(&cmd)
 	if err != nil {
 		return Error(500, "Failed to update alert notification", err)
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return Error(500, "Failed to update alert notification", err)
 	}
 
@@ -389,7 +402,7 @@ func DeleteAlertNotification(c *models.ReqContext) Response {
 		Id:    c.ParamsInt64("notificationId"),
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return Error(500, "Failed to delete alert notification", err)
 	}
 
@@ -402,7 +415,7 @@ func DeleteAlertNotificationByUID(c *models.ReqContext) Response {
 		Uid:   c.Params("uid"),
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return Error(500, "Failed to delete alert notification", err)
 	}
 
@@ -420,6 +433,10 @@ func NotificationTest(c *models.ReqContext, dto dtos.NotificationTestCommand) Re
 		SecureSettings: dto.SecureSettings,
 	}
 
+	if err := alerting.ValidateNotificationTemplates(cmd.Settings); err != nil {
+		return Error(400, err.Error(), err)
+	}
+
 	if err := bus.Dispatch(cmd); err != nil {
 		if err == models.ErrSmtpNotEnabled {
 			return Error(412, err.Error(), err)
@@ -427,7 +444,10 @@ func NotificationTest(c *models.ReqContext, dto dtos.NotificationTestCommand) Re
 		return Error(500, "Failed to send alert notifications", err)
 	}
 
-	return Success("Test notification sent")
+	return JSON(200, util.DynMap{
+		"message": "Test notification sent",
+		"preview": cmd.Result,
+	})
 }
 
 //POST /api/alerts/:alertId/pause
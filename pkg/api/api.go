@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 )
 
 func (hs *HTTPServer) registerRoutes() {
@@ -14,6 +15,7 @@ func (hs *HTTPServer) registerRoutes() {
 	reqGrafanaAdmin := middleware.ReqGrafanaAdmin
 	reqEditorRole := middleware.ReqEditorRole
 	reqOrgAdmin := middleware.ReqOrgAdmin
+	authorize := middleware.Authorize
 	reqCanAccessTeams := middleware.AdminOrFeatureEnabled(hs.Cfg.EditorsCanAdmin)
 	reqSnapshotPublicModeOrSignedIn := middleware.SnapshotPublicModeOrSignedIn()
 	redirectFromLegacyDashboardURL := middleware.RedirectFromLegacyDashboardURL()
@@ -37,10 +39,10 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/profile/switch-org/:id", reqSignedIn, hs.ChangeActiveOrgAndRedirectToHome)
 	r.Get("/org/", reqOrgAdmin, hs.Index)
 	r.Get("/org/new", reqGrafanaAdmin, hs.Index)
-	r.Get("/datasources/", reqOrgAdmin, hs.Index)
-	r.Get("/datasources/new", reqOrgAdmin, hs.Index)
-	r.Get("/datasources/edit/*", reqOrgAdmin, hs.Index)
-	r.Get("/org/users", reqOrgAdmin, hs.Index)
+	r.Get("/datasources/", authorize(ac.ActionDatasourcesRead), hs.Index)
+	r.Get("/datasources/new", authorize(ac.ActionDatasourcesCreate), hs.Index)
+	r.Get("/datasources/edit/*", authorize(ac.ActionDatasourcesRead), hs.Index)
+	r.Get("/org/users", authorize(ac.ActionUsersRead, ac.ScopeUsersAll), hs.Index)
 	r.Get("/org/users/new", reqOrgAdmin, hs.Index)
 	r.Get("/org/users/invite", reqOrgAdmin, hs.Index)
 	r.Get("/org/teams", reqCanAccessTeams, hs.Index)
@@ -178,10 +180,6 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
 			orgRoute.Put("/", bind(dtos.UpdateOrgForm{}), Wrap(UpdateOrgCurrent))
 			orgRoute.Put("/address", bind(dtos.UpdateOrgAddressForm{}), Wrap(UpdateOrgAddressCurrent))
-			orgRoute.Get("/users", Wrap(GetOrgUsersForCurrentOrg))
-			orgRoute.Post("/users", quota("user"), bind(models.AddOrgUserCommand{}), Wrap(AddOrgUserToCurrentOrg))
-			orgRoute.Patch("/users/:userId", bind(models.UpdateOrgUserCommand{}), Wrap(UpdateOrgUserForCurrentOrg))
-			orgRoute.Delete("/users/:userId", Wrap(RemoveOrgUserForCurrentOrg))
 
 			// invites
 			orgRoute.Get("/invites", Wrap(GetPendingOrgInvites))
@@ -193,6 +191,14 @@ func (hs *HTTPServer) registerRoutes() {
 			orgRoute.Put("/preferences", bind(dtos.UpdatePrefsCmd{}), Wrap(UpdateOrgPreferences))
 		}, reqOrgAdmin)
 
+		// current org users
+		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
+			orgRoute.Get("/users", authorize(ac.ActionUsersRead, ac.ScopeUsersAll), Wrap(GetOrgUsersForCurrentOrg))
+			orgRoute.Post("/users", authorize(ac.ActionUsersWrite, ac.ScopeUsersAll), quota("user"), bind(models.AddOrgUserCommand{}), Wrap(AddOrgUserToCurrentOrg))
+			orgRoute.Patch("/users/:userId", authorize(ac.ActionUsersWrite, ac.ScopeUsersAll), bind(models.UpdateOrgUserCommand{}), Wrap(UpdateOrgUserForCurrentOrg))
+			orgRoute.Delete("/users/:userId", authorize(ac.ActionUsersWrite, ac.ScopeUsersAll), Wrap(RemoveOrgUserForCurrentOrg))
+		})
+
 		// current org without requirement of user to be org admin
 		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
 			orgRoute.Get("/users/lookup", Wrap(GetOrgUsersForCurrentOrgLookup))
@@ -230,21 +236,54 @@ func (hs *HTTPServer) registerRoutes() {
 			keysRoute.Delete("/:id", Wrap(DeleteAPIKey))
 		}, reqOrgAdmin)
 
+		// service accounts
+		apiRoute.Group("/serviceaccounts", func(serviceAccountsRoute routing.RouteRegister) {
+			serviceAccountsRoute.Get("/", Wrap(hs.GetServiceAccounts))
+			serviceAccountsRoute.Post("/", bind(models.CreateServiceAccountCommand{}), Wrap(hs.CreateServiceAccount))
+			serviceAccountsRoute.Get("/:serviceAccountId", Wrap(hs.GetServiceAccountByID))
+			serviceAccountsRoute.Patch("/:serviceAccountId", bind(models.UpdateServiceAccountCommand{}), Wrap(hs.UpdateServiceAccount))
+			serviceAccountsRoute.Delete("/:serviceAccountId", Wrap(hs.DeleteServiceAccount))
+			serviceAccountsRoute.Get("/:serviceAccountId/tokens", Wrap(hs.GetServiceAccountTokens))
+			serviceAccountsRoute.Post("/:serviceAccountId/tokens", quota("api_key"), bind(models.AddServiceAccountTokenCommand{}), Wrap(hs.AddServiceAccountToken))
+			serviceAccountsRoute.Delete("/:serviceAccountId/tokens/:tokenId", Wrap(hs.DeleteServiceAccountToken))
+		}, reqOrgAdmin)
+
+		// access control
+		apiRoute.Group("/access-control", func(acRoute routing.RouteRegister) {
+			acRoute.Get("/roles", authorize(ac.ActionRolesRead, ac.ScopeRolesAll), Wrap(GetRoles))
+			acRoute.Post("/roles", authorize(ac.ActionRolesWrite, ac.ScopeRolesAll), bind(models.CreateRoleCommand{}), Wrap(CreateRole))
+			acRoute.Get("/roles/:roleId", authorize(ac.ActionRolesRead, ac.ScopeRolesAll), Wrap(GetRoleByID))
+			acRoute.Put("/roles/:roleId", authorize(ac.ActionRolesWrite, ac.ScopeRolesAll), bind(models.UpdateRoleCommand{}), Wrap(UpdateRole))
+			acRoute.Delete("/roles/:roleId", authorize(ac.ActionRolesWrite, ac.ScopeRolesAll), Wrap(DeleteRole))
+			acRoute.Get("/users/:userId/roles", authorize(ac.ActionRolesRead, ac.ScopeRolesAll), Wrap(GetUserRoles))
+			acRoute.Post("/users/:userId/roles", authorize(ac.ActionRolesWrite, ac.ScopeRolesAll), bind(models.AddUserRoleCommand{}), Wrap(AddUserRole))
+			acRoute.Delete("/users/:userId/roles/:roleId", authorize(ac.ActionRolesWrite, ac.ScopeRolesAll), Wrap(RemoveUserRole))
+			acRoute.Get("/teams/:teamId/roles", authorize(ac.ActionRolesRead, ac.ScopeRolesAll), Wrap(GetTeamRoles))
+			acRoute.Post("/teams/:teamId/roles", authorize(ac.ActionRolesWrite, ac.ScopeRolesAll), bind(models.AddTeamRoleCommand{}), Wrap(AddTeamRole))
+			acRoute.Delete("/teams/:teamId/roles/:roleId", authorize(ac.ActionRolesWrite, ac.ScopeRolesAll), Wrap(RemoveTeamRole))
+		})
+
 		// Preferences
 		apiRoute.Group("/preferences", func(prefRoute routing.RouteRegister) {
 			prefRoute.Post("/set-home-dash", bind(models.SavePreferencesCommand{}), Wrap(SetHomeDashboard))
 		})
 
 		// Data sources
+		// handlers check the scopes of the data sources they get
 		apiRoute.Group("/datasources", func(datasourceRoute routing.RouteRegister) {
-			datasourceRoute.Get("/", Wrap(GetDataSources))
-			datasourceRoute.Post("/", quota("data_source"), bind(models.AddDataSourceCommand{}), Wrap(AddDataSource))
-			datasourceRoute.Put("/:id", bind(models.UpdateDataSourceCommand{}), Wrap(UpdateDataSource))
-			datasourceRoute.Delete("/:id", Wrap(DeleteDataSourceById))
-			datasourceRoute.Delete("/name/:name", Wrap(DeleteDataSourceByName))
-			datasourceRoute.Get("/:id", Wrap(GetDataSourceById))
-			datasourceRoute.Get("/name/:name", Wrap(GetDataSourceByName))
-		}, reqOrgAdmin)
+			datasourceRoute.Get("/", authorize(ac.ActionDatasourcesRead), Wrap(GetDataSources))
+			datasourceRoute.Post("/", authorize(ac.ActionDatasourcesCreate), quota("data_source"), bind(models.AddDataSourceCommand{}), Wrap(AddDataSource))
+			datasourceRoute.Put("/:id", authorize(ac.ActionDatasourcesWrite), bind(models.UpdateDataSourceCommand{}), Wrap(UpdateDataSource))
+			datasourceRoute.Delete("/:id", authorize(ac.ActionDatasourcesDelete), Wrap(DeleteDataSourceById))
+			datasourceRoute.Delete("/name/:name", authorize(ac.ActionDatasourcesDelete), Wrap(DeleteDataSourceByName))
+			datasourceRoute.Get("/:id", authorize(ac.ActionDatasourcesRead), Wrap(GetDataSourceById))
+			datasourceRoute.Get("/name/:name", authorize(ac.ActionDatasourcesRead), Wrap(GetDataSourceByName))
+			datasourceRoute.Post("/:id/enable-permissions", Wrap(EnableDataSourcePermissions))
+			datasourceRoute.Post("/:id/disable-permissions", Wrap(DisableDataSourcePermissions))
+			datasourceRoute.Get("/:id/permissions", Wrap(GetDataSourcePermissions))
+			datasourceRoute.Post("/:id/permissions", bind(models.AddDataSourcePermissionCommand{}), Wrap(AddDataSourcePermission))
+			datasourceRoute.Delete("/:id/permissions/:permissionId", Wrap(RemoveDataSourcePermission))
+		})
 
 		apiRoute.Get("/datasources/id/:name", Wrap(GetDataSourceIdByName), reqSignedIn)
 
@@ -344,25 +383,51 @@ func (hs *HTTPServer) registerRoutes() {
 
 		apiRoute.Group("/alerts", func(alertsRoute routing.RouteRegister) {
 			alertsRoute.Post("/test", bind(dtos.AlertTestCommand{}), Wrap(AlertTest))
+			alertsRoute.Group("/silences", func(silencesRoute routing.RouteRegister) {
+				silencesRoute.Get("/", Wrap(GetAlertSilences))
+				silencesRoute.Post("/", reqEditorRole, bind(models.CreateAlertSilenceCommand{}), Wrap(CreateAlertSilence))
+				silencesRoute.Get("/:silenceId", Wrap(GetAlertSilenceByID))
+				silencesRoute.Put("/:silenceId", reqEditorRole, bind(models.UpdateAlertSilenceCommand{}), Wrap(UpdateAlertSilence))
+				silencesRoute.Delete("/:silenceId", reqEditorRole, Wrap(DeleteAlertSilence))
+			})
+			alertsRoute.Get("/history", Wrap(GetAlertStateHistory))
 			alertsRoute.Post("/:alertId/pause", reqEditorRole, bind(dtos.PauseAlertCommand{}), Wrap(PauseAlert))
+			alertsRoute.Get("/:alertId/history", ValidateOrgAlert, Wrap(GetAlertStateHistoryByAlertID))
 			alertsRoute.Get("/:alertId", ValidateOrgAlert, Wrap(GetAlert))
 			alertsRoute.Get("/", Wrap(GetAlerts))
 			alertsRoute.Get("/states-for-dashboard", Wrap(GetAlertStatesForDashboard))
 		})
 
-		apiRoute.Get("/alert-notifiers", reqEditorRole, Wrap(GetAlertNotifiers))
+		apiRoute.Group("/recording-rules", func(recordingRulesRoute routing.RouteRegister) {
+			recordingRulesRoute.Get("/", Wrap(GetRecordingRules))
+			recordingRulesRoute.Post("/", reqEditorRole, bind(models.CreateRecordingRuleCommand{}), Wrap(hs.CreateRecordingRule))
+			recordingRulesRoute.Get("/:ruleId", Wrap(GetRecordingRuleByID))
+			recordingRulesRoute.Put("/:ruleId", reqEditorRole, bind(models.UpdateRecordingRuleCommand{}), Wrap(hs.UpdateRecordingRule))
+			recordingRulesRoute.Delete("/:ruleId", reqEditorRole, Wrap(DeleteRecordingRule))
+		})
 
+		// Alertmanager compatible alerts
+		apiRoute.Get("/v2/alerts", Wrap(GetExternalAlerts))
+		apiRoute.Post("/v2/alerts", reqEditorRole, bind(dtos.PostableAlerts{}), Wrap(PostExternalAlerts))
+
+		apiRoute.Get("/alert-notifiers", authorize(ac.ActionAlertNotificationsRead, ac.ScopeAlertNotificationsAll), Wrap(GetAlertNotifiers))
+
+		reqAlertNotificationsRead := authorize(ac.ActionAlertNotificationsRead, ac.ScopeAlertNotificationsAll)
+		reqAlertNotificationsWrite := authorize(ac.ActionAlertNotificationsWrite, ac.ScopeAlertNotificationsAll)
 		apiRoute.Group("/alert-notifications", func(alertNotifications routing.RouteRegister) {
-			alertNotifications.Get("/", Wrap(GetAlertNotifications))
-			alertNotifications.Post("/test", bind(dtos.NotificationTestCommand{}), Wrap(NotificationTest))
-			alertNotifications.Post("/", bind(models.CreateAlertNotificationCommand{}), Wrap(CreateAlertNotification))
-			alertNotifications.Put("/:notificationId", bind(models.UpdateAlertNotificationCommand{}), Wrap(UpdateAlertNotification))
-			alertNotifications.Get("/:notificationId", Wrap(GetAlertNotificationByID))
-			alertNotifications.Delete("/:notificationId", Wrap(DeleteAlertNotification))
-			alertNotifications.Get("/uid/:uid", Wrap(GetAlertNotificationByUID))
-			alertNotifications.Put("/uid/:uid", bind(models.UpdateAlertNotificationWithUidCommand{}), Wrap(UpdateAlertNotificationByUID))
-			alertNotifications.Delete("/uid/:uid", Wrap(DeleteAlertNotificationByUID))
-		}, reqEditorRole)
+			alertNotifications.Get("/", reqAlertNotificationsRead, Wrap(GetAlertNotifications))
+			alertNotifications.Post("/test", reqAlertNotificationsWrite, bind(dtos.NotificationTestCommand{}), Wrap(NotificationTest))
+			alertNotifications.Get("/policy", reqAlertNotificationsRead, Wrap(GetAlertNotificationPolicy))
+			alertNotifications.Put("/policy", reqAlertNotificationsWrite, bind(models.SaveAlertNotificationPolicyCommand{}), Wrap(SaveAlertNotificationPolicy))
+			alertNotifications.Delete("/policy", reqAlertNotificationsWrite, Wrap(DeleteAlertNotificationPolicy))
+			alertNotifications.Post("/", reqAlertNotificationsWrite, bind(models.CreateAlertNotificationCommand{}), Wrap(CreateAlertNotification))
+			alertNotifications.Put("/:notificationId", reqAlertNotificationsWrite, bind(models.UpdateAlertNotificationCommand{}), Wrap(UpdateAlertNotification))
+			alertNotifications.Get("/:notificationId", reqAlertNotificationsRead, Wrap(GetAlertNotificationByID))
+			alertNotifications.Delete("/:notificationId", reqAlertNotificationsWrite, Wrap(DeleteAlertNotification))
+			alertNotifications.Get("/uid/:uid", reqAlertNotificationsRead, Wrap(GetAlertNotificationByUID))
+			alertNotifications.Put("/uid/:uid", reqAlertNotificationsWrite, bind(models.UpdateAlertNotificationWithUidCommand{}), Wrap(UpdateAlertNotificationByUID))
+			alertNotifications.Delete("/uid/:uid", reqAlertNotificationsWrite, Wrap(DeleteAlertNotificationByUID))
+		})
 
 		// alert notifications without requirement of user to be org editor
 		apiRoute.Group("/alert-notifications", func(orgRoute routing.RouteRegister) {
@@ -396,6 +461,7 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Get("/users/:id/quotas", Wrap(GetUserQuotas))
 		adminRoute.Put("/users/:id/quotas/:target", bind(models.UpdateUserQuotaCmd{}), Wrap(UpdateUserQuota))
 		adminRoute.Get("/stats", AdminGetStats)
+		adminRoute.Get("/audit", Wrap(AdminSearchAuditEntries))
 		adminRoute.Post("/pause-all-alerts", bind(dtos.PauseAllAlertsCommand{}), Wrap(PauseAllAlerts))
 
 		adminRoute.Post("/users/:id/logout", Wrap(hs.AdminLogoutUser))
@@ -406,6 +472,7 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/provisioning/plugins/reload", Wrap(hs.AdminProvisioningReloadPlugins))
 		adminRoute.Post("/provisioning/datasources/reload", Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", Wrap(hs.AdminProvisioningReloadNotifications))
+		adminRoute.Post("/provisioning/alerts/reload", Wrap(hs.AdminProvisioningReloadAlerts))
 		adminRoute.Post("/ldap/reload", Wrap(hs.ReloadLDAPCfg))
 		adminRoute.Post("/ldap/sync/:id", Wrap(hs.PostSyncUserWithLDAP))
 		adminRoute.Get("/ldap/:username", Wrap(hs.GetUserFromLDAP))
@@ -153,7 +153,7 @@ func setupScenarioContext(url string) *scenarioContext {
 		Delims:    macaron.Delims{Left: "[[", Right: "]]"},
 	}))
 
-	sc.m.Use(middleware.GetContextHandler(nil, nil, nil))
+	sc.m.Use(middleware.GetContextHandler(nil, nil, nil, nil))
 
 	return sc
 }
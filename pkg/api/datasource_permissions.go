@@ -0,0 +1,145 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// GET /api/datasources/:id/permissions
+func GetDataSourcePermissions(c *models.ReqContext) Response {
+	ds, rsp := getDataSourceForPermissions(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	query := models.GetDataSourcePermissionsQuery{DataSourceId: ds.Id, OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get datasource permissions", err)
+	}
+
+	for _, perm := range query.Result {
+		if perm.UserId > 0 {
+			perm.UserAvatarUrl = dtos.GetGravatarUrl(perm.UserEmail)
+		}
+		if perm.TeamId > 0 {
+			perm.TeamAvatarUrl = dtos.GetGravatarUrlWithDefault(perm.TeamEmail, perm.Team)
+		}
+	}
+
+	return JSON(200, &models.DataSourcePermissionsDTO{
+		DataSourceId: ds.Id,
+		Enabled:      ds.PermissionsEnabled,
+		Permissions:  query.Result,
+	})
+}
+
+// POST /api/datasources/:id/enable-permissions
+func EnableDataSourcePermissions(c *models.ReqContext) Response {
+	ds, rsp := getDataSourceForPermissions(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := models.EnableDataSourcePermissionsCommand{DataSourceId: ds.Id, OrgId: c.OrgId}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return Error(500, "Failed to enable datasource permissions", err)
+	}
+
+	return Success("Datasource permissions enabled")
+}
+
+// POST /api/datasources/:id/disable-permissions
+func DisableDataSourcePermissions(c *models.ReqContext) Response {
+	ds, rsp := getDataSourceForPermissions(c, true)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := models.DisableDataSourcePermissionsCommand{DataSourceId: ds.Id, OrgId: c.OrgId}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return Error(500, "Failed to disable datasource permissions", err)
+	}
+
+	return Success("Datasource permissions disabled")
+}
+
+// POST /api/datasources/:id/permissions
+func AddDataSourcePermission(c *models.ReqContext, cmd models.AddDataSourcePermissionCommand) Response {
+	ds, rsp := getDataSourceForPermissions(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd.DataSourceId = ds.Id
+	cmd.OrgId = c.OrgId
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		switch err {
+		case models.ErrDataSourcePermissionUserOrTeam, models.ErrDataSourcePermissionInvalid,
+			models.ErrDataSourcePermissionsNotEnabled, models.ErrDataSourcePermissionTargetNotFound:
+			return Error(400, err.Error(), err)
+		}
+		return Error(500, "Failed to add datasource permission", err)
+	}
+
+	return Success("Datasource permission added")
+}
+
+// DELETE /api/datasources/:id/permissions/:permissionId
+func RemoveDataSourcePermission(c *models.ReqContext) Response {
+	ds, rsp := getDataSourceForPermissions(c, false)
+	if rsp != nil {
+		return rsp
+	}
+
+	cmd := models.RemoveDataSourcePermissionCommand{
+		Id:           c.ParamsInt64(":permissionId"),
+		DataSourceId: ds.Id,
+		OrgId:        c.OrgId,
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrDataSourcePermissionNotFound {
+			return Error(404, "Datasource permission not found", err)
+		}
+		return Error(500, "Failed to remove datasource permission", err)
+	}
+
+	return Success("Datasource permission removed")
+}
+
+// getDataSourceForPermissions gets the data source of the request, if the user
+// can manage its permissions. Users who can write the data source can manage
+// its permissions, and users with the Admin permission on it can manage its
+// acl but not enable or disable it.
+func getDataSourceForPermissions(c *models.ReqContext, toggle bool) (*models.DataSource, Response) {
+	ds, err := getRawDataSourceById(c.ParamsInt64(":id"), c.OrgId)
+	if err != nil {
+		if err == models.ErrDataSourceNotFound {
+			return nil, Error(404, "Data source not found", nil)
+		}
+		return nil, Error(500, "Failed to query datasources", err)
+	}
+
+	canWrite, err := accesscontrol.HasAccess(c.SignedInUser, accesscontrol.ActionDatasourcesWrite, accesscontrol.ScopesDatasource(ds)...)
+	if err != nil {
+		return nil, Error(500, "Failed to evaluate permissions", err)
+	}
+	if canWrite {
+		return ds, nil
+	}
+
+	if !toggle && ds.PermissionsEnabled && c.UserId != 0 {
+		query := models.GetDataSourcePermissionForUserQuery{DataSourceId: ds.Id, OrgId: c.OrgId, UserId: c.UserId}
+		if err := bus.Dispatch(&query); err != nil {
+			return nil, Error(500, "Failed to get datasource permissions", err)
+		}
+		if query.Result == models.DsPermissionAdmin {
+			return ds, nil
+		}
+	}
+
+	return nil, Error(403, "Access denied to datasource permissions", nil)
+}
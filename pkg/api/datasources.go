@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/datasource/wrapper"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -25,8 +26,17 @@ func GetDataSources(c *models.ReqContext) Response {
 		return Error(500, "Failed to query datasources", err)
 	}
 
+	permissions, err := accesscontrol.GetUserPermissions(c.SignedInUser)
+	if err != nil {
+		return Error(500, "Failed to evaluate permissions", err)
+	}
+
 	result := make(dtos.DataSourceList, 0)
 	for _, ds := range query.Result {
+		if !accesscontrol.Evaluate(permissions, accesscontrol.ActionDatasourcesRead, accesscontrol.ScopesDatasource(ds)...) {
+			continue
+		}
+
 		dsItem := dtos.DataSourceListItemDTO{
 			OrgId:     ds.OrgId,
 			Id:        ds.Id,
@@ -71,6 +81,10 @@ func GetDataSourceById(c *models.ReqContext) Response {
 	}
 
 	ds := query.Result
+	if resp := checkDataSourceAccess(c, accesscontrol.ActionDatasourcesRead, ds); resp != nil {
+		return resp
+	}
+
 	dtos := convertModelToDtos(ds)
 
 	return JSON(200, &dtos)
@@ -88,13 +102,17 @@ func DeleteDataSourceById(c *models.ReqContext) Response {
 		return Error(400, "Failed to delete datasource", nil)
 	}
 
+	if resp := checkDataSourceAccess(c, accesscontrol.ActionDatasourcesDelete, ds); resp != nil {
+		return resp
+	}
+
 	if ds.ReadOnly {
 		return Error(403, "Cannot delete read-only data source", nil)
 	}
 
 	cmd := &models.DeleteDataSourceByIdCommand{Id: id, OrgId: c.OrgId}
 
-	err = bus.Dispatch(cmd)
+	err = bus.DispatchCtx(c.Req.Context(), cmd)
 	if err != nil {
 		return Error(500, "Failed to delete datasource", err)
 	}
@@ -117,12 +135,16 @@ func DeleteDataSourceByName(c *models.ReqContext) Response {
 		return Error(500, "Failed to delete datasource", err)
 	}
 
+	if resp := checkDataSourceAccess(c, accesscontrol.ActionDatasourcesDelete, getCmd.Result); resp != nil {
+		return resp
+	}
+
 	if getCmd.Result.ReadOnly {
 		return Error(403, "Cannot delete read-only data source", nil)
 	}
 
 	cmd := &models.DeleteDataSourceByNameCommand{Name: name, OrgId: c.OrgId}
-	err := bus.Dispatch(cmd)
+	err := bus.DispatchCtx(c.Req.Context(), cmd)
 	if err != nil {
 		return Error(500, "Failed to delete datasource", err)
 	}
@@ -149,7 +171,7 @@ func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) Respon
 		return resp
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrDataSourceNameExists || err == models.ErrDataSourceUidExists {
 			return Error(409, err.Error(), err)
 		}
@@ -174,12 +196,24 @@ func UpdateDataSource(c *models.ReqContext, cmd models.UpdateDataSourceCommand)
 		return resp
 	}
 
-	err := fillWithSecureJSONData(&cmd)
+	ds, err := getRawDataSourceById(cmd.Id, cmd.OrgId)
 	if err != nil {
+		if err == models.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", nil)
+		}
 		return Error(500, "Failed to update datasource", err)
 	}
 
-	err = bus.Dispatch(&cmd)
+	if resp := checkDataSourceAccess(c, accesscontrol.ActionDatasourcesWrite, ds); resp != nil {
+		return resp
+	}
+
+	err = fillWithSecureJSONData(&cmd)
+	if err != nil {
+		return Error(500, "Failed to update datasource", err)
+	}
+
+	err = bus.DispatchCtx(c.Req.Context(), &cmd)
 	if err != nil {
 		if err == models.ErrDataSourceUpdatingOldVersion {
 			return Error(500, "Failed to update datasource. Reload new version and try again", err)
@@ -257,6 +291,10 @@ func GetDataSourceByName(c *models.ReqContext) Response {
 		return Error(500, "Failed to query datasources", err)
 	}
 
+	if resp := checkDataSourceAccess(c, accesscontrol.ActionDatasourcesRead, query.Result); resp != nil {
+		return resp
+	}
+
 	dtos := convertModelToDtos(query.Result)
 	return JSON(200, &dtos)
 }
@@ -314,6 +352,20 @@ func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
 	hs.BackendPluginManager.CallResource(pCtx, c, c.Params("*"))
 }
 
+// checkDataSourceAccess checks an action on a data source, for users who have
+// been granted the action on some data sources only.
+func checkDataSourceAccess(c *models.ReqContext, action string, ds *models.DataSource) Response {
+	hasAccess, err := accesscontrol.HasAccess(c.SignedInUser, action, accesscontrol.ScopesDatasource(ds)...)
+	if err != nil {
+		return Error(500, "Failed to evaluate permissions", err)
+	}
+	if !hasAccess {
+		return Error(403, "Access denied to datasource", nil)
+	}
+
+	return nil
+}
+
 func convertModelToDtos(ds *models.DataSource) dtos.DataSource {
 	dto := dtos.DataSource{
 		Id:                ds.Id,
@@ -87,6 +87,112 @@ type AlertNotification struct {
 	SecureFields          map[string]bool  `json:"secureFields"`
 }
 
+func NewAlertSilence(silence *models.AlertSilence) *AlertSilence {
+	dto := &AlertSilence{
+		Id:           silence.Id,
+		RuleName:     silence.RuleName,
+		DashboardUid: silence.DashboardUid,
+		FolderUid:    silence.FolderUid,
+		Tags:         silence.Tags,
+		StartsAt:     silence.StartsAt,
+		EndsAt:       silence.EndsAt,
+		Cron:         silence.Cron,
+		Comment:      silence.Comment,
+		CreatedBy:    silence.CreatedBy,
+		Created:      silence.Created,
+		Updated:      silence.Updated,
+	}
+
+	if silence.Cron != "" {
+		dto.RepeatDuration = formatShort(silence.RepeatDuration)
+	}
+
+	if dto.Tags == nil {
+		dto.Tags = map[string]string{}
+	}
+
+	return dto
+}
+
+type AlertSilence struct {
+	Id             int64             `json:"id"`
+	RuleName       string            `json:"ruleName"`
+	DashboardUid   string            `json:"dashboardUid"`
+	FolderUid      string            `json:"folderUid"`
+	Tags           map[string]string `json:"tags"`
+	StartsAt       time.Time         `json:"startsAt"`
+	EndsAt         time.Time         `json:"endsAt"`
+	Cron           string            `json:"cron"`
+	RepeatDuration string            `json:"repeatDuration"`
+	Comment        string            `json:"comment"`
+	CreatedBy      int64             `json:"createdBy"`
+	Created        time.Time         `json:"created"`
+	Updated        time.Time         `json:"updated"`
+}
+
+func NewAlertNotificationPolicy(policy *models.AlertNotificationPolicy) *AlertNotificationPolicy {
+	return &AlertNotificationPolicy{
+		Policy:  policy.Policy,
+		Created: policy.Created,
+		Updated: policy.Updated,
+	}
+}
+
+type AlertNotificationPolicy struct {
+	Policy  *models.NotificationPolicy `json:"policy"`
+	Created time.Time                  `json:"created"`
+	Updated time.Time                  `json:"updated"`
+}
+
+func NewAlertStateHistory(history *models.AlertStateHistory) *AlertStateHistory {
+	dto := &AlertStateHistory{
+		Id:               history.Id,
+		AlertId:          history.AlertId,
+		DashboardId:      history.DashboardId,
+		PanelId:          history.PanelId,
+		RuleName:         history.RuleName,
+		PrevState:        history.PrevState,
+		NewState:         history.NewState,
+		EvalMatches:      history.EvalMatches,
+		ChangedInstances: history.ChangedInstances,
+		Error:            history.Error,
+		Notifications:    history.Notifications,
+		Time:             history.Epoch,
+	}
+
+	if dto.Notifications == nil {
+		dto.Notifications = models.AlertStateHistoryNotifications{}
+	}
+
+	if dto.ChangedInstances == nil {
+		dto.ChangedInstances = simplejson.NewFromAny([]interface{}{})
+	}
+
+	return dto
+}
+
+type AlertStateHistory struct {
+	Id               int64                                 `json:"id"`
+	AlertId          int64                                 `json:"alertId"`
+	DashboardId      int64                                 `json:"dashboardId"`
+	PanelId          int64                                 `json:"panelId"`
+	RuleName         string                                `json:"ruleName"`
+	PrevState        models.AlertStateType                 `json:"prevState"`
+	NewState         models.AlertStateType                 `json:"newState"`
+	EvalMatches      *simplejson.Json                      `json:"evalMatches"`
+	ChangedInstances *simplejson.Json                      `json:"changedInstances"`
+	Error            string                                `json:"error"`
+	Notifications    models.AlertStateHistoryNotifications `json:"notifications"`
+	Time             int64                                 `json:"time"`
+}
+
+type AlertStateHistoryResult struct {
+	TotalCount int64                `json:"totalCount"`
+	History    []*AlertStateHistory `json:"history"`
+	Page       int                  `json:"page"`
+	PerPage    int                  `json:"perPage"`
+}
+
 func NewAlertNotificationLookup(notification *models.AlertNotification) *AlertNotificationLookup {
 	return &AlertNotificationLookup{
 		Id:        notification.Id,
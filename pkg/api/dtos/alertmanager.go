@@ -0,0 +1,40 @@
+package dtos
+
+import (
+	"time"
+)
+
+// PostableAlert is an alert as sent by Prometheus and other clients of
+// the Alertmanager v2 API.
+type PostableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+}
+
+type PostableAlerts []PostableAlert
+
+// GettableAlert is an alert as returned by the Alertmanager v2 API.
+type GettableAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+	Status       AlertStatus       `json:"status"`
+	Receivers    []Receiver        `json:"receivers"`
+}
+
+type AlertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+type Receiver struct {
+	Name string `json:"name"`
+}
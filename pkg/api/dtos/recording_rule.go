@@ -0,0 +1,46 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func NewRecordingRule(rule *models.RecordingRule) *RecordingRule {
+	dto := &RecordingRule{
+		Id:           rule.Id,
+		Name:         rule.Name,
+		DatasourceId: rule.DatasourceId,
+		Query:        rule.Query,
+		QueryRange:   rule.QueryRange,
+		Frequency:    formatShort(time.Duration(rule.Frequency) * time.Second),
+		Reducer:      rule.Reducer,
+		Labels:       rule.Labels,
+		Paused:       rule.Paused,
+		CreatedBy:    rule.CreatedBy,
+		Created:      rule.Created,
+		Updated:      rule.Updated,
+	}
+
+	if dto.Labels == nil {
+		dto.Labels = map[string]string{}
+	}
+
+	return dto
+}
+
+type RecordingRule struct {
+	Id           int64             `json:"id"`
+	Name         string            `json:"name"`
+	DatasourceId int64             `json:"datasourceId"`
+	Query        *simplejson.Json  `json:"query"`
+	QueryRange   string            `json:"range"`
+	Frequency    string            `json:"frequency"`
+	Reducer      string            `json:"reducer"`
+	Labels       map[string]string `json:"labels"`
+	Paused       bool              `json:"paused"`
+	CreatedBy    int64             `json:"createdBy"`
+	Created      time.Time         `json:"created"`
+	Updated      time.Time         `json:"updated"`
+}
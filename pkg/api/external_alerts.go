@@ -0,0 +1,94 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+// GET /api/v2/alerts
+func GetExternalAlerts(c *models.ReqContext) Response {
+	now := time.Now()
+	query := &models.GetExternalAlertsQuery{OrgId: c.OrgId, EndedAfter: now}
+	if err := bus.Dispatch(query); err != nil {
+		return Error(500, "Failed to get alerts", err)
+	}
+
+	result := make([]*dtos.GettableAlert, 0, len(query.Result))
+	for _, alert := range query.Result {
+		if !alert.IsFiring(now) {
+			continue
+		}
+
+		silence, err := alerting.FindExternalAlertSilence(c.Req.Context(), alert, now)
+		if err != nil {
+			return Error(500, "Failed to get alert silences", err)
+		}
+
+		status := dtos.AlertStatus{State: "active", SilencedBy: []string{}, InhibitedBy: []string{}}
+		if silence != nil {
+			status.State = "suppressed"
+			status.SilencedBy = append(status.SilencedBy, strconv.FormatInt(silence.Id, 10))
+		}
+
+		result = append(result, &dtos.GettableAlert{
+			Labels:       alert.Labels,
+			Annotations:  alert.Annotations,
+			StartsAt:     alert.StartsAt,
+			EndsAt:       alert.EndsAt,
+			UpdatedAt:    alert.Updated,
+			GeneratorURL: alert.GeneratorUrl,
+			Fingerprint:  alert.Fingerprint,
+			Status:       status,
+			Receivers:    []dtos.Receiver{},
+		})
+	}
+
+	return JSON(200, result)
+}
+
+// POST /api/v2/alerts
+func PostExternalAlerts(c *models.ReqContext, alerts dtos.PostableAlerts) Response {
+	now := time.Now()
+	cmd := &models.SaveExternalAlertsCommand{OrgId: c.OrgId}
+
+	for _, alert := range alerts {
+		if len(alert.Labels) == 0 {
+			return Error(400, "Alert must have at least one label", nil)
+		}
+
+		startsAt := alert.StartsAt
+		if startsAt.IsZero() {
+			startsAt = now
+		}
+
+		endsAt := alert.EndsAt
+		if endsAt.IsZero() {
+			endsAt = now.Add(models.ExternalAlertResolveTimeout)
+		}
+
+		if endsAt.Before(startsAt) {
+			return Error(400, "Alert must end after it starts", nil)
+		}
+
+		cmd.Alerts = append(cmd.Alerts, &models.ExternalAlert{
+			OrgId:        c.OrgId,
+			Fingerprint:  models.ExternalAlertFingerprint(alert.Labels),
+			Labels:       alert.Labels,
+			Annotations:  alert.Annotations,
+			StartsAt:     startsAt,
+			EndsAt:       endsAt,
+			GeneratorUrl: alert.GeneratorURL,
+		})
+	}
+
+	if err := bus.Dispatch(cmd); err != nil {
+		return Error(500, "Failed to save alerts", err)
+	}
+
+	return Success("Alerts received")
+}
@@ -57,7 +57,7 @@ func GetFolderByID(c *models.ReqContext) Response {
 
 func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolderCommand) Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser)
-	err := s.CreateFolder(&cmd)
+	err := s.CreateFolder(c.Req.Context(), &cmd)
 	if err != nil {
 		return toFolderError(err)
 	}
@@ -74,7 +74,7 @@ func (hs *HTTPServer) CreateFolder(c *models.ReqContext, cmd models.CreateFolder
 
 func UpdateFolder(c *models.ReqContext, cmd models.UpdateFolderCommand) Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser)
-	err := s.UpdateFolder(c.Params(":uid"), &cmd)
+	err := s.UpdateFolder(c.Req.Context(), c.Params(":uid"), &cmd)
 	if err != nil {
 		return toFolderError(err)
 	}
@@ -85,7 +85,7 @@ func UpdateFolder(c *models.ReqContext, cmd models.UpdateFolderCommand) Response
 
 func DeleteFolder(c *models.ReqContext) Response {
 	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser)
-	f, err := s.DeleteFolder(c.Params(":uid"))
+	f, err := s.DeleteFolder(c.Req.Context(), c.Params(":uid"))
 	if err != nil {
 		return toFolderError(err)
 	}
@@ -26,6 +26,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/login"
@@ -71,6 +72,7 @@ type HTTPServer struct {
 	BackendPluginManager backendplugin.Manager            `inject:""`
 	PluginManager        *plugins.PluginManager           `inject:""`
 	SearchService        *search.SearchService            `inject:""`
+	JWTAuthService       *jwt.AuthService                 `inject:""`
 }
 
 func (hs *HTTPServer) Init() error {
@@ -319,6 +321,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 		hs.AuthTokenService,
 		hs.RemoteCacheService,
 		hs.RenderService,
+		hs.JWTAuthService,
 	))
 	m.Use(middleware.OrgRedirect())
 
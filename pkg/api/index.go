@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -270,7 +271,12 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 
 	configNodes := []*dtos.NavLink{}
 
-	if c.OrgRole == models.ROLE_ADMIN {
+	permissions, err := accesscontrol.GetUserPermissions(c.SignedInUser)
+	if err != nil {
+		return nil, err
+	}
+
+	if accesscontrol.Evaluate(permissions, accesscontrol.ActionDatasourcesRead) {
 		configNodes = append(configNodes, &dtos.NavLink{
 			Text:        "Data Sources",
 			Icon:        "database",
@@ -278,6 +284,9 @@ func (hs *HTTPServer) setIndexViewData(c *models.ReqContext) (*dtos.IndexViewDat
 			Id:          "datasources",
 			Url:         setting.AppSubUrl + "/datasources",
 		})
+	}
+
+	if accesscontrol.Evaluate(permissions, accesscontrol.ActionUsersRead, accesscontrol.ScopeUsersAll) {
 		configNodes = append(configNodes, &dtos.NavLink{
 			Text:        "Users",
 			Id:          "users",
@@ -5,13 +5,13 @@ import (
 	"sort"
 
 	"github.com/grafana/grafana/pkg/models"
-	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/expressions"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -27,22 +27,33 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDto dtos.MetricReq
 		TimeRange: tsdb.NewTimeRange(reqDto.From, reqDto.To),
 		Debug:     reqDto.Debug,
 		User:      c.SignedInUser,
+		SkipCache: c.SkipCache,
 	}
 
 	expr := false
-	var ds *models.DataSource
-	for i, query := range reqDto.Queries {
-		name := query.Get("datasource").MustString("")
-		if name == "__expr__" {
+	for _, query := range reqDto.Queries {
+		if expressions.IsExpression(query) {
 			expr = true
 		}
+	}
 
+	if expr && !setting.IsExpressionsEnabled() {
+		return Error(404, "Expressions feature toggle is not enabled", nil)
+	}
+
+	var ds *models.DataSource
+	for i, query := range reqDto.Queries {
 		datasourceID, err := query.Get("datasourceId").Int64()
-		if err != nil {
+		if err != nil && !expressions.IsExpression(query) {
 			return Error(500, "datasource missing ID", nil)
 		}
 
-		if i == 0 && !expr {
+		// requests with expressions can query several data sources, which are
+		// loaded per query to check the access of the user
+		switch {
+		case expressions.IsExpression(query):
+			ds = expressions.DataSource(c.OrgId)
+		case i == 0 || expr:
 			ds, err = hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
 			if err != nil {
 				if err == models.ErrDataSourceAccessDenied {
@@ -62,22 +73,13 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDto dtos.MetricReq
 		})
 	}
 
-	var resp *tsdb.Response
-	var err error
-	if !expr {
-		resp, err = tsdb.HandleRequest(c.Req.Context(), ds, request)
-		if err != nil {
-			return Error(500, "Metric request error", err)
-		}
-	} else {
-		if !setting.IsExpressionsEnabled() {
-			return Error(404, "Expressions feature toggle is not enabled", nil)
-		}
+	if expr {
+		ds = expressions.DataSource(c.OrgId)
+	}
 
-		resp, err = plugins.Transform.Transform(c.Req.Context(), request)
-		if err != nil {
-			return Error(500, "Transform request error", err)
-		}
+	resp, err := tsdb.HandleRequest(c.Req.Context(), ds, request)
+	if err != nil {
+		return Error(500, "Metric request error", err)
 	}
 
 	statusCode := 200
@@ -118,6 +120,7 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 		TimeRange: timeRange,
 		Debug:     reqDto.Debug,
 		User:      c.SignedInUser,
+		SkipCache: c.SkipCache,
 	}
 
 	for _, query := range reqDto.Queries {
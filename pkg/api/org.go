@@ -81,7 +81,7 @@ func CreateOrg(c *models.ReqContext, cmd models.CreateOrgCommand) Response {
 	}
 
 	cmd.UserId = c.UserId
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrOrgNameTaken {
 			return Error(409, "Organization name taken", err)
 		}
@@ -98,17 +98,17 @@ func CreateOrg(c *models.ReqContext, cmd models.CreateOrgCommand) Response {
 
 // PUT /api/org
 func UpdateOrgCurrent(c *models.ReqContext, form dtos.UpdateOrgForm) Response {
-	return updateOrgHelper(form, c.OrgId)
+	return updateOrgHelper(c, form, c.OrgId)
 }
 
 // PUT /api/orgs/:orgId
 func UpdateOrg(c *models.ReqContext, form dtos.UpdateOrgForm) Response {
-	return updateOrgHelper(form, c.ParamsInt64(":orgId"))
+	return updateOrgHelper(c, form, c.ParamsInt64(":orgId"))
 }
 
-func updateOrgHelper(form dtos.UpdateOrgForm, orgID int64) Response {
+func updateOrgHelper(c *models.ReqContext, form dtos.UpdateOrgForm, orgID int64) Response {
 	cmd := models.UpdateOrgCommand{Name: form.Name, OrgId: orgID}
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrOrgNameTaken {
 			return Error(400, "Organization name taken", err)
 		}
@@ -150,7 +150,7 @@ func updateOrgAddressHelper(form dtos.UpdateOrgAddressForm, orgID int64) Respons
 
 // GET /api/orgs/:orgId
 func DeleteOrgByID(c *models.ReqContext) Response {
-	if err := bus.Dispatch(&models.DeleteOrgCommand{Id: c.ParamsInt64(":orgId")}); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &models.DeleteOrgCommand{Id: c.ParamsInt64(":orgId")}); err != nil {
 		if err == models.ErrOrgNotFound {
 			return Error(404, "Failed to delete organization. ID not found", nil)
 		}
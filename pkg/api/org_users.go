@@ -8,17 +8,20 @@ import (
 
 // POST /api/org/users
 func AddOrgUserToCurrentOrg(c *models.ReqContext, cmd models.AddOrgUserCommand) Response {
+	if !c.OrgRole.Includes(cmd.Role) {
+		return Error(403, "Cannot assign a role higher than your own", nil)
+	}
 	cmd.OrgId = c.OrgId
-	return addOrgUserHelper(cmd)
+	return addOrgUserHelper(c, cmd)
 }
 
 // POST /api/orgs/:orgId/users
 func AddOrgUser(c *models.ReqContext, cmd models.AddOrgUserCommand) Response {
 	cmd.OrgId = c.ParamsInt64(":orgId")
-	return addOrgUserHelper(cmd)
+	return addOrgUserHelper(c, cmd)
 }
 
-func addOrgUserHelper(cmd models.AddOrgUserCommand) Response {
+func addOrgUserHelper(c *models.ReqContext, cmd models.AddOrgUserCommand) Response {
 	if !cmd.Role.IsValid() {
 		return Error(400, "Invalid role specified", nil)
 	}
@@ -33,7 +36,7 @@ func addOrgUserHelper(cmd models.AddOrgUserCommand) Response {
 
 	cmd.UserId = userToAdd.Id
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrOrgUserAlreadyAdded {
 			return Error(409, "User is already member of this organization", nil)
 		}
@@ -136,22 +139,28 @@ func getOrgUsersHelper(orgID int64, query string, limit int) ([]*models.OrgUserD
 func UpdateOrgUserForCurrentOrg(c *models.ReqContext, cmd models.UpdateOrgUserCommand) Response {
 	cmd.OrgId = c.OrgId
 	cmd.UserId = c.ParamsInt64(":userId")
-	return updateOrgUserHelper(cmd)
+	if !c.OrgRole.Includes(cmd.Role) {
+		return Error(403, "Cannot assign a role higher than your own", nil)
+	}
+	if resp := checkCanManageOrgUser(c, cmd.UserId); resp != nil {
+		return resp
+	}
+	return updateOrgUserHelper(c, cmd)
 }
 
 // PATCH /api/orgs/:orgId/users/:userId
 func UpdateOrgUser(c *models.ReqContext, cmd models.UpdateOrgUserCommand) Response {
 	cmd.OrgId = c.ParamsInt64(":orgId")
 	cmd.UserId = c.ParamsInt64(":userId")
-	return updateOrgUserHelper(cmd)
+	return updateOrgUserHelper(c, cmd)
 }
 
-func updateOrgUserHelper(cmd models.UpdateOrgUserCommand) Response {
+func updateOrgUserHelper(c *models.ReqContext, cmd models.UpdateOrgUserCommand) Response {
 	if !cmd.Role.IsValid() {
 		return Error(400, "Invalid role specified", nil)
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrLastOrgAdmin {
 			return Error(400, "Cannot change role so that there is no organization admin left", nil)
 		}
@@ -163,8 +172,13 @@ func updateOrgUserHelper(cmd models.UpdateOrgUserCommand) Response {
 
 // DELETE /api/org/users/:userId
 func RemoveOrgUserForCurrentOrg(c *models.ReqContext) Response {
-	return removeOrgUserHelper(&models.RemoveOrgUserCommand{
-		UserId:                   c.ParamsInt64(":userId"),
+	userID := c.ParamsInt64(":userId")
+	if resp := checkCanManageOrgUser(c, userID); resp != nil {
+		return resp
+	}
+
+	return removeOrgUserHelper(c, &models.RemoveOrgUserCommand{
+		UserId:                   userID,
 		OrgId:                    c.OrgId,
 		ShouldDeleteOrphanedUser: true,
 	})
@@ -172,14 +186,14 @@ func RemoveOrgUserForCurrentOrg(c *models.ReqContext) Response {
 
 // DELETE /api/orgs/:orgId/users/:userId
 func RemoveOrgUser(c *models.ReqContext) Response {
-	return removeOrgUserHelper(&models.RemoveOrgUserCommand{
+	return removeOrgUserHelper(c, &models.RemoveOrgUserCommand{
 		UserId: c.ParamsInt64(":userId"),
 		OrgId:  c.ParamsInt64(":orgId"),
 	})
 }
 
-func removeOrgUserHelper(cmd *models.RemoveOrgUserCommand) Response {
-	if err := bus.Dispatch(cmd); err != nil {
+func removeOrgUserHelper(c *models.ReqContext, cmd *models.RemoveOrgUserCommand) Response {
+	if err := bus.DispatchCtx(c.Req.Context(), cmd); err != nil {
 		if err == models.ErrLastOrgAdmin {
 			return Error(400, "Cannot remove last organization admin", nil)
 		}
@@ -192,3 +206,25 @@ func removeOrgUserHelper(cmd *models.RemoveOrgUserCommand) Response {
 
 	return Success("User removed from organization")
 }
+
+// checkCanManageOrgUser keeps users who manage org users through a custom role
+// from changing users with a higher role than their own.
+func checkCanManageOrgUser(c *models.ReqContext, userID int64) Response {
+	if c.OrgRole == models.ROLE_ADMIN {
+		return nil
+	}
+
+	query := models.GetSignedInUserQuery{UserId: userID, OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == models.ErrUserNotFound {
+			return Error(404, "User not found", nil)
+		}
+		return Error(500, "Failed to get organization user", err)
+	}
+
+	if !c.OrgRole.Includes(query.Result.OrgRole) {
+		return Error(403, "Cannot change a user with a higher role than your own", nil)
+	}
+
+	return nil
+}
@@ -252,7 +252,7 @@ func ImportDashboard(c *models.ReqContext, apiCmd dtos.ImportDashboardCommand) R
 		Dashboard: apiCmd.Dashboard,
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return dashboardSaveErrorToApiResponse(err)
 	}
 
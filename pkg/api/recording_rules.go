@@ -0,0 +1,120 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/recording-rules
+func GetRecordingRules(c *models.ReqContext) Response {
+	query := &models.GetRecordingRulesQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(query); err != nil {
+		return Error(500, "Failed to get recording rules", err)
+	}
+
+	result := make([]*dtos.RecordingRule, 0, len(query.Result))
+	for _, rule := range query.Result {
+		result = append(result, dtos.NewRecordingRule(rule))
+	}
+
+	return JSON(200, result)
+}
+
+// GET /api/recording-rules/:ruleId
+func GetRecordingRuleByID(c *models.ReqContext) Response {
+	query := &models.GetRecordingRuleByIdQuery{
+		Id:    c.ParamsInt64(":ruleId"),
+		OrgId: c.OrgId,
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		if err == models.ErrRecordingRuleNotFound {
+			return Error(404, "Recording rule not found", nil)
+		}
+		return Error(500, "Failed to get recording rule", err)
+	}
+
+	return JSON(200, dtos.NewRecordingRule(query.Result))
+}
+
+// POST /api/recording-rules
+func (hs *HTTPServer) CreateRecordingRule(c *models.ReqContext, cmd models.CreateRecordingRuleCommand) Response {
+	cmd.OrgId = c.OrgId
+	cmd.CreatedBy = c.UserId
+
+	if rsp := hs.validateRecordingRuleDatasource(c, cmd.DatasourceId); rsp != nil {
+		return rsp
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		return recordingRuleError(err, "Failed to create recording rule")
+	}
+
+	return JSON(200, dtos.NewRecordingRule(cmd.Result))
+}
+
+// PUT /api/recording-rules/:ruleId
+func (hs *HTTPServer) UpdateRecordingRule(c *models.ReqContext, cmd models.UpdateRecordingRuleCommand) Response {
+	cmd.Id = c.ParamsInt64(":ruleId")
+	cmd.OrgId = c.OrgId
+
+	if rsp := hs.validateRecordingRuleDatasource(c, cmd.DatasourceId); rsp != nil {
+		return rsp
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrRecordingRuleNotFound {
+			return Error(404, "Recording rule not found", nil)
+		}
+		return recordingRuleError(err, "Failed to update recording rule")
+	}
+
+	return JSON(200, dtos.NewRecordingRule(cmd.Result))
+}
+
+// DELETE /api/recording-rules/:ruleId
+func DeleteRecordingRule(c *models.ReqContext) Response {
+	cmd := &models.DeleteRecordingRuleCommand{
+		Id:    c.ParamsInt64(":ruleId"),
+		OrgId: c.OrgId,
+	}
+
+	if err := bus.Dispatch(cmd); err != nil {
+		return Error(500, "Failed to delete recording rule", err)
+	}
+
+	return Success("Recording rule deleted")
+}
+
+// validateRecordingRuleDatasource checks that the user can query the data
+// source of a rule, as rules are evaluated without a user and their results
+// can be read back from the remote write endpoint.
+func (hs *HTTPServer) validateRecordingRuleDatasource(c *models.ReqContext, datasourceID int64) Response {
+	if _, err := hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache); err != nil {
+		switch err {
+		case models.ErrDataSourceNotFound:
+			return Error(400, "Data source not found", nil)
+		case models.ErrDataSourceAccessDenied:
+			return Error(403, "Access denied to data source", err)
+		}
+		return Error(500, "Failed to get data source", err)
+	}
+
+	return nil
+}
+
+func recordingRuleError(err error, message string) Response {
+	switch err {
+	case models.ErrRecordingRuleInvalidName,
+		models.ErrRecordingRuleInvalidLabel,
+		models.ErrRecordingRuleInvalidFrequency,
+		models.ErrRecordingRuleInvalidRange,
+		models.ErrRecordingRuleInvalidReducer:
+		return Error(400, err.Error(), err)
+	case models.ErrRecordingRuleNameTaken:
+		return Error(409, err.Error(), err)
+	}
+
+	return Error(500, message, err)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	macaron "gopkg.in/macaron.v1"
+)
+
+type fakeDatasourceCache struct {
+	allowed map[int64]bool
+}
+
+func (c *fakeDatasourceCache) GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	allowed, ok := c.allowed[datasourceID]
+	if !ok {
+		return nil, models.ErrDataSourceNotFound
+	}
+	if !allowed {
+		return nil, models.ErrDataSourceAccessDenied
+	}
+	return &models.DataSource{Id: datasourceID, OrgId: user.OrgId}, nil
+}
+
+func TestRecordingRuleDatasourceAccess(t *testing.T) {
+	defer bus.ClearBusHandlers()
+
+	created := 0
+	bus.AddHandler("test", func(cmd *models.CreateRecordingRuleCommand) error {
+		created++
+		cmd.Result = &models.RecordingRule{Id: 1, OrgId: cmd.OrgId, DatasourceId: cmd.DatasourceId}
+		return nil
+	})
+
+	hs := &HTTPServer{DatasourceCache: &fakeDatasourceCache{allowed: map[int64]bool{1: true, 2: false}}}
+	c := &models.ReqContext{
+		Context:      &macaron.Context{},
+		SignedInUser: &models.SignedInUser{UserId: 3, OrgId: 1, OrgRole: models.ROLE_EDITOR},
+	}
+
+	t.Run("Should create rule on data source the user can query", func(t *testing.T) {
+		rsp := hs.CreateRecordingRule(c, models.CreateRecordingRuleCommand{DatasourceId: 1})
+		assert.Equal(t, 200, responseStatus(t, rsp))
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("Should deny rule on data source the user cannot query", func(t *testing.T) {
+		rsp := hs.CreateRecordingRule(c, models.CreateRecordingRuleCommand{DatasourceId: 2})
+		assert.Equal(t, 403, responseStatus(t, rsp))
+
+		rsp = hs.UpdateRecordingRule(c, models.UpdateRecordingRuleCommand{DatasourceId: 2})
+		assert.Equal(t, 403, responseStatus(t, rsp))
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("Should reject rule on unknown data source", func(t *testing.T) {
+		rsp := hs.CreateRecordingRule(c, models.CreateRecordingRuleCommand{DatasourceId: 5})
+		assert.Equal(t, 400, responseStatus(t, rsp))
+	})
+}
+
+func responseStatus(t *testing.T, rsp Response) int {
+	t.Helper()
+
+	nr, ok := rsp.(*NormalResponse)
+	require.True(t, ok, "should return *NormalResponse")
+	return nr.status
+}
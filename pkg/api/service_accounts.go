@@ -0,0 +1,165 @@
+package api
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GET /api/serviceaccounts
+func (hs *HTTPServer) GetServiceAccounts(c *models.ReqContext) Response {
+	query := models.GetServiceAccountsQuery{OrgId: c.OrgId}
+	if err := hs.Bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to list service accounts", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/serviceaccounts
+func (hs *HTTPServer) CreateServiceAccount(c *models.ReqContext, cmd models.CreateServiceAccountCommand) Response {
+	if !cmd.Role.IsValid() {
+		return Error(400, "Invalid role specified", nil)
+	}
+	cmd.OrgId = c.OrgId
+
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrServiceAccountNameTaken {
+			return Error(409, "Service account name taken", err)
+		}
+		return Error(500, "Failed to create service account", err)
+	}
+
+	return JSON(200, cmd.Result)
+}
+
+// GET /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) GetServiceAccountByID(c *models.ReqContext) Response {
+	query := models.GetServiceAccountByIdQuery{Id: c.ParamsInt64(":serviceAccountId"), OrgId: c.OrgId}
+	if err := hs.Bus.Dispatch(&query); err != nil {
+		if err == models.ErrServiceAccountNotFound {
+			return Error(404, "Service account not found", err)
+		}
+		return Error(500, "Failed to get service account", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// PATCH /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) UpdateServiceAccount(c *models.ReqContext, cmd models.UpdateServiceAccountCommand) Response {
+	if cmd.Role != "" && !cmd.Role.IsValid() {
+		return Error(400, "Invalid role specified", nil)
+	}
+	cmd.Id = c.ParamsInt64(":serviceAccountId")
+	cmd.OrgId = c.OrgId
+
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		switch err {
+		case models.ErrServiceAccountNotFound:
+			return Error(404, "Service account not found", err)
+		case models.ErrServiceAccountNameTaken:
+			return Error(409, "Service account name taken", err)
+		}
+		return Error(500, "Failed to update service account", err)
+	}
+
+	return Success("Service account updated")
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) DeleteServiceAccount(c *models.ReqContext) Response {
+	cmd := models.DeleteServiceAccountCommand{Id: c.ParamsInt64(":serviceAccountId"), OrgId: c.OrgId}
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrServiceAccountNotFound {
+			return Error(404, "Service account not found", err)
+		}
+		return Error(500, "Failed to delete service account", err)
+	}
+
+	return Success("Service account deleted")
+}
+
+// GET /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) GetServiceAccountTokens(c *models.ReqContext) Response {
+	query := models.GetServiceAccountTokensQuery{ServiceAccountId: c.ParamsInt64(":serviceAccountId"), OrgId: c.OrgId}
+	if err := hs.Bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to list service account tokens", err)
+	}
+
+	result := make([]*models.ServiceAccountTokenDTO, len(query.Result))
+	for i, t := range query.Result {
+		var expiration *time.Time
+		if t.Expires != nil {
+			v := time.Unix(*t.Expires, 0)
+			expiration = &v
+		}
+		result[i] = &models.ServiceAccountTokenDTO{
+			Id:         t.Id,
+			Name:       models.ServiceAccountTokenName(query.ServiceAccountId, t.Name),
+			Created:    t.Created,
+			Expiration: expiration,
+		}
+	}
+
+	return JSON(200, result)
+}
+
+// POST /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) AddServiceAccountToken(c *models.ReqContext, cmd models.AddServiceAccountTokenCommand) Response {
+	if hs.Cfg.ApiKeyMaxSecondsToLive != -1 {
+		if cmd.SecondsToLive == 0 {
+			return Error(400, "Number of seconds before expiration should be set", nil)
+		}
+		if cmd.SecondsToLive > hs.Cfg.ApiKeyMaxSecondsToLive {
+			return Error(400, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+	cmd.OrgId = c.OrgId
+	cmd.ServiceAccountId = c.ParamsInt64(":serviceAccountId")
+
+	newKeyInfo, err := apikeygen.New(cmd.OrgId, models.ServiceAccountTokenKeyName(cmd.ServiceAccountId, cmd.Name))
+	if err != nil {
+		return Error(500, "Generating service account token failed", err)
+	}
+
+	cmd.Key = newKeyInfo.HashedKey
+
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		switch err {
+		case models.ErrServiceAccountNotFound:
+			return Error(404, "Service account not found", err)
+		case models.ErrInvalidApiKeyExpiration:
+			return Error(400, err.Error(), nil)
+		case models.ErrDuplicateApiKey:
+			return Error(409, err.Error(), nil)
+		}
+		return Error(500, "Failed to add service account token", err)
+	}
+
+	return JSON(200, &util.DynMap{
+		"id":   cmd.Result.Id,
+		"name": cmd.Name,
+		"key":  newKeyInfo.ClientSecret,
+	})
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId/tokens/:tokenId
+func (hs *HTTPServer) DeleteServiceAccountToken(c *models.ReqContext) Response {
+	cmd := models.DeleteServiceAccountTokenCommand{
+		Id:               c.ParamsInt64(":tokenId"),
+		OrgId:            c.OrgId,
+		ServiceAccountId: c.ParamsInt64(":serviceAccountId"),
+	}
+
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		if err == models.ErrInvalidApiKey {
+			return Error(404, "Service account token not found", err)
+		}
+		return Error(500, "Failed to delete service account token", err)
+	}
+
+	return Success("Service account token deleted")
+}
@@ -16,7 +16,7 @@ func (hs *HTTPServer) CreateTeam(c *models.ReqContext, cmd models.CreateTeamComm
 		return Error(403, "Not allowed to create team.", nil)
 	}
 
-	if err := hs.Bus.Dispatch(&cmd); err != nil {
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrTeamNameTaken {
 			return Error(409, "Team name taken", err)
 		}
@@ -35,7 +35,7 @@ func (hs *HTTPServer) CreateTeam(c *models.ReqContext, cmd models.CreateTeamComm
 				Permission: models.PERMISSION_ADMIN,
 			}
 
-			if err := hs.Bus.Dispatch(&addMemberCmd); err != nil {
+			if err := hs.Bus.DispatchCtx(c.Req.Context(), &addMemberCmd); err != nil {
 				c.Logger.Error("Could not add creator to team.", "error", err)
 			}
 		} else {
@@ -58,7 +58,7 @@ func (hs *HTTPServer) UpdateTeam(c *models.ReqContext, cmd models.UpdateTeamComm
 		return Error(403, "Not allowed to update team", err)
 	}
 
-	if err := hs.Bus.Dispatch(&cmd); err != nil {
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if err == models.ErrTeamNameTaken {
 			return Error(400, "Team name taken", err)
 		}
@@ -78,7 +78,7 @@ func (hs *HTTPServer) DeleteTeamByID(c *models.ReqContext) Response {
 		return Error(403, "Not allowed to delete team", err)
 	}
 
-	if err := hs.Bus.Dispatch(&models.DeleteTeamCommand{OrgId: orgId, Id: teamId}); err != nil {
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &models.DeleteTeamCommand{OrgId: orgId, Id: teamId}); err != nil {
 		if err == models.ErrTeamNotFound {
 			return Error(404, "Failed to delete Team. ID not found", nil)
 		}
@@ -76,13 +76,13 @@ func UpdateSignedInUser(c *models.ReqContext, cmd models.UpdateUserCommand) Resp
 		}
 	}
 	cmd.UserId = c.UserId
-	return handleUpdateUser(cmd)
+	return handleUpdateUser(c, cmd)
 }
 
 // POST /api/users/:id
 func UpdateUser(c *models.ReqContext, cmd models.UpdateUserCommand) Response {
 	cmd.UserId = c.ParamsInt64(":id")
-	return handleUpdateUser(cmd)
+	return handleUpdateUser(c, cmd)
 }
 
 //POST /api/users/:id/using/:orgId
@@ -103,7 +103,7 @@ func UpdateUserActiveOrg(c *models.ReqContext) Response {
 	return Success("Active organization changed")
 }
 
-func handleUpdateUser(cmd models.UpdateUserCommand) Response {
+func handleUpdateUser(c *models.ReqContext, cmd models.UpdateUserCommand) Response {
 	if len(cmd.Login) == 0 {
 		cmd.Login = cmd.Email
 		if len(cmd.Login) == 0 {
@@ -111,7 +111,7 @@ func handleUpdateUser(cmd models.UpdateUserCommand) Response {
 		}
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		return Error(500, "Failed to update user", err)
 	}
 
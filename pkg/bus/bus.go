@@ -15,6 +15,10 @@ type CtxHandlerFunc func()
 // Msg defines a message interface.
 type Msg interface{}
 
+// DispatchHook is called before a message is handled. The function it returns,
+// if any, is called with the result of the handler.
+type DispatchHook func(ctx context.Context, msg Msg) func(err error)
+
 // ErrHandlerNotFound defines an error if a handler is not found
 var ErrHandlerNotFound = errors.New("handler not found")
 
@@ -38,6 +42,7 @@ type Bus interface {
 	AddHandler(handler HandlerFunc)
 	AddHandlerCtx(handler HandlerFunc)
 	AddEventListener(handler HandlerFunc)
+	AddDispatchHook(hook DispatchHook)
 
 	// SetTransactionManager allows the user to replace the internal
 	// noop TransactionManager that is responsible for managing
@@ -55,6 +60,7 @@ type InProcBus struct {
 	handlers        map[string]HandlerFunc
 	handlersWithCtx map[string]HandlerFunc
 	listeners       map[string][]HandlerFunc
+	dispatchHooks   []DispatchHook
 	txMng           TransactionManager
 }
 
@@ -83,28 +89,18 @@ func (b *InProcBus) SetTransactionManager(tm TransactionManager) {
 }
 
 // DispatchCtx function dispatch a message to the bus context.
+// Handlers registered without a context are used if the message has no
+// context handler.
 func (b *InProcBus) DispatchCtx(ctx context.Context, msg Msg) error {
-	var msgName = reflect.TypeOf(msg).Elem().Name()
-
-	var handler = b.handlersWithCtx[msgName]
-	if handler == nil {
-		return ErrHandlerNotFound
-	}
-
-	var params = []reflect.Value{}
-	params = append(params, reflect.ValueOf(ctx))
-	params = append(params, reflect.ValueOf(msg))
-
-	ret := reflect.ValueOf(handler).Call(params)
-	err := ret[0].Interface()
-	if err == nil {
-		return nil
-	}
-	return err.(error)
+	return b.dispatch(ctx, msg)
 }
 
 // Dispatch function dispatch a message to the bus.
 func (b *InProcBus) Dispatch(msg Msg) error {
+	return b.dispatch(context.Background(), msg)
+}
+
+func (b *InProcBus) dispatch(ctx context.Context, msg Msg) error {
 	var msgName = reflect.TypeOf(msg).Elem().Name()
 
 	var handler = b.handlersWithCtx[msgName]
@@ -119,18 +115,30 @@ func (b *InProcBus) Dispatch(msg Msg) error {
 		return ErrHandlerNotFound
 	}
 
+	afterHooks := make([]func(err error), 0, len(b.dispatchHooks))
+	for _, hook := range b.dispatchHooks {
+		if after := hook(ctx, msg); after != nil {
+			afterHooks = append(afterHooks, after)
+		}
+	}
+
 	var params = []reflect.Value{}
 	if withCtx {
-		params = append(params, reflect.ValueOf(context.Background()))
+		params = append(params, reflect.ValueOf(ctx))
 	}
 	params = append(params, reflect.ValueOf(msg))
 
 	ret := reflect.ValueOf(handler).Call(params)
-	err := ret[0].Interface()
-	if err == nil {
-		return nil
+	var err error
+	if errValue := ret[0].Interface(); errValue != nil {
+		err = errValue.(error)
+	}
+
+	for _, after := range afterHooks {
+		after(err)
 	}
-	return err.(error)
+
+	return err
 }
 
 // Publish function publish a message to the bus listener.
@@ -174,6 +182,10 @@ func (b *InProcBus) AddEventListener(handler HandlerFunc) {
 	b.listeners[eventName] = append(b.listeners[eventName], handler)
 }
 
+func (b *InProcBus) AddDispatchHook(hook DispatchHook) {
+	b.dispatchHooks = append(b.dispatchHooks, hook)
+}
+
 // AddHandler attaches a handler function to the global bus.
 // Package level function.
 func AddHandler(implName string, handler HandlerFunc) {
@@ -192,6 +204,12 @@ func AddEventListener(handler HandlerFunc) {
 	globalBus.AddEventListener(handler)
 }
 
+// AddDispatchHook attaches a dispatch hook to the global bus.
+// Package level function.
+func AddDispatchHook(hook DispatchHook) {
+	globalBus.AddDispatchHook(hook)
+}
+
 func Dispatch(msg Msg) error {
 	return globalBus.Dispatch(msg)
 }
@@ -77,6 +77,51 @@ func TestDispatchCtx_NoRegisteredHandler(t *testing.T) {
 		"expected bus to return HandlerNotFound since no handler is registered")
 }
 
+func TestDispatchCtx_Handler(t *testing.T) {
+	bus := New()
+
+	var invoked bool
+
+	bus.AddHandler(func(query *testQuery) error {
+		invoked = true
+		return nil
+	})
+
+	err := bus.DispatchCtx(context.Background(), &testQuery{})
+	require.NoError(t, err)
+
+	require.True(t, invoked, "expected handler to be called")
+}
+
+func TestDispatchHook(t *testing.T) {
+	bus := New()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	handlerErr := errors.New("handler error")
+
+	bus.AddHandler(func(query *testQuery) error {
+		return handlerErr
+	})
+
+	var calls []string
+	bus.AddDispatchHook(func(hookCtx context.Context, msg Msg) func(err error) {
+		require.Equal(t, "value", hookCtx.Value(ctxKey{}))
+		calls = append(calls, "before")
+		return func(err error) {
+			require.Equal(t, handlerErr, err)
+			calls = append(calls, "after")
+		}
+	})
+	bus.AddDispatchHook(func(hookCtx context.Context, msg Msg) func(err error) {
+		return nil
+	})
+
+	err := bus.DispatchCtx(ctx, &testQuery{})
+	require.Equal(t, handlerErr, err)
+	require.Equal(t, []string{"before", "after"}, calls)
+}
+
 func TestQuery(t *testing.T) {
 	bus := New()
 
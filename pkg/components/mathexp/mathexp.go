@@ -0,0 +1,411 @@
+// Package mathexp parses and evaluates the math expressions of alert
+// conditions and server-side expressions.
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/grafana/grafana/pkg/components/null"
+)
+
+// Expression is a parsed expression such as `$A / $B * 100 > 5`.
+// Variables are referenced by the refId of a query prefixed with `$`.
+// Comparison and logical operators evaluate to 1 (true) or 0 (false).
+type Expression struct {
+	Text string
+	root node
+	vars []string
+}
+
+// Variables returns the refIds referenced by the expression in the
+// order they first appear.
+func (e *Expression) Variables() []string {
+	return e.vars
+}
+
+// Eval evaluates the expression. A null operand makes the whole
+// result null, as does a division by zero.
+func (e *Expression) Eval(vars map[string]null.Float) null.Float {
+	return e.root.eval(vars)
+}
+
+// Parse parses the text of an expression.
+func Parse(text string) (*Expression, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	return &Expression{Text: text, root: root, vars: p.vars}, nil
+}
+
+type node interface {
+	eval(vars map[string]null.Float) null.Float
+}
+
+type numberNode struct {
+	value float64
+}
+
+func (n *numberNode) eval(vars map[string]null.Float) null.Float {
+	return null.FloatFrom(n.value)
+}
+
+type varNode struct {
+	name string
+}
+
+func (n *varNode) eval(vars map[string]null.Float) null.Float {
+	value, ok := vars[n.name]
+	if !ok {
+		return null.FloatFromPtr(nil)
+	}
+	return value
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(vars map[string]null.Float) null.Float {
+	v := n.operand.eval(vars)
+	if !v.Valid {
+		return v
+	}
+
+	switch n.op {
+	case "-":
+		return null.FloatFrom(-v.Float64)
+	case "!":
+		return boolToFloat(v.Float64 == 0)
+	}
+
+	return null.FloatFromPtr(nil)
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(vars map[string]null.Float) null.Float {
+	l := n.left.eval(vars)
+	r := n.right.eval(vars)
+	if !l.Valid || !r.Valid {
+		return null.FloatFromPtr(nil)
+	}
+
+	a, b := l.Float64, r.Float64
+
+	switch n.op {
+	case "+":
+		return null.FloatFrom(a + b)
+	case "-":
+		return null.FloatFrom(a - b)
+	case "*":
+		return null.FloatFrom(a * b)
+	case "/":
+		if b == 0 {
+			return null.FloatFromPtr(nil)
+		}
+		return null.FloatFrom(a / b)
+	case "%":
+		if b == 0 {
+			return null.FloatFromPtr(nil)
+		}
+		return null.FloatFrom(math.Mod(a, b))
+	case ">":
+		return boolToFloat(a > b)
+	case "<":
+		return boolToFloat(a < b)
+	case ">=":
+		return boolToFloat(a >= b)
+	case "<=":
+		return boolToFloat(a <= b)
+	case "==":
+		return boolToFloat(a == b)
+	case "!=":
+		return boolToFloat(a != b)
+	case "&&":
+		return boolToFloat(a != 0 && b != 0)
+	case "||":
+		return boolToFloat(a != 0 || b != 0)
+	}
+
+	return null.FloatFromPtr(nil)
+}
+
+var functions = map[string]func(float64) float64{
+	"abs":   math.Abs,
+	"ceil":  math.Ceil,
+	"floor": math.Floor,
+	"round": math.Round,
+	"sqrt":  math.Sqrt,
+	"log":   math.Log,
+}
+
+type funcNode struct {
+	name string
+	fn   func(float64) float64
+	arg  node
+}
+
+func (n *funcNode) eval(vars map[string]null.Float) null.Float {
+	v := n.arg.eval(vars)
+	if !v.Valid {
+		return v
+	}
+
+	result := n.fn(v.Float64)
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return null.FloatFromPtr(nil)
+	}
+	return null.FloatFrom(result)
+}
+
+func boolToFloat(b bool) null.Float {
+	if b {
+		return null.FloatFrom(1)
+	}
+	return null.FloatFrom(0)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenVar
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var twoCharOperators = []string{">=", "<=", "==", "!=", "&&", "||"}
+
+func tokenize(text string) ([]token, error) {
+	var tokens []token
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case r == '$':
+			start := i
+			i++
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			if i == start+1 {
+				return nil, fmt.Errorf("missing query reference after $ at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokenVar, text: string(runes[start+1 : i]), pos: start})
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			op := ""
+			if i+1 < len(runes) {
+				pair := string(runes[i : i+2])
+				for _, candidate := range twoCharOperators {
+					if pair == candidate {
+						op = pair
+						break
+					}
+				}
+			}
+			if op == "" && strings.ContainsRune("+-*/%<>!", r) {
+				op = string(r)
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	vars   []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) acceptOperator(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.acceptOperator(ops...)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *parser) parseComparison() (node, error) {
+	return p.parseBinary(p.parseAdditive, ">", "<", ">=", "<=", "==", "!=")
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.acceptOperator("-", "!"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return &numberNode{value: value}, nil
+	case tokenVar:
+		p.addVar(tok.text)
+		return &varNode{name: tok.text}, nil
+	case tokenIdent:
+		fn, ok := functions[tok.text]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %q at position %d", tok.text, tok.pos)
+		}
+		if p.next().kind != tokenLParen {
+			return nil, fmt.Errorf("expected ( after function %q", tok.text)
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) to close function %q", tok.text)
+		}
+		return &funcNode{name: tok.text, fn: fn, arg: arg}, nil
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+		}
+		return node, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+func (p *parser) addVar(name string) {
+	for _, v := range p.vars {
+		if v == name {
+			return
+		}
+	}
+	p.vars = append(p.vars, name)
+}
@@ -0,0 +1,65 @@
+package mathexp
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/grafana/grafana/pkg/components/null"
+)
+
+func evalMathExpression(text string, vars map[string]null.Float) null.Float {
+	expr, err := Parse(text)
+	So(err, ShouldBeNil)
+
+	return expr.Eval(vars)
+}
+
+func TestMathExpression(t *testing.T) {
+	vars := map[string]null.Float{
+		"A":    null.FloatFrom(6),
+		"B":    null.FloatFrom(120),
+		"Zero": null.FloatFrom(0),
+		"Null": null.FloatFromPtr(nil),
+	}
+
+	Convey("arithmetic", t, func() {
+		So(evalMathExpression("$A / $B * 100", vars).Float64, ShouldEqual, 5)
+		So(evalMathExpression("1 + 2 * 3", vars).Float64, ShouldEqual, 7)
+		So(evalMathExpression("(1 + 2) * 3", vars).Float64, ShouldEqual, 9)
+		So(evalMathExpression("-$A + 10", vars).Float64, ShouldEqual, 4)
+		So(evalMathExpression("7 % 4", vars).Float64, ShouldEqual, 3)
+		So(evalMathExpression("abs(0 - $A)", vars).Float64, ShouldEqual, 6)
+		So(evalMathExpression("round(2.6)", vars).Float64, ShouldEqual, 3)
+	})
+
+	Convey("comparisons and logical operators", t, func() {
+		So(evalMathExpression("$A / $B * 100 > 4", vars).Float64, ShouldEqual, 1)
+		So(evalMathExpression("$A / $B * 100 > 5", vars).Float64, ShouldEqual, 0)
+		So(evalMathExpression("$A / $B * 100 >= 5", vars).Float64, ShouldEqual, 1)
+		So(evalMathExpression("$A == 6 && $B != 6", vars).Float64, ShouldEqual, 1)
+		So(evalMathExpression("$A < 1 || $B < 1", vars).Float64, ShouldEqual, 0)
+		So(evalMathExpression("!($A > 1)", vars).Float64, ShouldEqual, 0)
+	})
+
+	Convey("null values", t, func() {
+		So(evalMathExpression("$A + $Null", vars).Valid, ShouldBeFalse)
+		So(evalMathExpression("$A / $Zero", vars).Valid, ShouldBeFalse)
+		So(evalMathExpression("$Missing * 2", vars).Valid, ShouldBeFalse)
+		So(evalMathExpression("log(-1)", vars).Valid, ShouldBeFalse)
+	})
+
+	Convey("variables", t, func() {
+		expr, err := Parse("$A / ($B + $A) > $C")
+		So(err, ShouldBeNil)
+		So(expr.Variables(), ShouldResemble, []string{"A", "B", "C"})
+	})
+
+	Convey("invalid expressions", t, func() {
+		invalid := []string{"", "$A +", "($A", "$A $B", "$ + 1", "foo($A)", "$A ^ 2", "1.2.3"}
+		for _, text := range invalid {
+			_, err := Parse(text)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
@@ -104,6 +104,9 @@ var (
 
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
+
+	// MQueryCacheRequestTotal is a metric counter for query cache lookups by result (hit or miss)
+	MQueryCacheRequestTotal *prometheus.CounterVec
 )
 
 // Timers
@@ -386,6 +389,16 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MQueryCacheRequestTotal = newCounterVecStartingAtZero(
+		prometheus.CounterOpts{
+			Name:      "query_cache_request_total",
+			Help:      "counter for query cache lookups by result",
+			Namespace: ExporterName,
+		},
+		[]string{"result"},
+		"hit", "miss",
+	)
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -553,6 +566,7 @@ func initMetricVars() {
 		MRenderingRequestTotal,
 		MRenderingSummary,
 		MRenderingQueue,
+		MQueryCacheRequestTotal,
 		MAlertingActiveAlerts,
 		MStatTotalDashboards,
 		MStatTotalUsers,
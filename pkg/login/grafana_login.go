@@ -33,6 +33,11 @@ var loginUsingGrafanaDB = func(query *models.LoginUserQuery) error {
 		return ErrUserDisabled
 	}
 
+	// service accounts authenticate with their tokens only
+	if user.IsServiceAccount {
+		return ErrInvalidCredentials
+	}
+
 	if err := validatePassword(query.Password, user.Password, user.Salt); err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 	macaron "gopkg.in/macaron.v1"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -86,6 +87,23 @@ func RoleAuth(roles ...models.RoleType) macaron.Handler {
 	}
 }
 
+// Authorize creates a middleware that allows access if the org role or the
+// custom roles of the signed in user grant the action on any of the scopes.
+// Without scopes, the action on any resource is enough, and handlers are
+// expected to check the resources they return.
+func Authorize(action string, scopes ...string) macaron.Handler {
+	return func(c *models.ReqContext) {
+		hasAccess, err := accesscontrol.HasAccess(c.SignedInUser, action, scopes...)
+		if err != nil {
+			c.JsonApiErr(500, "Failed to evaluate permissions", err)
+			return
+		}
+		if !hasAccess {
+			accessForbidden(c)
+		}
+	}
+}
+
 func Auth(options *AuthOptions) macaron.Handler {
 	return func(c *models.ReqContext) {
 		forceLogin := false
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth/jwt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	errStringInvalidJWT = "Invalid JWT"
+
+	// jwtCachePrefix is the prefix of the cache keys of the users signed in
+	// with a token
+	jwtCachePrefix = "auth-jwt-sync-ttl:%s"
+)
+
+func initContextWithJWT(jwtAuth *jwt.AuthService, store *remotecache.RemoteCache, ctx *models.ReqContext, orgID int64) bool {
+	if !jwtAuth.IsEnabled() {
+		return false
+	}
+
+	token := getJWT(ctx, jwtAuth.Cfg.JWTAuth.HeaderName)
+	if token == "" {
+		return false
+	}
+
+	logger := log.New("auth.jwt")
+
+	user, err := jwtAuth.Verify(token)
+	if err != nil {
+		logger.Debug("Failed to verify JWT", "error", err)
+		ctx.JsonApiErr(401, errStringInvalidJWT, err)
+		return true
+	}
+
+	cacheKey := fmt.Sprintf(jwtCachePrefix, hashJWT(token))
+
+	// the token was verified above, the cache only saves syncing its user
+	if cached, err := store.Get(cacheKey); err == nil {
+		query := models.GetSignedInUserQuery{UserId: cached.(int64), OrgId: orgID}
+		if err := bus.Dispatch(&query); err == nil {
+			ctx.SignedInUser = query.Result
+			ctx.IsSignedIn = true
+			return true
+		}
+
+		// the user of a cached token may have been deleted since
+		logger.Debug("Failed to get cached user, syncing user again", "userID", cached, "error", err)
+		if err := store.Delete(cacheKey); err != nil && !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			logger.Error("Failed to remove user from auth cache", "error", err)
+		}
+	}
+
+	upsert := &models.UpsertUserCommand{
+		ReqContext:    ctx,
+		SignupAllowed: jwtAuth.Cfg.JWTAuth.AutoSignUp,
+		ExternalUser:  jwtExternalUser(user),
+	}
+	if err := bus.Dispatch(upsert); err != nil {
+		logger.Error("Failed to sync user of JWT", "login", user.Login, "error", err)
+		ctx.JsonApiErr(401, "Failed to sign in with JWT", err)
+		return true
+	}
+
+	query := models.GetSignedInUserQuery{UserId: upsert.Result.Id, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		ctx.JsonApiErr(401, "Failed to sign in with JWT", err)
+		return true
+	}
+
+	ctx.SignedInUser = query.Result
+	ctx.IsSignedIn = true
+
+	expiration := jwtAuth.Cfg.JWTAuth.CacheTTL
+	if !user.Expiry.IsZero() {
+		if untilExpiry := time.Until(user.Expiry); untilExpiry < expiration {
+			expiration = untilExpiry
+		}
+	}
+	if expiration > 0 {
+		if err := store.Set(cacheKey, upsert.Result.Id, expiration); err != nil {
+			logger.Error("Failed to store user in auth cache", "login", user.Login, "error", err)
+		}
+	}
+
+	return true
+}
+
+// getJWT returns the token in header. Tokens in the Authorization header
+// follow the Bearer prefix, and are told apart from API keys by their three
+// dot separated parts.
+func getJWT(ctx *models.ReqContext, header string) string {
+	token := strings.TrimSpace(ctx.Req.Header.Get(header))
+	if token == "" {
+		return ""
+	}
+
+	if strings.EqualFold(header, "Authorization") {
+		parts := strings.SplitN(token, " ", 2)
+		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+			return ""
+		}
+		token = strings.TrimSpace(parts[1])
+		if strings.Count(token, ".") != 2 {
+			return ""
+		}
+	}
+
+	return token
+}
+
+func jwtExternalUser(user *jwt.User) *models.ExternalUserInfo {
+	extUser := &models.ExternalUserInfo{
+		AuthModule: "jwt",
+		AuthId:     user.Subject,
+		Login:      user.Login,
+		Email:      user.Email,
+		Name:       user.Name,
+		OrgRoles:   map[int64]models.RoleType{},
+	}
+
+	if user.Role != "" {
+		orgID := int64(1)
+		if setting.AutoAssignOrg && setting.AutoAssignOrgId > 0 {
+			orgID = int64(setting.AutoAssignOrgId)
+		}
+		extUser.OrgRoles[orgID] = user.Role
+	}
+
+	return extUser
+}
+
+// hashJWT hashes token with a collision resistant hash, as cached users are
+// looked up by the hash of their token.
+func hashJWT(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth/jwt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	macaron "gopkg.in/macaron.v1"
+	jose "gopkg.in/square/go-jose.v2"
+	josejwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestInitContextWithJWT(t *testing.T) {
+	const userID = int64(12)
+	const orgID = int64(3)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	jwtAuth := &jwt.AuthService{Cfg: &setting.Cfg{JWTAuth: setting.JWTAuthSettings{
+		Enabled:        true,
+		HeaderName:     "Authorization",
+		KeyFile:        keyFile,
+		ExpectedIssuer: "gateway",
+		LoginClaim:     "sub",
+		EmailClaim:     "email",
+		RoleClaim:      "role",
+		AutoSignUp:     true,
+		CacheTTL:       time.Hour,
+	}}}
+	require.NoError(t, jwtAuth.Init())
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	require.NoError(t, err)
+	token, err := josejwt.Signed(signer).
+		Claims(josejwt.Claims{Subject: "jdoe", Issuer: "gateway", Expiry: josejwt.NewNumericDate(time.Now().Add(time.Hour))}).
+		Claims(map[string]interface{}{"email": "jdoe@example.com", "role": "Editor"}).
+		CompactSerialize()
+	require.NoError(t, err)
+
+	var upserts []*models.UpsertUserCommand
+	bus.AddHandler("", func(cmd *models.UpsertUserCommand) error {
+		upserts = append(upserts, cmd)
+		cmd.Result = &models.User{Id: userID}
+		return nil
+	})
+	bus.AddHandler("", func(query *models.GetSignedInUserQuery) error {
+		if query.UserId != userID {
+			return models.ErrUserNotFound
+		}
+		query.Result = &models.SignedInUser{UserId: userID, OrgId: orgID, Login: "jdoe"}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	store := remotecache.NewFakeStore(t)
+	newContext := func(authorization string) *models.ReqContext {
+		req, err := http.NewRequest("GET", "http://example.com", nil)
+		require.NoError(t, err)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return &models.ReqContext{
+			Context: &macaron.Context{
+				Req:  macaron.Request{Request: req},
+				Data: map[string]interface{}{},
+			},
+			SignedInUser: &models.SignedInUser{},
+			Logger:       log.New("Test"),
+		}
+	}
+
+	t.Run("Should sign in and sync the user of a token", func(t *testing.T) {
+		ctx := newContext("Bearer " + token)
+		require.True(t, initContextWithJWT(jwtAuth, store, ctx, orgID))
+
+		assert.True(t, ctx.IsSignedIn)
+		assert.Equal(t, userID, ctx.SignedInUser.UserId)
+
+		require.Len(t, upserts, 1)
+		extUser := upserts[0].ExternalUser
+		assert.True(t, upserts[0].SignupAllowed)
+		assert.Equal(t, "jwt", extUser.AuthModule)
+		assert.Equal(t, "jdoe", extUser.Login)
+		assert.Equal(t, "jdoe@example.com", extUser.Email)
+		assert.Equal(t, map[int64]models.RoleType{1: models.ROLE_EDITOR}, extUser.OrgRoles)
+
+		cached, err := store.Get(fmt.Sprintf(jwtCachePrefix, hashJWT(token)))
+		require.NoError(t, err)
+		assert.Equal(t, userID, cached.(int64))
+	})
+
+	t.Run("Should sign in the cached user of a token", func(t *testing.T) {
+		upserts = nil
+		ctx := newContext("Bearer " + token)
+		require.True(t, initContextWithJWT(jwtAuth, store, ctx, orgID))
+
+		assert.True(t, ctx.IsSignedIn)
+		assert.Empty(t, upserts)
+	})
+
+	t.Run("Should reject invalid tokens", func(t *testing.T) {
+		m := macaron.New()
+		m.Use(macaron.Renderer())
+		m.Use(GetContextHandler(nil, store, nil, jwtAuth))
+		m.Get("/", func(c *models.ReqContext) {
+			c.JsonOK("OK")
+		})
+
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token[:len(token)-4]+"abcd")
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, req)
+
+		assert.Equal(t, 401, rr.Code)
+		assert.Contains(t, rr.Body.String(), errStringInvalidJWT)
+	})
+
+	t.Run("Should leave API keys and basic auth to other auth methods", func(t *testing.T) {
+		assert.False(t, initContextWithJWT(jwtAuth, store, newContext("Bearer eyJrIjoiT0tTcG1pUlY2RnVKZTFVaDFsNFZXdE9ZWmNrMkZYbk"), orgID))
+		assert.False(t, initContextWithJWT(jwtAuth, store, newContext("Basic YWRtaW46YWRtaW4="), orgID))
+		assert.False(t, initContextWithJWT(jwtAuth, store, newContext(""), orgID))
+	})
+
+	t.Run("Should do nothing when disabled", func(t *testing.T) {
+		assert.False(t, initContextWithJWT(nil, store, newContext("Bearer "+token), orgID))
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -41,6 +42,7 @@ func GetContextHandler(
 	ats models.UserTokenService,
 	remoteCache *remotecache.RemoteCache,
 	renderService rendering.Service,
+	jwtAuthService *jwt.AuthService,
 ) macaron.Handler {
 	return func(c *macaron.Context) {
 		ctx := &models.ReqContext{
@@ -59,12 +61,13 @@ func GetContextHandler(
 		}
 
 		// the order in which these are tested are important
-		// look for api key in Authorization header first
+		// look for a JWT, then for api key in Authorization header
 		// then init session and look for userId in session
 		// then look for api key in session (special case for render calls via api)
 		// then test if anonymous access is enabled
 		switch {
 		case initContextWithRenderAuth(ctx, renderService):
+		case initContextWithJWT(jwtAuthService, remoteCache, ctx, orgId):
 		case initContextWithApiKey(ctx):
 		case initContextWithBasicAuth(ctx, orgId):
 		case initContextWithAuthProxy(remoteCache, ctx, orgId):
@@ -75,6 +78,14 @@ func GetContextHandler(
 		ctx.Logger = log.New("context", "userId", ctx.UserId, "orgId", ctx.OrgId, "uname", ctx.Login)
 		ctx.Data["ctx"] = ctx
 
+		// commands dispatched with the request context are audited as made by
+		// the signed in user
+		ctx.Req.Request = ctx.Req.WithContext(models.WithAuditRequest(ctx.Req.Context(), &models.AuditRequest{
+			User:      ctx.SignedInUser,
+			IpAddress: ctx.RemoteAddr(),
+			UserAgent: ctx.Req.UserAgent(),
+		}))
+
 		c.Map(ctx)
 
 		// update last seen every 5min
@@ -146,6 +157,26 @@ func initContextWithApiKey(ctx *models.ReqContext) bool {
 		return true
 	}
 
+	// tokens of service accounts sign in as the service account, with its
+	// role, teams and permissions
+	if apikey.ServiceAccountId != nil {
+		query := models.GetSignedInUserQuery{UserId: *apikey.ServiceAccountId, OrgId: apikey.OrgId}
+		if err := bus.Dispatch(&query); err != nil {
+			ctx.Logger.Error("Failed to get service account of API key", "keyId", apikey.Id, "error", err)
+			ctx.JsonApiErr(401, errStringInvalidAPIKey, err)
+			return true
+		}
+		if !query.Result.IsServiceAccount || query.Result.OrgId != apikey.OrgId {
+			ctx.JsonApiErr(401, errStringInvalidAPIKey, nil)
+			return true
+		}
+
+		ctx.IsSignedIn = true
+		ctx.SignedInUser = query.Result
+		ctx.ApiKeyId = apikey.Id
+		return true
+	}
+
 	ctx.IsSignedIn = true
 	ctx.SignedInUser = &models.SignedInUser{}
 	ctx.OrgRole = apikey.Role
@@ -19,8 +19,8 @@ func RequestTracing(handler string) macaron.Handler {
 		span := tracer.StartSpan(fmt.Sprintf("HTTP %s", handler), ext.RPCServerOption(wireContext))
 		defer span.Finish()
 
-		ctx := opentracing.ContextWithSpan(req.Context(), span)
-		c.Req.Request = req.WithContext(ctx)
+		ctx := opentracing.ContextWithSpan(c.Req.Context(), span)
+		c.Req.Request = c.Req.WithContext(ctx)
 
 		c.Next()
 
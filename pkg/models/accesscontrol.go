@@ -0,0 +1,161 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrRoleNotFound          = errors.New("Role not found")
+	ErrRoleNameTaken         = errors.New("Role name is taken")
+	ErrRoleAlreadyAdded      = errors.New("Role is already added")
+	ErrRoleAssignmentMissing = errors.New("Role is not added")
+)
+
+// Role is a custom role of an org, granting its permissions to the users
+// and teams it is added to.
+type Role struct {
+	Id          int64
+	OrgId       int64
+	Name        string
+	Description string
+
+	Created time.Time
+	Updated time.Time
+}
+
+// Permission grants an action, such as `datasources:query`, on the resources
+// matched by its scope, such as `datasources:uid:abc` or `datasources:*`. An
+// empty scope matches all resources.
+type Permission struct {
+	Id     int64
+	RoleId int64
+	Action string
+	Scope  string
+
+	Created time.Time
+	Updated time.Time
+}
+
+type UserRole struct {
+	Id     int64
+	OrgId  int64
+	RoleId int64
+	UserId int64
+
+	Created time.Time
+}
+
+type TeamRole struct {
+	Id     int64
+	OrgId  int64
+	RoleId int64
+	TeamId int64
+
+	Created time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateRoleCommand struct {
+	Name        string           `json:"name" binding:"Required"`
+	Description string           `json:"description"`
+	Permissions []*PermissionDTO `json:"permissions"`
+	OrgId       int64            `json:"-"`
+
+	Result *RoleDTO `json:"-"`
+}
+
+// UpdateRoleCommand replaces the name, description and permissions of a role.
+type UpdateRoleCommand struct {
+	Id          int64            `json:"-"`
+	Name        string           `json:"name" binding:"Required"`
+	Description string           `json:"description"`
+	Permissions []*PermissionDTO `json:"permissions"`
+	OrgId       int64            `json:"-"`
+
+	Result *RoleDTO `json:"-"`
+}
+
+type DeleteRoleCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type AddUserRoleCommand struct {
+	RoleId int64 `json:"roleId" binding:"Required"`
+	UserId int64 `json:"-"`
+	OrgId  int64 `json:"-"`
+}
+
+type RemoveUserRoleCommand struct {
+	RoleId int64
+	UserId int64
+	OrgId  int64
+}
+
+type AddTeamRoleCommand struct {
+	RoleId int64 `json:"roleId" binding:"Required"`
+	TeamId int64 `json:"-"`
+	OrgId  int64 `json:"-"`
+}
+
+type RemoveTeamRoleCommand struct {
+	RoleId int64
+	TeamId int64
+	OrgId  int64
+}
+
+// ----------------------
+// QUERIES
+
+type GetRolesQuery struct {
+	OrgId  int64
+	Result []*RoleDTO
+}
+
+type GetRoleByIdQuery struct {
+	Id     int64
+	OrgId  int64
+	Result *RoleDTO
+}
+
+type GetUserRolesQuery struct {
+	OrgId  int64
+	UserId int64
+	Result []*RoleDTO
+}
+
+type GetTeamRolesQuery struct {
+	OrgId  int64
+	TeamId int64
+	Result []*RoleDTO
+}
+
+// GetUserPermissionsQuery returns the permissions of the custom roles added to
+// a user, or to the teams the user is a member of.
+type GetUserPermissionsQuery struct {
+	OrgId  int64
+	UserId int64
+	Result []*Permission
+}
+
+// ------------------------
+// DTO & Projections
+
+type RoleDTO struct {
+	Id          int64            `json:"id"`
+	OrgId       int64            `json:"orgId"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Permissions []*PermissionDTO `json:"permissions"`
+	Created     time.Time        `json:"created"`
+	Updated     time.Time        `json:"updated"`
+}
+
+type PermissionDTO struct {
+	Action string `json:"action" binding:"Required"`
+	Scope  string `json:"scope"`
+}
@@ -158,6 +158,22 @@ type SaveAlertsCommand struct {
 	Alerts []*Alert
 }
 
+type AlertProvisioning struct {
+	Id      int64
+	AlertId int64
+	Name    string
+	Updated int64
+}
+
+type SaveProvisionedAlertCommand struct {
+	Alert             *Alert
+	AlertProvisioning *AlertProvisioning
+}
+
+type DeleteProvisionedAlertCommand struct {
+	AlertId int64
+}
+
 type PauseAlertCommand struct {
 	OrgId       int64
 	AlertIds    []int64
@@ -203,6 +219,14 @@ type GetAlertByIdQuery struct {
 	Result *Alert
 }
 
+// GetProvisionedAlertsQuery returns the provisioned alert rules,
+// optionally limited to the ones of a dashboard.
+type GetProvisionedAlertsQuery struct {
+	DashboardId int64
+
+	Result []*Alert
+}
+
 type GetAlertStatesForDashboardQuery struct {
 	OrgId       int64
 	DashboardId int64
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// AlertInstance is the state of a single series (metric name plus tags)
+// matched by an alert rule.
+type AlertInstance struct {
+	Id           int64
+	OrgId        int64
+	AlertId      int64
+	InstanceKey  string
+	Metric       string
+	Tags         map[string]string
+	State        AlertStateType
+	NewStateDate time.Time
+	Created      time.Time
+	Updated      time.Time
+}
+
+// AlertInstanceKey returns the key identifying the instance of an alert
+// rule for a series with the given metric name and tags.
+func AlertInstanceKey(metric string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := sha1.New()
+	hash.Write([]byte(metric))
+	for _, k := range keys {
+		hash.Write([]byte{0})
+		hash.Write([]byte(k))
+		hash.Write([]byte{0})
+		hash.Write([]byte(tags[k]))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// SaveAlertInstancesCommand replaces the instances tracked for an alert.
+// Instances that are not part of the command are removed.
+type SaveAlertInstancesCommand struct {
+	OrgId     int64
+	AlertId   int64
+	Instances []*AlertInstance
+}
+
+type GetAlertInstancesQuery struct {
+	OrgId   int64
+	AlertId int64
+
+	Result []*AlertInstance
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrAlertNotificationPolicyNotFound = errors.New("Alert notification policy not found")
+)
+
+// NotificationPolicySeverityTag is the alert rule tag matched by the
+// severity of a notification policy.
+const NotificationPolicySeverityTag = "severity"
+
+// AlertNotificationPolicy is the root of the notification routing tree of
+// an organization.
+type AlertNotificationPolicy struct {
+	Id      int64
+	OrgId   int64
+	Policy  *NotificationPolicy
+	Created time.Time
+	Updated time.Time
+}
+
+// NotificationPolicy routes the notifications of the alert rules it
+// matches to notification channels, in addition to the channels set on
+// the alert rules.
+//
+// A policy matches an alert rule when all of its non empty matchers match.
+// The child policies of a matching policy are tried in order and the first
+// matching one is used, unless it sets Continue. When no child policy
+// matches, the policy itself is used. GroupBy, GroupInterval and
+// RepeatInterval are inherited from the parent policy when not set.
+type NotificationPolicy struct {
+	Tags             map[string]string     `json:"tags,omitempty"`
+	Severity         string                `json:"severity,omitempty"`
+	FolderUid        string                `json:"folderUid,omitempty"`
+	NotificationUids []string              `json:"notificationUids,omitempty"`
+	GroupBy          []string              `json:"groupBy,omitempty"`
+	GroupInterval    string                `json:"groupInterval,omitempty"`
+	RepeatInterval   string                `json:"repeatInterval,omitempty"`
+	Continue         bool                  `json:"continue,omitempty"`
+	Routes           []*NotificationPolicy `json:"routes,omitempty"`
+}
+
+// FromDB implements the xorm Conversion interface.
+func (p *NotificationPolicy) FromDB(data []byte) error {
+	return json.Unmarshal(data, p)
+}
+
+// ToDB implements the xorm Conversion interface.
+func (p *NotificationPolicy) ToDB() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+type SaveAlertNotificationPolicyCommand struct {
+	Policy *NotificationPolicy `json:"policy" binding:"Required"`
+
+	OrgId  int64 `json:"-"`
+	Result *AlertNotificationPolicy
+}
+
+type DeleteAlertNotificationPolicyCommand struct {
+	OrgId int64
+}
+
+type GetAlertNotificationPolicyQuery struct {
+	OrgId int64
+
+	Result *AlertNotificationPolicy
+}
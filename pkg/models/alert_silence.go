@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAlertSilenceNotFound       = errors.New("Alert silence not found")
+	ErrAlertSilenceInvalidPeriod  = errors.New("Alert silence must end after it starts")
+	ErrAlertSilenceMissingRepeat  = errors.New("Alert silence with a cron schedule requires a repeat duration")
+	ErrAlertSilenceInvalidCron    = errors.New("Alert silence cron schedule is invalid")
+	ErrAlertSilenceMissingMatcher = errors.New("Alert silence requires a rule name, dashboard, folder or tags to match")
+)
+
+// AlertSilence mutes the notifications of the alert rules it matches while
+// it is active. Alert rules keep being evaluated and their state changes
+// are still recorded.
+//
+// A silence matches an alert rule when all of its non empty matchers match,
+// and it has at least one.
+// When Cron is set the silence is only active for RepeatDuration after each
+// time the schedule triggers, within StartsAt and EndsAt.
+type AlertSilence struct {
+	Id             int64
+	OrgId          int64
+	RuleName       string
+	DashboardUid   string
+	FolderUid      string
+	Tags           map[string]string
+	StartsAt       time.Time
+	EndsAt         time.Time
+	Cron           string
+	RepeatDuration time.Duration
+	Comment        string
+	CreatedBy      int64
+	Created        time.Time
+	Updated        time.Time
+}
+
+type CreateAlertSilenceCommand struct {
+	RuleName       string            `json:"ruleName"`
+	DashboardUid   string            `json:"dashboardUid"`
+	FolderUid      string            `json:"folderUid"`
+	Tags           map[string]string `json:"tags"`
+	StartsAt       time.Time         `json:"startsAt" binding:"Required"`
+	EndsAt         time.Time         `json:"endsAt" binding:"Required"`
+	Cron           string            `json:"cron"`
+	RepeatDuration string            `json:"repeatDuration"`
+	Comment        string            `json:"comment"`
+
+	OrgId     int64 `json:"-"`
+	CreatedBy int64 `json:"-"`
+	Result    *AlertSilence
+}
+
+type UpdateAlertSilenceCommand struct {
+	Id             int64             `json:"-"`
+	RuleName       string            `json:"ruleName"`
+	DashboardUid   string            `json:"dashboardUid"`
+	FolderUid      string            `json:"folderUid"`
+	Tags           map[string]string `json:"tags"`
+	StartsAt       time.Time         `json:"startsAt" binding:"Required"`
+	EndsAt         time.Time         `json:"endsAt" binding:"Required"`
+	Cron           string            `json:"cron"`
+	RepeatDuration string            `json:"repeatDuration"`
+	Comment        string            `json:"comment"`
+
+	OrgId  int64 `json:"-"`
+	Result *AlertSilence
+}
+
+type DeleteAlertSilenceCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type GetAlertSilenceByIdQuery struct {
+	Id    int64
+	OrgId int64
+
+	Result *AlertSilence
+}
+
+// GetAlertSilencesQuery returns the silences of an organization. When
+// ActiveAt is set only the silences whose period contains it are returned.
+type GetAlertSilencesQuery struct {
+	OrgId    int64
+	ActiveAt time.Time
+
+	Result []*AlertSilence
+}
+
+// HasMatchers returns true if the silence sets any matcher.
+func (s *AlertSilence) HasMatchers() bool {
+	return s.RuleName != "" || s.DashboardUid != "" || s.FolderUid != "" || len(s.Tags) > 0
+}
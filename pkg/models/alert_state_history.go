@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// AlertStateHistory is a state change of an alert rule or of its alert
+// instances, with the notifications that were sent for it. When only
+// instances changed state, PrevState and NewState are the same.
+type AlertStateHistory struct {
+	Id               int64
+	OrgId            int64
+	AlertId          int64
+	DashboardId      int64
+	PanelId          int64
+	RuleName         string
+	PrevState        AlertStateType
+	NewState         AlertStateType
+	EvalMatches      *simplejson.Json
+	ChangedInstances *simplejson.Json
+	Error            string
+	Notifications    AlertStateHistoryNotifications
+	Epoch            int64
+}
+
+// AlertStateHistoryNotification is the result of sending a notification
+// to a notification channel.
+type AlertStateHistoryNotification struct {
+	Uid   string `json:"uid"`
+	Type  string `json:"type"`
+	Sent  bool   `json:"sent"`
+	Error string `json:"error,omitempty"`
+}
+
+// AlertStateHistoryNotifications implements the xorm Conversion interface
+// to store the notifications as json.
+type AlertStateHistoryNotifications []*AlertStateHistoryNotification
+
+func (n *AlertStateHistoryNotifications) FromDB(data []byte) error {
+	return json.Unmarshal(data, n)
+}
+
+func (n *AlertStateHistoryNotifications) ToDB() ([]byte, error) {
+	if *n == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(n)
+}
+
+type SaveAlertStateHistoryCommand struct {
+	History *AlertStateHistory
+}
+
+// GetAlertStateHistoryQuery returns the state history of the alert rules
+// of an organization, most recent first. AlertId, From, To and State
+// narrow down the history when set. When User is set only the history of
+// the dashboards the user can view is returned.
+type GetAlertStateHistoryQuery struct {
+	OrgId   int64
+	AlertId int64
+	From    int64
+	To      int64
+	State   AlertStateType
+	Page    int
+	PerPage int
+	User    *SignedInUser
+
+	Result AlertStateHistoryResult
+}
+
+type AlertStateHistoryResult struct {
+	TotalCount int64
+	History    []*AlertStateHistory
+	Page       int
+	PerPage    int
+}
+
+// DeleteExpiredAlertStateHistoryCommand deletes the state history older
+// than OlderThan, in epoch milliseconds.
+type DeleteExpiredAlertStateHistoryCommand struct {
+	OlderThan int64
+
+	DeletedRows int64
+}
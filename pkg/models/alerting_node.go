@@ -0,0 +1,40 @@
+package models
+
+// AlertingNode is a Grafana server taking part in the evaluation of alert
+// rules when alert evaluation is sharded between the servers of a high
+// availability setup. Heartbeat is the last time the server reported
+// being alive, in epoch seconds.
+type AlertingNode struct {
+	Id        int64
+	NodeId    string
+	Heartbeat int64
+}
+
+// SaveAlertingNodeHeartbeatCommand records that a node is alive,
+// registering it if it is new.
+type SaveAlertingNodeHeartbeatCommand struct {
+	NodeId    string
+	Heartbeat int64
+}
+
+// GetAlertingNodesQuery returns the nodes whose last heartbeat is not
+// older than ActiveSince, ordered by node id.
+type GetAlertingNodesQuery struct {
+	ActiveSince int64
+
+	Result []*AlertingNode
+}
+
+// DeleteAlertingNodeCommand removes a node, so that the others take over
+// its alert rules without waiting for its heartbeat to expire.
+type DeleteAlertingNodeCommand struct {
+	NodeId string
+}
+
+// DeleteExpiredAlertingNodesCommand removes the nodes whose last heartbeat
+// is older than OlderThan.
+type DeleteExpiredAlertingNodesCommand struct {
+	OlderThan int64
+
+	DeletedRows int64
+}
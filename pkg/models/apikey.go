@@ -18,6 +18,9 @@ type ApiKey struct {
 	Created time.Time
 	Updated time.Time
 	Expires *int64
+	// ServiceAccountId is the service account of a token of one, which
+	// authenticates requests as the service account instead of its role.
+	ServiceAccountId *int64
 }
 
 // ---------------------
@@ -29,6 +32,8 @@ type AddApiKeyCommand struct {
 	Key           string   `json:"-"`
 	SecondsToLive int64    `json:"secondsToLive"`
 
+	ServiceAccountId *int64 `json:"-"`
+
 	Result *ApiKey `json:"-"`
 }
 
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records a change of a resource made through the HTTP API.
+// BeforeJson and AfterJson hold the resource before it was changed and as
+// requested by the change, without its secrets.
+type AuditEntry struct {
+	Id    int64
+	OrgId int64
+
+	ActorId    int64
+	ActorLogin string
+	IpAddress  string
+	UserAgent  string
+
+	Action       string
+	ResourceType string
+	ResourceId   string
+
+	BeforeJson string
+	AfterJson  string
+
+	Created time.Time
+}
+
+// AuditRequest is who made an HTTP request. It is stored in the context of the
+// request so the audit log can tell who dispatched a command.
+type AuditRequest struct {
+	User      *SignedInUser
+	IpAddress string
+	UserAgent string
+}
+
+type auditRequestKey struct{}
+
+// WithAuditRequest returns a copy of ctx carrying r.
+func WithAuditRequest(ctx context.Context, r *AuditRequest) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, r)
+}
+
+// AuditRequestFromContext returns the request stored in ctx, or nil.
+func AuditRequestFromContext(ctx context.Context) *AuditRequest {
+	r, _ := ctx.Value(auditRequestKey{}).(*AuditRequest)
+	return r
+}
+
+// ---------------------
+// COMMANDS
+
+type AddAuditEntryCommand struct {
+	Entry *AuditEntry
+}
+
+type DeleteExpiredAuditEntriesCommand struct {
+	OlderThan   time.Time
+	DeletedRows int64
+}
+
+// ---------------------
+// QUERIES
+
+// SearchAuditEntriesQuery returns the entries matching all of its filters
+// which are set, most recent first.
+type SearchAuditEntriesQuery struct {
+	OrgId        int64
+	ActorId      int64
+	ActorLogin   string
+	Action       string
+	ResourceType string
+	ResourceId   string
+	From         time.Time
+	To           time.Time
+	Page         int
+	Limit        int
+
+	Result SearchAuditEntriesQueryResult
+}
+
+type SearchAuditEntriesQueryResult struct {
+	TotalCount int64            `json:"totalCount"`
+	Entries    []*AuditEntryDTO `json:"entries"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"perPage"`
+}
+
+// ---------------------
+// DTOs
+
+type AuditEntryDTO struct {
+	Id           int64           `json:"id"`
+	OrgId        int64           `json:"orgId"`
+	ActorId      int64           `json:"actorId"`
+	ActorLogin   string          `json:"actorLogin"`
+	IpAddress    string          `json:"ipAddress"`
+	UserAgent    string          `json:"userAgent"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resourceType"`
+	ResourceId   string          `json:"resourceId"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	Created      time.Time       `json:"created"`
+}
+
+func (e *AuditEntry) ToDTO() *AuditEntryDTO {
+	dto := &AuditEntryDTO{
+		Id:           e.Id,
+		OrgId:        e.OrgId,
+		ActorId:      e.ActorId,
+		ActorLogin:   e.ActorLogin,
+		IpAddress:    e.IpAddress,
+		UserAgent:    e.UserAgent,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceId:   e.ResourceId,
+		Created:      e.Created,
+	}
+	if e.BeforeJson != "" {
+		dto.Before = json.RawMessage(e.BeforeJson)
+	}
+	if e.AfterJson != "" {
+		dto.After = json.RawMessage(e.AfterJson)
+	}
+	return dto
+}
@@ -63,6 +63,10 @@ type DataSource struct {
 	ReadOnly          bool
 	Uid               string
 
+	// PermissionsEnabled restricts queries to org admins and the users and
+	// teams of the data source acl.
+	PermissionsEnabled bool
+
 	Created time.Time
 	Updated time.Time
 }
@@ -231,16 +235,22 @@ type DsPermissionType int
 const (
 	DsPermissionNoAccess DsPermissionType = iota
 	DsPermissionQuery
+	DsPermissionAdmin
 )
 
 func (p DsPermissionType) String() string {
 	names := map[int]string{
 		int(DsPermissionQuery):    "Query",
+		int(DsPermissionAdmin):    "Admin",
 		int(DsPermissionNoAccess): "No Access",
 	}
 	return names[int(p)]
 }
 
+func (p DsPermissionType) IsValid() bool {
+	return p == DsPermissionQuery || p == DsPermissionAdmin
+}
+
 type DatasourcesPermissionFilterQuery struct {
 	User        *SignedInUser
 	Datasources []*DataSource
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrDataSourcePermissionNotFound       = errors.New("Data source permission not found")
+	ErrDataSourcePermissionsNotEnabled    = errors.New("Permissions are not enabled for the data source")
+	ErrDataSourcePermissionUserOrTeam     = errors.New("Either userId or teamId must be set")
+	ErrDataSourcePermissionInvalid        = errors.New("Invalid data source permission")
+	ErrDataSourcePermissionTargetNotFound = errors.New("User or team not found in organization")
+)
+
+// DataSourceAcl gives a user or a team a permission on a data source which
+// has permissions enabled.
+type DataSourceAcl struct {
+	Id           int64
+	OrgId        int64
+	DataSourceId int64
+
+	UserId     int64
+	TeamId     int64
+	Permission DsPermissionType
+
+	Created time.Time
+	Updated time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+type EnableDataSourcePermissionsCommand struct {
+	DataSourceId int64
+	OrgId        int64
+}
+
+// DisableDataSourcePermissionsCommand opens the data source to the org again,
+// removing its acl.
+type DisableDataSourcePermissionsCommand struct {
+	DataSourceId int64
+	OrgId        int64
+}
+
+// AddDataSourcePermissionCommand adds the permission of a user or team, or
+// replaces it if the user or team already has one.
+type AddDataSourcePermissionCommand struct {
+	UserId       int64            `json:"userId"`
+	TeamId       int64            `json:"teamId"`
+	Permission   DsPermissionType `json:"permission"`
+	DataSourceId int64            `json:"-"`
+	OrgId        int64            `json:"-"`
+}
+
+type RemoveDataSourcePermissionCommand struct {
+	Id           int64
+	DataSourceId int64
+	OrgId        int64
+}
+
+// ---------------------
+// QUERIES
+
+type GetDataSourcePermissionsQuery struct {
+	DataSourceId int64
+	OrgId        int64
+	Result       []*DataSourceAclInfoDTO
+}
+
+// GetDataSourcePermissionForUserQuery returns the highest permission a user
+// has on a data source, directly or through its teams.
+type GetDataSourcePermissionForUserQuery struct {
+	DataSourceId int64
+	OrgId        int64
+	UserId       int64
+	Result       DsPermissionType
+}
+
+// ---------------------
+// DTOs
+
+type DataSourceAclInfoDTO struct {
+	Id            int64  `json:"id"`
+	DataSourceId  int64  `json:"datasourceId"`
+	UserId        int64  `json:"userId,omitempty"`
+	UserLogin     string `json:"userLogin,omitempty"`
+	UserEmail     string `json:"userEmail,omitempty"`
+	UserAvatarUrl string `json:"userAvatarUrl,omitempty"`
+	TeamId        int64  `json:"teamId,omitempty"`
+	Team          string `json:"team,omitempty"`
+	TeamEmail     string `json:"-"`
+	TeamAvatarUrl string `json:"teamAvatarUrl,omitempty"`
+
+	Permission     DsPermissionType `json:"permission"`
+	PermissionName string           `json:"permissionName"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type DataSourcePermissionsDTO struct {
+	DataSourceId int64                   `json:"datasourceId"`
+	Enabled      bool                    `json:"enabled"`
+	Permissions  []*DataSourceAclInfoDTO `json:"permissions"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// ExternalAlert is an alert sent to Grafana by an external tool through
+// the Alertmanager compatible API. Alerts with the same labels are the
+// same alert and are identified by the fingerprint of their labels.
+type ExternalAlert struct {
+	Id           int64
+	OrgId        int64
+	Fingerprint  string
+	Labels       map[string]string
+	Annotations  map[string]string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	GeneratorUrl string
+	Updated      time.Time
+}
+
+// ExternalAlertResolveTimeout is how long an alert sent without an end time
+// is considered firing, unless it is sent again. Alerts are also kept for
+// this long after they end, so their groups get notified as resolved.
+const ExternalAlertResolveTimeout = 5 * time.Minute
+
+// IsFiring returns true if the alert is firing at the given time.
+func (a *ExternalAlert) IsFiring(now time.Time) bool {
+	return !a.StartsAt.After(now) && a.EndsAt.After(now)
+}
+
+// ExternalAlertFingerprint returns the fingerprint of the labels of an
+// external alert.
+func ExternalAlertFingerprint(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write([]byte{0xff})
+		hash.Write([]byte(labels[name]))
+		hash.Write([]byte{0xff})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// SaveExternalAlertsCommand stores external alerts, updating the alerts
+// that are already stored with the same labels.
+type SaveExternalAlertsCommand struct {
+	OrgId  int64
+	Alerts []*ExternalAlert
+}
+
+// GetExternalAlertsQuery returns the external alerts of an organization,
+// or of all organizations when OrgId is 0. When EndedAfter is set, the
+// alerts that ended before it are left out.
+type GetExternalAlertsQuery struct {
+	OrgId      int64
+	EndedAfter time.Time
+
+	Result []*ExternalAlert
+}
+
+// GetExternalAlertOrgIdsQuery returns the organizations that have external
+// alerts which ended after EndedAfter.
+type GetExternalAlertOrgIdsQuery struct {
+	EndedAfter time.Time
+
+	Result []int64
+}
+
+// DeleteExpiredExternalAlertsCommand deletes the external alerts that
+// ended before OlderThan.
+type DeleteExpiredExternalAlertsCommand struct {
+	OlderThan time.Time
+
+	DeletedRows int64
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrRecordingRuleNotFound         = errors.New("Recording rule not found")
+	ErrRecordingRuleInvalidName      = errors.New("Recording rule name must be a valid metric name")
+	ErrRecordingRuleInvalidLabel     = errors.New("Recording rule label names must be valid label names other than __name__ and org_id")
+	ErrRecordingRuleInvalidFrequency = errors.New("Recording rule frequency must be at least 10s")
+	ErrRecordingRuleInvalidRange     = errors.New("Recording rule range is invalid")
+	ErrRecordingRuleInvalidReducer   = errors.New("Recording rule reducer is invalid")
+	ErrRecordingRuleNameTaken        = errors.New("A recording rule with the same name already exists")
+)
+
+// RecordingRuleReducers are the reducers a recording rule can use, the
+// same as the ones of alert conditions.
+var RecordingRuleReducers = []string{"avg", "min", "max", "sum", "count", "last", "median", "diff", "diff_abs", "percent_diff", "percent_diff_abs", "count_non_null"}
+
+// RecordingRule is a query of a data source that is evaluated every
+// Frequency seconds over the last QueryRange, with its result written as
+// a new series named Name. When Reducer is set each series of the result
+// is reduced to a single value, otherwise all of its points are written.
+// Names are unique per organization, and the recorded series get the id of
+// the organization as the org_id label.
+type RecordingRule struct {
+	Id           int64
+	OrgId        int64
+	Name         string
+	DatasourceId int64
+	Query        *simplejson.Json
+	QueryRange   string
+	Frequency    int64
+	Reducer      string
+	Labels       map[string]string
+	Paused       bool
+	CreatedBy    int64
+	Created      time.Time
+	Updated      time.Time
+}
+
+type CreateRecordingRuleCommand struct {
+	Name         string            `json:"name" binding:"Required"`
+	DatasourceId int64             `json:"datasourceId" binding:"Required"`
+	Query        *simplejson.Json  `json:"query" binding:"Required"`
+	QueryRange   string            `json:"range"`
+	Frequency    string            `json:"frequency" binding:"Required"`
+	Reducer      string            `json:"reducer"`
+	Labels       map[string]string `json:"labels"`
+	Paused       bool              `json:"paused"`
+
+	OrgId     int64 `json:"-"`
+	CreatedBy int64 `json:"-"`
+	Result    *RecordingRule
+}
+
+type UpdateRecordingRuleCommand struct {
+	Id           int64             `json:"-"`
+	Name         string            `json:"name" binding:"Required"`
+	DatasourceId int64             `json:"datasourceId" binding:"Required"`
+	Query        *simplejson.Json  `json:"query" binding:"Required"`
+	QueryRange   string            `json:"range"`
+	Frequency    string            `json:"frequency" binding:"Required"`
+	Reducer      string            `json:"reducer"`
+	Labels       map[string]string `json:"labels"`
+	Paused       bool              `json:"paused"`
+
+	OrgId  int64 `json:"-"`
+	Result *RecordingRule
+}
+
+type DeleteRecordingRuleCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type GetRecordingRuleByIdQuery struct {
+	Id    int64
+	OrgId int64
+
+	Result *RecordingRule
+}
+
+// GetRecordingRulesQuery returns the recording rules of an organization,
+// or of all organizations when OrgId is 0.
+type GetRecordingRulesQuery struct {
+	OrgId int64
+
+	Result []*RecordingRule
+}
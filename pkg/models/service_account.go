@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrServiceAccountNotFound  = errors.New("Service account not found")
+	ErrServiceAccountNameTaken = errors.New("Service account name is taken")
+)
+
+// ServiceAccountLoginPrefix prefixes the logins of service accounts, which
+// are users that cannot log in and authenticate with tokens instead.
+const ServiceAccountLoginPrefix = "sa-"
+
+// ServiceAccountTokenKeyName returns the name a token of a service account is
+// stored with as an api key. Api key names are unique per org, so the name is
+// prefixed with the service account to let accounts reuse token names.
+func ServiceAccountTokenKeyName(serviceAccountId int64, name string) string {
+	return fmt.Sprintf("%s%d-%s", ServiceAccountLoginPrefix, serviceAccountId, name)
+}
+
+// ServiceAccountTokenName returns the name of a token given when it was added.
+func ServiceAccountTokenName(serviceAccountId int64, keyName string) string {
+	return strings.TrimPrefix(keyName, fmt.Sprintf("%s%d-", ServiceAccountLoginPrefix, serviceAccountId))
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateServiceAccountCommand struct {
+	Name  string   `json:"name" binding:"Required"`
+	Role  RoleType `json:"role" binding:"Required"`
+	OrgId int64    `json:"-"`
+
+	Result *ServiceAccountDTO `json:"-"`
+}
+
+type UpdateServiceAccountCommand struct {
+	Id    int64    `json:"-"`
+	Name  string   `json:"name"`
+	Role  RoleType `json:"role"`
+	OrgId int64    `json:"-"`
+}
+
+type DeleteServiceAccountCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type AddServiceAccountTokenCommand struct {
+	Name             string `json:"name" binding:"Required"`
+	SecondsToLive    int64  `json:"secondsToLive"`
+	OrgId            int64  `json:"-"`
+	ServiceAccountId int64  `json:"-"`
+	Key              string `json:"-"`
+
+	Result *ApiKey `json:"-"`
+}
+
+type DeleteServiceAccountTokenCommand struct {
+	Id               int64
+	OrgId            int64
+	ServiceAccountId int64
+}
+
+// ----------------------
+// QUERIES
+
+type GetServiceAccountsQuery struct {
+	OrgId  int64
+	Result []*ServiceAccountDTO
+}
+
+type GetServiceAccountByIdQuery struct {
+	Id     int64
+	OrgId  int64
+	Result *ServiceAccountDTO
+}
+
+type GetServiceAccountTokensQuery struct {
+	OrgId            int64
+	ServiceAccountId int64
+	Result           []*ApiKey
+}
+
+// ------------------------
+// DTO & Projections
+
+type ServiceAccountDTO struct {
+	Id      int64     `json:"id"`
+	OrgId   int64     `json:"orgId"`
+	Name    string    `json:"name"`
+	Login   string    `json:"login"`
+	Role    RoleType  `json:"role"`
+	Tokens  int64     `json:"tokens"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type ServiceAccountTokenDTO struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Created    time.Time  `json:"created"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
@@ -32,6 +32,10 @@ type User struct {
 	HelpFlags1    HelpFlags1
 	IsDisabled    bool
 
+	// IsServiceAccount is true for users which cannot log in and are
+	// authenticated by the tokens of the service account.
+	IsServiceAccount bool
+
 	IsAdmin bool
 	OrgId   int64
 
@@ -169,20 +173,21 @@ type GetUserOrgListQuery struct {
 // DTO & Projections
 
 type SignedInUser struct {
-	UserId         int64
-	OrgId          int64
-	OrgName        string
-	OrgRole        RoleType
-	Login          string
-	Name           string
-	Email          string
-	ApiKeyId       int64
-	OrgCount       int
-	IsGrafanaAdmin bool
-	IsAnonymous    bool
-	HelpFlags1     HelpFlags1
-	LastSeenAt     time.Time
-	Teams          []int64
+	UserId           int64
+	OrgId            int64
+	OrgName          string
+	OrgRole          RoleType
+	Login            string
+	Name             string
+	Email            string
+	ApiKeyId         int64
+	OrgCount         int
+	IsGrafanaAdmin   bool
+	IsAnonymous      bool
+	IsServiceAccount bool
+	HelpFlags1       HelpFlags1
+	LastSeenAt       time.Time
+	Teams            []int64
 }
 
 func (u *SignedInUser) ShouldUpdateLastSeenAt() bool {
@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -42,10 +43,10 @@ func (e DashboardInputMissingError) Error() string {
 }
 
 func init() {
-	bus.AddHandler("plugins", ImportDashboard)
+	bus.AddHandlerCtx("plugins", ImportDashboard)
 }
 
-func ImportDashboard(cmd *ImportDashboardCommand) error {
+func ImportDashboard(ctx context.Context, cmd *ImportDashboardCommand) error {
 	var dashboard *models.Dashboard
 	var err error
 
@@ -83,7 +84,7 @@ func ImportDashboard(cmd *ImportDashboardCommand) error {
 		User:      cmd.User,
 	}
 
-	savedDash, err := dashboards.NewService().ImportDashboard(dto)
+	savedDash, err := dashboards.NewService().ImportDashboard(ctx, dto)
 
 	if err != nil {
 		return err
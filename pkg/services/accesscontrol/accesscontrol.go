@@ -0,0 +1,202 @@
+package accesscontrol
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Permission actions
+const (
+	ActionDatasourcesRead   = "datasources:read"
+	ActionDatasourcesQuery  = "datasources:query"
+	ActionDatasourcesCreate = "datasources:create"
+	ActionDatasourcesWrite  = "datasources:write"
+	ActionDatasourcesDelete = "datasources:delete"
+
+	ActionAlertNotificationsRead  = "alert.notifications:read"
+	ActionAlertNotificationsWrite = "alert.notifications:write"
+
+	ActionUsersRead  = "users:read"
+	ActionUsersWrite = "users:write"
+
+	ActionRolesRead  = "roles:read"
+	ActionRolesWrite = "roles:write"
+)
+
+// Actions lists the actions that permissions of custom roles can grant.
+var Actions = []string{
+	ActionDatasourcesRead,
+	ActionDatasourcesQuery,
+	ActionDatasourcesCreate,
+	ActionDatasourcesWrite,
+	ActionDatasourcesDelete,
+	ActionAlertNotificationsRead,
+	ActionAlertNotificationsWrite,
+	ActionUsersRead,
+	ActionUsersWrite,
+	ActionRolesRead,
+	ActionRolesWrite,
+}
+
+// Permission scopes
+const (
+	ScopeAll                   = "*"
+	ScopeDatasourcesAll        = "datasources:*"
+	ScopeAlertNotificationsAll = "alert.notifications:*"
+	ScopeUsersAll              = "users:*"
+	ScopeRolesAll              = "roles:*"
+)
+
+// scopePrefixes lists the resources that scopes of permissions can refer to.
+var scopePrefixes = []string{
+	"datasources:",
+	"alert.notifications:",
+	"users:",
+	"roles:",
+}
+
+func ScopeDatasourceID(id int64) string {
+	return fmt.Sprintf("datasources:id:%d", id)
+}
+
+func ScopeDatasourceUID(uid string) string {
+	return "datasources:uid:" + uid
+}
+
+func ScopeDatasourceName(name string) string {
+	return "datasources:name:" + name
+}
+
+// ScopesDatasource returns the scopes matching a data source, so that a
+// permission can refer to it by id, uid or name.
+func ScopesDatasource(ds *models.DataSource) []string {
+	return []string{ScopeDatasourceID(ds.Id), ScopeDatasourceUID(ds.Uid), ScopeDatasourceName(ds.Name)}
+}
+
+var viewerPermissions = []*models.Permission{
+	{Action: ActionDatasourcesQuery, Scope: ScopeDatasourcesAll},
+}
+
+var editorPermissions = append([]*models.Permission{
+	{Action: ActionAlertNotificationsRead, Scope: ScopeAlertNotificationsAll},
+	{Action: ActionAlertNotificationsWrite, Scope: ScopeAlertNotificationsAll},
+}, viewerPermissions...)
+
+var adminPermissions = append([]*models.Permission{
+	{Action: ActionDatasourcesRead, Scope: ScopeDatasourcesAll},
+	{Action: ActionDatasourcesCreate, Scope: ScopeDatasourcesAll},
+	{Action: ActionDatasourcesWrite, Scope: ScopeDatasourcesAll},
+	{Action: ActionDatasourcesDelete, Scope: ScopeDatasourcesAll},
+	{Action: ActionUsersRead, Scope: ScopeUsersAll},
+	{Action: ActionUsersWrite, Scope: ScopeUsersAll},
+	{Action: ActionRolesRead, Scope: ScopeRolesAll},
+	{Action: ActionRolesWrite, Scope: ScopeRolesAll},
+}, editorPermissions...)
+
+// BuiltInRolePermissions returns the permissions granted by an org role.
+func BuiltInRolePermissions(role models.RoleType) []*models.Permission {
+	switch role {
+	case models.ROLE_ADMIN:
+		return adminPermissions
+	case models.ROLE_EDITOR:
+		return editorPermissions
+	case models.ROLE_VIEWER:
+		return viewerPermissions
+	}
+	return nil
+}
+
+// IsValidAction reports whether a custom role can grant the action.
+func IsValidAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidScope reports whether a custom role can grant a permission with the
+// scope, which must be `*` or refer to a known resource.
+func IsValidScope(scope string) bool {
+	if scope == ScopeAll {
+		return true
+	}
+	for _, prefix := range scopePrefixes {
+		if strings.HasPrefix(scope, prefix) && len(scope) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserPermissions returns the permissions of the org role of a user, and
+// of the custom roles added to the user or its teams.
+func GetUserPermissions(user *models.SignedInUser) ([]*models.Permission, error) {
+	permissions := BuiltInRolePermissions(user.OrgRole)
+
+	// org admins have every permission, and api keys only have the
+	// permissions of their role
+	if user.OrgRole == models.ROLE_ADMIN || user.UserId == 0 {
+		return permissions, nil
+	}
+
+	query := models.GetUserPermissionsQuery{OrgId: user.OrgId, UserId: user.UserId}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	return append(query.Result, permissions...), nil
+}
+
+// Evaluate reports whether the permissions grant the action on any of the
+// scopes. Without scopes, the action on any resource is enough.
+func Evaluate(permissions []*models.Permission, action string, scopes ...string) bool {
+	for _, p := range permissions {
+		if p.Action != action {
+			continue
+		}
+		if len(scopes) == 0 {
+			return true
+		}
+		for _, scope := range scopes {
+			if scopeMatches(p.Scope, scope) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasAccess reports whether the user has been granted the action on any of
+// the scopes.
+var HasAccess = func(user *models.SignedInUser, action string, scopes ...string) (bool, error) {
+	// most checks are granted by the org role, without looking up custom roles
+	if Evaluate(BuiltInRolePermissions(user.OrgRole), action, scopes...) {
+		return true, nil
+	}
+
+	permissions, err := GetUserPermissions(user)
+	if err != nil {
+		return false, err
+	}
+
+	return Evaluate(permissions, action, scopes...), nil
+}
+
+// scopeMatches reports whether the scope of a permission matches a scope,
+// with a trailing `*` matching any suffix.
+func scopeMatches(permissionScope string, scope string) bool {
+	if permissionScope == ScopeAll || permissionScope == scope {
+		return true
+	}
+
+	if strings.HasSuffix(permissionScope, "*") {
+		return strings.HasPrefix(scope, strings.TrimSuffix(permissionScope, "*"))
+	}
+
+	return false
+}
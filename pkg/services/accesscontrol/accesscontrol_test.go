@@ -0,0 +1,103 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	permissions := []*models.Permission{
+		{Action: ActionDatasourcesQuery, Scope: "datasources:uid:abc"},
+		{Action: ActionDatasourcesRead, Scope: ScopeDatasourcesAll},
+		{Action: ActionAlertNotificationsWrite},
+	}
+
+	tests := []struct {
+		name     string
+		action   string
+		scopes   []string
+		expected bool
+	}{
+		{"exact scope", ActionDatasourcesQuery, []string{"datasources:uid:abc"}, true},
+		{"any of the scopes", ActionDatasourcesQuery, []string{"datasources:id:1", "datasources:uid:abc"}, true},
+		{"other scope", ActionDatasourcesQuery, []string{"datasources:uid:abcd"}, false},
+		{"wildcard scope", ActionDatasourcesRead, []string{"datasources:uid:xyz"}, true},
+		{"wildcard scope of other resource", ActionDatasourcesRead, []string{"users:id:1"}, false},
+		{"empty scope", ActionAlertNotificationsWrite, []string{ScopeAlertNotificationsAll}, false},
+		{"without scopes", ActionDatasourcesQuery, nil, true},
+		{"missing action", ActionDatasourcesDelete, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Evaluate(permissions, tt.action, tt.scopes...))
+		})
+	}
+}
+
+func TestIsValidScope(t *testing.T) {
+	assert.True(t, IsValidScope(ScopeAll))
+	assert.True(t, IsValidScope(ScopeDatasourcesAll))
+	assert.True(t, IsValidScope(ScopeDatasourceUID("abc")))
+	assert.True(t, IsValidScope("alert.notifications:uid:email"))
+
+	assert.False(t, IsValidScope(""))
+	assert.False(t, IsValidScope("datasources:"))
+	assert.False(t, IsValidScope("dashboards:*"))
+	assert.False(t, IsValidScope("datasources"))
+}
+
+func TestHasAccess(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	lookups := 0
+	bus.AddHandler("test", func(query *models.GetUserPermissionsQuery) error {
+		lookups++
+		if query.UserId == 2 {
+			query.Result = []*models.Permission{{Action: ActionAlertNotificationsWrite, Scope: ScopeAlertNotificationsAll}}
+		}
+		return nil
+	})
+
+	t.Run("Should grant permissions of org role without lookup", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		hasAccess, err := HasAccess(user, ActionDatasourcesQuery, ScopeDatasourceUID("abc"))
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+		assert.Equal(t, 0, lookups)
+	})
+
+	t.Run("Should grant permissions of custom roles", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		hasAccess, err := HasAccess(user, ActionAlertNotificationsWrite, ScopeAlertNotificationsAll)
+		require.NoError(t, err)
+		assert.True(t, hasAccess)
+
+		hasAccess, err = HasAccess(user, ActionUsersRead, ScopeUsersAll)
+		require.NoError(t, err)
+		assert.False(t, hasAccess)
+	})
+
+	t.Run("Should only grant permissions of role to api keys", func(t *testing.T) {
+		lookups = 0
+		user := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR}
+		hasAccess, err := HasAccess(user, ActionUsersRead, ScopeUsersAll)
+		require.NoError(t, err)
+		assert.False(t, hasAccess)
+		assert.Equal(t, 0, lookups)
+	})
+
+	t.Run("Should grant every action to org admins", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 3, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+		for _, action := range Actions {
+			hasAccess, err := HasAccess(user, action, "datasources:uid:abc", ScopeAlertNotificationsAll, ScopeUsersAll, ScopeRolesAll)
+			require.NoError(t, err)
+			assert.True(t, hasAccess, action)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb/expressions"
 )
 
 // DatasourceAlertUsage is a hash where the key represents the
@@ -61,6 +62,11 @@ func (ae *AlertEngine) mapRulesToUsageStats(rules []*models.Alert) (DatasourceAl
 	// map of datsource types and frequency
 	result := map[string]int{}
 	for k, v := range typeCount {
+		if k == expressions.DatasourceID {
+			result[expressions.DatasourceName] += v
+			continue
+		}
+
 		query := &models.GetDataSourceByIdQuery{Id: k}
 		err := ae.Bus.Dispatch(query)
 		if err != nil {
@@ -93,18 +99,42 @@ func (ae *AlertEngine) parseAlertRuleModel(settings json.Marshaler) ([]int64, er
 	}
 
 	for _, condition := range model.Conditions {
-		datasourceIDs = append(datasourceIDs, condition.Query.DatasourceID)
+		if condition.Query != nil {
+			datasourceIDs = append(datasourceIDs, condition.Query.datasourceIDs()...)
+		}
+
+		for _, q := range condition.Queries {
+			if q.Query != nil {
+				datasourceIDs = append(datasourceIDs, q.Query.datasourceIDs()...)
+			}
+		}
 	}
 
 	return datasourceIDs, nil
 }
 
 type alertCondition struct {
+	Query   *conditionQuery       `json:"query"`
+	Queries []*mathConditionQuery `json:"queries"`
+}
+
+type mathConditionQuery struct {
 	Query *conditionQuery `json:"query"`
 }
 
 type conditionQuery struct {
-	DatasourceID int64 `json:"datasourceId"`
+	DatasourceID int64             `json:"datasourceId"`
+	Inputs       []*conditionQuery `json:"inputs"`
+}
+
+// datasourceIDs returns the id of the datasource of the query and of the
+// datasources of its inputs if it is an expression.
+func (q *conditionQuery) datasourceIDs() []int64 {
+	ids := []int64{q.DatasourceID}
+	for _, input := range q.Inputs {
+		ids = append(ids, input.DatasourceID)
+	}
+	return ids
 }
 
 type alertJSONModel struct {
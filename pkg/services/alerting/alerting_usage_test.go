@@ -31,6 +31,7 @@ func TestAlertingUsageStats(t *testing.T) {
 			{Id: 2, Settings: createFake("testdata/settings/two_conditions.json")},
 			{Id: 2, Settings: createFake("testdata/settings/three_conditions.json")},
 			{Id: 3, Settings: createFake("testdata/settings/empty.json")},
+			{Id: 4, Settings: createFake("testdata/settings/expression_condition.json")},
 		}
 		return nil
 	})
@@ -59,8 +60,9 @@ func TestAlertingUsageStats(t *testing.T) {
 	require.NoError(t, err, "getAlertingUsage should not return error")
 
 	expected := map[string]int{
-		"prometheus": 4,
+		"prometheus": 6,
 		"graphite":   2,
+		"__expr__":   1,
 	}
 
 	for k := range expected {
@@ -89,6 +91,18 @@ func TestParsingAlertRuleSettings(t *testing.T) {
 			expected:  []int64{3, 2},
 			shouldErr: require.NoError,
 		},
+		{
+			name:      "can parse math condition",
+			file:      "testdata/settings/math_condition.json",
+			expected:  []int64{3, 4},
+			shouldErr: require.NoError,
+		},
+		{
+			name:      "can parse expression condition",
+			file:      "testdata/settings/expression_condition.json",
+			expected:  []int64{-100, 3, 4},
+			shouldErr: require.NoError,
+		},
 		{
 			name:      "can parse empty json",
 			file:      "testdata/settings/empty.json",
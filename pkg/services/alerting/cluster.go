@@ -0,0 +1,204 @@
+package alerting
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// ringReplicas is the number of points each node has on the hash ring,
+	// which evens out the number of alert rules each node gets.
+	ringReplicas = 100
+
+	// missedHeartbeats is the number of heartbeats a node can miss before
+	// the other nodes take over its alert rules.
+	missedHeartbeats = 3
+
+	// expiredNodeTimeout is how long after its last heartbeat a node is
+	// removed from the database.
+	expiredNodeTimeout = 24 * time.Hour
+)
+
+// hashRing assigns keys to nodes with consistent hashing, so that when a
+// node joins or leaves only the keys of that node move.
+type hashRing struct {
+	points []uint64
+	nodes  map[uint64]string
+}
+
+func newHashRing(nodeIDs []string) *hashRing {
+	ring := &hashRing{nodes: make(map[uint64]string)}
+
+	for _, nodeID := range nodeIDs {
+		for i := 0; i < ringReplicas; i++ {
+			point := ringHash(nodeID + "#" + strconv.Itoa(i))
+			if _, exists := ring.nodes[point]; exists {
+				continue
+			}
+			ring.nodes[point] = nodeID
+			ring.points = append(ring.points, point)
+		}
+	}
+
+	sort.Slice(ring.points, func(i, j int) bool { return ring.points[i] < ring.points[j] })
+	return ring
+}
+
+// get returns the node the key is assigned to, which is the node of the
+// first point on the ring at or after the hash of the key.
+func (r *hashRing) get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	hash := ringHash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.nodes[r.points[i]]
+}
+
+// ringHash hashes keys and nodes onto the ring. Unlike fnv it spreads
+// short keys such as alert rule ids evenly.
+func ringHash(key string) uint64 {
+	sum := md5.Sum([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// alertingCluster distributes the evaluation of alert rules between the
+// Grafana servers of a high availability setup. Each server registers
+// itself as a node in the database and keeps sending heartbeats. The
+// nodes that sent a heartbeat recently are the members of the cluster,
+// and each alert rule is evaluated by the member it hashes to.
+type alertingCluster struct {
+	nodeID   string
+	interval time.Duration
+	log      log.Logger
+
+	mu      sync.RWMutex
+	members []string
+	ring    *hashRing
+}
+
+func newAlertingCluster() *alertingCluster {
+	// the random suffix keeps node ids unique when servers share a name,
+	// and lets a restarted server join as a new node.
+	nodeID := fmt.Sprintf("%s-%s", setting.InstanceName, util.GenerateShortUID())
+
+	c := &alertingCluster{
+		nodeID:   nodeID,
+		interval: setting.AlertingHAHeartbeatInterval,
+		log:      log.New("alerting.cluster"),
+	}
+
+	// until the first heartbeat this node evaluates all alert rules
+	c.setMembers([]string{nodeID})
+	return c
+}
+
+func (c *alertingCluster) run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.leave()
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := c.heartbeat(ctx, now); err != nil {
+				c.log.Error("Failed to send alerting heartbeat", "error", err)
+			}
+		}
+	}
+}
+
+// heartbeat records that this node is alive and refreshes the members of
+// the cluster. When the members cannot be loaded the previous ones are
+// kept.
+func (c *alertingCluster) heartbeat(ctx context.Context, now time.Time) error {
+	cmd := &models.SaveAlertingNodeHeartbeatCommand{NodeId: c.nodeID, Heartbeat: now.Unix()}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return err
+	}
+
+	activeSince := now.Add(-c.interval * missedHeartbeats)
+	query := &models.GetAlertingNodesQuery{ActiveSince: activeSince.Unix()}
+	if err := bus.DispatchCtx(ctx, query); err != nil {
+		return err
+	}
+
+	members := []string{c.nodeID}
+	for _, node := range query.Result {
+		if node.NodeId != c.nodeID {
+			members = append(members, node.NodeId)
+		}
+	}
+	sort.Strings(members)
+	c.setMembers(members)
+
+	expired := &models.DeleteExpiredAlertingNodesCommand{OlderThan: now.Add(-expiredNodeTimeout).Unix()}
+	if err := bus.DispatchCtx(ctx, expired); err != nil {
+		c.log.Warn("Failed to delete expired alerting nodes", "error", err)
+	}
+
+	return nil
+}
+
+func (c *alertingCluster) setMembers(members []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if strings.Join(members, ",") == strings.Join(c.members, ",") {
+		return
+	}
+
+	if c.members != nil {
+		c.log.Info("Alerting cluster members changed", "node", c.nodeID, "members", strings.Join(members, ","))
+	}
+
+	c.members = members
+	c.ring = newHashRing(members)
+}
+
+// leave removes this node from the database, so that the other nodes take
+// over its alert rules on their next heartbeat.
+func (c *alertingCluster) leave() {
+	if err := bus.Dispatch(&models.DeleteAlertingNodeCommand{NodeId: c.nodeID}); err != nil {
+		c.log.Warn("Failed to remove alerting node", "node", c.nodeID, "error", err)
+	}
+}
+
+// owns returns true if the key is assigned to this node.
+func (c *alertingCluster) owns(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.ring.get(key) == c.nodeID
+}
+
+// filterRules returns the alert rules that this node evaluates.
+func (c *alertingCluster) filterRules(rules []*Rule) []*Rule {
+	result := make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		if c.owns(strconv.FormatInt(rule.ID, 10)) {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
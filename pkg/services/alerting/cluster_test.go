@@ -0,0 +1,134 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]string, 3000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	ring := newHashRing([]string{"a", "b", "c"})
+	counts := map[string]int{}
+	for _, key := range keys {
+		counts[ring.get(key)]++
+	}
+
+	require.Len(t, counts, 3)
+	for node, count := range counts {
+		assert.Greater(t, count, 600, "node %s", node)
+	}
+
+	t.Run("only keys of removed node move", func(t *testing.T) {
+		smaller := newHashRing([]string{"a", "c"})
+		for _, key := range keys {
+			if before := ring.get(key); before != "b" {
+				assert.Equal(t, before, smaller.get(key))
+			}
+		}
+	})
+
+	t.Run("empty ring", func(t *testing.T) {
+		assert.Equal(t, "", newHashRing(nil).get("1"))
+	})
+}
+
+func TestAlertingCluster(t *testing.T) {
+	heartbeats := map[string]int64{}
+
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SaveAlertingNodeHeartbeatCommand) error {
+		heartbeats[cmd.NodeId] = cmd.Heartbeat
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertingNodesQuery) error {
+		query.Result = nil
+		for nodeID, heartbeat := range heartbeats {
+			if heartbeat >= query.ActiveSince {
+				query.Result = append(query.Result, &models.AlertingNode{NodeId: nodeID, Heartbeat: heartbeat})
+			}
+		}
+		sort.Slice(query.Result, func(i, j int) bool { return query.Result[i].NodeId < query.Result[j].NodeId })
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.DeleteExpiredAlertingNodesCommand) error {
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.DeleteAlertingNodeCommand) error {
+		delete(heartbeats, cmd.NodeId)
+		return nil
+	})
+
+	newNode := func(nodeID string) *alertingCluster {
+		c := &alertingCluster{nodeID: nodeID, interval: 10 * time.Second, log: log.New("test")}
+		c.setMembers([]string{nodeID})
+		return c
+	}
+
+	rules := make([]*Rule, 100)
+	for i := range rules {
+		rules[i] = &Rule{ID: int64(i + 1), Name: fmt.Sprintf("rule %d", i+1)}
+	}
+
+	ruleIDs := func(rules []*Rule) []int64 {
+		ids := make([]int64, 0, len(rules))
+		for _, rule := range rules {
+			ids = append(ids, rule.ID)
+		}
+		return ids
+	}
+
+	start := time.Now()
+	a := newNode("grafana-a")
+	b := newNode("grafana-b")
+
+	t.Run("single node evaluates all rules", func(t *testing.T) {
+		require.NoError(t, a.heartbeat(context.Background(), start))
+		assert.Len(t, a.filterRules(rules), len(rules))
+	})
+
+	t.Run("rules are split between nodes", func(t *testing.T) {
+		require.NoError(t, b.heartbeat(context.Background(), start))
+		require.NoError(t, a.heartbeat(context.Background(), start))
+
+		rulesA := a.filterRules(rules)
+		rulesB := b.filterRules(rules)
+		assert.NotEmpty(t, rulesA)
+		assert.NotEmpty(t, rulesB)
+
+		all := append(ruleIDs(rulesA), ruleIDs(rulesB)...)
+		assert.ElementsMatch(t, ruleIDs(rules), all)
+	})
+
+	t.Run("rules of dead node are taken over", func(t *testing.T) {
+		require.NoError(t, a.heartbeat(context.Background(), start.Add(20*time.Second)))
+		assert.Less(t, len(a.filterRules(rules)), len(rules))
+
+		require.NoError(t, a.heartbeat(context.Background(), start.Add(31*time.Second)))
+		assert.Len(t, a.filterRules(rules), len(rules))
+	})
+
+	t.Run("rules of node that left are taken over", func(t *testing.T) {
+		now := start.Add(time.Minute)
+		require.NoError(t, b.heartbeat(context.Background(), now))
+		require.NoError(t, a.heartbeat(context.Background(), now))
+		assert.Less(t, len(a.filterRules(rules)), len(rules))
+
+		b.leave()
+		require.NoError(t, a.heartbeat(context.Background(), now.Add(time.Second)))
+		assert.Len(t, a.filterRules(rules), len(rules))
+	})
+}
@@ -0,0 +1,273 @@
+package conditions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/mathexp"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+func init() {
+	alerting.RegisterCondition("math", func(model *simplejson.Json, index int) (alerting.Condition, error) {
+		return newMathCondition(model, index)
+	})
+}
+
+// MathCondition executes several queries, reduces each of their series
+// and combines the reduced values with an expression such as
+// `$A / $B * 100 > 5`.
+//
+// Series from different queries are matched by their tags (or by name
+// when they have no tags). A query returning a single series is matched
+// against every series of the other queries.
+type MathCondition struct {
+	Index         int
+	Expression    *mathexp.Expression
+	Queries       []*QueryCondition
+	Evaluator     AlertEvaluator
+	Operator      string
+	HandleRequest tsdb.HandleRequestFunc
+}
+
+// mathRow holds the reduced values for one set of matched series.
+type mathRow struct {
+	name   string
+	tags   map[string]string
+	values map[string]null.Float
+}
+
+// Eval evaluates the `MathCondition`.
+func (c *MathCondition) Eval(context *alerting.EvalContext) (*alerting.ConditionResult, error) {
+	reduced := make(map[string]map[string]*reducedSeries, len(c.Queries))
+	keyOrder := []string{}
+	seenKeys := map[string]bool{}
+
+	for _, query := range c.Queries {
+		query.HandleRequest = c.HandleRequest
+		timeRange := tsdb.NewTimeRange(query.Query.From, query.Query.To)
+
+		seriesList, err := query.executeQuery(context, timeRange)
+		if err != nil {
+			return nil, err
+		}
+
+		refID := query.Query.RefID
+		reduced[refID] = make(map[string]*reducedSeries, len(seriesList))
+
+		for _, series := range seriesList {
+			key := seriesKey(series)
+			reduced[refID][key] = &reducedSeries{
+				series: series,
+				value:  query.Reducer.Reduce(series),
+			}
+
+			if len(seriesList) > 1 && !seenKeys[key] {
+				seenKeys[key] = true
+				keyOrder = append(keyOrder, key)
+			}
+		}
+	}
+
+	rows := c.buildRows(reduced, keyOrder)
+
+	emptyRowCount := 0
+	evalMatchCount := 0
+	var matches []*alerting.EvalMatch
+
+	for _, row := range rows {
+		hasNull := false
+		for _, v := range row.values {
+			if !v.Valid {
+				hasNull = true
+			}
+		}
+		if hasNull {
+			emptyRowCount++
+		}
+
+		result := c.Expression.Eval(row.values)
+		evalMatch := c.evalResult(result)
+
+		if context.IsTestRun {
+			context.Logs = append(context.Logs, &alerting.ResultLogEntry{
+				Message: fmt.Sprintf("Condition[%d]: Eval: %v, Metric: %s, Expression: %s, Value: %s", c.Index, evalMatch, row.name, c.Expression.Text, result),
+			})
+		}
+
+		if evalMatch {
+			evalMatchCount++
+
+			matches = append(matches, &alerting.EvalMatch{
+				Metric: row.name,
+				Value:  result,
+				Tags:   row.tags,
+			})
+		}
+	}
+
+	return &alerting.ConditionResult{
+		Firing:      evalMatchCount > 0,
+		NoDataFound: emptyRowCount == len(rows),
+		Operator:    c.Operator,
+		EvalMatches: matches,
+	}, nil
+}
+
+// evalResult decides if the expression result is firing. Without an
+// evaluator any valid non zero result fires, which makes expressions
+// containing a comparison such as `$A > 5` work as expected.
+func (c *MathCondition) evalResult(result null.Float) bool {
+	if c.Evaluator != nil {
+		return c.Evaluator.Eval(result)
+	}
+
+	return result.Valid && result.Float64 != 0
+}
+
+func (c *MathCondition) buildRows(reduced map[string]map[string]*reducedSeries, keys []string) []*mathRow {
+	// no query returned more than one series, so there is a single row,
+	// tagged with the tags of the series by ref id, the first setting a tag wins
+	if len(keys) == 0 {
+		refIDs := make([]string, 0, len(reduced))
+		for refID := range reduced {
+			refIDs = append(refIDs, refID)
+		}
+		sort.Strings(refIDs)
+
+		row := &mathRow{name: "NoData", values: map[string]null.Float{}}
+		for _, refID := range refIDs {
+			row.values[refID] = null.FloatFromPtr(nil)
+			for _, s := range reduced[refID] {
+				row.name = c.Expression.Text
+				row.values[refID] = s.value
+				for k, v := range s.series.Tags {
+					if row.tags == nil {
+						row.tags = map[string]string{}
+					}
+					if _, ok := row.tags[k]; !ok {
+						row.tags[k] = v
+					}
+				}
+			}
+		}
+		return []*mathRow{row}
+	}
+
+	rows := make([]*mathRow, 0, len(keys))
+	for _, key := range keys {
+		row := &mathRow{values: map[string]null.Float{}}
+
+		for _, query := range c.Queries {
+			refID := query.Query.RefID
+			seriesByKey := reduced[refID]
+			row.values[refID] = null.FloatFromPtr(nil)
+
+			var match *reducedSeries
+			if len(seriesByKey) == 1 {
+				for _, s := range seriesByKey {
+					match = s
+				}
+			} else {
+				match = seriesByKey[key]
+			}
+
+			if match == nil {
+				continue
+			}
+
+			row.values[refID] = match.value
+			if len(seriesByKey) > 1 && row.name == "" {
+				row.name = match.series.Name
+				row.tags = match.series.Tags
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+type reducedSeries struct {
+	series *tsdb.TimeSeries
+	value  null.Float
+}
+
+// seriesKey returns the key used to match series from different queries.
+func seriesKey(series *tsdb.TimeSeries) string {
+	if len(series.Tags) == 0 {
+		return series.Name
+	}
+
+	pairs := make([]string, 0, len(series.Tags))
+	for k, v := range series.Tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func newMathCondition(model *simplejson.Json, index int) (*MathCondition, error) {
+	condition := MathCondition{}
+	condition.Index = index
+	condition.HandleRequest = tsdb.HandleRequest
+
+	expression, err := mathexp.Parse(model.Get("expression").MustString())
+	if err != nil {
+		return nil, fmt.Errorf("error in condition %v: invalid expression: %v", index, err)
+	}
+	condition.Expression = expression
+
+	refIDs := map[string]bool{}
+	for _, queryObj := range model.Get("queries").MustArray() {
+		queryModel := simplejson.NewFromAny(queryObj)
+
+		query, err := newAlertQuery(queryModel.Get("query"))
+		if err != nil {
+			return nil, fmt.Errorf("error in condition %v: %v", index, err)
+		}
+
+		if query.RefID == "" {
+			return nil, fmt.Errorf("error in condition %v: query is missing a refId", index)
+		}
+
+		if refIDs[query.RefID] {
+			return nil, fmt.Errorf("error in condition %v: query %s is used more than once", index, query.RefID)
+		}
+		refIDs[query.RefID] = true
+
+		condition.Queries = append(condition.Queries, &QueryCondition{
+			Index:   index,
+			Query:   query,
+			Reducer: newSimpleReducer(queryModel.Get("reducer").Get("type").MustString("avg")),
+		})
+	}
+
+	if len(condition.Queries) == 0 {
+		return nil, fmt.Errorf("error in condition %v: math condition has no queries", index)
+	}
+
+	for _, v := range expression.Variables() {
+		if !refIDs[v] {
+			return nil, fmt.Errorf("error in condition %v: expression refers to query $%s that is not part of the condition", index, v)
+		}
+	}
+
+	if evaluatorJSON, ok := model.CheckGet("evaluator"); ok {
+		evaluator, err := NewAlertEvaluator(evaluatorJSON)
+		if err != nil {
+			return nil, fmt.Errorf("error in condition %v: %v", index, err)
+		}
+		condition.Evaluator = evaluator
+	}
+
+	condition.Operator = model.Get("operator").Get("type").MustString("and")
+
+	return &condition, nil
+}
@@ -0,0 +1,207 @@
+package conditions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+func TestMathCondition(t *testing.T) {
+	Convey("when evaluating math condition", t, func() {
+		mathConditionScenario("Given $A / $B * 100 > 5", func(ctx *mathConditionTestContext) {
+			ctx.expression = "$A / $B * 100 > 5"
+
+			Convey("Can read math condition from json model", func() {
+				_, err := ctx.exec()
+				So(err, ShouldBeNil)
+
+				So(ctx.condition.Expression.Text, ShouldEqual, "$A / $B * 100 > 5")
+				So(len(ctx.condition.Queries), ShouldEqual, 2)
+				So(ctx.condition.Queries[0].Query.RefID, ShouldEqual, "A")
+				So(ctx.condition.Queries[0].Query.DatasourceID, ShouldEqual, 1)
+				So(ctx.condition.Queries[1].Reducer.Type, ShouldEqual, "sum")
+				So(ctx.condition.Evaluator, ShouldBeNil)
+			})
+
+			Convey("Should fire when ratio is above 5", func() {
+				ctx.series["A"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("errors", tsdb.NewTimeSeriesPointsFromArgs(10, 0))}
+				ctx.series["B"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("requests", tsdb.NewTimeSeriesPointsFromArgs(100, 0))}
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(cr.Firing, ShouldBeTrue)
+				So(cr.NoDataFound, ShouldBeFalse)
+				So(len(cr.EvalMatches), ShouldEqual, 1)
+				So(cr.EvalMatches[0].Metric, ShouldEqual, "$A / $B * 100 > 5")
+			})
+
+			Convey("Should not fire when ratio is below 5", func() {
+				ctx.series["A"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("errors", tsdb.NewTimeSeriesPointsFromArgs(1, 0))}
+				ctx.series["B"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("requests", tsdb.NewTimeSeriesPointsFromArgs(100, 0))}
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(cr.Firing, ShouldBeFalse)
+			})
+
+			Convey("Should match series by tags", func() {
+				ctx.series["A"] = tsdb.TimeSeriesSlice{
+					&tsdb.TimeSeries{Name: "errors host1", Tags: map[string]string{"host": "host1"}, Points: tsdb.NewTimeSeriesPointsFromArgs(10, 0)},
+					&tsdb.TimeSeries{Name: "errors host2", Tags: map[string]string{"host": "host2"}, Points: tsdb.NewTimeSeriesPointsFromArgs(1, 0)},
+				}
+				ctx.series["B"] = tsdb.TimeSeriesSlice{
+					&tsdb.TimeSeries{Name: "requests host2", Tags: map[string]string{"host": "host2"}, Points: tsdb.NewTimeSeriesPointsFromArgs(100, 0)},
+					&tsdb.TimeSeries{Name: "requests host1", Tags: map[string]string{"host": "host1"}, Points: tsdb.NewTimeSeriesPointsFromArgs(100, 0)},
+				}
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(cr.Firing, ShouldBeTrue)
+				So(len(cr.EvalMatches), ShouldEqual, 1)
+				So(cr.EvalMatches[0].Metric, ShouldEqual, "errors host1")
+				So(cr.EvalMatches[0].Tags["host"], ShouldEqual, "host1")
+			})
+
+			Convey("Should merge tags of single series by ref id", func() {
+				ctx.series["A"] = tsdb.TimeSeriesSlice{
+					&tsdb.TimeSeries{Name: "errors", Tags: map[string]string{"host": "host1", "region": "eu"}, Points: tsdb.NewTimeSeriesPointsFromArgs(10, 0)},
+				}
+				ctx.series["B"] = tsdb.TimeSeriesSlice{
+					&tsdb.TimeSeries{Name: "requests", Tags: map[string]string{"host": "lb", "dc": "dc1"}, Points: tsdb.NewTimeSeriesPointsFromArgs(100, 0)},
+				}
+
+				for i := 0; i < 10; i++ {
+					cr, err := ctx.exec()
+
+					So(err, ShouldBeNil)
+					So(len(cr.EvalMatches), ShouldEqual, 1)
+					So(cr.EvalMatches[0].Tags, ShouldResemble, map[string]string{"host": "host1", "region": "eu", "dc": "dc1"})
+				}
+			})
+
+			Convey("Should use a single series for every series of the other query", func() {
+				ctx.series["A"] = tsdb.TimeSeriesSlice{
+					tsdb.NewTimeSeries("errors host1", tsdb.NewTimeSeriesPointsFromArgs(10, 0)),
+					tsdb.NewTimeSeries("errors host2", tsdb.NewTimeSeriesPointsFromArgs(20, 0)),
+				}
+				ctx.series["B"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("requests", tsdb.NewTimeSeriesPointsFromArgs(100, 0))}
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(len(cr.EvalMatches), ShouldEqual, 2)
+			})
+
+			Convey("Should set NoDataFound when a query returns no series", func() {
+				ctx.series["A"] = tsdb.TimeSeriesSlice{}
+				ctx.series["B"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("requests", tsdb.NewTimeSeriesPointsFromArgs(100, 0))}
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(cr.Firing, ShouldBeFalse)
+				So(cr.NoDataFound, ShouldBeTrue)
+			})
+		})
+
+		mathConditionScenario("Given $A / $B and an evaluator", func(ctx *mathConditionTestContext) {
+			ctx.expression = "$A / $B"
+			ctx.evaluator = `{"type": "gt", "params": [0.5]}`
+
+			Convey("Should use the evaluator on the expression result", func() {
+				ctx.series["A"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("errors", tsdb.NewTimeSeriesPointsFromArgs(60, 0))}
+				ctx.series["B"] = tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("requests", tsdb.NewTimeSeriesPointsFromArgs(100, 0))}
+				cr, err := ctx.exec()
+
+				So(err, ShouldBeNil)
+				So(cr.Firing, ShouldBeTrue)
+				So(cr.EvalMatches[0].Value.Float64, ShouldEqual, 0.6)
+			})
+		})
+
+		mathConditionScenario("Given an expression referring to an unknown query", func(ctx *mathConditionTestContext) {
+			ctx.expression = "$A / $C > 1"
+
+			Convey("Should return an error", func() {
+				_, err := newMathCondition(ctx.model(), 0)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+type mathConditionTestContext struct {
+	expression string
+	evaluator  string
+	series     map[string]tsdb.TimeSeriesSlice
+	result     *alerting.EvalContext
+	condition  *MathCondition
+}
+
+type mathConditionScenarioFunc func(c *mathConditionTestContext)
+
+func (ctx *mathConditionTestContext) model() *simplejson.Json {
+	evaluator := ""
+	if ctx.evaluator != "" {
+		evaluator = `"evaluator": ` + ctx.evaluator + `,`
+	}
+
+	jsonModel, err := simplejson.NewJson([]byte(fmt.Sprintf(`{
+            "type": "math",
+            "expression": %q,
+            %s
+            "queries": [
+              {
+                "query": {"params": ["A", "5m", "now"], "datasourceId": 1, "model": {"refId": "A"}},
+                "reducer": {"type": "sum"}
+              },
+              {
+                "query": {"params": ["B", "5m", "now"], "datasourceId": 1, "model": {"refId": "B"}},
+                "reducer": {"type": "sum"}
+              }
+            ]
+          }`, ctx.expression, evaluator)))
+	So(err, ShouldBeNil)
+
+	return jsonModel
+}
+
+func (ctx *mathConditionTestContext) exec() (*alerting.ConditionResult, error) {
+	condition, err := newMathCondition(ctx.model(), 0)
+	So(err, ShouldBeNil)
+
+	ctx.condition = condition
+
+	condition.HandleRequest = func(context context.Context, dsInfo *models.DataSource, req *tsdb.TsdbQuery) (*tsdb.Response, error) {
+		refID := req.Queries[0].Model.Get("refId").MustString()
+		return &tsdb.Response{
+			Results: map[string]*tsdb.QueryResult{
+				"A": {Series: ctx.series[refID]},
+			},
+		}, nil
+	}
+
+	return condition.Eval(ctx.result)
+}
+
+func mathConditionScenario(desc string, fn mathConditionScenarioFunc) {
+	Convey(desc, func() {
+		bus.AddHandler("test", func(query *models.GetDataSourceByIdQuery) error {
+			query.Result = &models.DataSource{Id: 1, Type: "graphite"}
+			return nil
+		})
+
+		ctx := &mathConditionTestContext{series: map[string]tsdb.TimeSeriesSlice{}}
+		ctx.result = &alerting.EvalContext{
+			Rule: &alerting.Rule{},
+		}
+
+		fn(ctx)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/expressions"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -34,12 +35,15 @@ type QueryCondition struct {
 }
 
 // AlertQuery contains information about what datasource a query
-// should be sent to and the query object.
+// should be sent to and the query object. Expression queries also
+// contain the queries the expression reads as inputs.
 type AlertQuery struct {
+	RefID        string
 	Model        *simplejson.Json
 	DatasourceID int64
 	From         string
 	To           string
+	Inputs       []*simplejson.Json
 }
 
 // Eval evaluates the `QueryCondition`.
@@ -106,16 +110,32 @@ func (c *QueryCondition) Eval(context *alerting.EvalContext) (*alerting.Conditio
 }
 
 func (c *QueryCondition) executeQuery(context *alerting.EvalContext, timeRange *tsdb.TimeRange) (tsdb.TimeSeriesSlice, error) {
-	getDsInfo := &models.GetDataSourceByIdQuery{
-		Id:    c.Query.DatasourceID,
-		OrgId: context.Rule.OrgID,
-	}
+	var datasource *models.DataSource
+	var req *tsdb.TsdbQuery
+	isExpression := c.Query.DatasourceID == expressions.DatasourceID
+
+	if isExpression {
+		datasource = expressions.DataSource(context.Rule.OrgID)
+
+		var err error
+		req, err = c.getExpressionRequestForAlertRule(datasource, timeRange, context.IsDebug)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		getDsInfo := &models.GetDataSourceByIdQuery{
+			Id:    c.Query.DatasourceID,
+			OrgId: context.Rule.OrgID,
+		}
+
+		if err := bus.Dispatch(getDsInfo); err != nil {
+			return nil, fmt.Errorf("Could not find datasource %v", err)
+		}
 
-	if err := bus.Dispatch(getDsInfo); err != nil {
-		return nil, fmt.Errorf("Could not find datasource %v", err)
+		datasource = getDsInfo.Result
+		req = c.getRequestForAlertRule(datasource, timeRange, context.IsDebug)
 	}
 
-	req := c.getRequestForAlertRule(getDsInfo.Result, timeRange, context.IsDebug)
 	result := make(tsdb.TimeSeriesSlice, 0)
 
 	if context.IsDebug {
@@ -155,7 +175,7 @@ func (c *QueryCondition) executeQuery(context *alerting.EvalContext, timeRange *
 		})
 	}
 
-	resp, err := c.HandleRequest(context.Ctx, getDsInfo.Result, req)
+	resp, err := c.HandleRequest(context.Ctx, datasource, req)
 	if err != nil {
 		if err == gocontext.DeadlineExceeded {
 			return nil, fmt.Errorf("Alert execution exceeded the timeout")
@@ -165,6 +185,11 @@ func (c *QueryCondition) executeQuery(context *alerting.EvalContext, timeRange *
 	}
 
 	for _, v := range resp.Results {
+		// the results of the inputs of an expression are not evaluated
+		if isExpression && v.RefId != c.Query.RefID {
+			continue
+		}
+
 		if v.Error != nil {
 			return nil, fmt.Errorf("tsdb.HandleRequest() response error %v", v)
 		}
@@ -232,26 +257,59 @@ func (c *QueryCondition) getRequestForAlertRule(datasource *models.DataSource, t
 	return req
 }
 
-func newQueryCondition(model *simplejson.Json, index int) (*QueryCondition, error) {
-	condition := QueryCondition{}
-	condition.Index = index
-	condition.HandleRequest = tsdb.HandleRequest
+// getExpressionRequestForAlertRule returns a request for the expression with
+// its inputs, each sent to the datasource it targets.
+func (c *QueryCondition) getExpressionRequestForAlertRule(datasource *models.DataSource, timeRange *tsdb.TimeRange, debug bool) (*tsdb.TsdbQuery, error) {
+	req := &tsdb.TsdbQuery{
+		TimeRange: timeRange,
+		Queries: []*tsdb.Query{
+			{
+				RefId:      c.Query.RefID,
+				Model:      c.Query.Model,
+				DataSource: datasource,
+			},
+		},
+		Headers: map[string]string{
+			"FromAlert": "true",
+		},
+		Debug: debug,
+	}
 
-	queryJSON := model.Get("query")
+	for _, input := range c.Query.Inputs {
+		query := &tsdb.Query{
+			RefId:      input.Get("refId").MustString(),
+			Model:      input,
+			DataSource: datasource,
+		}
 
-	condition.Query.Model = queryJSON.Get("model")
-	condition.Query.From = queryJSON.Get("params").MustArray()[1].(string)
-	condition.Query.To = queryJSON.Get("params").MustArray()[2].(string)
+		if !expressions.IsExpression(input) {
+			getDsInfo := &models.GetDataSourceByIdQuery{
+				Id:    input.Get("datasourceId").MustInt64(),
+				OrgId: datasource.OrgId,
+			}
 
-	if err := validateFromValue(condition.Query.From); err != nil {
-		return nil, err
+			if err := bus.Dispatch(getDsInfo); err != nil {
+				return nil, fmt.Errorf("Could not find datasource of query %s: %v", query.RefId, err)
+			}
+			query.DataSource = getDsInfo.Result
+		}
+
+		req.Queries = append(req.Queries, query)
 	}
 
-	if err := validateToValue(condition.Query.To); err != nil {
+	return req, nil
+}
+
+func newQueryCondition(model *simplejson.Json, index int) (*QueryCondition, error) {
+	condition := QueryCondition{}
+	condition.Index = index
+	condition.HandleRequest = tsdb.HandleRequest
+
+	query, err := newAlertQuery(model.Get("query"))
+	if err != nil {
 		return nil, err
 	}
-
-	condition.Query.DatasourceID = queryJSON.Get("datasourceId").MustInt64()
+	condition.Query = query
 
 	reducerJSON := model.Get("reducer")
 	condition.Reducer = newSimpleReducer(reducerJSON.Get("type").MustString())
@@ -270,6 +328,44 @@ func newQueryCondition(model *simplejson.Json, index int) (*QueryCondition, erro
 	return &condition, nil
 }
 
+// newAlertQuery reads the query part of a condition, e.g.
+// `{"params": ["A", "5m", "now"], "datasourceId": 1, "model": {...}}`.
+func newAlertQuery(queryJSON *simplejson.Json) (AlertQuery, error) {
+	query := AlertQuery{}
+
+	params := queryJSON.Get("params").MustArray()
+	if len(params) < 3 {
+		return query, fmt.Errorf("Query is missing the from and to parameters")
+	}
+
+	refID, _ := params[0].(string)
+	from, fromOk := params[1].(string)
+	to, toOk := params[2].(string)
+	if !fromOk || !toOk {
+		return query, fmt.Errorf("Query has invalid from and to parameters")
+	}
+
+	if err := validateFromValue(from); err != nil {
+		return query, err
+	}
+
+	if err := validateToValue(to); err != nil {
+		return query, err
+	}
+
+	query.RefID = refID
+	query.Model = queryJSON.Get("model")
+	query.From = from
+	query.To = to
+	query.DatasourceID = queryJSON.Get("datasourceId").MustInt64()
+
+	for _, input := range queryJSON.Get("inputs").MustArray() {
+		query.Inputs = append(query.Inputs, simplejson.NewFromAny(input))
+	}
+
+	return query, nil
+}
+
 func validateFromValue(from string) error {
 	fromRaw := strings.Replace(from, "now-", "", 1)
 
@@ -226,3 +226,62 @@ func queryConditionScenario(desc string, fn queryConditionScenarioFunc) {
 		fn(ctx)
 	})
 }
+
+func TestQueryConditionWithExpression(t *testing.T) {
+	Convey("when evaluating query condition on an expression", t, func() {
+		bus.AddHandler("test", func(query *models.GetDataSourceByIdQuery) error {
+			query.Result = &models.DataSource{Id: query.Id, OrgId: query.OrgId, Type: "graphite"}
+			return nil
+		})
+
+		jsonModel, err := simplejson.NewJson([]byte(`{
+            "type": "query",
+            "query":  {
+              "params": ["C", "5m", "now"],
+              "datasourceId": -100,
+              "model": {"refId": "C", "datasource": "__expr__", "type": "math", "expression": "$A + $B"},
+              "inputs": [
+                {"refId": "A", "datasourceId": 1, "target": "a"},
+                {"refId": "B", "datasourceId": 2, "target": "b"}
+              ]
+            },
+            "reducer": {"type": "avg"},
+            "evaluator": {"type": "gt", "params": [100]}
+          }`))
+		So(err, ShouldBeNil)
+
+		condition, err := newQueryCondition(jsonModel, 0)
+		So(err, ShouldBeNil)
+		So(condition.Query.Inputs, ShouldHaveLength, 2)
+
+		var request *tsdb.TsdbQuery
+		var datasource *models.DataSource
+		condition.HandleRequest = func(context context.Context, dsInfo *models.DataSource, req *tsdb.TsdbQuery) (*tsdb.Response, error) {
+			request = req
+			datasource = dsInfo
+			return &tsdb.Response{
+				Results: map[string]*tsdb.QueryResult{
+					"A": {RefId: "A", Series: tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("a", tsdb.NewTimeSeriesPointsFromArgs(150, 0))}},
+					"C": {RefId: "C", Series: tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("c", tsdb.NewTimeSeriesPointsFromArgs(50, 0))}},
+				},
+			}, nil
+		}
+
+		cr, err := condition.Eval(&alerting.EvalContext{Rule: &alerting.Rule{OrgID: 3}})
+		So(err, ShouldBeNil)
+
+		Convey("Should send the expression with its inputs to the expression datasource", func() {
+			So(datasource.Id, ShouldEqual, -100)
+			So(request.Queries, ShouldHaveLength, 3)
+			So(request.Queries[0].RefId, ShouldEqual, "C")
+			So(request.Queries[1].RefId, ShouldEqual, "A")
+			So(request.Queries[1].DataSource.Id, ShouldEqual, 1)
+			So(request.Queries[1].DataSource.OrgId, ShouldEqual, 3)
+			So(request.Queries[2].DataSource.Id, ShouldEqual, 2)
+		})
+
+		Convey("Should only evaluate the result of the expression", func() {
+			So(cr.Firing, ShouldBeFalse)
+		})
+	})
+}
@@ -125,6 +125,12 @@ func newSimpleReducer(t string) *queryReducer {
 	return &queryReducer{Type: t}
 }
 
+// ReduceSeries reduces the series to a single value with the reducer of
+// the given type, as query conditions do.
+func ReduceSeries(reducerType string, series *tsdb.TimeSeries) null.Float {
+	return newSimpleReducer(reducerType).Reduce(series)
+}
+
 func calculateDiff(series *tsdb.TimeSeries, allNull bool, value float64, fn func(float64, float64) float64) (bool, float64) {
 	var (
 		points = series.Points
@@ -26,13 +26,16 @@ type AlertEngine struct {
 	RenderService rendering.Service `inject:""`
 	Bus           bus.Bus           `inject:""`
 
-	execQueue     chan *Job
-	ticker        *Ticker
-	scheduler     scheduler
-	evalHandler   evalHandler
-	ruleReader    ruleReader
-	log           log.Logger
-	resultHandler resultHandler
+	execQueue      chan *Job
+	ticker         *Ticker
+	scheduler      scheduler
+	evalHandler    evalHandler
+	ruleReader     ruleReader
+	log            log.Logger
+	resultHandler  resultHandler
+	notifications  *notificationService
+	externalAlerts *externalAlertDispatcher
+	cluster        *alertingCluster
 }
 
 func init() {
@@ -52,15 +55,30 @@ func (e *AlertEngine) Init() error {
 	e.evalHandler = NewEvalHandler()
 	e.ruleReader = newRuleReader()
 	e.log = log.New("alerting.engine")
-	e.resultHandler = newResultHandler(e.RenderService)
+	handler := newResultHandler(e.RenderService)
+	e.resultHandler = handler
+	e.notifications = handler.notifier
+	e.externalAlerts = newExternalAlertDispatcher()
+	if setting.AlertingHASharding {
+		e.cluster = newAlertingCluster()
+		e.externalAlerts.cluster = e.cluster
+	}
 	return nil
 }
 
 // Run starts the alerting service background process.
 func (e *AlertEngine) Run(ctx context.Context) error {
 	alertGroup, ctx := errgroup.WithContext(ctx)
+	if e.cluster != nil {
+		if err := e.cluster.heartbeat(ctx, time.Now()); err != nil {
+			e.log.Error("Failed to join alerting cluster", "error", err)
+		}
+		alertGroup.Go(func() error { return e.cluster.run(ctx) })
+	}
 	alertGroup.Go(func() error { return e.alertingTicker(ctx) })
 	alertGroup.Go(func() error { return e.runJobDispatcher(ctx) })
+	alertGroup.Go(func() error { return e.notifications.runGroups(ctx) })
+	alertGroup.Go(func() error { return e.externalAlerts.run(ctx) })
 
 	err := alertGroup.Wait()
 	return err
@@ -82,7 +100,11 @@ func (e *AlertEngine) alertingTicker(grafanaCtx context.Context) error {
 		case tick := <-e.ticker.C:
 			// TEMP SOLUTION update rules ever tenth tick
 			if tickIndex%10 == 0 {
-				e.scheduler.Update(e.ruleReader.fetch())
+				rules := e.ruleReader.fetch()
+				if e.cluster != nil {
+					rules = e.cluster.filterRules(rules)
+				}
+				e.scheduler.Update(rules)
 			}
 
 			e.scheduler.Tick(tick, e.execQueue)
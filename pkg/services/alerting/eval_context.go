@@ -26,12 +26,21 @@ type EvalContext struct {
 	log            log.Logger
 
 	dashboardRef *models.DashboardRef
+	ruleURL      string
 
 	ImagePublicURL  string
 	ImageOnDiskPath string
 	NoDataFound     bool
 	PrevAlertState  models.AlertStateType
 
+	// ChangedInstances holds the alert instances whose state changed
+	// in this evaluation, including the ones that are no longer matched.
+	ChangedInstances []*EvalMatch
+
+	// NotificationResults holds the result of every notification sent
+	// for this evaluation.
+	NotificationResults []*models.AlertStateHistoryNotification
+
 	Ctx context.Context
 }
 
@@ -87,6 +96,125 @@ func (c *EvalContext) shouldUpdateAlertState() bool {
 	return c.Rule.State != c.PrevAlertState
 }
 
+// GetNewlyAlertingInstances returns the alert instances that
+// started alerting in this evaluation.
+func (c *EvalContext) GetNewlyAlertingInstances() []*EvalMatch {
+	var result []*EvalMatch
+	for _, m := range c.ChangedInstances {
+		if m.State == models.AlertStateAlerting {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// GetResolvedInstances returns the alert instances that
+// stopped alerting in this evaluation.
+func (c *EvalContext) GetResolvedInstances() []*EvalMatch {
+	var result []*EvalMatch
+	for _, m := range c.ChangedInstances {
+		if m.PrevState == models.AlertStateAlerting && m.State == models.AlertStateOK {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// evalInstanceStates computes the state of every alert instance from the
+// eval matches and the instances tracked so far. It returns the instances
+// that should be tracked from now on and sets ChangedInstances.
+func (c *EvalContext) evalInstanceStates(prev []*models.AlertInstance, now time.Time) []*models.AlertInstance {
+	prevByKey := make(map[string]*models.AlertInstance, len(prev))
+	for _, instance := range prev {
+		prevByKey[instance.InstanceKey] = instance
+	}
+
+	var matches []*EvalMatch
+	if c.Firing {
+		matches = c.EvalMatches
+	}
+
+	current := make([]*models.AlertInstance, 0, len(matches))
+	currentByKey := make(map[string]*models.AlertInstance, len(matches))
+	c.ChangedInstances = nil
+
+	for _, m := range matches {
+		key := models.AlertInstanceKey(m.Metric, m.Tags)
+		prevInstance := prevByKey[key]
+		m.PrevState = instanceState(prevInstance)
+
+		// the same series can match more than one condition
+		if instance, ok := currentByKey[key]; ok {
+			m.State = instance.State
+			continue
+		}
+
+		instance := newAlertInstance(c.Rule, key, m, prevInstance, now)
+		currentByKey[key] = instance
+		current = append(current, instance)
+
+		m.State = instance.State
+		if m.State != m.PrevState {
+			c.ChangedInstances = append(c.ChangedInstances, m)
+		}
+	}
+
+	for _, instance := range prev {
+		if _, ok := currentByKey[instance.InstanceKey]; ok {
+			continue
+		}
+
+		c.ChangedInstances = append(c.ChangedInstances, &EvalMatch{
+			Metric:    instance.Metric,
+			Tags:      instance.Tags,
+			State:     models.AlertStateOK,
+			PrevState: instance.State,
+		})
+	}
+
+	return current
+}
+
+// instanceState returns the state of a tracked instance. Instances
+// that are not tracked are ok.
+func instanceState(instance *models.AlertInstance) models.AlertStateType {
+	if instance == nil {
+		return models.AlertStateOK
+	}
+	return instance.State
+}
+
+func newAlertInstance(rule *Rule, key string, m *EvalMatch, prev *models.AlertInstance, now time.Time) *models.AlertInstance {
+	instance := &models.AlertInstance{
+		OrgId:        rule.OrgID,
+		AlertId:      rule.ID,
+		InstanceKey:  key,
+		Metric:       m.Metric,
+		Tags:         m.Tags,
+		State:        models.AlertStateAlerting,
+		NewStateDate: now,
+	}
+
+	if prev != nil {
+		instance.NewStateDate = prev.NewStateDate
+	}
+
+	if rule.For != 0 {
+		switch {
+		case prev == nil:
+			instance.State = models.AlertStatePending
+		case prev.State == models.AlertStatePending && now.Sub(prev.NewStateDate) <= rule.For:
+			instance.State = models.AlertStatePending
+		}
+	}
+
+	if prev != nil && prev.State != instance.State {
+		instance.NewStateDate = now
+	}
+
+	return instance
+}
+
 // GetDurationMs returns the duration of the alert evaluation.
 func (c *EvalContext) GetDurationMs() float64 {
 	return float64(c.EndTime.Nanosecond()-c.StartTime.Nanosecond()) / float64(1000000)
@@ -120,6 +248,10 @@ func (c *EvalContext) GetRuleURL() (string, error) {
 		return setting.AppUrl, nil
 	}
 
+	if c.ruleURL != "" {
+		return c.ruleURL, nil
+	}
+
 	ref, err := c.GetDashboardUID()
 	if err != nil {
 		return "", err
@@ -204,3 +204,96 @@ func TestGetStateFromEvalContext(t *testing.T) {
 		assert.Equal(t, tc.expected, newState, "failed: %s \n expected '%s' have '%s'\n", tc.name, tc.expected, string(newState))
 	}
 }
+
+func TestEvalInstanceStates(t *testing.T) {
+	now := time.Now()
+	host1 := map[string]string{"host": "host1"}
+	host2 := map[string]string{"host": "host2"}
+
+	trackedInstance := func(tags map[string]string, state models.AlertStateType, since time.Duration) *models.AlertInstance {
+		return &models.AlertInstance{
+			InstanceKey:  models.AlertInstanceKey("cpu", tags),
+			Metric:       "cpu",
+			Tags:         tags,
+			State:        state,
+			NewStateDate: now.Add(-since),
+		}
+	}
+
+	t.Run("new matches start alerting", func(t *testing.T) {
+		ctx := NewEvalContext(context.TODO(), &Rule{})
+		ctx.Firing = true
+		ctx.EvalMatches = []*EvalMatch{{Metric: "cpu", Tags: host1}}
+
+		instances := ctx.evalInstanceStates(nil, now)
+
+		assert.Len(t, instances, 1)
+		assert.Equal(t, models.AlertStateAlerting, instances[0].State)
+		assert.Len(t, ctx.ChangedInstances, 1)
+		assert.Equal(t, models.AlertStateOK, ctx.EvalMatches[0].PrevState)
+		assert.Equal(t, models.AlertStateAlerting, ctx.EvalMatches[0].State)
+		assert.Len(t, ctx.GetNewlyAlertingInstances(), 1)
+	})
+
+	t.Run("one instance recovers while another starts alerting", func(t *testing.T) {
+		ctx := NewEvalContext(context.TODO(), &Rule{})
+		ctx.Firing = true
+		ctx.EvalMatches = []*EvalMatch{{Metric: "cpu", Tags: host2}}
+
+		instances := ctx.evalInstanceStates([]*models.AlertInstance{
+			trackedInstance(host1, models.AlertStateAlerting, time.Hour),
+		}, now)
+
+		assert.Len(t, instances, 1)
+		assert.Equal(t, "host2", instances[0].Tags["host"])
+		assert.Len(t, ctx.ChangedInstances, 2)
+		assert.Len(t, ctx.GetNewlyAlertingInstances(), 1)
+
+		resolved := ctx.GetResolvedInstances()
+		assert.Len(t, resolved, 1)
+		assert.Equal(t, "host1", resolved[0].Tags["host"])
+	})
+
+	t.Run("unchanged instances are not reported", func(t *testing.T) {
+		ctx := NewEvalContext(context.TODO(), &Rule{})
+		ctx.Firing = true
+		ctx.EvalMatches = []*EvalMatch{{Metric: "cpu", Tags: host1}}
+
+		instances := ctx.evalInstanceStates([]*models.AlertInstance{
+			trackedInstance(host1, models.AlertStateAlerting, time.Hour),
+		}, now)
+
+		assert.Len(t, instances, 1)
+		assert.Equal(t, now.Add(-time.Hour), instances[0].NewStateDate)
+		assert.Len(t, ctx.ChangedInstances, 0)
+	})
+
+	t.Run("instances are pending until the for duration has passed", func(t *testing.T) {
+		ctx := NewEvalContext(context.TODO(), &Rule{For: 5 * time.Minute})
+		ctx.Firing = true
+		ctx.EvalMatches = []*EvalMatch{{Metric: "cpu", Tags: host1}, {Metric: "cpu", Tags: host2}}
+
+		instances := ctx.evalInstanceStates([]*models.AlertInstance{
+			trackedInstance(host1, models.AlertStatePending, 10*time.Minute),
+		}, now)
+
+		assert.Len(t, instances, 2)
+		assert.Equal(t, models.AlertStateAlerting, instances[0].State)
+		assert.Equal(t, now, instances[0].NewStateDate)
+		assert.Equal(t, models.AlertStatePending, instances[1].State)
+		assert.Len(t, ctx.ChangedInstances, 2)
+	})
+
+	t.Run("all instances resolve when the rule is not firing", func(t *testing.T) {
+		ctx := NewEvalContext(context.TODO(), &Rule{})
+		ctx.Firing = false
+		ctx.EvalMatches = []*EvalMatch{{Metric: "cpu", Tags: host1}}
+
+		instances := ctx.evalInstanceStates([]*models.AlertInstance{
+			trackedInstance(host1, models.AlertStateAlerting, time.Hour),
+		}, now)
+
+		assert.Len(t, instances, 0)
+		assert.Len(t, ctx.GetResolvedInstances(), 1)
+	})
+}
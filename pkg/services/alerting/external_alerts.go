@@ -0,0 +1,394 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// externalAlertDispatchInterval is how often external alerts are grouped
+// and their notifications sent.
+var externalAlertDispatchInterval = 10 * time.Second
+
+// externalAlertDispatcher delivers the alerts sent to Grafana by external
+// tools through the notification channels. Alerts are grouped by the
+// notification policies they match, and a notification is sent for a
+// group when its firing alerts change, at most once per group interval,
+// and as a reminder every repeat interval while it keeps firing.
+//
+// When alert evaluation is sharded, each group is notified by the node it
+// is assigned to.
+type externalAlertDispatcher struct {
+	log     log.Logger
+	groups  map[string]*externalAlertGroupState
+	cluster *alertingCluster
+}
+
+// externalAlertGroupState is what was last notified for a group.
+type externalAlertGroupState struct {
+	firing       map[string]bool
+	lastNotified time.Time
+}
+
+// externalAlertGroup holds the alerts of a group for one dispatch.
+type externalAlertGroup struct {
+	key    string
+	orgID  int64
+	route  *notificationRoute
+	alerts []*models.ExternalAlert
+}
+
+func newExternalAlertDispatcher() *externalAlertDispatcher {
+	return &externalAlertDispatcher{
+		log:    log.New("alerting.external"),
+		groups: make(map[string]*externalAlertGroupState),
+	}
+}
+
+func (d *externalAlertDispatcher) run(ctx context.Context) error {
+	ticker := time.NewTicker(externalAlertDispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if err := d.dispatch(ctx, now); err != nil {
+				d.log.Error("Failed to dispatch external alerts", "error", err)
+			}
+		}
+	}
+}
+
+func (d *externalAlertDispatcher) dispatch(ctx context.Context, now time.Time) error {
+	// resolved alerts are dispatched until the resolve timeout passes, so
+	// their groups are notified as resolved
+	endedAfter := now.Add(-models.ExternalAlertResolveTimeout)
+
+	orgsQuery := &models.GetExternalAlertOrgIdsQuery{EndedAfter: endedAfter}
+	if err := bus.DispatchCtx(ctx, orgsQuery); err != nil {
+		return err
+	}
+
+	groups := make(map[string]*externalAlertGroup)
+	for _, orgID := range orgsQuery.Result {
+		if err := d.groupOrgAlerts(ctx, orgID, endedAfter, now, groups); err != nil {
+			return err
+		}
+	}
+
+	for key := range groups {
+		if d.cluster != nil && !d.cluster.owns(key) {
+			delete(groups, key)
+		}
+	}
+
+	for key := range d.groups {
+		if _, ok := groups[key]; !ok {
+			delete(d.groups, key)
+		}
+	}
+
+	for _, group := range groups {
+		d.notifyGroup(ctx, group, now)
+	}
+
+	return nil
+}
+
+// groupOrgAlerts adds the alerts of the organization to the groups of the
+// notification policies they match, leaving out the firing alerts that are
+// silenced. The silences and the policy of the organization are loaded once
+// for all of its alerts.
+func (d *externalAlertDispatcher) groupOrgAlerts(ctx context.Context, orgID int64, endedAfter time.Time, now time.Time, groups map[string]*externalAlertGroup) error {
+	alertsQuery := &models.GetExternalAlertsQuery{OrgId: orgID, EndedAfter: endedAfter}
+	if err := bus.DispatchCtx(ctx, alertsQuery); err != nil {
+		return err
+	}
+
+	if len(alertsQuery.Result) == 0 {
+		return nil
+	}
+
+	silencesQuery := &models.GetAlertSilencesQuery{OrgId: orgID, ActiveAt: now}
+	if err := bus.DispatchCtx(ctx, silencesQuery); err != nil {
+		return err
+	}
+
+	var policy *models.NotificationPolicy
+	policyQuery := &models.GetAlertNotificationPolicyQuery{OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, policyQuery); err != nil {
+		if err != models.ErrAlertNotificationPolicyNotFound {
+			return err
+		}
+	} else {
+		policy = policyQuery.Result.Policy
+	}
+
+	for _, alert := range alertsQuery.Result {
+		evalCtx := NewEvalContext(ctx, externalAlertRule(alert))
+		evalCtx.StartTime = now
+
+		if alert.IsFiring(now) {
+			silence, err := findMatchingSilence(evalCtx, silencesQuery.Result)
+			if err != nil {
+				return err
+			}
+
+			if silence != nil {
+				continue
+			}
+		}
+
+		target := &ruleTarget{rule: evalCtx.Rule}
+		routes, err := policyNotificationRoutes(evalCtx, target, policy)
+		if err != nil {
+			return err
+		}
+
+		if len(routes) == 0 {
+			routes = []*notificationRoute{{key: "default"}}
+		}
+
+		for _, route := range routes {
+			key := fmt.Sprintf("%d/%s/%s", alert.OrgId, route.key, route.groupValues(target))
+			group, ok := groups[key]
+			if !ok {
+				group = &externalAlertGroup{key: key, orgID: alert.OrgId, route: route}
+				groups[key] = group
+			}
+			group.alerts = append(group.alerts, alert)
+		}
+	}
+
+	return nil
+}
+
+// notifyGroup sends a notification for the group if its firing alerts
+// changed since the last notification or a reminder is due.
+func (d *externalAlertDispatcher) notifyGroup(ctx context.Context, group *externalAlertGroup, now time.Time) {
+	firing := make(map[string]bool)
+	for _, alert := range group.alerts {
+		if alert.IsFiring(now) {
+			firing[alert.Fingerprint] = true
+		}
+	}
+
+	state := d.groups[group.key]
+	if state == nil {
+		if len(firing) == 0 {
+			return
+		}
+		state = &externalAlertGroupState{firing: map[string]bool{}}
+		d.groups[group.key] = state
+	}
+
+	repeatInterval := group.route.repeatInterval
+	if repeatInterval == 0 {
+		repeatInterval = defaultPolicyRepeatInterval
+	}
+
+	sinceLast := now.Sub(state.lastNotified)
+	switch {
+	case len(firing) == 0 && len(state.firing) == 0:
+		delete(d.groups, group.key)
+		return
+	case sameFingerprints(firing, state.firing):
+		if sinceLast < repeatInterval {
+			return
+		}
+	case !state.lastNotified.IsZero() && sinceLast < group.route.groupInterval:
+		return
+	}
+
+	evalCtx := newExternalAlertEvalContext(ctx, group, state, firing, now)
+	d.sendNotifications(evalCtx, group)
+
+	state.firing = firing
+	state.lastNotified = now
+	if len(firing) == 0 {
+		delete(d.groups, group.key)
+	}
+}
+
+func (d *externalAlertDispatcher) sendNotifications(evalCtx *EvalContext, group *externalAlertGroup) {
+	query := &models.GetAlertNotificationsWithUidToSendQuery{OrgId: group.orgID, Uids: group.route.notificationUids}
+	if err := bus.DispatchCtx(evalCtx.Ctx, query); err != nil {
+		d.log.Error("Failed to get notification channels", "group", group.key, "error", err)
+		return
+	}
+
+	for _, notification := range query.Result {
+		notifier, err := InitNotifier(notification)
+		if err != nil {
+			d.log.Error("Could not create notifier", "notifier", notification.Uid, "error", err)
+			continue
+		}
+
+		if evalCtx.Rule.State == models.AlertStateOK && notifier.GetDisableResolveMessage() {
+			continue
+		}
+
+		d.log.Debug("Sending external alert notification", "group", group.key, "state", evalCtx.Rule.State, "uid", notifier.GetNotifierUID())
+		metrics.MAlertingNotificationSent.WithLabelValues(notifier.GetType()).Inc()
+
+		if err := notifier.Notify(evalCtx); err != nil {
+			d.log.Error("Failed to send external alert notification", "uid", notifier.GetNotifierUID(), "error", err)
+			metrics.MAlertingNotificationFailed.WithLabelValues(notifier.GetType()).Inc()
+		}
+	}
+}
+
+// FindExternalAlertSilence returns the silence that is active at the
+// given time and matches the labels of the external alert, or nil.
+func FindExternalAlertSilence(ctx context.Context, alert *models.ExternalAlert, now time.Time) (*models.AlertSilence, error) {
+	evalCtx := NewEvalContext(ctx, externalAlertRule(alert))
+	evalCtx.StartTime = now
+	return findActiveSilence(evalCtx)
+}
+
+// externalAlertRule returns the alert rule used to match an external alert
+// against silences and notification policies. Its tags are the labels of
+// the alert.
+func externalAlertRule(alert *models.ExternalAlert) *Rule {
+	rule := &Rule{
+		OrgID: alert.OrgId,
+		Name:  alert.Labels["alertname"],
+	}
+
+	for _, name := range sortedLabelNames(alert.Labels) {
+		rule.AlertRuleTags = append(rule.AlertRuleTags, &models.Tag{Key: name, Value: alert.Labels[name]})
+	}
+
+	return rule
+}
+
+// newExternalAlertEvalContext returns the eval context notifiers are
+// called with for a group of external alerts. The alert rule of the group
+// has the labels all its alerts have in common as tags, and the alerts as
+// eval matches.
+func newExternalAlertEvalContext(ctx context.Context, group *externalAlertGroup, state *externalAlertGroupState, firing map[string]bool, now time.Time) *EvalContext {
+	alerts := make([]*models.ExternalAlert, 0, len(group.alerts))
+	for _, alert := range group.alerts {
+		// a resolved notification lists the alerts that were firing
+		if firing[alert.Fingerprint] || (len(firing) == 0 && state.firing[alert.Fingerprint]) {
+			alerts = append(alerts, alert)
+		}
+	}
+
+	common := commonLabels(alerts)
+	rule := &Rule{
+		// notifiers use the id of the rule to deduplicate incidents, so the
+		// group gets a negative id that cannot clash with alert rules.
+		ID:    -int64(hashString(group.key) >> 1),
+		OrgID: group.orgID,
+		Name:  common["alertname"],
+		State: models.AlertStateAlerting,
+	}
+
+	if rule.Name == "" {
+		rule.Name = fmt.Sprintf("%d alerts", len(alerts))
+	}
+
+	if len(firing) == 0 {
+		rule.State = models.AlertStateOK
+	}
+
+	for _, name := range sortedLabelNames(common) {
+		rule.AlertRuleTags = append(rule.AlertRuleTags, &models.Tag{Key: name, Value: common[name]})
+	}
+
+	evalCtx := NewEvalContext(ctx, rule)
+	evalCtx.StartTime = now
+	evalCtx.Firing = len(firing) > 0
+	evalCtx.PrevAlertState = models.AlertStateOK
+	if len(state.firing) > 0 {
+		evalCtx.PrevAlertState = models.AlertStateAlerting
+	}
+
+	messages := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		evalCtx.EvalMatches = append(evalCtx.EvalMatches, &EvalMatch{
+			Metric: alert.Labels["alertname"],
+			Tags:   alert.Labels,
+			Value:  null.FloatFromPtr(nil),
+			State:  rule.State,
+		})
+
+		if summary := alertSummary(alert); summary != "" {
+			messages = append(messages, summary)
+		}
+
+		if evalCtx.ruleURL == "" {
+			evalCtx.ruleURL = alert.GeneratorUrl
+		}
+	}
+	rule.Message = strings.Join(messages, "\n")
+
+	return evalCtx
+}
+
+func alertSummary(alert *models.ExternalAlert) string {
+	for _, name := range []string{"summary", "description", "message"} {
+		if value := alert.Annotations[name]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func commonLabels(alerts []*models.ExternalAlert) map[string]string {
+	common := make(map[string]string)
+	for i, alert := range alerts {
+		if i == 0 {
+			for name, value := range alert.Labels {
+				common[name] = value
+			}
+			continue
+		}
+
+		for name, value := range common {
+			if alert.Labels[name] != value {
+				delete(common, name)
+			}
+		}
+	}
+	return common
+}
+
+func sortedLabelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sameFingerprints(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for fingerprint := range a {
+		if !b[fingerprint] {
+			return false
+		}
+	}
+	return true
+}
+
+func hashString(s string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(s))
+	return hash.Sum64()
+}
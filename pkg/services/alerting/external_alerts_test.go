@@ -0,0 +1,175 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type recordingNotifier struct {
+	testNotifier
+	sent *[]*EvalContext
+}
+
+func (n *recordingNotifier) Notify(evalCtx *EvalContext) error {
+	*n.sent = append(*n.sent, evalCtx)
+	return nil
+}
+
+func TestExternalAlertDispatcher(t *testing.T) {
+	var sent []*EvalContext
+	RegisterNotifier(&NotifierPlugin{
+		Type: "recording",
+		Name: "Recording",
+		Factory: func(model *models.AlertNotification) (Notifier, error) {
+			return &recordingNotifier{testNotifier: testNotifier{UID: model.Uid, Type: model.Type}, sent: &sent}, nil
+		},
+	})
+
+	var alerts []*models.ExternalAlert
+	var silences []*models.AlertSilence
+	policy := &models.NotificationPolicy{
+		GroupBy:        []string{"alertname"},
+		GroupInterval:  "1m",
+		RepeatInterval: "30m",
+	}
+
+	var silenceQueries, policyQueries int
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetExternalAlertOrgIdsQuery) error {
+		query.Result = []int64{1}
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetExternalAlertsQuery) error {
+		query.Result = alerts
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertSilencesQuery) error {
+		silenceQueries++
+		query.Result = silences
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationPolicyQuery) error {
+		policyQueries++
+		query.Result = &models.AlertNotificationPolicy{OrgId: 1, Policy: policy}
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationsWithUidToSendQuery) error {
+		query.Result = []*models.AlertNotification{{Id: 1, Uid: "oncall", Type: "recording", Settings: simplejson.New()}}
+		return nil
+	})
+
+	start := time.Now()
+	newAlert := func(name, instance string, endsAt time.Time) *models.ExternalAlert {
+		labels := map[string]string{"alertname": name, "instance": instance}
+		return &models.ExternalAlert{
+			OrgId:        1,
+			Fingerprint:  models.ExternalAlertFingerprint(labels),
+			Labels:       labels,
+			Annotations:  map[string]string{"summary": name + " on " + instance},
+			StartsAt:     start,
+			EndsAt:       endsAt,
+			GeneratorUrl: "http://prometheus/graph",
+		}
+	}
+
+	d := newExternalAlertDispatcher()
+	dispatch := func(offset time.Duration) {
+		sent = nil
+		silenceQueries, policyQueries = 0, 0
+		require.NoError(t, d.dispatch(context.Background(), start.Add(offset)))
+	}
+
+	t.Run("notifies new firing group", func(t *testing.T) {
+		alerts = []*models.ExternalAlert{
+			newAlert("HighLatency", "a", start.Add(time.Hour)),
+			newAlert("DiskFull", "b", start.Add(time.Hour)),
+		}
+		dispatch(0)
+
+		require.Len(t, sent, 2)
+		names := []string{sent[0].Rule.Name, sent[1].Rule.Name}
+		assert.ElementsMatch(t, []string{"HighLatency", "DiskFull"}, names)
+		assert.Equal(t, models.AlertStateAlerting, sent[0].Rule.State)
+		assert.Less(t, sent[0].Rule.ID, int64(0))
+
+		ruleURL, err := sent[0].GetRuleURL()
+		require.NoError(t, err)
+		assert.Equal(t, "http://prometheus/graph", ruleURL)
+
+		assert.Equal(t, 1, silenceQueries)
+		assert.Equal(t, 1, policyQueries)
+	})
+
+	t.Run("does not notify again without changes", func(t *testing.T) {
+		dispatch(10 * time.Second)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("holds back new alert of group within group interval", func(t *testing.T) {
+		alerts = append(alerts, newAlert("HighLatency", "c", start.Add(time.Hour)))
+		dispatch(20 * time.Second)
+		assert.Empty(t, sent)
+
+		dispatch(70 * time.Second)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "HighLatency", sent[0].Rule.Name)
+		assert.Len(t, sent[0].EvalMatches, 2)
+		assert.Equal(t, "HighLatency on a\nHighLatency on c", sent[0].Rule.Message)
+	})
+
+	t.Run("sends reminders after repeat interval", func(t *testing.T) {
+		dispatch(30 * time.Minute)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "DiskFull", sent[0].Rule.Name)
+
+		dispatch(40 * time.Minute)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "HighLatency", sent[0].Rule.Name)
+	})
+
+	t.Run("notifies resolved group", func(t *testing.T) {
+		alerts = []*models.ExternalAlert{
+			newAlert("HighLatency", "a", start.Add(time.Hour)),
+			newAlert("HighLatency", "c", start.Add(time.Hour)),
+			newAlert("DiskFull", "b", start.Add(time.Hour-30*time.Second)),
+		}
+		dispatch(time.Hour - 20*time.Second)
+		require.Len(t, sent, 1)
+		assert.Equal(t, "DiskFull", sent[0].Rule.Name)
+		assert.Equal(t, models.AlertStateOK, sent[0].Rule.State)
+		assert.Equal(t, models.AlertStateAlerting, sent[0].PrevAlertState)
+
+		dispatch(time.Hour - 10*time.Second)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("does not notify silenced alerts", func(t *testing.T) {
+		silences = []*models.AlertSilence{
+			{
+				Id:       1,
+				Tags:     map[string]string{"alertname": "Deploy"},
+				StartsAt: start,
+				EndsAt:   start.Add(2 * time.Hour),
+			},
+		}
+		alerts = append(alerts, newAlert("Deploy", "a", start.Add(2*time.Hour)))
+		dispatch(time.Hour - 5*time.Second)
+		assert.Empty(t, sent)
+	})
+}
+
+func TestCommonLabels(t *testing.T) {
+	alerts := []*models.ExternalAlert{
+		{Labels: map[string]string{"alertname": "HighLatency", "instance": "a", "team": "payments"}},
+		{Labels: map[string]string{"alertname": "HighLatency", "instance": "b", "team": "payments"}},
+	}
+
+	assert.Equal(t, map[string]string{"alertname": "HighLatency", "team": "payments"}, commonLabels(alerts))
+}
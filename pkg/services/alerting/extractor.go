@@ -10,6 +10,8 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/expressions"
 )
 
 // DashAlertExtractor extracts alerts from the dashboard json.
@@ -65,6 +67,151 @@ func findPanelQueryByRefID(panel *simplejson.Json, refID string) *simplejson.Jso
 	return nil
 }
 
+// conditionQueries returns the queries of a condition. A query condition
+// has a single query while a math condition can have several.
+func conditionQueries(jsonCondition *simplejson.Json) []*simplejson.Json {
+	queries := jsonCondition.Get("queries").MustArray()
+	if len(queries) == 0 {
+		return []*simplejson.Json{jsonCondition.Get("query")}
+	}
+
+	result := make([]*simplejson.Json, 0, len(queries))
+	for _, q := range queries {
+		result = append(result, simplejson.NewFromAny(q).Get("query"))
+	}
+	return result
+}
+
+// resolveConditionQuery copies the panel query referenced by the condition
+// query into its model and sets the id of the datasource it targets.
+func (e *DashAlertExtractor) resolveConditionQuery(panel *simplejson.Json, alert *models.Alert, jsonQuery *simplejson.Json) error {
+	queryRefID, _ := jsonQuery.Get("params").GetIndex(0).String()
+	panelQuery := findPanelQueryByRefID(panel, queryRefID)
+
+	if panelQuery == nil {
+		reason := fmt.Sprintf("Alert on PanelId: %v refers to query(%s) that cannot be found", alert.PanelId, queryRefID)
+		return ValidationError{Reason: reason}
+	}
+
+	if expressions.IsExpression(panelQuery) {
+		return e.resolveExpressionQuery(panel, alert, jsonQuery, panelQuery)
+	}
+
+	datasource, err := e.lookupPanelQueryDatasource(panel, alert, panelQuery)
+	if err != nil {
+		return err
+	}
+
+	jsonQuery.SetPath([]string{"datasourceId"}, datasource.Id)
+
+	if interval, err := panel.Get("interval").String(); err == nil {
+		panelQuery.Set("interval", interval)
+	}
+
+	jsonQuery.Set("model", panelQuery.Interface())
+
+	return nil
+}
+
+// resolveExpressionQuery copies the panel expression referenced by the
+// condition query into its model, and the panel queries the expression reads,
+// directly or through other expressions, into its inputs. Each input gets the
+// id of the datasource it targets.
+func (e *DashAlertExtractor) resolveExpressionQuery(panel *simplejson.Json, alert *models.Alert, jsonQuery *simplejson.Json, panelQuery *simplejson.Json) error {
+	if !setting.IsExpressionsEnabled() {
+		return ValidationError{Reason: fmt.Sprintf("Alert on PanelId: %v uses an expression, but the expressions feature toggle is not enabled", alert.PanelId)}
+	}
+
+	inputs := make([]interface{}, 0)
+	seen := map[string]bool{panelQuery.Get("refId").MustString(): true}
+	pending := []*simplejson.Json{panelQuery}
+
+	for len(pending) > 0 {
+		expression := pending[0]
+		pending = pending[1:]
+
+		refIDs, err := expressions.Inputs(expression.Get("refId").MustString(), expression)
+		if err != nil {
+			return ValidationError{Reason: fmt.Sprintf("Alert on PanelId: %v has an invalid expression: %v", alert.PanelId, err)}
+		}
+
+		for _, refID := range refIDs {
+			if seen[refID] {
+				continue
+			}
+			seen[refID] = true
+
+			input := findPanelQueryByRefID(panel, refID)
+			if input == nil {
+				reason := fmt.Sprintf("Alert on PanelId: %v refers to query(%s) that cannot be found", alert.PanelId, refID)
+				return ValidationError{Reason: reason}
+			}
+
+			input, err := copyJSON(input)
+			if err != nil {
+				return err
+			}
+
+			if expressions.IsExpression(input) {
+				input.Set("datasourceId", expressions.DatasourceID)
+				pending = append(pending, input)
+			} else {
+				datasource, err := e.lookupPanelQueryDatasource(panel, alert, input)
+				if err != nil {
+					return err
+				}
+				input.Set("datasourceId", datasource.Id)
+
+				if interval, err := panel.Get("interval").String(); err == nil {
+					input.Set("interval", interval)
+				}
+			}
+
+			inputs = append(inputs, input.Interface())
+		}
+	}
+
+	jsonQuery.SetPath([]string{"datasourceId"}, expressions.DatasourceID)
+	jsonQuery.Set("model", panelQuery.Interface())
+	jsonQuery.Set("inputs", inputs)
+
+	return nil
+}
+
+// lookupPanelQueryDatasource returns the datasource the panel query targets,
+// if the user can query it.
+func (e *DashAlertExtractor) lookupPanelQueryDatasource(panel *simplejson.Json, alert *models.Alert, panelQuery *simplejson.Json) (*models.DataSource, error) {
+	dsName := ""
+	if panelQuery.Get("datasource").MustString() != "" {
+		dsName = panelQuery.Get("datasource").MustString()
+	} else if panel.Get("datasource").MustString() != "" {
+		dsName = panel.Get("datasource").MustString()
+	}
+
+	datasource, err := e.lookupDatasourceID(dsName)
+	if err != nil {
+		e.log.Debug("Error looking up datasource", "error", err)
+		return nil, ValidationError{Reason: fmt.Sprintf("Data source used by alert rule not found, alertName=%v, datasource=%s", alert.Name, dsName)}
+	}
+
+	dsFilterQuery := models.DatasourcesPermissionFilterQuery{
+		User:        e.User,
+		Datasources: []*models.DataSource{datasource},
+	}
+
+	if err := bus.Dispatch(&dsFilterQuery); err != nil {
+		if err != bus.ErrHandlerNotFound {
+			return nil, err
+		}
+	} else {
+		if len(dsFilterQuery.Result) == 0 {
+			return nil, models.ErrDataSourceAccessDenied
+		}
+	}
+
+	return datasource, nil
+}
+
 func copyJSON(in json.Marshaler) (*simplejson.Json, error) {
 	rawJSON, err := in.MarshalJSON()
 	if err != nil {
@@ -139,50 +286,11 @@ func (e *DashAlertExtractor) getAlertFromPanels(jsonWithPanels *simplejson.Json,
 		for _, condition := range jsonAlert.Get("conditions").MustArray() {
 			jsonCondition := simplejson.NewFromAny(condition)
 
-			jsonQuery := jsonCondition.Get("query")
-			queryRefID := jsonQuery.Get("params").MustArray()[0].(string)
-			panelQuery := findPanelQueryByRefID(panel, queryRefID)
-
-			if panelQuery == nil {
-				reason := fmt.Sprintf("Alert on PanelId: %v refers to query(%s) that cannot be found", alert.PanelId, queryRefID)
-				return nil, ValidationError{Reason: reason}
-			}
-
-			dsName := ""
-			if panelQuery.Get("datasource").MustString() != "" {
-				dsName = panelQuery.Get("datasource").MustString()
-			} else if panel.Get("datasource").MustString() != "" {
-				dsName = panel.Get("datasource").MustString()
-			}
-
-			datasource, err := e.lookupDatasourceID(dsName)
-			if err != nil {
-				e.log.Debug("Error looking up datasource", "error", err)
-				return nil, ValidationError{Reason: fmt.Sprintf("Data source used by alert rule not found, alertName=%v, datasource=%s", alert.Name, dsName)}
-			}
-
-			dsFilterQuery := models.DatasourcesPermissionFilterQuery{
-				User:        e.User,
-				Datasources: []*models.DataSource{datasource},
-			}
-
-			if err := bus.Dispatch(&dsFilterQuery); err != nil {
-				if err != bus.ErrHandlerNotFound {
+			for _, jsonQuery := range conditionQueries(jsonCondition) {
+				if err := e.resolveConditionQuery(panel, alert, jsonQuery); err != nil {
 					return nil, err
 				}
-			} else {
-				if len(dsFilterQuery.Result) == 0 {
-					return nil, models.ErrDataSourceAccessDenied
-				}
 			}
-
-			jsonQuery.SetPath([]string{"datasourceId"}, datasource.Id)
-
-			if interval, err := panel.Get("interval").String(); err == nil {
-				panelQuery.Set("interval", interval)
-			}
-
-			jsonQuery.Set("model", panelQuery.Interface())
 		}
 
 		alert.Settings = jsonAlert
@@ -247,7 +355,8 @@ func (e *DashAlertExtractor) extractAlerts(validateFunc func(alert *models.Alert
 }
 
 // ValidateAlerts validates alerts in the dashboard json but does not require a valid dashboard id
-// in the first validation pass.
+// in the first validation pass. Alert rules on panels whose alert rule is provisioned are validated
+// too, but are not saved, as those can only be changed in the provisioning files.
 func (e *DashAlertExtractor) ValidateAlerts() error {
 	_, err := e.extractAlerts(func(alert *models.Alert) bool { return alert.OrgId != 0 && alert.PanelId != 0 })
 	return err
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -17,6 +18,9 @@ func TestAlertRuleExtraction(t *testing.T) {
 		RegisterCondition("query", func(model *simplejson.Json, index int) (Condition, error) {
 			return &FakeCondition{}, nil
 		})
+		RegisterCondition("math", func(model *simplejson.Json, index int) (Condition, error) {
+			return &FakeCondition{}, nil
+		})
 
 		// mock data
 		defaultDs := &models.DataSource{Id: 12, OrgId: 1, Name: "I am default", IsDefault: true}
@@ -195,6 +199,79 @@ func TestAlertRuleExtraction(t *testing.T) {
 			})
 		})
 
+		Convey("Parse alerts with a math condition", func() {
+			json, err := ioutil.ReadFile("./testdata/math-alert.json")
+			So(err, ShouldBeNil)
+
+			dashJSON, err := simplejson.NewJson(json)
+			So(err, ShouldBeNil)
+			dash := models.NewDashboardFromJson(dashJSON)
+			extractor := NewDashAlertExtractor(dash, 1, nil)
+
+			alerts, err := extractor.GetAlerts()
+
+			Convey("Get rules without error", func() {
+				So(err, ShouldBeNil)
+				So(len(alerts), ShouldEqual, 1)
+			})
+
+			Convey("should set datasourceId and model for every query", func() {
+				condition := simplejson.NewFromAny(alerts[0].Settings.Get("conditions").MustArray()[0])
+				queries := condition.Get("queries").MustArray()
+				So(len(queries), ShouldEqual, 2)
+
+				queryA := simplejson.NewFromAny(queries[0]).Get("query")
+				So(queryA.Get("datasourceId").MustInt64(), ShouldEqual, 15)
+				So(queryA.Get("model").Get("target").MustString(), ShouldEqual, "sumSeries(app.requests.errors)")
+
+				queryB := simplejson.NewFromAny(queries[1]).Get("query")
+				So(queryB.Get("datasourceId").MustInt64(), ShouldEqual, 17)
+				So(queryB.Get("model").Get("expr").MustString(), ShouldEqual, "sum(rate(http_requests_total[5m]))")
+			})
+		})
+
+		Convey("Parse alerts with an expression condition", func() {
+			setting.FeatureToggles = map[string]bool{"expressions": true}
+			defer func() { setting.FeatureToggles = map[string]bool{} }()
+
+			json, err := ioutil.ReadFile("./testdata/expression-alert.json")
+			So(err, ShouldBeNil)
+
+			dashJSON, err := simplejson.NewJson(json)
+			So(err, ShouldBeNil)
+			dash := models.NewDashboardFromJson(dashJSON)
+			extractor := NewDashAlertExtractor(dash, 1, nil)
+
+			alerts, err := extractor.GetAlerts()
+
+			Convey("Get rules without error", func() {
+				So(err, ShouldBeNil)
+				So(len(alerts), ShouldEqual, 1)
+			})
+
+			Convey("should set the expression datasource and its inputs", func() {
+				condition := simplejson.NewFromAny(alerts[0].Settings.Get("conditions").MustArray()[0])
+				query := condition.Get("query")
+				So(query.Get("datasourceId").MustInt64(), ShouldEqual, -100)
+				So(query.Get("model").Get("type").MustString(), ShouldEqual, "reduce")
+
+				inputs := query.Get("inputs").MustArray()
+				So(len(inputs), ShouldEqual, 3)
+
+				inputC := simplejson.NewFromAny(inputs[0])
+				So(inputC.Get("refId").MustString(), ShouldEqual, "C")
+				So(inputC.Get("datasourceId").MustInt64(), ShouldEqual, -100)
+
+				inputA := simplejson.NewFromAny(inputs[1])
+				So(inputA.Get("refId").MustString(), ShouldEqual, "A")
+				So(inputA.Get("datasourceId").MustInt64(), ShouldEqual, 15)
+
+				inputB := simplejson.NewFromAny(inputs[2])
+				So(inputB.Get("refId").MustString(), ShouldEqual, "B")
+				So(inputB.Get("datasourceId").MustInt64(), ShouldEqual, 17)
+			})
+		})
+
 		Convey("Alert notifications are in DB", func() {
 			sqlstore.InitTestDB(t)
 			firstNotification := models.CreateAlertNotificationCommand{Uid: "notifier1", OrgId: 1, Name: "1"}
@@ -274,6 +351,25 @@ func TestAlertRuleExtraction(t *testing.T) {
 					So(err.Error(), ShouldEqual, "Alert validation error: Panel id is not correct, alertName=Influxdb, panelId=1")
 				})
 			})
+
+			Convey("Parse and validate dashboard containing an alert on a provisioned panel", func() {
+				bus.AddHandler("test", func(query *models.GetProvisionedAlertsQuery) error {
+					query.Result = []*models.Alert{{Id: 3, DashboardId: query.DashboardId, PanelId: 3}}
+					return nil
+				})
+
+				dashJSON, err := simplejson.NewJson(json)
+				So(err, ShouldBeNil)
+				dash := models.NewDashboardFromJson(dashJSON)
+				dash.Id = 57
+				extractor := NewDashAlertExtractor(dash, 1, nil)
+
+				err = extractor.ValidateAlerts()
+
+				Convey("Should not fail validation of the other panels", func() {
+					So(err, ShouldBeNil)
+				})
+			})
 		})
 	})
 }
@@ -35,6 +35,11 @@ type Notifier interface {
 type notifierState struct {
 	notifier Notifier
 	state    *models.AlertNotificationState
+
+	// groupKey is set when the notification is batched by the group
+	// interval of a notification policy.
+	groupKey      string
+	groupInterval time.Duration
 }
 
 type notifierStateSlice []*notifierState
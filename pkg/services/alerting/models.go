@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/models"
 )
 
 // Job holds state about when the alert rule should be evaluated.
@@ -41,4 +42,9 @@ type EvalMatch struct {
 	Value  null.Float        `json:"value"`
 	Metric string            `json:"metric"`
 	Tags   map[string]string `json:"tags"`
+
+	// State and PrevState are the state of the alert instance
+	// identified by Metric and Tags, before and after the evaluation.
+	State     models.AlertStateType `json:"state,omitempty"`
+	PrevState models.AlertStateType `json:"prevState,omitempty"`
 }
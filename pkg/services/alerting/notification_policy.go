@@ -0,0 +1,311 @@
+package alerting
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// defaultPolicyRepeatInterval is the repeat interval of groups of external
+// alerts whose notification policies do not set one. Alert rules only send
+// reminders when their policy sets a repeat interval.
+const defaultPolicyRepeatInterval = 4 * time.Hour
+
+// notificationRoute is a matching notification policy with its inherited
+// settings resolved.
+type notificationRoute struct {
+	key              string
+	notificationUids []string
+	groupBy          []string
+	groupInterval    time.Duration
+	repeatInterval   time.Duration
+}
+
+// groupKey returns the key of the notification group of the alert rule
+// for a notification channel of the route.
+func (r *notificationRoute) groupKey(target *ruleTarget, notificationUID string) string {
+	return fmt.Sprintf("%s/%s/%s", r.key, notificationUID, r.groupValues(target))
+}
+
+// groupValues returns the values of the group by tags of the alert rule.
+func (r *notificationRoute) groupValues(target *ruleTarget) string {
+	values := make([]string, 0, len(r.groupBy))
+	for _, key := range r.groupBy {
+		values = append(values, key+"="+target.tagValue(key))
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+// findNotificationRoutes returns the routes of the notification policy of
+// the organization that match the alert rule of the eval context.
+func findNotificationRoutes(evalCtx *EvalContext, target *ruleTarget) ([]*notificationRoute, error) {
+	query := &models.GetAlertNotificationPolicyQuery{OrgId: evalCtx.Rule.OrgID}
+	if err := bus.DispatchCtx(evalCtx.Ctx, query); err != nil {
+		if err == models.ErrAlertNotificationPolicyNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return policyNotificationRoutes(evalCtx, target, query.Result.Policy)
+}
+
+// policyNotificationRoutes returns the routes of the given notification
+// policy of the organization that match the alert rule.
+func policyNotificationRoutes(evalCtx *EvalContext, target *ruleTarget, policy *models.NotificationPolicy) ([]*notificationRoute, error) {
+	root := &notificationRoute{key: "0"}
+	return matchNotificationPolicy(evalCtx, target, policy, root)
+}
+
+// matchNotificationPolicy returns the routes of the policy and its child
+// policies that match the alert rule. The first matching child policy is
+// used, and the following ones only when it sets continue. When no child
+// policy matches the policy itself is used.
+func matchNotificationPolicy(evalCtx *EvalContext, target *ruleTarget, policy *models.NotificationPolicy, parent *notificationRoute) ([]*notificationRoute, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	matches, err := target.matchesPolicy(evalCtx, policy)
+	if err != nil || !matches {
+		return nil, err
+	}
+
+	route, err := newNotificationRoute(policy, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*notificationRoute
+	for i, child := range policy.Routes {
+		childParent := *route
+		childParent.key = fmt.Sprintf("%s.%d", route.key, i)
+
+		routes, err := matchNotificationPolicy(evalCtx, target, child, &childParent)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(routes) == 0 {
+			continue
+		}
+
+		result = append(result, routes...)
+		if !child.Continue {
+			break
+		}
+	}
+
+	if len(result) == 0 {
+		result = append(result, route)
+	}
+
+	return result, nil
+}
+
+// newNotificationRoute resolves the settings of the policy, inheriting
+// the ones it does not set from its parent.
+func newNotificationRoute(policy *models.NotificationPolicy, parent *notificationRoute) (*notificationRoute, error) {
+	route := &notificationRoute{
+		key:              parent.key,
+		notificationUids: policy.NotificationUids,
+		groupBy:          parent.groupBy,
+		groupInterval:    parent.groupInterval,
+		repeatInterval:   parent.repeatInterval,
+	}
+
+	if len(policy.GroupBy) > 0 {
+		route.groupBy = policy.GroupBy
+	}
+
+	var err error
+	if policy.GroupInterval != "" {
+		if route.groupInterval, err = time.ParseDuration(policy.GroupInterval); err != nil {
+			return nil, fmt.Errorf("invalid group interval %q: %v", policy.GroupInterval, err)
+		}
+	}
+
+	if policy.RepeatInterval != "" {
+		if route.repeatInterval, err = time.ParseDuration(policy.RepeatInterval); err != nil {
+			return nil, fmt.Errorf("invalid repeat interval %q: %v", policy.RepeatInterval, err)
+		}
+	}
+
+	return route, nil
+}
+
+func (t *ruleTarget) matchesPolicy(evalCtx *EvalContext, policy *models.NotificationPolicy) (bool, error) {
+	for key, value := range policy.Tags {
+		if !t.hasTag(key, value) {
+			return false, nil
+		}
+	}
+
+	if policy.Severity != "" && !t.hasTag(models.NotificationPolicySeverityTag, policy.Severity) {
+		return false, nil
+	}
+
+	if policy.FolderUid == "" {
+		return true, nil
+	}
+
+	if err := t.loadDashboard(evalCtx); err != nil {
+		return false, err
+	}
+
+	return policy.FolderUid == t.folderUID, nil
+}
+
+// ValidateNotificationPolicy checks the intervals of the policy and its
+// child policies, and that the notification channels they use exist.
+func ValidateNotificationPolicy(orgID int64, policy *models.NotificationPolicy) error {
+	return validateNotificationPolicy(orgID, policy, &notificationRoute{})
+}
+
+func validateNotificationPolicy(orgID int64, policy *models.NotificationPolicy, parent *notificationRoute) error {
+	if policy == nil {
+		return fmt.Errorf("notification policy cannot be empty")
+	}
+
+	route, err := newNotificationRoute(policy, parent)
+	if err != nil {
+		return err
+	}
+
+	for _, uid := range policy.NotificationUids {
+		query := &models.GetAlertNotificationsWithUidQuery{OrgId: orgID, Uid: uid}
+		if err := bus.Dispatch(query); err != nil {
+			return err
+		}
+
+		if query.Result == nil {
+			return fmt.Errorf("notification channel %q not found", uid)
+		}
+	}
+
+	for _, child := range policy.Routes {
+		if err := validateNotificationPolicy(orgID, child, route); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notificationGroupFlushInterval is how often the notification groups are
+// checked for batched notifications that are due.
+var notificationGroupFlushInterval = time.Second
+
+// notificationGroupTimeout is how long a notification group without
+// batched notifications is kept after its group interval has passed.
+const notificationGroupTimeout = time.Hour
+
+// notificationGroups batches the notifications of the alert rules in the
+// same group of a notification policy, the way external alerts are. The
+// alerting rules of a group are sent together as one notification at most
+// once per group interval. The first notification of a new group is sent
+// on the next flush.
+type notificationGroups struct {
+	mu     sync.Mutex
+	groups map[string]*notificationGroup
+}
+
+type notificationGroup struct {
+	orgID    int64
+	notifier Notifier
+	interval time.Duration
+	lastSent time.Time
+	pending  map[int64]*groupedNotification
+}
+
+// groupedNotification is the notification of an alert rule waiting for
+// the next notification of its group.
+type groupedNotification struct {
+	evalCtx *EvalContext
+	state   *models.AlertNotificationState
+}
+
+// notificationBatch holds the notifications of a group for one flush.
+type notificationBatch struct {
+	key           string
+	orgID         int64
+	notifier      Notifier
+	notifications []*groupedNotification
+}
+
+func newNotificationGroups() *notificationGroups {
+	return &notificationGroups{groups: make(map[string]*notificationGroup)}
+}
+
+// add batches the notification of the alert rule of the eval context into
+// its group, replacing the one it was already waiting with.
+func (g *notificationGroups) add(evalCtx *EvalContext, notifierState *notifierState) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[notifierState.groupKey]
+	if !ok {
+		group = &notificationGroup{orgID: evalCtx.Rule.OrgID, pending: make(map[int64]*groupedNotification)}
+		g.groups[notifierState.groupKey] = group
+	}
+
+	group.notifier = notifierState.notifier
+	group.interval = notifierState.groupInterval
+	group.pending[evalCtx.Rule.ID] = &groupedNotification{evalCtx: evalCtx, state: notifierState.state}
+}
+
+// remove drops the batched notification of the alert rule, and returns
+// true if there was one.
+func (g *notificationGroups) remove(key string, ruleID int64) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[key]
+	if !ok || group.pending[ruleID] == nil {
+		return false
+	}
+
+	delete(group.pending, ruleID)
+	return true
+}
+
+// due returns the batches of the groups whose group interval has passed,
+// and evicts the groups without batched notifications that timed out.
+func (g *notificationGroups) due(now time.Time) []*notificationBatch {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var batches []*notificationBatch
+	for key, group := range g.groups {
+		next := group.lastSent.Add(group.interval)
+		if len(group.pending) == 0 {
+			if !next.Add(notificationGroupTimeout).After(now) {
+				delete(g.groups, key)
+			}
+			continue
+		}
+
+		if next.After(now) {
+			continue
+		}
+
+		batch := &notificationBatch{key: key, orgID: group.orgID, notifier: group.notifier}
+		for _, notification := range group.pending {
+			batch.notifications = append(batch.notifications, notification)
+		}
+		sort.Slice(batch.notifications, func(i, j int) bool {
+			return batch.notifications[i].evalCtx.Rule.ID < batch.notifications[j].evalCtx.Rule.ID
+		})
+
+		batches = append(batches, batch)
+		group.pending = make(map[int64]*groupedNotification)
+		group.lastSent = now
+	}
+
+	return batches
+}
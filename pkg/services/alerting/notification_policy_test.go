@@ -0,0 +1,286 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestMatchNotificationPolicy(t *testing.T) {
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+		switch query.Id {
+		case 1:
+			query.Result = &models.Dashboard{Id: 1, Uid: "dash", FolderId: 10}
+		case 10:
+			query.Result = &models.Dashboard{Id: 10, Uid: "platform", IsFolder: true}
+		}
+		return nil
+	})
+
+	rule := &Rule{
+		ID:          1,
+		OrgID:       1,
+		DashboardID: 1,
+		AlertRuleTags: []*models.Tag{
+			{Key: "team", Value: "payments"},
+			{Key: "severity", Value: "critical"},
+			{Key: "region", Value: "eu"},
+		},
+	}
+	evalCtx := NewEvalContext(context.Background(), rule)
+
+	match := func(t *testing.T, policy *models.NotificationPolicy) []*notificationRoute {
+		routes, err := matchNotificationPolicy(evalCtx, &ruleTarget{rule: rule}, policy, &notificationRoute{key: "0"})
+		require.NoError(t, err)
+		return routes
+	}
+
+	uidsOf := func(routes []*notificationRoute) []string {
+		var uids []string
+		for _, route := range routes {
+			uids = append(uids, route.notificationUids...)
+		}
+		return uids
+	}
+
+	t.Run("uses root policy when no child policy matches", func(t *testing.T) {
+		routes := match(t, &models.NotificationPolicy{
+			NotificationUids: []string{"default"},
+			Routes: []*models.NotificationPolicy{
+				{Tags: map[string]string{"team": "search"}, NotificationUids: []string{"search"}},
+			},
+		})
+		assert.Equal(t, []string{"default"}, uidsOf(routes))
+	})
+
+	t.Run("uses first matching child policy", func(t *testing.T) {
+		routes := match(t, &models.NotificationPolicy{
+			NotificationUids: []string{"default"},
+			Routes: []*models.NotificationPolicy{
+				{Tags: map[string]string{"team": "payments"}, NotificationUids: []string{"payments"}},
+				{Severity: "critical", NotificationUids: []string{"critical"}},
+			},
+		})
+		assert.Equal(t, []string{"payments"}, uidsOf(routes))
+	})
+
+	t.Run("continues to next child policy when set", func(t *testing.T) {
+		routes := match(t, &models.NotificationPolicy{
+			Routes: []*models.NotificationPolicy{
+				{Tags: map[string]string{"team": "payments"}, NotificationUids: []string{"payments"}, Continue: true},
+				{Severity: "warning", NotificationUids: []string{"warning"}},
+				{Severity: "critical", NotificationUids: []string{"critical"}},
+			},
+		})
+		assert.Equal(t, []string{"payments", "critical"}, uidsOf(routes))
+	})
+
+	t.Run("matches nested policies and folder", func(t *testing.T) {
+		routes := match(t, &models.NotificationPolicy{
+			Routes: []*models.NotificationPolicy{
+				{
+					FolderUid:        "other",
+					NotificationUids: []string{"other"},
+				},
+				{
+					FolderUid:        "platform",
+					NotificationUids: []string{"platform"},
+					Routes: []*models.NotificationPolicy{
+						{Tags: map[string]string{"region": "us"}, NotificationUids: []string{"platform-us"}},
+						{Tags: map[string]string{"region": "eu"}, NotificationUids: []string{"platform-eu"}},
+					},
+				},
+			},
+		})
+		require.Len(t, routes, 1)
+		assert.Equal(t, []string{"platform-eu"}, routes[0].notificationUids)
+		assert.Equal(t, "0.1.1", routes[0].key)
+	})
+
+	t.Run("inherits intervals and grouping from parent policy", func(t *testing.T) {
+		routes := match(t, &models.NotificationPolicy{
+			GroupBy:        []string{"team"},
+			GroupInterval:  "5m",
+			RepeatInterval: "1h",
+			Routes: []*models.NotificationPolicy{
+				{Tags: map[string]string{"team": "payments"}, RepeatInterval: "30m", NotificationUids: []string{"payments"}},
+			},
+		})
+		require.Len(t, routes, 1)
+		assert.Equal(t, []string{"team"}, routes[0].groupBy)
+		assert.Equal(t, 5*time.Minute, routes[0].groupInterval)
+		assert.Equal(t, 30*time.Minute, routes[0].repeatInterval)
+		assert.Equal(t, "0.0/payments/{team=payments}", routes[0].groupKey(&ruleTarget{rule: rule}, "payments"))
+	})
+
+	t.Run("root policy with matchers that do not match routes nothing", func(t *testing.T) {
+		routes := match(t, &models.NotificationPolicy{Severity: "warning", NotificationUids: []string{"warning"}})
+		assert.Empty(t, routes)
+	})
+
+	t.Run("returns error for invalid interval", func(t *testing.T) {
+		_, err := matchNotificationPolicy(evalCtx, &ruleTarget{rule: rule}, &models.NotificationPolicy{GroupInterval: "soon"}, &notificationRoute{})
+		require.Error(t, err)
+	})
+}
+
+func TestValidateNotificationPolicy(t *testing.T) {
+	bus.AddHandler("test", func(query *models.GetAlertNotificationsWithUidQuery) error {
+		if query.Uid == "payments" {
+			query.Result = &models.AlertNotification{Uid: "payments"}
+		}
+		return nil
+	})
+
+	t.Run("valid policy", func(t *testing.T) {
+		err := ValidateNotificationPolicy(1, &models.NotificationPolicy{
+			RepeatInterval: "4h",
+			Routes: []*models.NotificationPolicy{
+				{Tags: map[string]string{"team": "payments"}, NotificationUids: []string{"payments"}},
+			},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown notification channel", func(t *testing.T) {
+		err := ValidateNotificationPolicy(1, &models.NotificationPolicy{
+			Routes: []*models.NotificationPolicy{
+				{NotificationUids: []string{"missing"}},
+			},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing")
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		err := ValidateNotificationPolicy(1, &models.NotificationPolicy{
+			Routes: []*models.NotificationPolicy{
+				{RepeatInterval: "daily"},
+			},
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestNotificationGroups(t *testing.T) {
+	groups := newNotificationGroups()
+	start := time.Now()
+
+	add := func(key string, ruleID int64) {
+		evalCtx := NewEvalContext(context.Background(), &Rule{ID: ruleID, OrgID: 1, State: models.AlertStateAlerting})
+		groups.add(evalCtx, &notifierState{
+			notifier:      &testNotifier{},
+			state:         &models.AlertNotificationState{Id: ruleID},
+			groupKey:      key,
+			groupInterval: 5 * time.Minute,
+		})
+	}
+
+	ruleIDs := func(batch *notificationBatch) []int64 {
+		var ids []int64
+		for _, notification := range batch.notifications {
+			ids = append(ids, notification.evalCtx.Rule.ID)
+		}
+		return ids
+	}
+
+	// the first notification of a group is sent on the next flush
+	add("group", 1)
+	batches := groups.due(start)
+	require.Len(t, batches, 1)
+	assert.Equal(t, []int64{1}, ruleIDs(batches[0]))
+
+	// the following ones are batched until the group interval has passed
+	add("group", 3)
+	add("group", 2)
+	require.Empty(t, groups.due(start.Add(time.Minute)))
+	batches = groups.due(start.Add(5 * time.Minute))
+	require.Len(t, batches, 1)
+	assert.Equal(t, []int64{2, 3}, ruleIDs(batches[0]))
+
+	// removed notifications are not sent
+	add("group", 4)
+	assert.True(t, groups.remove("group", 4))
+	assert.False(t, groups.remove("group", 4))
+	require.Empty(t, groups.due(start.Add(10*time.Minute)))
+
+	// other groups are not affected
+	add("other", 5)
+	batches = groups.due(start.Add(6 * time.Minute))
+	require.Len(t, batches, 1)
+	assert.Equal(t, "other", batches[0].key)
+
+	// groups without batched notifications are evicted after the timeout
+	groups.due(start.Add(10*time.Minute + notificationGroupTimeout))
+	assert.Len(t, groups.groups, 1)
+	assert.Contains(t, groups.groups, "other")
+}
+
+func TestSendNotificationBatch(t *testing.T) {
+	var pending, complete []int64
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToPendingCommand) error {
+		if cmd.Id == 3 {
+			return models.ErrAlertNotificationStateVersionConflict
+		}
+		pending = append(pending, cmd.Id)
+		cmd.ResultVersion = cmd.Version + 1
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToCompleteCommand) error {
+		complete = append(complete, cmd.Id)
+		return nil
+	})
+
+	newNotification := func(ruleID int64, team string) *groupedNotification {
+		evalCtx := NewEvalContext(context.Background(), &Rule{
+			ID:            ruleID,
+			OrgID:         1,
+			Name:          fmt.Sprintf("Rule %d", ruleID),
+			Message:       "is bad",
+			State:         models.AlertStateAlerting,
+			AlertRuleTags: []*models.Tag{{Key: "team", Value: "payments"}, {Key: "env", Value: team}},
+		})
+		evalCtx.ruleURL = fmt.Sprintf("http://grafana/rule/%d", ruleID)
+		evalCtx.EvalMatches = []*EvalMatch{{Metric: fmt.Sprintf("series%d", ruleID)}}
+		return &groupedNotification{evalCtx: evalCtx, state: &models.AlertNotificationState{Id: ruleID}}
+	}
+
+	var sent []*EvalContext
+	notifier := &recordingNotifier{sent: &sent}
+	batch := &notificationBatch{
+		key:      "0/payments-oncall/{}",
+		orgID:    1,
+		notifier: notifier,
+		notifications: []*groupedNotification{
+			newNotification(1, "prod"),
+			newNotification(2, "dev"),
+			newNotification(3, "prod"),
+		},
+	}
+
+	n := newNotificationService(nil)
+	n.sendBatch(context.Background(), batch, time.Now())
+
+	assert.Equal(t, []int64{1, 2}, pending)
+	assert.Equal(t, []int64{1, 2}, complete)
+
+	require.Len(t, sent, 1)
+	evalCtx := sent[0]
+	assert.Equal(t, "2 alerts", evalCtx.Rule.Name)
+	assert.Equal(t, "Rule 1: is bad\nRule 2: is bad", evalCtx.Rule.Message)
+	assert.Less(t, evalCtx.Rule.ID, int64(0))
+	assert.Equal(t, []*models.Tag{{Key: "team", Value: "payments"}}, evalCtx.Rule.AlertRuleTags)
+	assert.Len(t, evalCtx.EvalMatches, 2)
+	assert.True(t, evalCtx.Firing)
+
+	ruleURL, err := evalCtx.GetRuleURL()
+	require.NoError(t, err)
+	assert.Equal(t, "http://grafana/rule/1", ruleURL)
+}
@@ -0,0 +1,152 @@
+package alerting
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	// TitleTemplateSetting is the notification channel setting holding the title template.
+	TitleTemplateSetting = "titleTemplate"
+	// BodyTemplateSetting is the notification channel setting holding the body template.
+	BodyTemplateSetting = "bodyTemplate"
+)
+
+// NotificationTemplateData is the data available to notification templates.
+type NotificationTemplateData struct {
+	Title            string
+	RuleID           int64
+	RuleName         string
+	Message          string
+	State            string
+	PrevState        string
+	RuleURL          string
+	ImageURL         string
+	Error            string
+	EvalMatches      []*EvalMatch
+	ChangedInstances []*EvalMatch
+	Tags             map[string]string
+	IsTestRun        bool
+}
+
+var notificationTemplateFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"toUpper": strings.ToUpper,
+	"toLower": strings.ToLower,
+}
+
+// NotificationTemplates renders the title and body of the notifications
+// of a notification channel. Without templates the default title and the
+// message of the alert rule are used.
+type NotificationTemplates struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// NewNotificationTemplates parses the templates of the notification
+// channel settings.
+func NewNotificationTemplates(settings *simplejson.Json) (*NotificationTemplates, error) {
+	templates := &NotificationTemplates{}
+	if settings == nil {
+		return templates, nil
+	}
+
+	var err error
+	if templates.title, err = parseNotificationTemplate(TitleTemplateSetting, settings.Get(TitleTemplateSetting).MustString()); err != nil {
+		return nil, err
+	}
+
+	if templates.body, err = parseNotificationTemplate(BodyTemplateSetting, settings.Get(BodyTemplateSetting).MustString()); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func parseNotificationTemplate(name string, text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(name).Funcs(notificationTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// Title renders the title of the notification.
+func (t *NotificationTemplates) Title(evalContext *EvalContext) (string, error) {
+	if t == nil || t.title == nil {
+		return evalContext.GetNotificationTitle(), nil
+	}
+
+	return executeNotificationTemplate(t.title, evalContext.NotificationTemplateData())
+}
+
+// Body renders the body of the notification.
+func (t *NotificationTemplates) Body(evalContext *EvalContext) (string, error) {
+	if t == nil || t.body == nil {
+		return evalContext.Rule.Message, nil
+	}
+
+	return executeNotificationTemplate(t.body, evalContext.NotificationTemplateData())
+}
+
+func executeNotificationTemplate(tmpl *template.Template, data *NotificationTemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %v", tmpl.Name(), err)
+	}
+
+	return buf.String(), nil
+}
+
+// NotificationTemplateData returns the data available to notification
+// templates for the evaluation.
+func (c *EvalContext) NotificationTemplateData() *NotificationTemplateData {
+	data := &NotificationTemplateData{
+		Title:            c.GetNotificationTitle(),
+		RuleID:           c.Rule.ID,
+		RuleName:         c.Rule.Name,
+		Message:          c.Rule.Message,
+		State:            string(c.Rule.State),
+		PrevState:        string(c.PrevAlertState),
+		ImageURL:         c.ImagePublicURL,
+		EvalMatches:      c.EvalMatches,
+		ChangedInstances: c.ChangedInstances,
+		Tags:             make(map[string]string, len(c.Rule.AlertRuleTags)),
+		IsTestRun:        c.IsTestRun,
+	}
+
+	if ruleURL, err := c.GetRuleURL(); err == nil {
+		data.RuleURL = ruleURL
+	}
+
+	if c.Error != nil {
+		data.Error = c.Error.Error()
+	}
+
+	for _, tag := range c.Rule.AlertRuleTags {
+		data.Tags[tag.Key] = tag.Value
+	}
+
+	return data
+}
+
+// ValidateNotificationTemplates checks that the templates of the
+// notification channel settings parse and render for a test alert.
+func ValidateNotificationTemplates(settings *simplejson.Json) error {
+	if settings == nil {
+		return nil
+	}
+
+	evalContext := createTestEvalContext(&NotificationTestCommand{Settings: settings})
+	_, err := renderNotificationPreview(settings, evalContext)
+	return err
+}
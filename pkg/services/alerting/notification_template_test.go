@@ -0,0 +1,129 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestNotificationTemplates(t *testing.T) {
+	bus.AddHandler("test", func(query *models.GetDashboardRefByIdQuery) error {
+		query.Result = &models.DashboardRef{Uid: "abcdef", Slug: "cpu"}
+		return nil
+	})
+
+	newEvalContext := func() *EvalContext {
+		evalContext := NewEvalContext(context.Background(), &Rule{
+			ID:      1,
+			OrgID:   1,
+			PanelID: 2,
+			Name:    "CPU usage",
+			Message: "CPU is high",
+			State:   models.AlertStateAlerting,
+			AlertRuleTags: []*models.Tag{
+				{Key: "team", Value: "backend"},
+			},
+		})
+		evalContext.ImagePublicURL = "http://images.example.com/1.png"
+		evalContext.EvalMatches = []*EvalMatch{
+			{Metric: "server1", Value: null.FloatFrom(95)},
+			{Metric: "server2", Value: null.FloatFrom(97)},
+		}
+		return evalContext
+	}
+
+	t.Run("falls back to default title and rule message without templates", func(t *testing.T) {
+		templates, err := NewNotificationTemplates(simplejson.New())
+		require.NoError(t, err)
+
+		evalContext := newEvalContext()
+		title, err := templates.Title(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, evalContext.GetNotificationTitle(), title)
+
+		body, err := templates.Body(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, "CPU is high", body)
+	})
+
+	t.Run("nil templates fall back to defaults", func(t *testing.T) {
+		var templates *NotificationTemplates
+
+		body, err := templates.Body(newEvalContext())
+		require.NoError(t, err)
+		assert.Equal(t, "CPU is high", body)
+	})
+
+	t.Run("renders title and body templates", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			TitleTemplateSetting: "{{ .State | toUpper }}: {{ .RuleName }} ({{ .Tags.team }})",
+			BodyTemplateSetting:  "{{ .Message }}{{ range .EvalMatches }}\n{{ .Metric }}={{ .Value }}{{ end }}\n{{ .RuleURL }}\n{{ .ImageURL }}",
+		})
+		templates, err := NewNotificationTemplates(settings)
+		require.NoError(t, err)
+
+		evalContext := newEvalContext()
+		title, err := templates.Title(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, "ALERTING: CPU usage (backend)", title)
+
+		ruleURL, err := evalContext.GetRuleURL()
+		require.NoError(t, err)
+		assert.Contains(t, ruleURL, "d/abcdef/cpu")
+
+		body, err := templates.Body(evalContext)
+		require.NoError(t, err)
+		assert.Equal(t, "CPU is high\nserver1=95.000\nserver2=97.000\n"+ruleURL+"\nhttp://images.example.com/1.png", body)
+	})
+
+	t.Run("returns error for template that does not parse", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			TitleTemplateSetting: "{{ .RuleName ",
+		})
+
+		_, err := NewNotificationTemplates(settings)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid titleTemplate")
+	})
+
+	t.Run("returns error for template that fails to render", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			BodyTemplateSetting: "{{ .Unknown }}",
+		})
+
+		templates, err := NewNotificationTemplates(settings)
+		require.NoError(t, err)
+
+		_, err = templates.Body(newEvalContext())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to render bodyTemplate")
+	})
+}
+
+func TestValidateNotificationTemplates(t *testing.T) {
+	t.Run("valid templates", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			TitleTemplateSetting: "[{{ .State }}] {{ .RuleName }}",
+			BodyTemplateSetting:  "{{ .Message }} {{ .RuleURL }}",
+		})
+		assert.NoError(t, ValidateNotificationTemplates(settings))
+	})
+
+	t.Run("no templates", func(t *testing.T) {
+		assert.NoError(t, ValidateNotificationTemplates(simplejson.New()))
+	})
+
+	t.Run("template referencing unknown field", func(t *testing.T) {
+		settings := simplejson.NewFromAny(map[string]interface{}{
+			TitleTemplateSetting: "{{ .Unknown }}",
+		})
+		assert.Error(t, ValidateNotificationTemplates(settings))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -87,16 +88,35 @@ func newNotificationService(renderService rendering.Service) *notificationServic
 	return &notificationService{
 		log:           log.New("alerting.notifier"),
 		renderService: renderService,
+		groups:        newNotificationGroups(),
 	}
 }
 
 type notificationService struct {
 	log           log.Logger
 	renderService rendering.Service
+	groups        *notificationGroups
 }
 
 func (n *notificationService) SendIfNeeded(evalCtx *EvalContext) error {
-	notifierStates, err := n.getNeededNotifiers(evalCtx.Rule.OrgID, evalCtx.Rule.Notifications, evalCtx)
+	var silence *models.AlertSilence
+	var routes []*notificationRoute
+	if !evalCtx.IsTestRun {
+		var err error
+		silence, err = findActiveSilence(evalCtx)
+		if err != nil {
+			n.log.Error("Failed to get alert silences", "error", err)
+			return err
+		}
+
+		routes, err = findNotificationRoutes(evalCtx, &ruleTarget{rule: evalCtx.Rule})
+		if err != nil {
+			n.log.Error("Failed to get alert notification policy", "error", err)
+			return err
+		}
+	}
+
+	notifierStates, err := n.getNeededNotifiers(evalCtx.Rule.OrgID, evalCtx.Rule.Notifications, routes, evalCtx)
 	if err != nil {
 		n.log.Error("Failed to get alert notifiers", "error", err)
 		return err
@@ -106,6 +126,11 @@ func (n *notificationService) SendIfNeeded(evalCtx *EvalContext) error {
 		return nil
 	}
 
+	if silence != nil {
+		n.log.Debug("Notifications are silenced", "ruleId", evalCtx.Rule.ID, "silenceId", silence.Id)
+		return n.holdNotifications(evalCtx, notifierStates)
+	}
+
 	if notifierStates.ShouldUploadImage() {
 		// Create a copy of EvalContext and give it a new, shorter, timeout context to upload the image
 		uploadEvalCtx := *evalCtx
@@ -122,7 +147,27 @@ func (n *notificationService) SendIfNeeded(evalCtx *EvalContext) error {
 		evalCtx.ImagePublicURL = uploadEvalCtx.ImagePublicURL
 	}
 
-	return n.sendNotifications(evalCtx, notifierStates)
+	return n.sendNotifications(evalCtx, n.groupNotifications(evalCtx, notifierStates))
+}
+
+// holdNotifications leaves the notifications muted by a silence pending, so
+// that they are sent once the silence ends if the alert rule is still
+// alerting.
+func (n *notificationService) holdNotifications(evalCtx *EvalContext, notifierStates notifierStateSlice) error {
+	for _, notifierState := range notifierStates {
+		cmd := &models.SetAlertNotificationStateToPendingCommand{
+			Id:                           notifierState.state.Id,
+			Version:                      notifierState.state.Version,
+			AlertRuleStateUpdatedVersion: evalCtx.Rule.StateChanges,
+		}
+
+		err := bus.DispatchCtx(evalCtx.Ctx, cmd)
+		if err != nil && err != models.ErrAlertNotificationStateVersionConflict {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (n *notificationService) sendAndMarkAsComplete(evalContext *EvalContext, notifierState *notifierState) error {
@@ -133,6 +178,12 @@ func (n *notificationService) sendAndMarkAsComplete(evalContext *EvalContext, no
 
 	err := notifier.Notify(evalContext)
 
+	result := &models.AlertStateHistoryNotification{Uid: notifier.GetNotifierUID(), Type: notifier.GetType(), Sent: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	evalContext.NotificationResults = append(evalContext.NotificationResults, result)
+
 	if err != nil {
 		n.log.Error("failed to send notification", "uid", notifier.GetNotifierUID(), "error", err)
 		metrics.MAlertingNotificationFailed.WithLabelValues(notifier.GetType()).Inc()
@@ -239,15 +290,43 @@ func (n *notificationService) renderAndUploadImage(evalCtx *EvalContext, timeout
 	return nil
 }
 
-func (n *notificationService) getNeededNotifiers(orgID int64, notificationUids []string, evalContext *EvalContext) (notifierStateSlice, error) {
-	query := &models.GetAlertNotificationsWithUidToSendQuery{OrgId: orgID, Uids: notificationUids}
+// getNeededNotifiers returns the notifiers that should notify for the
+// alert rule, among the notification channels set on the rule and the ones
+// of the notification policies it matches. Channels set on the rule keep
+// their own reminder settings.
+func (n *notificationService) getNeededNotifiers(orgID int64, notificationUids []string, routes []*notificationRoute, evalContext *EvalContext) (notifierStateSlice, error) {
+	onRule := make(map[string]bool, len(notificationUids))
+	for _, uid := range notificationUids {
+		onRule[uid] = true
+	}
+
+	routesByUID := make(map[string]*notificationRoute)
+	uids := append([]string{}, notificationUids...)
+	for _, route := range routes {
+		for _, uid := range route.notificationUids {
+			if _, ok := routesByUID[uid]; !ok && !onRule[uid] {
+				routesByUID[uid] = route
+				uids = append(uids, uid)
+			}
+		}
+	}
+
+	query := &models.GetAlertNotificationsWithUidToSendQuery{OrgId: orgID, Uids: uids}
 
 	if err := bus.Dispatch(query); err != nil {
 		return nil, err
 	}
 
+	target := &ruleTarget{rule: evalContext.Rule}
+
 	var result notifierStateSlice
 	for _, notification := range query.Result {
+		route := routesByUID[notification.Uid]
+		if route != nil && route.repeatInterval > 0 && !notification.SendReminder {
+			notification.SendReminder = true
+			notification.Frequency = route.repeatInterval
+		}
+
 		not, err := InitNotifier(notification)
 		if err != nil {
 			n.log.Error("Could not create notifier", "notifier", notification.Uid, "error", err)
@@ -266,17 +345,187 @@ func (n *notificationService) getNeededNotifiers(orgID int64, notificationUids [
 			continue
 		}
 
+		state := &notifierState{
+			notifier: not,
+			state:    query.Result,
+		}
+
+		// firing notifications of channels with a group interval are
+		// batched with the other alert rules of their group, resolved
+		// ones are sent right away unless the firing one was never sent.
+		if route != nil && route.groupInterval > 0 {
+			groupKey := route.groupKey(target, notification.Uid)
+			if evalContext.Rule.State != models.AlertStateAlerting {
+				if n.groups.remove(groupKey, evalContext.Rule.ID) {
+					continue
+				}
+			} else {
+				state.groupKey = groupKey
+				state.groupInterval = route.groupInterval
+			}
+		}
+
 		if not.ShouldNotify(evalContext.Ctx, evalContext, query.Result) {
-			result = append(result, &notifierState{
-				notifier: not,
-				state:    query.Result,
-			})
+			result = append(result, state)
 		}
 	}
 
 	return result, nil
 }
 
+// groupNotifications batches the notifications of the notifier states with
+// a group key into their groups, and returns the ones to send right away.
+func (n *notificationService) groupNotifications(evalCtx *EvalContext, notifierStates notifierStateSlice) notifierStateSlice {
+	var result notifierStateSlice
+	for _, notifierState := range notifierStates {
+		if notifierState.groupKey == "" {
+			result = append(result, notifierState)
+			continue
+		}
+
+		// the eval context outlives the evaluation, its context is
+		// replaced when the group is sent.
+		grouped := *evalCtx
+		n.log.Debug("Batching notification", "ruleId", evalCtx.Rule.ID, "group", notifierState.groupKey)
+		n.groups.add(&grouped, notifierState)
+	}
+	return result
+}
+
+// runGroups sends the batched notifications of the notification groups
+// once their group interval has passed.
+func (n *notificationService) runGroups(ctx context.Context) error {
+	ticker := time.NewTicker(notificationGroupFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			for _, batch := range n.groups.due(now) {
+				n.sendBatch(ctx, batch, now)
+			}
+		}
+	}
+}
+
+// sendBatch sends the notifications of a group as one notification, and
+// marks them as complete for each of their alert rules. Notifications
+// another node already sends are left out.
+func (n *notificationService) sendBatch(ctx context.Context, batch *notificationBatch, now time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, setting.AlertingNotificationTimeout)
+	defer cancel()
+
+	var notifications []*groupedNotification
+	for _, notification := range batch.notifications {
+		cmd := &models.SetAlertNotificationStateToPendingCommand{
+			Id:                           notification.state.Id,
+			Version:                      notification.state.Version,
+			AlertRuleStateUpdatedVersion: notification.evalCtx.Rule.StateChanges,
+		}
+
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			if err != models.ErrAlertNotificationStateVersionConflict {
+				n.log.Error("Failed to set notification state to pending", "ruleId", notification.evalCtx.Rule.ID, "error", err)
+			}
+			continue
+		}
+
+		notification.state.Version = cmd.ResultVersion
+		notifications = append(notifications, notification)
+	}
+
+	if len(notifications) == 0 {
+		return
+	}
+
+	notifier := batch.notifier
+	n.log.Debug("Sending batched notification", "group", batch.key, "alerts", len(notifications), "uid", notifier.GetNotifierUID())
+	metrics.MAlertingNotificationSent.WithLabelValues(notifier.GetType()).Inc()
+
+	if err := notifier.Notify(newGroupedEvalContext(ctx, batch, notifications, now)); err != nil {
+		n.log.Error("failed to send notification", "uid", notifier.GetNotifierUID(), "group", batch.key, "error", err)
+		metrics.MAlertingNotificationFailed.WithLabelValues(notifier.GetType()).Inc()
+		return
+	}
+
+	for _, notification := range notifications {
+		cmd := &models.SetAlertNotificationStateToCompleteCommand{
+			Id:      notification.state.Id,
+			Version: notification.state.Version,
+		}
+
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			n.log.Error("Failed to set notification state to complete", "ruleId", notification.evalCtx.Rule.ID, "error", err)
+		}
+	}
+}
+
+// newGroupedEvalContext returns the eval context notifiers are called with
+// for a batch. A single alert rule is sent as is. Several alert rules are
+// merged into one alert rule, with the tags they have in common, their
+// eval matches and their messages.
+func newGroupedEvalContext(ctx context.Context, batch *notificationBatch, notifications []*groupedNotification, now time.Time) *EvalContext {
+	if len(notifications) == 1 {
+		evalCtx := *notifications[0].evalCtx
+		evalCtx.Ctx = ctx
+		return &evalCtx
+	}
+
+	rule := &Rule{
+		// notifiers use the id of the rule to deduplicate incidents, so the
+		// group gets a negative id that cannot clash with alert rules.
+		ID:    -int64(hashString(batch.key) >> 1),
+		OrgID: batch.orgID,
+		Name:  fmt.Sprintf("%d alerts", len(notifications)),
+		State: models.AlertStateAlerting,
+	}
+
+	evalCtx := NewEvalContext(ctx, rule)
+	evalCtx.StartTime = now
+	evalCtx.Firing = true
+	evalCtx.PrevAlertState = models.AlertStateOK
+
+	var common map[string]string
+	messages := make([]string, 0, len(notifications))
+	for i, notification := range notifications {
+		grouped := notification.evalCtx
+		tags := make(map[string]string, len(grouped.Rule.AlertRuleTags))
+		for _, tag := range grouped.Rule.AlertRuleTags {
+			tags[tag.Key] = tag.Value
+		}
+
+		if i == 0 {
+			common = tags
+		}
+		for name, value := range common {
+			if tags[name] != value {
+				delete(common, name)
+			}
+		}
+
+		evalCtx.EvalMatches = append(evalCtx.EvalMatches, grouped.EvalMatches...)
+
+		message := grouped.Rule.Name
+		if grouped.Rule.Message != "" {
+			message += ": " + grouped.Rule.Message
+		}
+		messages = append(messages, message)
+
+		if evalCtx.ruleURL == "" {
+			evalCtx.ruleURL, _ = grouped.GetRuleURL()
+		}
+	}
+	rule.Message = strings.Join(messages, "\n")
+
+	for _, name := range sortedLabelNames(common) {
+		rule.AlertRuleTags = append(rule.AlertRuleTags, &models.Tag{Key: name, Value: common[name]})
+	}
+
+	return evalCtx
+}
+
 // InitNotifier instantiate a new notifier based on the model.
 func InitNotifier(model *models.AlertNotification) (Notifier, error) {
 	notifierPlugin, found := notifierFactories[model.Type]
@@ -49,6 +49,127 @@ func TestNotificationService(t *testing.T) {
 		require.Truef(t, evalCtx.Ctx.Value(notificationSent{}).(bool), "expected notification to be sent, but wasn't")
 	})
 
+	silencedEvalCtx := NewEvalContext(context.Background(), testRule)
+	notificationServiceScenario(t, "Given alert rule matched by an active silence should not send notification", silencedEvalCtx, false, func(scenarioCtx *scenarioContext) {
+		scenarioCtx.silences = []*models.AlertSilence{
+			{
+				Id:       1,
+				RuleName: "Test",
+				StartsAt: silencedEvalCtx.StartTime.Add(-time.Hour),
+				EndsAt:   silencedEvalCtx.StartTime.Add(time.Hour),
+			},
+		}
+
+		err := scenarioCtx.notificationService.SendIfNeeded(silencedEvalCtx)
+		require.NoError(t, err)
+
+		require.Equalf(t, 0, scenarioCtx.renderCount, "expected render not to be called, but it was")
+		require.Nilf(t, silencedEvalCtx.Ctx.Value(notificationSent{}), "expected notification not to be sent, but it was")
+		require.Equalf(t, 1, scenarioCtx.pendingCount, "expected notification to be kept pending, but it wasn't")
+	})
+
+	routedEvalCtx := NewEvalContext(context.Background(), &Rule{
+		ID:            2,
+		OrgID:         1,
+		Name:          "Payments",
+		State:         models.AlertStateAlerting,
+		Notifications: []string{"1"},
+		AlertRuleTags: []*models.Tag{{Key: "team", Value: "payments"}},
+	})
+	notificationServiceScenario(t, "Given alert rule matched by a notification policy should send to policy channels", routedEvalCtx, false, func(scenarioCtx *scenarioContext) {
+		scenarioCtx.policy = &models.NotificationPolicy{
+			NotificationUids: []string{"default"},
+			Routes: []*models.NotificationPolicy{
+				{Tags: map[string]string{"team": "payments"}, NotificationUids: []string{"payments-oncall", "1"}},
+				{Tags: map[string]string{"team": "payments"}, NotificationUids: []string{"never"}},
+			},
+		}
+
+		var requestedUids []string
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationsWithUidToSendQuery) error {
+			requestedUids = query.Uids
+			query.Result = []*models.AlertNotification{
+				{Id: 1, Uid: "1", Type: "test", Settings: simplejson.New()},
+				{Id: 2, Uid: "payments-oncall", Type: "test", Settings: simplejson.New()},
+			}
+			return nil
+		})
+
+		states, err := scenarioCtx.notificationService.getNeededNotifiers(1, routedEvalCtx.Rule.Notifications, mustFindRoutes(t, routedEvalCtx), routedEvalCtx)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"1", "payments-oncall"}, requestedUids)
+		require.Len(t, states, 2)
+		require.False(t, states[0].notifier.GetSendReminder(), "expected rule channel to keep its reminder settings")
+		require.False(t, states[1].notifier.GetSendReminder(), "expected policy channel without repeat interval not to send reminders")
+	})
+
+	notificationServiceScenario(t, "Given alert rule matched by a notification policy with repeat interval should send reminders to policy channels", routedEvalCtx, false, func(scenarioCtx *scenarioContext) {
+		scenarioCtx.policy = &models.NotificationPolicy{
+			RepeatInterval: "1h",
+			Routes: []*models.NotificationPolicy{
+				{Tags: map[string]string{"team": "payments"}, NotificationUids: []string{"payments-oncall", "payments-pager"}},
+			},
+		}
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationsWithUidToSendQuery) error {
+			query.Result = []*models.AlertNotification{
+				{Id: 2, Uid: "payments-oncall", Type: "test", Settings: simplejson.New()},
+				{Id: 3, Uid: "payments-pager", Type: "test", Settings: simplejson.New(), SendReminder: true, Frequency: 10 * time.Minute},
+			}
+			return nil
+		})
+
+		states, err := scenarioCtx.notificationService.getNeededNotifiers(1, nil, mustFindRoutes(t, routedEvalCtx), routedEvalCtx)
+		require.NoError(t, err)
+
+		require.Len(t, states, 2)
+		require.True(t, states[0].notifier.GetSendReminder(), "expected policy channel to send reminders")
+		require.Equal(t, time.Hour, states[0].notifier.GetFrequency())
+		require.True(t, states[1].notifier.GetSendReminder())
+		require.Equal(t, 10*time.Minute, states[1].notifier.GetFrequency(), "expected policy channel to keep its own reminder settings")
+	})
+
+	groupedEvalCtx := NewEvalContext(context.Background(), &Rule{
+		ID:            3,
+		OrgID:         1,
+		Name:          "Payments latency",
+		State:         models.AlertStateAlerting,
+		AlertRuleTags: []*models.Tag{{Key: "team", Value: "payments"}},
+	})
+	notificationServiceScenario(t, "Given alert rule matched by a notification policy with group interval should batch notification", groupedEvalCtx, false, func(scenarioCtx *scenarioContext) {
+		scenarioCtx.policy = &models.NotificationPolicy{
+			GroupInterval:    "5m",
+			NotificationUids: []string{"payments-oncall"},
+		}
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationsWithUidToSendQuery) error {
+			query.Result = []*models.AlertNotification{
+				{Id: 2, Uid: "payments-oncall", Type: "test", Settings: simplejson.NewFromAny(map[string]interface{}{"uploadImage": false})},
+			}
+			return nil
+		})
+
+		err := scenarioCtx.notificationService.SendIfNeeded(groupedEvalCtx)
+		require.NoError(t, err)
+
+		require.Nilf(t, groupedEvalCtx.Ctx.Value(notificationSent{}), "expected notification to be batched, but it was sent")
+		batches := scenarioCtx.notificationService.groups.due(groupedEvalCtx.StartTime)
+		require.Len(t, batches, 1)
+		require.Len(t, batches[0].notifications, 1)
+		require.Equal(t, int64(3), batches[0].notifications[0].evalCtx.Rule.ID)
+	})
+
+	resultsEvalCtx := NewEvalContext(context.Background(), testRule)
+	notificationServiceScenario(t, "Given alert rule with notification sent should record notification result", resultsEvalCtx, false, func(scenarioCtx *scenarioContext) {
+		err := scenarioCtx.notificationService.SendIfNeeded(resultsEvalCtx)
+		require.NoError(t, err)
+
+		require.Len(t, resultsEvalCtx.NotificationResults, 1)
+		require.Equal(t, "test", resultsEvalCtx.NotificationResults[0].Type)
+		require.True(t, resultsEvalCtx.NotificationResults[0].Sent)
+	})
+
 	notificationServiceScenario(t, "Given alert rule with upload image disabled should not render and upload image, but send notification", evalCtx, false, func(scenarioCtx *scenarioContext) {
 		err := scenarioCtx.notificationService.SendIfNeeded(evalCtx)
 		require.NoError(t, err)
@@ -117,6 +238,12 @@ func TestNotificationService(t *testing.T) {
 	})
 }
 
+func mustFindRoutes(t *testing.T, evalCtx *EvalContext) []*notificationRoute {
+	routes, err := findNotificationRoutes(evalCtx, &ruleTarget{rule: evalCtx.Rule})
+	require.NoError(t, err)
+	return routes
+}
+
 type scenarioContext struct {
 	evalCtx             *EvalContext
 	notificationService *notificationService
@@ -125,6 +252,9 @@ type scenarioContext struct {
 	uploadProvider      func(ctx context.Context, path string) (string, error)
 	renderProvider      func(ctx context.Context, opts rendering.Opts) (*rendering.RenderResult, error)
 	rendererAvailable   bool
+	silences            []*models.AlertSilence
+	policy              *models.NotificationPolicy
+	pendingCount        int
 }
 
 type scenarioFunc func(c *scenarioContext)
@@ -164,10 +294,6 @@ func notificationServiceScenario(t *testing.T, name string, evalCtx *EvalContext
 			return nil
 		})
 
-		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToPendingCommand) error {
-			return nil
-		})
-
 		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToCompleteCommand) error {
 			return nil
 		})
@@ -178,6 +304,24 @@ func notificationServiceScenario(t *testing.T, name string, evalCtx *EvalContext
 			evalCtx: evalCtx,
 		}
 
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToPendingCommand) error {
+			scenarioCtx.pendingCount++
+			return nil
+		})
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertSilencesQuery) error {
+			query.Result = scenarioCtx.silences
+			return nil
+		})
+
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationPolicyQuery) error {
+			if scenarioCtx.policy == nil {
+				return models.ErrAlertNotificationPolicyNotFound
+			}
+			query.Result = &models.AlertNotificationPolicy{OrgId: 1, Policy: scenarioCtx.policy}
+			return nil
+		})
+
 		uploadProvider := func(ctx context.Context, path string) (string, error) {
 			scenarioCtx.imageUploadCount++
 			return "", nil
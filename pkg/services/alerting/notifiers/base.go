@@ -24,7 +24,8 @@ type NotifierBase struct {
 	DisableResolveMessage bool
 	Frequency             time.Duration
 
-	log log.Logger
+	templates *alerting.NotificationTemplates
+	log       log.Logger
 }
 
 // NewNotifierBase returns a new `NotifierBase`.
@@ -35,6 +36,13 @@ func NewNotifierBase(model *models.AlertNotification) NotifierBase {
 		uploadImage = value.MustBool()
 	}
 
+	logger := log.New("alerting.notifier." + model.Name)
+
+	templates, err := alerting.NewNotificationTemplates(model.Settings)
+	if err != nil {
+		logger.Error("Failed to parse notification templates, using the default layout", "error", err)
+	}
+
 	return NotifierBase{
 		UID:                   model.Uid,
 		Name:                  model.Name,
@@ -44,7 +52,8 @@ func NewNotifierBase(model *models.AlertNotification) NotifierBase {
 		SendReminder:          model.SendReminder,
 		DisableResolveMessage: model.DisableResolveMessage,
 		Frequency:             model.Frequency,
-		log:                   log.New("alerting.notifier." + model.Name),
+		templates:             templates,
+		log:                   logger,
 	}
 }
 
@@ -53,6 +62,22 @@ func (n *NotifierBase) ShouldNotify(ctx context.Context, context *alerting.EvalC
 	prevState := context.PrevAlertState
 	newState := context.Rule.State
 
+	// Notify when the rule keeps alerting but some of its instances changed
+	// state, unless the channel sends reminders and its frequency has not
+	// elapsed since the last notification.
+	if prevState == newState && newState == models.AlertStateAlerting && n.hasInstanceChangesToNotify(context) {
+		if n.SendReminder && n.isLastNotificationRecent(notifierState) {
+			return false
+		}
+		return !n.isPendingNotificationRecent(notifierState)
+	}
+
+	// Notify when the rule keeps alerting but the notification of the change
+	// is still pending, such as when it was held back by a silence.
+	if prevState == newState && newState == models.AlertStateAlerting && notifierState.State == models.AlertNotificationStatePending {
+		return !n.isPendingNotificationRecent(notifierState)
+	}
+
 	// Only notify on state change.
 	if prevState == newState && !n.SendReminder {
 		return false
@@ -60,8 +85,7 @@ func (n *NotifierBase) ShouldNotify(ctx context.Context, context *alerting.EvalC
 
 	if prevState == newState && n.SendReminder {
 		// Do not notify if interval has not elapsed
-		lastNotify := time.Unix(notifierState.UpdatedAt, 0)
-		if notifierState.UpdatedAt != 0 && lastNotify.Add(n.Frequency).After(time.Now()) {
+		if n.isLastNotificationRecent(notifierState) {
 			return false
 		}
 
@@ -94,11 +118,8 @@ func (n *NotifierBase) ShouldNotify(ctx context.Context, context *alerting.EvalC
 	}
 
 	// Do not notify if state pending and it have been updated last minute
-	if notifierState.State == models.AlertNotificationStatePending {
-		lastUpdated := time.Unix(notifierState.UpdatedAt, 0)
-		if lastUpdated.Add(1 * time.Minute).After(time.Now()) {
-			return false
-		}
+	if n.isPendingNotificationRecent(notifierState) {
+		return false
 	}
 
 	// Do not notify when state is OK if DisableResolveMessage is set to true
@@ -109,6 +130,28 @@ func (n *NotifierBase) ShouldNotify(ctx context.Context, context *alerting.EvalC
 	return true
 }
 
+func (n *NotifierBase) isLastNotificationRecent(notifierState *models.AlertNotificationState) bool {
+	lastNotify := time.Unix(notifierState.UpdatedAt, 0)
+	return notifierState.UpdatedAt != 0 && lastNotify.Add(n.Frequency).After(time.Now())
+}
+
+func (n *NotifierBase) isPendingNotificationRecent(notifierState *models.AlertNotificationState) bool {
+	if notifierState.State != models.AlertNotificationStatePending {
+		return false
+	}
+
+	lastUpdated := time.Unix(notifierState.UpdatedAt, 0)
+	return lastUpdated.Add(1 * time.Minute).After(time.Now())
+}
+
+func (n *NotifierBase) hasInstanceChangesToNotify(context *alerting.EvalContext) bool {
+	if len(context.GetNewlyAlertingInstances()) > 0 {
+		return true
+	}
+
+	return len(context.GetResolvedInstances()) > 0 && !n.DisableResolveMessage
+}
+
 // GetType returns the notifier type.
 func (n *NotifierBase) GetType() string {
 	return n.Type
@@ -146,3 +189,27 @@ func (n *NotifierBase) GetDisableResolveMessage() bool {
 func (n *NotifierBase) GetFrequency() time.Duration {
 	return n.Frequency
 }
+
+// GetTitle returns the title of the notification, rendered from the title
+// template of the notification channel if it has one.
+func (n *NotifierBase) GetTitle(evalContext *alerting.EvalContext) string {
+	title, err := n.templates.Title(evalContext)
+	if err != nil {
+		n.log.Error("Failed to render notification title, using the default title", "error", err)
+		return evalContext.GetNotificationTitle()
+	}
+	return title
+}
+
+// GetMessage returns the message of the notification, rendered from the
+// body template of the notification channel if it has one. The changed
+// instances are only part of the message when the body template uses
+// .ChangedInstances; the default message is the alert rule message.
+func (n *NotifierBase) GetMessage(evalContext *alerting.EvalContext) string {
+	message, err := n.templates.Body(evalContext)
+	if err != nil {
+		n.log.Error("Failed to render notification body, using the alert rule message", "error", err)
+		return evalContext.Rule.Message
+	}
+	return message
+}
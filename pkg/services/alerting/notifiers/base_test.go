@@ -23,6 +23,8 @@ func TestShouldSendAlertNotification(t *testing.T) {
 		sendReminder bool
 		frequency    time.Duration
 		state        *models.AlertNotificationState
+		instances    []*alerting.EvalMatch
+		noResolve    bool
 
 		expect bool
 	}{
@@ -164,6 +166,86 @@ func TestShouldSendAlertNotification(t *testing.T) {
 
 			expect: true,
 		},
+		{
+			name:      "alerting -> alerting with a new alerting instance should trigger",
+			prevState: models.AlertStateAlerting,
+			newState:  models.AlertStateAlerting,
+			instances: []*alerting.EvalMatch{{Metric: "host2", PrevState: models.AlertStateOK, State: models.AlertStateAlerting}},
+
+			expect: true,
+		},
+		{
+			name:      "alerting -> alerting with a resolved instance should trigger",
+			prevState: models.AlertStateAlerting,
+			newState:  models.AlertStateAlerting,
+			instances: []*alerting.EvalMatch{{Metric: "host1", PrevState: models.AlertStateAlerting, State: models.AlertStateOK}},
+
+			expect: true,
+		},
+		{
+			name:      "alerting -> alerting with a resolved instance and resolve message disabled should not trigger",
+			prevState: models.AlertStateAlerting,
+			newState:  models.AlertStateAlerting,
+			instances: []*alerting.EvalMatch{{Metric: "host1", PrevState: models.AlertStateAlerting, State: models.AlertStateOK}},
+			noResolve: true,
+
+			expect: false,
+		},
+		{
+			name:      "alerting -> alerting with a new pending instance should not trigger",
+			prevState: models.AlertStateAlerting,
+			newState:  models.AlertStateAlerting,
+			instances: []*alerting.EvalMatch{{Metric: "host2", PrevState: models.AlertStateOK, State: models.AlertStatePending}},
+
+			expect: false,
+		},
+		{
+			name:      "alerting -> alerting with a new alerting instance and notification state pending and updated 30 seconds ago should not trigger",
+			prevState: models.AlertStateAlerting,
+			newState:  models.AlertStateAlerting,
+			instances: []*alerting.EvalMatch{{Metric: "host2", PrevState: models.AlertStateOK, State: models.AlertStateAlerting}},
+			state:     &models.AlertNotificationState{State: models.AlertNotificationStatePending, UpdatedAt: tnow.Add(-30 * time.Second).Unix()},
+
+			expect: false,
+		},
+		{
+			name:         "alerting -> alerting with a new alerting instance and reminder frequency not elapsed should not trigger",
+			prevState:    models.AlertStateAlerting,
+			newState:     models.AlertStateAlerting,
+			instances:    []*alerting.EvalMatch{{Metric: "host2", PrevState: models.AlertStateOK, State: models.AlertStateAlerting}},
+			sendReminder: true,
+			frequency:    time.Minute * 10,
+			state:        &models.AlertNotificationState{State: models.AlertNotificationStateCompleted, UpdatedAt: tnow.Add(-time.Minute).Unix()},
+
+			expect: false,
+		},
+		{
+			name:         "alerting -> alerting with a new alerting instance and reminder frequency elapsed should trigger",
+			prevState:    models.AlertStateAlerting,
+			newState:     models.AlertStateAlerting,
+			instances:    []*alerting.EvalMatch{{Metric: "host2", PrevState: models.AlertStateOK, State: models.AlertStateAlerting}},
+			sendReminder: true,
+			frequency:    time.Minute * 10,
+			state:        &models.AlertNotificationState{State: models.AlertNotificationStateCompleted, UpdatedAt: tnow.Add(-11 * time.Minute).Unix()},
+
+			expect: true,
+		},
+		{
+			name:      "alerting -> alerting with notification state pending and updated 2 minutes ago should trigger",
+			prevState: models.AlertStateAlerting,
+			newState:  models.AlertStateAlerting,
+			state:     &models.AlertNotificationState{State: models.AlertNotificationStatePending, UpdatedAt: tnow.Add(-2 * time.Minute).Unix()},
+
+			expect: true,
+		},
+		{
+			name:      "ok -> ok with notification state pending should not trigger",
+			prevState: models.AlertStateOK,
+			newState:  models.AlertStateOK,
+			state:     &models.AlertNotificationState{State: models.AlertNotificationStatePending, UpdatedAt: tnow.Add(-2 * time.Minute).Unix()},
+
+			expect: false,
+		},
 	}
 
 	for _, tc := range tcs {
@@ -176,7 +258,8 @@ func TestShouldSendAlertNotification(t *testing.T) {
 		}
 
 		evalContext.Rule.State = tc.newState
-		nb := &NotifierBase{SendReminder: tc.sendReminder, Frequency: tc.frequency}
+		evalContext.ChangedInstances = tc.instances
+		nb := &NotifierBase{SendReminder: tc.sendReminder, Frequency: tc.frequency, DisableResolveMessage: tc.noResolve}
 
 		r := nb.ShouldNotify(evalContext.Ctx, evalContext, tc.state)
 		assert.Equal(t, r, tc.expect, "failed test %s. expected %+v to return: %v", tc.name, tc, tc.expect)
@@ -123,9 +123,9 @@ func (dd *DingDingNotifier) genBody(evalContext *alerting.EvalContext, messageUR
 
 	dd.log.Info("messageUrl:" + messageURL)
 
-	message := evalContext.Rule.Message
+	message := dd.GetMessage(evalContext)
 	picURL := evalContext.ImagePublicURL
-	title := evalContext.GetNotificationTitle()
+	title := dd.GetTitle(evalContext)
 	if message == "" {
 		message = title
 	}
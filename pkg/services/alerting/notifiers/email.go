@@ -105,13 +105,13 @@ func (en *EmailNotifier) Notify(evalContext *alerting.EvalContext) error {
 
 	cmd := &models.SendEmailCommandSync{
 		SendEmailCommand: models.SendEmailCommand{
-			Subject: evalContext.GetNotificationTitle(),
+			Subject: en.GetTitle(evalContext),
 			Data: map[string]interface{}{
-				"Title":         evalContext.GetNotificationTitle(),
+				"Title":         en.GetTitle(evalContext),
 				"State":         evalContext.Rule.State,
 				"Name":          evalContext.Rule.Name,
 				"StateModel":    evalContext.GetStateModel(),
-				"Message":       evalContext.Rule.Message,
+				"Message":       en.GetMessage(evalContext),
 				"Error":         error,
 				"RuleUrl":       ruleURL,
 				"ImageLink":     "",
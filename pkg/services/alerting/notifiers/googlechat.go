@@ -137,12 +137,12 @@ func (gcn *GoogleChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 	}
 
 	widgets := []widget{}
-	if len(evalContext.Rule.Message) > 0 {
+	if message := gcn.GetMessage(evalContext); len(message) > 0 {
 		// add a text paragraph widget for the message if there is a message
 		// Google Chat API doesn't accept an empty text property
 		widgets = append(widgets, textParagraphWidget{
 			Text: text{
-				Text: evalContext.Rule.Message,
+				Text: message,
 			},
 		})
 	}
@@ -202,11 +202,11 @@ func (gcn *GoogleChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 
 	// nest the required structs
 	res1D := &outerStruct{
-		FallbackText: evalContext.GetNotificationTitle(),
+		FallbackText: gcn.GetTitle(evalContext),
 		Cards: []card{
 			{
 				Header: header{
-					Title: evalContext.GetNotificationTitle(),
+					Title: gcn.GetTitle(evalContext),
 				},
 				Sections: []section{
 					{
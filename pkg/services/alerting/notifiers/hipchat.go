@@ -141,11 +141,11 @@ func (hc *HipChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 
 	message := ""
 	if evalContext.Rule.State != models.AlertStateOK { //don't add message when going back to alert state ok.
-		message += " " + evalContext.Rule.Message
+		message += " " + hc.GetMessage(evalContext)
 	}
 
 	if message == "" {
-		message = evalContext.GetNotificationTitle() + " in state " + evalContext.GetStateModel().Text
+		message = hc.GetTitle(evalContext) + " in state " + evalContext.GetStateModel().Text
 	}
 
 	//HipChat has a set list of colors
@@ -164,7 +164,7 @@ func (hc *HipChatNotifier) Notify(evalContext *alerting.EvalContext) error {
 		"style":       "application",
 		"url":         ruleURL,
 		"id":          "1",
-		"title":       evalContext.GetNotificationTitle(),
+		"title":       hc.GetTitle(evalContext),
 		"description": message,
 		"icon": map[string]interface{}{
 			"url": "https://grafana.com/assets/img/fav32.png",
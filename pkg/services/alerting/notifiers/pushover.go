@@ -313,7 +313,7 @@ func (pn *PushoverNotifier) Notify(evalContext *alerting.EvalContext) error {
 		return err
 	}
 
-	message := evalContext.Rule.Message
+	message := pn.GetMessage(evalContext)
 	for idx, evt := range evalContext.EvalMatches {
 		message += fmt.Sprintf("\n<b>%s</b>: %v", evt.Metric, evt.Value)
 		if idx > 4 {
@@ -424,7 +424,7 @@ func (pn *PushoverNotifier) genPushoverBody(evalContext *alerting.EvalContext, m
 	}
 
 	// Add title
-	err = w.WriteField("title", evalContext.GetNotificationTitle())
+	err = w.WriteField("title", pn.GetTitle(evalContext))
 	if err != nil {
 		return nil, b, err
 	}
@@ -357,7 +357,7 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 	}
 	msg := ""
 	if evalContext.Rule.State != models.AlertStateOK { //don't add message when going back to alert state ok.
-		msg = evalContext.Rule.Message
+		msg = sn.GetMessage(evalContext)
 	}
 	imageURL := ""
 	// default to file.upload API method if a token is provided
@@ -379,10 +379,10 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 	}
 	attachment := map[string]interface{}{
 		"color":       evalContext.GetStateModel().Color,
-		"title":       evalContext.GetNotificationTitle(),
+		"title":       sn.GetTitle(evalContext),
 		"title_link":  ruleURL,
 		"text":        msg,
-		"fallback":    evalContext.GetNotificationTitle(),
+		"fallback":    sn.GetTitle(evalContext),
 		"fields":      fields,
 		"footer":      "Grafana v" + setting.BuildVersion,
 		"footer_icon": "https://grafana.com/assets/img/fav32.png",
@@ -392,7 +392,7 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 		attachment["image_url"] = imageURL
 	}
 	body := map[string]interface{}{
-		"text": evalContext.GetNotificationTitle(),
+		"text": sn.GetTitle(evalContext),
 		"attachments": []map[string]interface{}{
 			attachment,
 		},
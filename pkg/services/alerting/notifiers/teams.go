@@ -88,7 +88,7 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 
 	message := ""
 	if evalContext.Rule.State != models.AlertStateOK { //don't add message when going back to alert state ok.
-		message = evalContext.Rule.Message
+		message = tn.GetMessage(evalContext)
 	}
 
 	images := make([]map[string]interface{}, 0)
@@ -103,8 +103,8 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 		"@context": "http://schema.org/extensions",
 		// summary MUST not be empty or the webhook request fails
 		// summary SHOULD contain some meaningful information, since it is used for mobile notifications
-		"summary":    evalContext.GetNotificationTitle(),
-		"title":      evalContext.GetNotificationTitle(),
+		"summary":    tn.GetTitle(evalContext),
+		"title":      tn.GetTitle(evalContext),
 		"themeColor": evalContext.GetStateModel().Color,
 		"sections": []map[string]interface{}{
 			{
@@ -121,7 +121,7 @@ func (tn *TelegramNotifier) buildMessage(evalContext *alerting.EvalContext, send
 }
 
 func (tn *TelegramNotifier) buildMessageLinkedImage(evalContext *alerting.EvalContext) (*models.SendWebhookSync, error) {
-	message := fmt.Sprintf("<b>%s</b>\nState: %s\nMessage: %s\n", evalContext.GetNotificationTitle(), evalContext.Rule.Name, evalContext.Rule.Message)
+	message := fmt.Sprintf("<b>%s</b>\nState: %s\nMessage: %s\n", tn.GetTitle(evalContext), evalContext.Rule.Name, tn.GetMessage(evalContext))
 
 	ruleURL, err := evalContext.GetRuleURL()
 	if err == nil {
@@ -172,7 +172,7 @@ func (tn *TelegramNotifier) buildMessageInlineImage(evalContext *alerting.EvalCo
 	}
 
 	metrics := generateMetricsMessage(evalContext)
-	message := generateImageCaption(evalContext, ruleURL, metrics)
+	message := tn.generateImageCaption(evalContext, ruleURL, metrics)
 
 	return tn.generateTelegramCmd(message, "caption", "sendPhoto", func(w *multipart.Writer) {
 		fw, err := w.CreateFormFile("photo", evalContext.ImageOnDiskPath)
@@ -237,11 +237,11 @@ func generateMetricsMessage(evalContext *alerting.EvalContext) string {
 	return metrics
 }
 
-func generateImageCaption(evalContext *alerting.EvalContext, ruleURL string, metrics string) string {
-	message := evalContext.GetNotificationTitle()
+func (tn *TelegramNotifier) generateImageCaption(evalContext *alerting.EvalContext, ruleURL string, metrics string) string {
+	message := tn.GetTitle(evalContext)
 
-	if len(evalContext.Rule.Message) > 0 {
-		message = fmt.Sprintf("%s\nMessage: %s", message, evalContext.Rule.Message)
+	if ruleMessage := tn.GetMessage(evalContext); len(ruleMessage) > 0 {
+		message = fmt.Sprintf("%s\nMessage: %s", message, ruleMessage)
 	}
 
 	if len(message) > captionLengthLimit {
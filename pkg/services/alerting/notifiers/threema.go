@@ -173,8 +173,8 @@ func (notifier *ThreemaNotifier) Notify(evalContext *alerting.EvalContext) error
 
 	// Build message
 	message := fmt.Sprintf("%s%s\n\n*State:* %s\n*Message:* %s\n",
-		stateEmoji, evalContext.GetNotificationTitle(),
-		evalContext.Rule.Name, evalContext.Rule.Message)
+		stateEmoji, notifier.GetTitle(evalContext),
+		evalContext.Rule.Name, notifier.GetMessage(evalContext))
 	ruleURL, err := evalContext.GetRuleURL()
 	if err == nil {
 		message += fmt.Sprintf("*URL:* %s\n", ruleURL)
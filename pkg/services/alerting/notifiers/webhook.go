@@ -124,11 +124,14 @@ func (wn *WebhookNotifier) Notify(evalContext *alerting.EvalContext) error {
 	wn.log.Info("Sending webhook")
 
 	bodyJSON := simplejson.New()
-	bodyJSON.Set("title", evalContext.GetNotificationTitle())
+	bodyJSON.Set("title", wn.GetTitle(evalContext))
 	bodyJSON.Set("ruleId", evalContext.Rule.ID)
 	bodyJSON.Set("ruleName", evalContext.Rule.Name)
 	bodyJSON.Set("state", evalContext.Rule.State)
 	bodyJSON.Set("evalMatches", evalContext.EvalMatches)
+	if len(evalContext.ChangedInstances) > 0 {
+		bodyJSON.Set("changedInstances", evalContext.ChangedInstances)
+	}
 	bodyJSON.Set("orgId", evalContext.Rule.OrgID)
 	bodyJSON.Set("dashboardId", evalContext.Rule.DashboardID)
 	bodyJSON.Set("panelId", evalContext.Rule.PanelID)
@@ -150,8 +153,8 @@ func (wn *WebhookNotifier) Notify(evalContext *alerting.EvalContext) error {
 		bodyJSON.Set("imageUrl", evalContext.ImagePublicURL)
 	}
 
-	if evalContext.Rule.Message != "" {
-		bodyJSON.Set("message", evalContext.Rule.Message)
+	if message := wn.GetMessage(evalContext); message != "" {
+		bodyJSON.Set("message", message)
 	}
 
 	body, _ := bodyJSON.MarshalJSON()
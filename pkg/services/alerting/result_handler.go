@@ -35,6 +35,10 @@ func (handler *defaultResultHandler) handle(evalContext *EvalContext) error {
 	executionError := ""
 	annotationData := simplejson.New()
 
+	if err := handler.updateInstanceStates(evalContext); err != nil {
+		handler.log.Error("Failed to update alert instance states", "ruleId", evalContext.Rule.ID, "error", err)
+	}
+
 	if len(evalContext.EvalMatches) > 0 {
 		annotationData.Set("evalMatches", simplejson.NewFromAny(evalContext.EvalMatches))
 	}
@@ -46,6 +50,8 @@ func (handler *defaultResultHandler) handle(evalContext *EvalContext) error {
 		annotationData.Set("noData", true)
 	}
 
+	var history *models.AlertStateHistory
+
 	metrics.MAlertingResultState.WithLabelValues(string(evalContext.Rule.State)).Inc()
 	if evalContext.shouldUpdateAlertState() {
 		handler.log.Info("New state change", "ruleId", evalContext.Rule.ID, "newState", evalContext.Rule.State, "prev state", evalContext.PrevAlertState)
@@ -97,6 +103,11 @@ func (handler *defaultResultHandler) handle(evalContext *EvalContext) error {
 		if err := annotationRepo.Save(&item); err != nil {
 			handler.log.Error("Failed to save annotation for new alert state", "error", err)
 		}
+
+		history = newAlertStateHistory(evalContext, executionError, item.Epoch)
+	} else if len(evalContext.ChangedInstances) > 0 {
+		// instances can change state while the alert rule keeps its state
+		history = newAlertStateHistory(evalContext, executionError, time.Now().UnixNano()/int64(time.Millisecond))
 	}
 
 	if err := handler.notifier.SendIfNeeded(evalContext); err != nil {
@@ -110,5 +121,70 @@ func (handler *defaultResultHandler) handle(evalContext *EvalContext) error {
 		}
 	}
 
+	// the history is saved after sending notifications to record who was notified
+	if history != nil {
+		history.Notifications = evalContext.NotificationResults
+		if err := bus.Dispatch(&models.SaveAlertStateHistoryCommand{History: history}); err != nil {
+			handler.log.Error("Failed to save alert state history", "ruleId", evalContext.Rule.ID, "error", err)
+		}
+	}
+
 	return nil
 }
+
+// newAlertStateHistory returns the state history entry of the evaluation,
+// with the alert instances that changed state in it.
+func newAlertStateHistory(evalContext *EvalContext, executionError string, epoch int64) *models.AlertStateHistory {
+	history := &models.AlertStateHistory{
+		OrgId:       evalContext.Rule.OrgID,
+		AlertId:     evalContext.Rule.ID,
+		DashboardId: evalContext.Rule.DashboardID,
+		PanelId:     evalContext.Rule.PanelID,
+		RuleName:    evalContext.Rule.Name,
+		PrevState:   evalContext.PrevAlertState,
+		NewState:    evalContext.Rule.State,
+		EvalMatches: simplejson.NewFromAny(evalContext.EvalMatches),
+		Error:       executionError,
+		Epoch:       epoch,
+	}
+
+	if len(evalContext.ChangedInstances) > 0 {
+		history.ChangedInstances = simplejson.NewFromAny(evalContext.ChangedInstances)
+	}
+
+	return history
+}
+
+// updateInstanceStates tracks the state of every series matched by the
+// alert rule, so that notifiers know which instances changed state.
+func (handler *defaultResultHandler) updateInstanceStates(evalContext *EvalContext) error {
+	// keep the instances as they are when the rule could not be evaluated
+	if evalContext.Error != nil || (evalContext.NoDataFound && !evalContext.Firing) {
+		return nil
+	}
+
+	query := &models.GetAlertInstancesQuery{
+		OrgId:   evalContext.Rule.OrgID,
+		AlertId: evalContext.Rule.ID,
+	}
+	if err := bus.Dispatch(query); err != nil {
+		return err
+	}
+
+	instances := evalContext.evalInstanceStates(query.Result, time.Now())
+	if len(evalContext.ChangedInstances) == 0 {
+		return nil
+	}
+
+	for _, m := range evalContext.ChangedInstances {
+		handler.log.Info("New instance state change", "ruleId", evalContext.Rule.ID, "metric", m.Metric, "tags", m.Tags, "newState", m.State, "prev state", m.PrevState)
+	}
+
+	cmd := &models.SaveAlertInstancesCommand{
+		OrgId:     evalContext.Rule.OrgID,
+		AlertId:   evalContext.Rule.ID,
+		Instances: instances,
+	}
+
+	return bus.Dispatch(cmd)
+}
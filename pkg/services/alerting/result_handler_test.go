@@ -0,0 +1,48 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestNewAlertStateHistory(t *testing.T) {
+	evalCtx := NewEvalContext(context.Background(), &Rule{ID: 1, OrgID: 1, Name: "High CPU", State: models.AlertStateAlerting})
+	evalCtx.PrevAlertState = models.AlertStateAlerting
+	evalCtx.EvalMatches = []*EvalMatch{
+		{Metric: "server1", State: models.AlertStateAlerting, PrevState: models.AlertStateAlerting},
+		{Metric: "server2", State: models.AlertStateAlerting, PrevState: models.AlertStateOK},
+	}
+
+	t.Run("without changed instances", func(t *testing.T) {
+		history := newAlertStateHistory(evalCtx, "", 1000)
+		assert.Equal(t, models.AlertStateAlerting, history.NewState)
+		assert.Len(t, decodeJSON(t, history.EvalMatches).MustArray(), 2)
+		assert.Nil(t, history.ChangedInstances)
+	})
+
+	t.Run("with changed instances", func(t *testing.T) {
+		evalCtx.ChangedInstances = evalCtx.EvalMatches[1:]
+		history := newAlertStateHistory(evalCtx, "", 1000)
+		require.NotNil(t, history.ChangedInstances)
+		changed := decodeJSON(t, history.ChangedInstances)
+		require.Len(t, changed.MustArray(), 1)
+		assert.Equal(t, "server2", changed.GetIndex(0).Get("metric").MustString())
+		assert.Equal(t, "ok", changed.GetIndex(0).Get("prevState").MustString())
+		assert.Equal(t, int64(1000), history.Epoch)
+	})
+}
+
+// decodeJSON returns the json as it is read back from the database.
+func decodeJSON(t *testing.T, value *simplejson.Json) *simplejson.Json {
+	data, err := value.Encode()
+	require.NoError(t, err)
+	decoded, err := simplejson.NewJson(data)
+	require.NoError(t, err)
+	return decoded
+}
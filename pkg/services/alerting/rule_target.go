@@ -0,0 +1,61 @@
+package alerting
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// ruleTarget matches silences and notification policies against an alert
+// rule, looking up the dashboard and folder of the rule once and only when
+// a matcher needs them.
+type ruleTarget struct {
+	rule      *Rule
+	dashboard *models.Dashboard
+	folderUID string
+}
+
+func (t *ruleTarget) hasTag(key, value string) bool {
+	for _, tag := range t.rule.AlertRuleTags {
+		if tag.Key == key && tag.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *ruleTarget) loadDashboard(evalCtx *EvalContext) error {
+	if t.dashboard != nil {
+		return nil
+	}
+
+	// external alerts are not defined on a dashboard
+	if t.rule.DashboardID == 0 {
+		t.dashboard = &models.Dashboard{}
+		return nil
+	}
+
+	query := &models.GetDashboardQuery{Id: t.rule.DashboardID, OrgId: t.rule.OrgID}
+	if err := bus.DispatchCtx(evalCtx.Ctx, query); err != nil {
+		return err
+	}
+	t.dashboard = query.Result
+
+	if t.dashboard.FolderId > 0 {
+		folderQuery := &models.GetDashboardQuery{Id: t.dashboard.FolderId, OrgId: t.rule.OrgID}
+		if err := bus.DispatchCtx(evalCtx.Ctx, folderQuery); err != nil {
+			return err
+		}
+		t.folderUID = folderQuery.Result.Uid
+	}
+
+	return nil
+}
+
+func (t *ruleTarget) tagValue(key string) string {
+	for _, tag := range t.rule.AlertRuleTags {
+		if tag.Key == key {
+			return tag.Value
+		}
+	}
+	return ""
+}
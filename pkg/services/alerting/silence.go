@@ -0,0 +1,103 @@
+package alerting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/robfig/cron/v3"
+)
+
+// findActiveSilence returns the first silence that is active for the alert
+// rule of the eval context, or nil if its notifications should be sent.
+func findActiveSilence(evalCtx *EvalContext) (*models.AlertSilence, error) {
+	now := evalCtx.StartTime
+
+	query := &models.GetAlertSilencesQuery{OrgId: evalCtx.Rule.OrgID, ActiveAt: now}
+	if err := bus.DispatchCtx(evalCtx.Ctx, query); err != nil {
+		return nil, err
+	}
+
+	return findMatchingSilence(evalCtx, query.Result)
+}
+
+// findMatchingSilence returns the first of the silences of the organization
+// that is active for the alert rule of the eval context, or nil.
+func findMatchingSilence(evalCtx *EvalContext, silences []*models.AlertSilence) (*models.AlertSilence, error) {
+	now := evalCtx.StartTime
+	target := &ruleTarget{rule: evalCtx.Rule}
+	for _, silence := range silences {
+		if !isSilenceActive(silence, now) {
+			continue
+		}
+
+		matches, err := target.matchesSilence(evalCtx, silence)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			return silence, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// isSilenceActive returns true if the silence mutes notifications at the
+// given time. Silences with a cron schedule are only active for their
+// repeat duration after each time the schedule triggers.
+func isSilenceActive(silence *models.AlertSilence, now time.Time) bool {
+	if silence.StartsAt.After(now) || !silence.EndsAt.After(now) {
+		return false
+	}
+
+	if silence.Cron == "" {
+		return true
+	}
+
+	schedule, err := cron.ParseStandard(silence.Cron)
+	if err != nil {
+		return false
+	}
+
+	// the schedule triggered within the repeat duration if its next
+	// trigger after the start of that window is not in the future.
+	return !schedule.Next(now.Add(-silence.RepeatDuration)).After(now)
+}
+
+func (t *ruleTarget) matchesSilence(evalCtx *EvalContext, silence *models.AlertSilence) (bool, error) {
+	// silences without matchers are rejected when saved, so one stored
+	// before then does not mute the whole org
+	if !silence.HasMatchers() {
+		return false, nil
+	}
+
+	if silence.RuleName != "" && silence.RuleName != t.rule.Name {
+		return false, nil
+	}
+
+	for key, value := range silence.Tags {
+		if !t.hasTag(key, value) {
+			return false, nil
+		}
+	}
+
+	if silence.DashboardUid == "" && silence.FolderUid == "" {
+		return true, nil
+	}
+
+	if err := t.loadDashboard(evalCtx); err != nil {
+		return false, err
+	}
+
+	if silence.DashboardUid != "" && silence.DashboardUid != t.dashboard.Uid {
+		return false, nil
+	}
+
+	if silence.FolderUid != "" && silence.FolderUid != t.folderUID {
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,125 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlertSilences(t *testing.T) {
+	Convey("Alert silences", t, func() {
+		now := time.Date(2020, 6, 10, 2, 30, 0, 0, time.UTC)
+
+		Convey("Silence without schedule is active within its period", func() {
+			silence := &models.AlertSilence{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+			So(isSilenceActive(silence, now), ShouldBeTrue)
+			So(isSilenceActive(silence, now.Add(-2*time.Hour)), ShouldBeFalse)
+			So(isSilenceActive(silence, now.Add(time.Hour)), ShouldBeFalse)
+		})
+
+		Convey("Silence with schedule is active for the repeat duration after the schedule triggers", func() {
+			silence := &models.AlertSilence{
+				StartsAt:       now.AddDate(0, -1, 0),
+				EndsAt:         now.AddDate(0, 1, 0),
+				Cron:           "0 2 * * *",
+				RepeatDuration: time.Hour,
+			}
+
+			So(isSilenceActive(silence, now), ShouldBeTrue)
+			So(isSilenceActive(silence, now.Add(-30*time.Minute)), ShouldBeTrue)
+			So(isSilenceActive(silence, now.Add(-31*time.Minute)), ShouldBeFalse)
+			So(isSilenceActive(silence, now.Add(30*time.Minute)), ShouldBeFalse)
+			So(isSilenceActive(silence, now.Add(24*time.Hour)), ShouldBeTrue)
+			So(isSilenceActive(silence, now.AddDate(0, 2, 0)), ShouldBeFalse)
+		})
+
+		Convey("Given an alert rule", func() {
+			rule := &Rule{
+				ID:            1,
+				OrgID:         1,
+				DashboardID:   2,
+				Name:          "High CPU",
+				AlertRuleTags: []*models.Tag{{Key: "team", Value: "backend"}},
+			}
+			evalCtx := NewEvalContext(context.Background(), rule)
+			evalCtx.StartTime = now
+
+			var silences []*models.AlertSilence
+			bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertSilencesQuery) error {
+				query.Result = silences
+				return nil
+			})
+
+			bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetDashboardQuery) error {
+				switch query.Id {
+				case 2:
+					query.Result = &models.Dashboard{Id: 2, Uid: "dash", FolderId: 3}
+				case 3:
+					query.Result = &models.Dashboard{Id: 3, Uid: "folder", IsFolder: true}
+				default:
+					return models.ErrDashboardNotFound
+				}
+				return nil
+			})
+
+			active := func(s *models.AlertSilence) *models.AlertSilence {
+				s.StartsAt = now.Add(-time.Hour)
+				s.EndsAt = now.Add(time.Hour)
+				return s
+			}
+
+			Convey("Should match on rule name, tags, dashboard and folder", func() {
+				silences = []*models.AlertSilence{active(&models.AlertSilence{
+					Id:           1,
+					RuleName:     "High CPU",
+					DashboardUid: "dash",
+					FolderUid:    "folder",
+					Tags:         map[string]string{"team": "backend"},
+				})}
+
+				silence, err := findActiveSilence(evalCtx)
+				So(err, ShouldBeNil)
+				So(silence, ShouldNotBeNil)
+				So(silence.Id, ShouldEqual, 1)
+			})
+
+			Convey("Should not match when a matcher differs", func() {
+				silences = []*models.AlertSilence{
+					active(&models.AlertSilence{Id: 1, RuleName: "Other"}),
+					active(&models.AlertSilence{Id: 2, Tags: map[string]string{"team": "frontend"}}),
+					active(&models.AlertSilence{Id: 3, DashboardUid: "other"}),
+					active(&models.AlertSilence{Id: 4, FolderUid: "other"}),
+				}
+
+				silence, err := findActiveSilence(evalCtx)
+				So(err, ShouldBeNil)
+				So(silence, ShouldBeNil)
+			})
+
+			Convey("Should not match silences without matchers", func() {
+				silences = []*models.AlertSilence{active(&models.AlertSilence{Id: 1})}
+
+				silence, err := findActiveSilence(evalCtx)
+				So(err, ShouldBeNil)
+				So(silence, ShouldBeNil)
+			})
+
+			Convey("Should not match silences outside their schedule", func() {
+				silences = []*models.AlertSilence{active(&models.AlertSilence{
+					Id:             1,
+					RuleName:       "High CPU",
+					Cron:           "0 4 * * *",
+					RepeatDuration: time.Hour,
+				})}
+
+				silence, err := findActiveSilence(evalCtx)
+				So(err, ShouldBeNil)
+				So(silence, ShouldBeNil)
+			})
+		})
+	})
+}
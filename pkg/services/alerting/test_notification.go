@@ -23,6 +23,14 @@ type NotificationTestCommand struct {
 	Type           string
 	Settings       *simplejson.Json
 	SecureSettings map[string]string
+
+	Result *NotificationPreview
+}
+
+// NotificationPreview is the title and body rendered for a test notification.
+type NotificationPreview struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
 }
 
 var (
@@ -71,7 +79,33 @@ func handleNotificationTestCommand(cmd *NotificationTestCommand) error {
 		return err
 	}
 
-	return notifier.sendNotifications(createTestEvalContext(cmd), notifierStateSlice{{notifier: notifiers}})
+	evalContext := createTestEvalContext(cmd)
+	preview, err := renderNotificationPreview(cmd.Settings, evalContext)
+	if err != nil {
+		return err
+	}
+	cmd.Result = preview
+
+	return notifier.sendNotifications(evalContext, notifierStateSlice{{notifier: notifiers}})
+}
+
+func renderNotificationPreview(settings *simplejson.Json, evalContext *EvalContext) (*NotificationPreview, error) {
+	templates, err := NewNotificationTemplates(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	title, err := templates.Title(evalContext)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := templates.Body(evalContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationPreview{Title: title, Body: body}, nil
 }
 
 func createTestEvalContext(cmd *NotificationTestCommand) *EvalContext {
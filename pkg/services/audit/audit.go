@@ -0,0 +1,233 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+func init() {
+	registry.RegisterService(&AuditService{})
+}
+
+// AuditService records in the audit log who changed data sources, permissions,
+// API keys, users, teams and organizations through the HTTP API.
+//
+// It hooks into the dispatch of the commands making these changes. Only
+// commands dispatched with the context of an HTTP request are recorded, which
+// leaves out the changes made by provisioning and by the server itself.
+type AuditService struct {
+	Cfg *setting.Cfg `inject:""`
+
+	log  log.Logger
+	sink *fileSink
+}
+
+// IsDisabled returns true if the audit log is disabled.
+func (s *AuditService) IsDisabled() bool {
+	return !s.Cfg.Audit.Enabled
+}
+
+func (s *AuditService) Init() error {
+	s.log = log.New("audit")
+
+	if path := s.Cfg.Audit.FilePath; path != "" {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.Cfg.LogsPath, path)
+		}
+
+		sink, err := newFileSink(path)
+		if err != nil {
+			return errutil.Wrap("failed to open audit log file", err)
+		}
+		s.sink = sink
+	}
+
+	bus.AddDispatchHook(s.onDispatch)
+	bus.AddEventListener(s.onSignUpCompleted)
+	return nil
+}
+
+// Run closes the audit log file when the server stops.
+func (s *AuditService) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	if s.sink != nil {
+		if err := s.sink.close(); err != nil {
+			s.log.Error("Failed to close audit log file", "error", err)
+		}
+	}
+
+	return ctx.Err()
+}
+
+func (s *AuditService) onDispatch(ctx context.Context, msg bus.Msg) func(err error) {
+	req := models.AuditRequestFromContext(ctx)
+	if req == nil || req.User == nil {
+		return nil
+	}
+
+	c := describe(msg, req.User)
+	if c == nil {
+		return nil
+	}
+
+	// the resource is loaded before the handler changes it
+	var before interface{}
+	if c.current != nil {
+		var err error
+		if before, err = c.current(); err != nil {
+			s.log.Warn("Failed to load resource before change", "resourceType", c.resourceType, "error", err)
+			before = nil
+		}
+	}
+
+	return func(err error) {
+		if err != nil {
+			return
+		}
+
+		resourceType := c.resourceType
+		if c.resourceTypeOf != nil {
+			resourceType = c.resourceTypeOf(before)
+		}
+
+		entry := &models.AuditEntry{
+			OrgId:        c.orgId,
+			ActorId:      req.User.UserId,
+			ActorLogin:   actorLogin(req.User),
+			IpAddress:    req.IpAddress,
+			UserAgent:    truncate(req.UserAgent, 255),
+			Action:       c.action,
+			ResourceType: resourceType,
+			ResourceId:   c.resourceId(before),
+			BeforeJson:   s.toJSON(before),
+			Created:      time.Now(),
+		}
+		switch {
+		case c.after != nil:
+			entry.AfterJson = s.toJSON(c.after)
+		case !c.omitAfter:
+			entry.AfterJson = s.toJSON(msg)
+		}
+
+		s.record(entry)
+	}
+}
+
+// onSignUpCompleted records the users who signed up themselves, which they do
+// without being signed in.
+func (s *AuditService) onSignUpCompleted(evt *events.SignUpCompleted) error {
+	s.record(&models.AuditEntry{
+		ActorLogin:   evt.Email,
+		Action:       "signup",
+		ResourceType: "user",
+		ResourceId:   evt.Email,
+		AfterJson:    s.toJSON(evt),
+		Created:      evt.Timestamp,
+	})
+	return nil
+}
+
+func (s *AuditService) record(entry *models.AuditEntry) {
+	if err := bus.Dispatch(&models.AddAuditEntryCommand{Entry: entry}); err != nil {
+		s.log.Error("Failed to save audit entry", "action", entry.Action, "resourceType", entry.ResourceType, "resourceId", entry.ResourceId, "error", err)
+	}
+
+	if s.sink != nil {
+		if err := s.sink.write(entry.ToDTO()); err != nil {
+			s.log.Error("Failed to write audit entry to file", "error", err)
+		}
+	}
+}
+
+func (s *AuditService) toJSON(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	out, err := redactedJSON(v)
+	if err != nil {
+		s.log.Warn("Failed to encode audited resource", "error", err)
+		return ""
+	}
+	if out == "null" {
+		return ""
+	}
+	return out
+}
+
+// secretKeys are the fields left out of the audit log, in lower case.
+// Result fields of commands are left out as well, as they duplicate the
+// resource.
+var secretKeys = map[string]bool{
+	"password":          true,
+	"newpassword":       true,
+	"oldpassword":       true,
+	"basicauthpassword": true,
+	"securejsondata":    true,
+	"securesettings":    true,
+	"key":               true,
+	"salt":              true,
+	"rands":             true,
+	"result":            true,
+}
+
+// redactedJSON encodes v to JSON without the fields holding secrets.
+func redactedJSON(v interface{}) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return "", err
+	}
+
+	out, err := json.Marshal(redact(decoded))
+	return string(out), err
+}
+
+func redact(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if secretKeys[strings.ToLower(k)] {
+				delete(value, k)
+				continue
+			}
+			value[k] = redact(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redact(item)
+		}
+	}
+	return v
+}
+
+func actorLogin(user *models.SignedInUser) string {
+	if user.Login == "" && user.ApiKeyId != 0 {
+		return fmt.Sprintf("api-key:%d", user.ApiKeyId)
+	}
+	return user.Login
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
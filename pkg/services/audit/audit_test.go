@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactedJSON(t *testing.T) {
+	cmd := models.UpdateDataSourceCommand{
+		Id:                1,
+		Name:              "prod",
+		Password:          "secret",
+		BasicAuthPassword: "secret",
+		SecureJsonData:    map[string]string{"token": "secret"},
+	}
+
+	out, err := redactedJSON(cmd)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "secret")
+	assert.Contains(t, out, `"name":"prod"`)
+}
+
+func TestAuditService(t *testing.T) {
+	bus.ClearBusHandlers()
+	defer bus.ClearBusHandlers()
+
+	var entries []*models.AuditEntry
+	bus.AddHandler("test", func(cmd *models.AddAuditEntryCommand) error {
+		entries = append(entries, cmd.Entry)
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetDataSourceByIdQuery) error {
+		query.Result = &models.DataSource{Id: query.Id, OrgId: query.OrgId, Name: "old", Password: "secret"}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.UpdateDataSourceCommand) error {
+		return nil
+	})
+
+	bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+		query.Result = &models.Dashboard{Id: query.Id, OrgId: query.OrgId, Title: "old", IsFolder: query.Id == 5}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.SaveDashboardCommand) error {
+		cmd.Result = cmd.GetDashboardModel()
+		if cmd.Result.Id == 0 {
+			cmd.Result.Id = 6
+		}
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.DeleteDashboardCommand) error {
+		return nil
+	})
+
+	s := &AuditService{log: log.New("audit.test")}
+	bus.AddDispatchHook(s.onDispatch)
+
+	user := &models.SignedInUser{UserId: 2, OrgId: 1, Login: "admin"}
+	ctx := models.WithAuditRequest(context.Background(), &models.AuditRequest{User: user, IpAddress: "10.0.0.1", UserAgent: "curl"})
+
+	t.Run("Should record commands dispatched with a request", func(t *testing.T) {
+		entries = nil
+		cmd := models.UpdateDataSourceCommand{Id: 3, OrgId: 1, Name: "new", Password: "changed"}
+		require.NoError(t, bus.DispatchCtx(ctx, &cmd))
+
+		require.Len(t, entries, 1)
+		entry := entries[0]
+		assert.Equal(t, "update", entry.Action)
+		assert.Equal(t, "datasource", entry.ResourceType)
+		assert.Equal(t, "3", entry.ResourceId)
+		assert.Equal(t, int64(1), entry.OrgId)
+		assert.Equal(t, int64(2), entry.ActorId)
+		assert.Equal(t, "admin", entry.ActorLogin)
+		assert.Equal(t, "10.0.0.1", entry.IpAddress)
+		assert.Contains(t, entry.BeforeJson, `"Name":"old"`)
+		assert.Contains(t, entry.AfterJson, `"name":"new"`)
+		assert.NotContains(t, entry.BeforeJson, "secret")
+		assert.NotContains(t, entry.AfterJson, "changed")
+	})
+
+	t.Run("Should record created dashboards", func(t *testing.T) {
+		entries = nil
+		cmd := models.SaveDashboardCommand{OrgId: 1, Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "new"})}
+		require.NoError(t, bus.DispatchCtx(ctx, &cmd))
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, "create", entries[0].Action)
+		assert.Equal(t, "dashboard", entries[0].ResourceType)
+		assert.Equal(t, "6", entries[0].ResourceId)
+		assert.Empty(t, entries[0].BeforeJson)
+		assert.Contains(t, entries[0].AfterJson, `"title":"new"`)
+	})
+
+	t.Run("Should record updated folders", func(t *testing.T) {
+		entries = nil
+		cmd := models.SaveDashboardCommand{OrgId: 1, IsFolder: true, Dashboard: simplejson.NewFromAny(map[string]interface{}{"id": 5, "title": "new"})}
+		require.NoError(t, bus.DispatchCtx(ctx, &cmd))
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, "update", entries[0].Action)
+		assert.Equal(t, "folder", entries[0].ResourceType)
+		assert.Equal(t, "5", entries[0].ResourceId)
+		assert.Contains(t, entries[0].BeforeJson, `"Title":"old"`)
+	})
+
+	t.Run("Should record deleted dashboards and folders", func(t *testing.T) {
+		entries = nil
+		require.NoError(t, bus.DispatchCtx(ctx, &models.DeleteDashboardCommand{Id: 4, OrgId: 1}))
+		require.NoError(t, bus.DispatchCtx(ctx, &models.DeleteDashboardCommand{Id: 5, OrgId: 1}))
+
+		require.Len(t, entries, 2)
+		assert.Equal(t, "delete", entries[0].Action)
+		assert.Equal(t, "dashboard", entries[0].ResourceType)
+		assert.Equal(t, "4", entries[0].ResourceId)
+		assert.Equal(t, "folder", entries[1].ResourceType)
+		assert.Equal(t, "5", entries[1].ResourceId)
+		assert.Empty(t, entries[1].AfterJson)
+	})
+
+	t.Run("Should not record commands dispatched without a request", func(t *testing.T) {
+		entries = nil
+		require.NoError(t, bus.Dispatch(&models.UpdateDataSourceCommand{Id: 3, OrgId: 1}))
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Should not record queries", func(t *testing.T) {
+		entries = nil
+		require.NoError(t, bus.DispatchCtx(ctx, &models.GetDataSourceByIdQuery{Id: 3, OrgId: 1}))
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,362 @@
+package audit
+
+import (
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// change describes how an audited command changes a resource.
+type change struct {
+	action       string
+	resourceType string
+	orgId        int64
+
+	// resourceTypeOf replaces resourceType for the commands shared by
+	// different resources, with the resource loaded by current.
+	resourceTypeOf func(before interface{}) string
+
+	// resourceId is called after the command is handled, so it can read the
+	// id of created resources from the command result, with the resource
+	// loaded by current.
+	resourceId func(before interface{}) string
+
+	// current loads the resource before the command changes it.
+	current func() (interface{}, error)
+
+	// after replaces the command in the entry, for the commands which hide
+	// fields the entry needs from JSON.
+	after interface{}
+
+	// omitAfter leaves the command out of the entry, for the commands which
+	// only identify the resource.
+	omitAfter bool
+}
+
+// describe returns how msg changes a resource, or nil if msg is not audited.
+func describe(msg bus.Msg, user *models.SignedInUser) *change {
+	switch cmd := msg.(type) {
+	// data sources
+	case *models.AddDataSourceCommand:
+		return &change{action: "create", resourceType: "datasource", orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.UpdateDataSourceCommand:
+		return &change{action: "update", resourceType: "datasource", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.Id), current: dataSourceById(cmd.Id, cmd.OrgId)}
+	case *models.DeleteDataSourceByIdCommand:
+		return &change{action: "delete", resourceType: "datasource", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.Id), current: dataSourceById(cmd.Id, cmd.OrgId)}
+	case *models.DeleteDataSourceByNameCommand:
+		return &change{action: "delete", resourceType: "datasource", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: dataSourceIdOr(cmd.Name), current: dataSourceByName(cmd.Name, cmd.OrgId)}
+
+	// data source permissions
+	case *models.EnableDataSourcePermissionsCommand:
+		return &change{action: "enable", resourceType: "datasource-permissions", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.DataSourceId)}
+	case *models.DisableDataSourcePermissionsCommand:
+		return &change{action: "disable", resourceType: "datasource-permissions", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.DataSourceId), current: dataSourcePermissions(cmd.DataSourceId, cmd.OrgId)}
+	case *models.AddDataSourcePermissionCommand:
+		return &change{action: "add", resourceType: "datasource-permissions", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.DataSourceId), current: dataSourcePermissions(cmd.DataSourceId, cmd.OrgId)}
+	case *models.RemoveDataSourcePermissionCommand:
+		return &change{action: "remove", resourceType: "datasource-permissions", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.DataSourceId), current: dataSourcePermissions(cmd.DataSourceId, cmd.OrgId)}
+
+	// dashboards and folders
+	case *models.SaveDashboardCommand:
+		resourceType := "dashboard"
+		if cmd.IsFolder {
+			resourceType = "folder"
+		}
+		// the id of existing dashboards is set before they are saved
+		if dashboardId := cmd.GetDashboardModel().Id; dashboardId != 0 {
+			return &change{action: "update", resourceType: resourceType, orgId: cmd.OrgId,
+				resourceId: idOf(dashboardId), current: dashboardById(dashboardId, cmd.OrgId)}
+		}
+		return &change{action: "create", resourceType: resourceType, orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.DeleteDashboardCommand:
+		return &change{action: "delete", resourceType: "dashboard", orgId: cmd.OrgId, omitAfter: true,
+			resourceTypeOf: dashboardOrFolder, resourceId: idOf(cmd.Id), current: dashboardById(cmd.Id, cmd.OrgId)}
+
+	// dashboard and folder permissions
+	case *models.UpdateDashboardAclCommand:
+		return &change{action: "update", resourceType: "dashboard-permissions", orgId: user.OrgId,
+			resourceId: idOf(cmd.DashboardId), current: dashboardAcl(cmd.DashboardId, user.OrgId)}
+
+	// API keys and service accounts
+	case *models.AddApiKeyCommand:
+		return &change{action: "create", resourceType: "api-key", orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.DeleteApiKeyCommand:
+		return &change{action: "delete", resourceType: "api-key", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.Id), current: apiKeyById(cmd.Id)}
+	case *models.CreateServiceAccountCommand:
+		return &change{action: "create", resourceType: "service-account", orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.UpdateServiceAccountCommand:
+		return &change{action: "update", resourceType: "service-account", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.Id), current: serviceAccountById(cmd.Id, cmd.OrgId)}
+	case *models.DeleteServiceAccountCommand:
+		return &change{action: "delete", resourceType: "service-account", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.Id), current: serviceAccountById(cmd.Id, cmd.OrgId)}
+	case *models.AddServiceAccountTokenCommand:
+		return &change{action: "create", resourceType: "service-account-token", orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.DeleteServiceAccountTokenCommand:
+		return &change{action: "delete", resourceType: "service-account-token", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.Id), current: apiKeyById(cmd.Id)}
+
+	// users, managed by server admins
+	case *models.CreateUserCommand:
+		return &change{action: "create", resourceType: "user",
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.UpdateUserCommand:
+		return &change{action: "update", resourceType: "user",
+			resourceId: idOf(cmd.UserId), current: userById(cmd.UserId)}
+	case *models.ChangeUserPasswordCommand:
+		return &change{action: "update-password", resourceType: "user", omitAfter: true,
+			resourceId: idOf(cmd.UserId)}
+	case *models.UpdateUserPermissionsCommand:
+		return &change{action: "update-permissions", resourceType: "user",
+			resourceId: idOf(cmd.UserId), current: userById(cmd.UserId)}
+	case *models.DisableUserCommand:
+		action := "enable"
+		if cmd.IsDisabled {
+			action = "disable"
+		}
+		return &change{action: action, resourceType: "user", omitAfter: true,
+			resourceId: idOf(cmd.UserId)}
+	case *models.DeleteUserCommand:
+		return &change{action: "delete", resourceType: "user", omitAfter: true,
+			resourceId: idOf(cmd.UserId), current: userById(cmd.UserId)}
+
+	// organizations and their users
+	case *models.CreateOrgCommand:
+		return &change{action: "create", resourceType: "org",
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.UpdateOrgCommand:
+		return &change{action: "update", resourceType: "org", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.OrgId), current: orgById(cmd.OrgId)}
+	case *models.DeleteOrgCommand:
+		return &change{action: "delete", resourceType: "org", orgId: cmd.Id, omitAfter: true,
+			resourceId: idOf(cmd.Id), current: orgById(cmd.Id)}
+	case *models.AddOrgUserCommand:
+		return &change{action: "add", resourceType: "org-user", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.UserId)}
+	case *models.UpdateOrgUserCommand:
+		return &change{action: "update", resourceType: "org-user", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.UserId)}
+	case *models.RemoveOrgUserCommand:
+		return &change{action: "remove", resourceType: "org-user", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.UserId)}
+
+	// teams
+	case *models.CreateTeamCommand:
+		return &change{action: "create", resourceType: "team", orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.UpdateTeamCommand:
+		return &change{action: "update", resourceType: "team", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.Id), current: teamById(cmd.Id, cmd.OrgId)}
+	case *models.DeleteTeamCommand:
+		return &change{action: "delete", resourceType: "team", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.Id), current: teamById(cmd.Id, cmd.OrgId)}
+	case *models.AddTeamMemberCommand:
+		return &change{action: "add", resourceType: "team-member", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.TeamId)}
+	case *models.UpdateTeamMemberCommand:
+		return &change{action: "update", resourceType: "team-member", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.TeamId), after: map[string]interface{}{"userId": cmd.UserId, "permission": cmd.Permission}}
+	case *models.RemoveTeamMemberCommand:
+		return &change{action: "remove", resourceType: "team-member", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.TeamId)}
+
+	// custom roles
+	case *models.CreateRoleCommand:
+		return &change{action: "create", resourceType: "role", orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.UpdateRoleCommand:
+		return &change{action: "update", resourceType: "role", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.Id), current: roleById(cmd.Id, cmd.OrgId)}
+	case *models.DeleteRoleCommand:
+		return &change{action: "delete", resourceType: "role", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.Id), current: roleById(cmd.Id, cmd.OrgId)}
+	case *models.AddUserRoleCommand:
+		return &change{action: "add", resourceType: "user-role", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.UserId)}
+	case *models.RemoveUserRoleCommand:
+		return &change{action: "remove", resourceType: "user-role", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.UserId)}
+	case *models.AddTeamRoleCommand:
+		return &change{action: "add", resourceType: "team-role", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.TeamId)}
+	case *models.RemoveTeamRoleCommand:
+		return &change{action: "remove", resourceType: "team-role", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.TeamId)}
+
+	// alert notification channels
+	case *models.CreateAlertNotificationCommand:
+		return &change{action: "create", resourceType: "alert-notification", orgId: cmd.OrgId,
+			resourceId: func(interface{}) string { return formatId(cmd.Result.Id) }}
+	case *models.UpdateAlertNotificationCommand:
+		return &change{action: "update", resourceType: "alert-notification", orgId: cmd.OrgId,
+			resourceId: idOf(cmd.Id), current: alertNotificationById(cmd.Id, cmd.OrgId)}
+	case *models.DeleteAlertNotificationCommand:
+		return &change{action: "delete", resourceType: "alert-notification", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: idOf(cmd.Id), current: alertNotificationById(cmd.Id, cmd.OrgId)}
+	case *models.UpdateAlertNotificationWithUidCommand:
+		return &change{action: "update", resourceType: "alert-notification", orgId: cmd.OrgId,
+			resourceId: alertNotificationIdOr(cmd.Uid), current: alertNotificationByUid(cmd.Uid, cmd.OrgId)}
+	case *models.DeleteAlertNotificationWithUidCommand:
+		return &change{action: "delete", resourceType: "alert-notification", orgId: cmd.OrgId, omitAfter: true,
+			resourceId: alertNotificationIdOr(cmd.Uid), current: alertNotificationByUid(cmd.Uid, cmd.OrgId)}
+	}
+
+	return nil
+}
+
+func formatId(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func idOf(id int64) func(interface{}) string {
+	return func(interface{}) string { return formatId(id) }
+}
+
+// dataSourceIdOr returns the id of the data source loaded before the change,
+// or name if it could not be loaded.
+func dataSourceIdOr(name string) func(interface{}) string {
+	return func(before interface{}) string {
+		if ds, ok := before.(*models.DataSource); ok && ds != nil {
+			return formatId(ds.Id)
+		}
+		return name
+	}
+}
+
+// alertNotificationIdOr returns the id of the alert notification loaded before
+// the change, or uid if it could not be loaded.
+func alertNotificationIdOr(uid string) func(interface{}) string {
+	return func(before interface{}) string {
+		if n, ok := before.(*models.AlertNotification); ok && n != nil {
+			return formatId(n.Id)
+		}
+		return uid
+	}
+}
+
+// dashboardOrFolder returns whether the dashboard loaded before the change
+// is a folder.
+func dashboardOrFolder(before interface{}) string {
+	if dash, ok := before.(*models.Dashboard); ok && dash != nil && dash.IsFolder {
+		return "folder"
+	}
+	return "dashboard"
+}
+
+func dataSourceById(id int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetDataSourceByIdQuery{Id: id, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func dataSourceByName(name string, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetDataSourceByNameQuery{Name: name, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func dataSourcePermissions(dataSourceId int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetDataSourcePermissionsQuery{DataSourceId: dataSourceId, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func dashboardById(id int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetDashboardQuery{Id: id, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func dashboardAcl(dashboardId int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetDashboardAclInfoListQuery{DashboardId: dashboardId, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func apiKeyById(id int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetApiKeyByIdQuery{ApiKeyId: id}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func serviceAccountById(id int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetServiceAccountByIdQuery{Id: id, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func userById(id int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetUserByIdQuery{Id: id}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func orgById(id int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetOrgByIdQuery{Id: id}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func teamById(id int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetTeamByIdQuery{Id: id, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func roleById(id int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetRoleByIdQuery{Id: id, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func alertNotificationById(id int64, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetAlertNotificationsQuery{Id: id, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
+
+func alertNotificationByUid(uid string, orgId int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		query := models.GetAlertNotificationsWithUidQuery{Uid: uid, OrgId: orgId}
+		err := bus.Dispatch(&query)
+		return query.Result, err
+	}
+}
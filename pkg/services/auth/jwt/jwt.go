@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+var (
+	ErrNoKeys           = errors.New("jwt auth requires jwk_set_file or key_file")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrInvalidSignature = errors.New("token signature is invalid")
+	ErrMissingLogin     = errors.New("token has neither a login nor an email claim")
+	ErrMissingExpiry    = errors.New("token has no expiry claim")
+)
+
+func init() {
+	registry.RegisterService(&AuthService{})
+}
+
+// User is the user a token is issued to, read from the claims configured in
+// the auth.jwt section.
+type User struct {
+	Subject string
+	Login   string
+	Email   string
+	Name    string
+
+	// Role is empty if the token has no valid role claim.
+	Role models.RoleType
+
+	// Expiry is when the token expires, or zero if it does not.
+	Expiry time.Time
+}
+
+// AuthService verifies the JSON web tokens requests sign in with when JWT
+// auth is enabled.
+type AuthService struct {
+	Cfg *setting.Cfg `inject:""`
+
+	log  log.Logger
+	keys []jose.JSONWebKey
+}
+
+func (s *AuthService) Init() error {
+	s.log = log.New("auth.jwt")
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	return s.loadKeys()
+}
+
+// IsEnabled returns true if requests may sign in with a JSON web token.
+func (s *AuthService) IsEnabled() bool {
+	return s != nil && s.Cfg != nil && s.Cfg.JWTAuth.Enabled
+}
+
+// Verify checks the signature, issuer, audience and validity period of
+// token and returns the user it is issued to. Tokens without an expiry are
+// rejected unless allow_tokens_without_expiry is set.
+func (s *AuthService) Verify(token string) (*User, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims jwt.Claims
+	var values map[string]interface{}
+	if err := s.verifySignature(parsed, &claims, &values); err != nil {
+		return nil, err
+	}
+
+	expected := jwt.Expected{
+		Issuer:   s.Cfg.JWTAuth.ExpectedIssuer,
+		Audience: jwt.Audience(s.Cfg.JWTAuth.ExpectedAudience),
+		Time:     time.Now(),
+	}
+	if err := claims.Validate(expected); err != nil {
+		return nil, err
+	}
+	if claims.Expiry == nil && !s.Cfg.JWTAuth.AllowNoExpiry {
+		return nil, ErrMissingExpiry
+	}
+
+	user := &User{
+		Subject: claims.Subject,
+		Login:   stringClaim(values, s.Cfg.JWTAuth.LoginClaim),
+		Email:   stringClaim(values, s.Cfg.JWTAuth.EmailClaim),
+		Name:    stringClaim(values, s.Cfg.JWTAuth.NameClaim),
+	}
+	if claims.Expiry != nil {
+		user.Expiry = claims.Expiry.Time()
+	}
+
+	if s.Cfg.JWTAuth.RoleClaim != "" {
+		role := roleFromClaim(stringClaim(values, s.Cfg.JWTAuth.RoleClaim))
+		if role.IsValid() {
+			user.Role = role
+		} else {
+			s.log.Debug("Ignoring invalid role claim", "login", user.Login, "role", role)
+		}
+	}
+
+	if user.Login == "" {
+		user.Login = user.Email
+	}
+	if user.Login == "" {
+		return nil, ErrMissingLogin
+	}
+	if user.Subject == "" {
+		user.Subject = user.Login
+	}
+
+	return user, nil
+}
+
+// verifySignature decodes the claims of token with the first key verifying
+// its signature. Only the keys with the key id of the token are tried when it
+// has one.
+func (s *AuthService) verifySignature(token *jwt.JSONWebToken, dest ...interface{}) error {
+	var keyID string
+	for _, header := range token.Headers {
+		if header.KeyID != "" {
+			keyID = header.KeyID
+			break
+		}
+	}
+
+	for _, key := range s.keys {
+		if keyID != "" && key.KeyID != "" && key.KeyID != keyID {
+			continue
+		}
+		if err := token.Claims(key.Key, dest...); err == nil {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func stringClaim(values map[string]interface{}, name string) string {
+	if name == "" {
+		return ""
+	}
+
+	value, _ := values[name].(string)
+	return strings.TrimSpace(value)
+}
+
+// roleFromClaim accepts the roles in any case, like "admin" or "EDITOR".
+func roleFromClaim(value string) models.RoleType {
+	for _, role := range []models.RoleType{models.ROLE_VIEWER, models.ROLE_EDITOR, models.ROLE_ADMIN} {
+		if strings.EqualFold(value, string(role)) {
+			return role
+		}
+	}
+	return models.RoleType(value)
+}
@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestAuthService(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	jwksPath := filepath.Join(dir, "jwks.json")
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: otherKey.Public(), KeyID: "other", Algorithm: "RS256", Use: "sig"},
+		{Key: key, KeyID: "current", Algorithm: "RS256", Use: "sig"},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(jwksPath, jwks, 0600))
+
+	pemPath := filepath.Join(dir, "public.pem")
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	newService := func(t *testing.T, settings setting.JWTAuthSettings) *AuthService {
+		settings.Enabled = true
+		settings.LoginClaim = "sub"
+		settings.EmailClaim = "email"
+		settings.NameClaim = "name"
+
+		s := &AuthService{Cfg: &setting.Cfg{JWTAuth: settings}}
+		require.NoError(t, s.Init())
+		return s
+	}
+
+	t.Run("Should verify tokens with a JWK set", func(t *testing.T) {
+		s := newService(t, setting.JWTAuthSettings{
+			JWKSetFile:       jwksPath,
+			ExpectedIssuer:   "gateway",
+			ExpectedAudience: []string{"grafana"},
+			RoleClaim:        "role",
+		})
+
+		token := sign(t, key, "current", jwt.Claims{
+			Subject:  "jdoe",
+			Issuer:   "gateway",
+			Audience: jwt.Audience{"grafana"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}, map[string]interface{}{"email": "jdoe@example.com", "name": "John Doe", "role": "editor"})
+
+		user, err := s.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe", user.Login)
+		assert.Equal(t, "jdoe", user.Subject)
+		assert.Equal(t, "jdoe@example.com", user.Email)
+		assert.Equal(t, "John Doe", user.Name)
+		assert.Equal(t, models.ROLE_EDITOR, user.Role)
+		assert.False(t, user.Expiry.IsZero())
+	})
+
+	t.Run("Should verify tokens with PEM keys", func(t *testing.T) {
+		s := newService(t, setting.JWTAuthSettings{KeyFile: pemPath})
+
+		token := sign(t, key, "", jwt.Claims{Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}, map[string]interface{}{"email": "jdoe@example.com", "role": "Owner"})
+
+		user, err := s.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe@example.com", user.Login)
+		assert.Equal(t, models.RoleType(""), user.Role)
+	})
+
+	t.Run("Should reject invalid tokens", func(t *testing.T) {
+		s := newService(t, setting.JWTAuthSettings{
+			JWKSetFile:       jwksPath,
+			ExpectedIssuer:   "gateway",
+			ExpectedAudience: []string{"grafana"},
+		})
+		valid := jwt.Claims{Subject: "jdoe", Issuer: "gateway", Audience: jwt.Audience{"grafana"}, Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+		_, err := s.Verify("not-a-token")
+		assert.Equal(t, ErrInvalidToken, err)
+
+		_, err = s.Verify(sign(t, otherKey, "current", valid, nil))
+		assert.Equal(t, ErrInvalidSignature, err)
+
+		unknownKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		_, err = s.Verify(sign(t, unknownKey, "", valid, nil))
+		assert.Equal(t, ErrInvalidSignature, err)
+
+		wrongIssuer := valid
+		wrongIssuer.Issuer = "other"
+		_, err = s.Verify(sign(t, key, "current", wrongIssuer, nil))
+		assert.Equal(t, jwt.ErrInvalidIssuer, err)
+
+		wrongAudience := valid
+		wrongAudience.Audience = jwt.Audience{"other"}
+		_, err = s.Verify(sign(t, key, "current", wrongAudience, nil))
+		assert.Equal(t, jwt.ErrInvalidAudience, err)
+
+		expired := valid
+		expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		_, err = s.Verify(sign(t, key, "current", expired, nil))
+		assert.Equal(t, jwt.ErrExpired, err)
+
+		noLogin := valid
+		noLogin.Subject = ""
+		_, err = s.Verify(sign(t, key, "current", noLogin, nil))
+		assert.Equal(t, ErrMissingLogin, err)
+
+		noExpiry := valid
+		noExpiry.Expiry = nil
+		_, err = s.Verify(sign(t, key, "current", noExpiry, nil))
+		assert.Equal(t, ErrMissingExpiry, err)
+	})
+
+	t.Run("Should verify tokens without expiry when allowed", func(t *testing.T) {
+		s := newService(t, setting.JWTAuthSettings{KeyFile: pemPath, AllowNoExpiry: true})
+
+		user, err := s.Verify(sign(t, key, "", jwt.Claims{Subject: "jdoe"}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe", user.Login)
+		assert.True(t, user.Expiry.IsZero())
+	})
+
+	t.Run("Should require keys", func(t *testing.T) {
+		s := &AuthService{Cfg: &setting.Cfg{JWTAuth: setting.JWTAuthSettings{Enabled: true}}}
+		assert.Equal(t, ErrNoKeys, s.Init())
+	})
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, keyID string, claims jwt.Claims, extra map[string]interface{}) string {
+	t.Helper()
+
+	opts := &jose.SignerOptions{}
+	if keyID != "" {
+		opts = opts.WithHeader("kid", keyID)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, opts)
+	require.NoError(t, err)
+
+	builder := jwt.Signed(signer).Claims(claims)
+	if extra != nil {
+		builder = builder.Claims(extra)
+	}
+	token, err := builder.CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
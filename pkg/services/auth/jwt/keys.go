@@ -0,0 +1,107 @@
+package jwt
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+func (s *AuthService) loadKeys() error {
+	s.keys = nil
+
+	if path := s.Cfg.JWTAuth.JWKSetFile; path != "" {
+		keys, err := readJWKSetFile(path)
+		if err != nil {
+			return err
+		}
+		s.keys = append(s.keys, keys...)
+	}
+
+	if path := s.Cfg.JWTAuth.KeyFile; path != "" {
+		keys, err := readPEMFile(path)
+		if err != nil {
+			return err
+		}
+		s.keys = append(s.keys, keys...)
+	}
+
+	if len(s.keys) == 0 {
+		return ErrNoKeys
+	}
+
+	s.log.Info("Loaded JWT verification keys", "count", len(s.keys))
+	return nil
+}
+
+// readJWKSetFile reads the keys of a JSON Web Key Set. Private keys are
+// replaced by their public keys.
+func readJWKSetFile(path string) ([]jose.JSONWebKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWK set file: %w", err)
+	}
+
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWK set file %q: %w", path, err)
+	}
+
+	keys := make([]jose.JSONWebKey, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		if !key.IsPublic() {
+			if public := key.Public(); public.Valid() {
+				key = public
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// readPEMFile reads the public keys and certificates of a PEM file.
+func readPEMFile(path string) ([]jose.JSONWebKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	keys := make([]jose.JSONWebKey, 0)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key interface{}
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			return nil, fmt.Errorf("key file %q has unsupported PEM block %q", path, block.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key file %q: %w", path, err)
+		}
+
+		keys = append(keys, jose.JSONWebKey{Key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("key file %q has no PEM encoded keys", path)
+	}
+
+	return keys, nil
+}
@@ -40,6 +40,9 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			srv.cleanUpTmpFiles()
 			srv.deleteExpiredSnapshots()
 			srv.deleteExpiredDashboardVersions()
+			srv.deleteExpiredAlertStateHistory()
+			srv.deleteExpiredExternalAlerts()
+			srv.deleteExpiredAuditEntries()
 			err := srv.ServerLockService.LockAndExecute(ctx, "delete old login attempts",
 				time.Minute*10, func() {
 					srv.deleteOldLoginAttempts()
@@ -110,6 +113,47 @@ func (srv *CleanUpService) deleteExpiredDashboardVersions() {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredAlertStateHistory() {
+	if srv.Cfg.AlertingStateHistoryRetention <= 0 {
+		return
+	}
+
+	cmd := models.DeleteExpiredAlertStateHistoryCommand{
+		OlderThan: time.Now().Add(-srv.Cfg.AlertingStateHistoryRetention).UnixNano() / int64(time.Millisecond),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to delete expired alert state history", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired alert state history", "rows affected", cmd.DeletedRows)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredExternalAlerts() {
+	cmd := models.DeleteExpiredExternalAlertsCommand{
+		OlderThan: time.Now().Add(-24 * time.Hour),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to delete expired external alerts", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired external alerts", "rows affected", cmd.DeletedRows)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredAuditEntries() {
+	if srv.Cfg.Audit.Retention <= 0 {
+		return
+	}
+
+	cmd := models.DeleteExpiredAuditEntriesCommand{
+		OlderThan: time.Now().Add(-srv.Cfg.Audit.Retention),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Failed to delete expired audit entries", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted expired audit entries", "rows affected", cmd.DeletedRows)
+	}
+}
+
 func (srv *CleanUpService) deleteOldLoginAttempts() {
 	if srv.Cfg.DisableBruteForceLoginProtection {
 		return
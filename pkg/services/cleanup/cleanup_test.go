@@ -4,6 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -39,3 +42,32 @@ func TestCleanUpTmpFiles(t *testing.T) {
 		})
 	})
 }
+
+func TestDeleteExpiredAlertStateHistory(t *testing.T) {
+	Convey("Alert state history cleanup", t, func() {
+		cfg := setting.Cfg{}
+		service := CleanUpService{Cfg: &cfg, log: log.New("cleanup")}
+
+		var cmd *models.DeleteExpiredAlertStateHistoryCommand
+		bus.AddHandler("test", func(c *models.DeleteExpiredAlertStateHistoryCommand) error {
+			cmd = c
+			return nil
+		})
+
+		Convey("Should delete history older than retention", func() {
+			cfg.AlertingStateHistoryRetention = 24 * time.Hour
+			service.deleteExpiredAlertStateHistory()
+
+			So(cmd, ShouldNotBeNil)
+			expected := time.Now().Add(-24*time.Hour).UnixNano() / int64(time.Millisecond)
+			So(cmd.OlderThan, ShouldAlmostEqual, expected, 1000)
+		})
+
+		Convey("Should keep history when retention is 0", func() {
+			cfg.AlertingStateHistoryRetention = 0
+			service.deleteExpiredAlertStateHistory()
+
+			So(cmd, ShouldBeNil)
+		})
+	})
+}
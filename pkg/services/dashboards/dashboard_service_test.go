@@ -1,6 +1,7 @@
 package dashboards
 
 import (
+	"context"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -32,7 +33,7 @@ func TestDashboardService(t *testing.T) {
 
 				for _, title := range titles {
 					dto.Dashboard = models.NewDashboard(title)
-					_, err := service.SaveDashboard(dto, false)
+					_, err := service.SaveDashboard(context.Background(), dto, false)
 					So(err, ShouldEqual, models.ErrDashboardTitleEmpty)
 				}
 			})
@@ -40,13 +41,13 @@ func TestDashboardService(t *testing.T) {
 			Convey("Should return validation error if it's a folder and have a folder id", func() {
 				dto.Dashboard = models.NewDashboardFolder("Folder")
 				dto.Dashboard.FolderId = 1
-				_, err := service.SaveDashboard(dto, false)
+				_, err := service.SaveDashboard(context.Background(), dto, false)
 				So(err, ShouldEqual, models.ErrDashboardFolderCannotHaveParent)
 			})
 
 			Convey("Should return validation error if folder is named General", func() {
 				dto.Dashboard = models.NewDashboardFolder("General")
-				_, err := service.SaveDashboard(dto, false)
+				_, err := service.SaveDashboard(context.Background(), dto, false)
 				So(err, ShouldEqual, models.ErrDashboardFolderNameExists)
 			})
 
@@ -108,7 +109,7 @@ func TestDashboardService(t *testing.T) {
 				dto.Dashboard = models.NewDashboard("Dash")
 				dto.Dashboard.SetId(3)
 				dto.User = &models.SignedInUser{UserId: 1}
-				_, err := service.SaveDashboard(dto, false)
+				_, err := service.SaveDashboard(context.Background(), dto, false)
 				So(provisioningValidated, ShouldBeTrue)
 				So(err, ShouldEqual, models.ErrDashboardCannotSaveProvisionedDashboard)
 			})
@@ -133,7 +134,7 @@ func TestDashboardService(t *testing.T) {
 				dto.Dashboard = models.NewDashboard("Dash")
 				dto.Dashboard.SetId(3)
 				dto.User = &models.SignedInUser{UserId: 1}
-				_, err := service.SaveDashboard(dto, true)
+				_, err := service.SaveDashboard(context.Background(), dto, true)
 				So(provisioningValidated, ShouldBeFalse)
 				So(err, ShouldNotBeNil)
 			})
@@ -149,7 +150,7 @@ func TestDashboardService(t *testing.T) {
 				})
 
 				dto.Dashboard = models.NewDashboard("Dash")
-				_, err := service.SaveDashboard(dto, false)
+				_, err := service.SaveDashboard(context.Background(), dto, false)
 				So(err.Error(), ShouldEqual, "Alert validation error")
 			})
 		})
@@ -258,7 +259,7 @@ func TestDashboardService(t *testing.T) {
 				dto.Dashboard = models.NewDashboard("Dash")
 				dto.Dashboard.SetId(3)
 				dto.User = &models.SignedInUser{UserId: 1}
-				_, err := service.ImportDashboard(dto)
+				_, err := service.ImportDashboard(context.Background(), dto)
 				So(provisioningValidated, ShouldBeTrue)
 				So(err, ShouldEqual, models.ErrDashboardCannotSaveProvisionedDashboard)
 			})
@@ -274,7 +275,7 @@ func TestDashboardService(t *testing.T) {
 			})
 
 			Convey("DeleteDashboard should fail to delete it", func() {
-				err := service.DeleteDashboard(1, 1)
+				err := service.DeleteDashboard(context.Background(), 1, 1)
 				So(err, ShouldEqual, models.ErrDashboardCannotDeleteProvisionedDashboard)
 				So(result.deleteWasCalled, ShouldBeFalse)
 			})
@@ -290,10 +291,24 @@ func TestDashboardService(t *testing.T) {
 			})
 
 			Convey("DeleteDashboard should delete it", func() {
-				err := service.DeleteDashboard(1, 1)
+				err := service.DeleteDashboard(context.Background(), 1, 1)
 				So(err, ShouldBeNil)
 				So(result.deleteWasCalled, ShouldBeTrue)
 			})
+
+			Convey("DeleteDashboard should delete it with the context of the request", func() {
+				type ctxKey struct{}
+				var deleteCtx context.Context
+				bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.DeleteDashboardCommand) error {
+					deleteCtx = ctx
+					return nil
+				})
+
+				err := service.DeleteDashboard(context.WithValue(context.Background(), ctxKey{}, "request"), 1, 1)
+				So(err, ShouldBeNil)
+				So(deleteCtx, ShouldNotBeNil)
+				So(deleteCtx.Value(ctxKey{}), ShouldEqual, "request")
+			})
 		})
 
 		Reset(func() {
@@ -1,6 +1,8 @@
 package dashboards
 
 import (
+	"context"
+
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/guardian"
@@ -12,9 +14,9 @@ type FolderService interface {
 	GetFolders(limit int64) ([]*models.Folder, error)
 	GetFolderByID(id int64) (*models.Folder, error)
 	GetFolderByUID(uid string) (*models.Folder, error)
-	CreateFolder(cmd *models.CreateFolderCommand) error
-	UpdateFolder(uid string, cmd *models.UpdateFolderCommand) error
-	DeleteFolder(uid string) (*models.Folder, error)
+	CreateFolder(ctx context.Context, cmd *models.CreateFolderCommand) error
+	UpdateFolder(ctx context.Context, uid string, cmd *models.UpdateFolderCommand) error
+	DeleteFolder(ctx context.Context, uid string) (*models.Folder, error)
 }
 
 // NewFolderService factory for creating a new folder service
@@ -91,7 +93,7 @@ func (dr *dashboardServiceImpl) GetFolderByUID(uid string) (*models.Folder, erro
 	return dashToFolder(dashFolder), nil
 }
 
-func (dr *dashboardServiceImpl) CreateFolder(cmd *models.CreateFolderCommand) error {
+func (dr *dashboardServiceImpl) CreateFolder(ctx context.Context, cmd *models.CreateFolderCommand) error {
 	dashFolder := cmd.GetDashboardModel(dr.orgId, dr.user.UserId)
 
 	dto := &SaveDashboardDTO{
@@ -105,7 +107,7 @@ func (dr *dashboardServiceImpl) CreateFolder(cmd *models.CreateFolderCommand) er
 		return toFolderError(err)
 	}
 
-	err = bus.Dispatch(saveDashboardCmd)
+	err = bus.DispatchCtx(ctx, saveDashboardCmd)
 	if err != nil {
 		return toFolderError(err)
 	}
@@ -121,7 +123,7 @@ func (dr *dashboardServiceImpl) CreateFolder(cmd *models.CreateFolderCommand) er
 	return nil
 }
 
-func (dr *dashboardServiceImpl) UpdateFolder(existingUid string, cmd *models.UpdateFolderCommand) error {
+func (dr *dashboardServiceImpl) UpdateFolder(ctx context.Context, existingUid string, cmd *models.UpdateFolderCommand) error {
 	query := models.GetDashboardQuery{OrgId: dr.orgId, Uid: existingUid}
 	dashFolder, err := getFolder(query)
 	if err != nil {
@@ -142,7 +144,7 @@ func (dr *dashboardServiceImpl) UpdateFolder(existingUid string, cmd *models.Upd
 		return toFolderError(err)
 	}
 
-	err = bus.Dispatch(saveDashboardCmd)
+	err = bus.DispatchCtx(ctx, saveDashboardCmd)
 	if err != nil {
 		return toFolderError(err)
 	}
@@ -158,7 +160,7 @@ func (dr *dashboardServiceImpl) UpdateFolder(existingUid string, cmd *models.Upd
 	return nil
 }
 
-func (dr *dashboardServiceImpl) DeleteFolder(uid string) (*models.Folder, error) {
+func (dr *dashboardServiceImpl) DeleteFolder(ctx context.Context, uid string) (*models.Folder, error) {
 	query := models.GetDashboardQuery{OrgId: dr.orgId, Uid: uid}
 	dashFolder, err := getFolder(query)
 	if err != nil {
@@ -174,7 +176,7 @@ func (dr *dashboardServiceImpl) DeleteFolder(uid string) (*models.Folder, error)
 	}
 
 	deleteCmd := models.DeleteDashboardCommand{OrgId: dr.orgId, Id: dashFolder.Id}
-	if err := bus.Dispatch(&deleteCmd); err != nil {
+	if err := bus.DispatchCtx(ctx, &deleteCmd); err != nil {
 		return nil, toFolderError(err)
 	}
 
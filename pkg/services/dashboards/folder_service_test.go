@@ -1,6 +1,7 @@
 package dashboards
 
 import (
+	"context"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -49,7 +50,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			Convey("When creating folder should return access denied error", func() {
-				err := service.CreateFolder(&models.CreateFolderCommand{
+				err := service.CreateFolder(context.Background(), &models.CreateFolderCommand{
 					Title: "Folder",
 				})
 				So(err, ShouldNotBeNil)
@@ -57,7 +58,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			Convey("When updating folder should return access denied error", func() {
-				err := service.UpdateFolder("uid", &models.UpdateFolderCommand{
+				err := service.UpdateFolder(context.Background(), "uid", &models.UpdateFolderCommand{
 					Uid:   "uid",
 					Title: "Folder",
 				})
@@ -66,7 +67,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			Convey("When deleting folder by uid should return access denied error", func() {
-				_, err := service.DeleteFolder("uid")
+				_, err := service.DeleteFolder(context.Background(), "uid")
 				So(err, ShouldNotBeNil)
 				So(err, ShouldEqual, models.ErrFolderAccessDenied)
 			})
@@ -119,7 +120,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			Convey("When creating folder should not return access denied error", func() {
-				err := service.CreateFolder(&models.CreateFolderCommand{
+				err := service.CreateFolder(context.Background(), &models.CreateFolderCommand{
 					Title: "Folder",
 				})
 				So(err, ShouldBeNil)
@@ -127,7 +128,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			Convey("When updating folder should not return access denied error", func() {
-				err := service.UpdateFolder("uid", &models.UpdateFolderCommand{
+				err := service.UpdateFolder(context.Background(), "uid", &models.UpdateFolderCommand{
 					Uid:   "uid",
 					Title: "Folder",
 				})
@@ -136,7 +137,7 @@ func TestFolderService(t *testing.T) {
 			})
 
 			Convey("When deleting folder by uid should not return access denied error", func() {
-				_, err := service.DeleteFolder("uid")
+				_, err := service.DeleteFolder(context.Background(), "uid")
 				So(err, ShouldBeNil)
 			})
 
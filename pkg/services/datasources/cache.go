@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 )
 
 type CacheService interface {
@@ -32,18 +33,50 @@ func (dc *CacheServiceImpl) Init() error {
 }
 
 func (dc *CacheServiceImpl) GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	ds, err := dc.getDatasource(datasourceID, user.OrgId, skipCache)
+	if err != nil {
+		return nil, err
+	}
+
+	hasAccess, err := accesscontrol.HasAccess(user, accesscontrol.ActionDatasourcesQuery, accesscontrol.ScopesDatasource(ds)...)
+	if err != nil {
+		return nil, err
+	}
+	if !hasAccess {
+		return nil, models.ErrDataSourceAccessDenied
+	}
+
+	// data sources with permissions enabled can only be queried by the users
+	// and teams of their acl
+	dsFilterQuery := models.DatasourcesPermissionFilterQuery{
+		User:        user,
+		Datasources: []*models.DataSource{ds},
+	}
+
+	if err := dc.Bus.Dispatch(&dsFilterQuery); err != nil {
+		if err != bus.ErrHandlerNotFound {
+			return nil, err
+		}
+	} else if len(dsFilterQuery.Result) == 0 {
+		return nil, models.ErrDataSourceAccessDenied
+	}
+
+	return ds, nil
+}
+
+func (dc *CacheServiceImpl) getDatasource(datasourceID int64, orgID int64, skipCache bool) (*models.DataSource, error) {
 	cacheKey := fmt.Sprintf("ds-%d", datasourceID)
 
 	if !skipCache {
 		if cached, found := dc.CacheService.Get(cacheKey); found {
 			ds := cached.(*models.DataSource)
-			if ds.OrgId == user.OrgId {
+			if ds.OrgId == orgID {
 				return ds, nil
 			}
 		}
 	}
 
-	query := models.GetDataSourceByIdQuery{Id: datasourceID, OrgId: user.OrgId}
+	query := models.GetDataSourceByIdQuery{Id: datasourceID, OrgId: orgID}
 	if err := dc.Bus.Dispatch(&query); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,200 @@
+package alerts
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+var pollInterval = 10 * time.Second
+
+// Provision alert rules
+func Provision(configDirectory string) error {
+	ap := newAlertProvisioner(log.New("provisioning.alerts"))
+	return ap.applyChanges(configDirectory)
+}
+
+// PollChanges provisions the alert rules again every time the files
+// in the config directory change, until the context is cancelled. The
+// lock is held while they are provisioned.
+func PollChanges(ctx context.Context, configDirectory string, lock sync.Locker) {
+	ap := newAlertProvisioner(log.New("provisioning.alerts"))
+	ap.pollChanges(ctx, configDirectory, lock)
+}
+
+// AlertProvisioner is responsible for provisioning alert rules
+type AlertProvisioner struct {
+	log         log.Logger
+	cfgProvider *configReader
+}
+
+func newAlertProvisioner(log log.Logger) AlertProvisioner {
+	return AlertProvisioner{
+		log:         log,
+		cfgProvider: &configReader{log: log},
+	}
+}
+
+func (ap *AlertProvisioner) applyChanges(configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	provisioned := map[int64]bool{}
+	for _, cfg := range configs {
+		for _, alert := range cfg.Alerts {
+			alertID, err := ap.saveAlert(cfg.Path, alert)
+			if err != nil {
+				return err
+			}
+
+			if alertID != 0 {
+				provisioned[alertID] = true
+			}
+		}
+	}
+
+	return ap.deleteRemovedAlerts(provisioned)
+}
+
+// saveAlert provisions an alert rule and returns its id. Rules of
+// dashboards that do not exist (yet) are skipped and return 0.
+func (ap *AlertProvisioner) saveAlert(path string, cfg *alertFromConfig) (int64, error) {
+	if cfg.OrgID == 0 && cfg.OrgName != "" {
+		getOrg := &models.GetOrgByNameQuery{Name: cfg.OrgName}
+		if err := bus.Dispatch(getOrg); err != nil {
+			return 0, err
+		}
+		cfg.OrgID = getOrg.Result.Id
+	}
+
+	getDashboard := &models.GetDashboardQuery{Uid: cfg.DashboardUID, OrgId: cfg.OrgID}
+	if err := bus.Dispatch(getDashboard); err != nil {
+		if err == models.ErrDashboardNotFound {
+			ap.log.Warn("Skipping alert rule of missing dashboard", "name", cfg.Name, "dashboardUid", cfg.DashboardUID)
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	dashboardJSON := simplejson.New()
+	dashboardJSON.Set("panels", []interface{}{cfg.panelJSON().Interface()})
+
+	dash := &models.Dashboard{
+		Id:    getDashboard.Result.Id,
+		OrgId: cfg.OrgID,
+		Data:  dashboardJSON,
+	}
+
+	extractor := alerting.NewDashAlertExtractor(dash, cfg.OrgID, provisioningUser(cfg.OrgID))
+	alerts, err := extractor.GetAlerts()
+	if err != nil {
+		return 0, fmt.Errorf("alert rule %q is invalid: %v", cfg.Name, err)
+	}
+
+	if len(alerts) != 1 {
+		return 0, fmt.Errorf("alert rule %q is invalid", cfg.Name)
+	}
+
+	ap.log.Debug("Provisioning alert rule", "name", cfg.Name, "dashboardUid", cfg.DashboardUID, "panelId", cfg.PanelID)
+
+	cmd := &models.SaveProvisionedAlertCommand{
+		Alert:             alerts[0],
+		AlertProvisioning: &models.AlertProvisioning{Name: path},
+	}
+	if err := bus.Dispatch(cmd); err != nil {
+		return 0, err
+	}
+
+	return cmd.Alert.Id, nil
+}
+
+// provisioningUser is the user alert rules are provisioned as, rather than
+// none. As an org admin it can query all data sources of the organization,
+// as provisioning files are trusted.
+func provisioningUser(orgID int64) *models.SignedInUser {
+	return &models.SignedInUser{
+		UserId:  0,
+		OrgRole: models.ROLE_ADMIN,
+		OrgId:   orgID,
+	}
+}
+
+func (ap *AlertProvisioner) deleteRemovedAlerts(provisioned map[int64]bool) error {
+	query := &models.GetProvisionedAlertsQuery{}
+	if err := bus.Dispatch(query); err != nil {
+		return err
+	}
+
+	for _, alert := range query.Result {
+		if provisioned[alert.Id] {
+			continue
+		}
+
+		ap.log.Info("Deleting alert rule removed from provisioning", "name", alert.Name, "alertId", alert.Id)
+		if err := bus.Dispatch(&models.DeleteProvisionedAlertCommand{AlertId: alert.Id}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ap *AlertProvisioner) pollChanges(ctx context.Context, configPath string, lock sync.Locker) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	lastChecksum := configChecksum(configPath)
+
+	for {
+		select {
+		case <-ticker.C:
+			checksum := configChecksum(configPath)
+			if checksum == lastChecksum {
+				continue
+			}
+
+			ap.log.Info("Alert rule provisioning files changed, provisioning alert rules")
+			lock.Lock()
+			err := ap.applyChanges(configPath)
+			lock.Unlock()
+			if err != nil {
+				ap.log.Error("Failed to provision alert rules", "error", err)
+				continue
+			}
+			lastChecksum = checksum
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// configChecksum returns a checksum of the names, sizes and modification
+// times of the provisioning files, which changes whenever a file does.
+func configChecksum(configPath string) string {
+	files, err := ioutil.ReadDir(configPath)
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.New()
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+		fmt.Fprintf(hash, "%s:%d:%d\n", file.Name(), file.Size(), file.ModTime().UnixNano())
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
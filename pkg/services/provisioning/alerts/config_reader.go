@@ -0,0 +1,144 @@
+package alerts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"gopkg.in/yaml.v2"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+func (cr *configReader) readConfig(path string) ([]*alertsAsConfig, error) {
+	var alerts []*alertsAsConfig
+	cr.log.Debug("Looking for alert rule provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alert rule provisioning files from directory", "path", path, "error", err)
+		return alerts, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alert rules provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseAlertConfig(path, file)
+			if err != nil {
+				return nil, err
+			}
+
+			if cfg != nil {
+				alerts = append(alerts, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alert rules")
+	if err = validateRequiredField(alerts); err != nil {
+		return nil, err
+	}
+
+	checkOrgIDAndOrgName(alerts)
+
+	if err = validateUniquePanels(alerts); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+func (cr *configReader) parseAlertConfig(path string, file os.FileInfo) (*alertsAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *alertsAsConfigV0
+	err = yaml.Unmarshal(yamlFile, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := cfg.mapToAlertsFromConfig()
+	alerts.Path = filename
+
+	return alerts, nil
+}
+
+func checkOrgIDAndOrgName(alerts []*alertsAsConfig) {
+	for i := range alerts {
+		for _, alert := range alerts[i].Alerts {
+			if alert.OrgID < 1 {
+				if alert.OrgName == "" {
+					alert.OrgID = 1
+				} else {
+					alert.OrgID = 0
+				}
+			}
+		}
+	}
+}
+
+func validateRequiredField(alerts []*alertsAsConfig) error {
+	for i := range alerts {
+		var errStrings []string
+		for index, alert := range alerts[i].Alerts {
+			if alert.Name == "" {
+				errStrings = append(
+					errStrings,
+					fmt.Sprintf("Added alert rule item %d in configuration doesn't contain required field name", index+1),
+				)
+			}
+
+			if alert.DashboardUID == "" {
+				errStrings = append(
+					errStrings,
+					fmt.Sprintf("Added alert rule item %d in configuration doesn't contain required field dashboard_uid", index+1),
+				)
+			}
+
+			if alert.PanelID == 0 {
+				errStrings = append(
+					errStrings,
+					fmt.Sprintf("Added alert rule item %d in configuration doesn't contain required field panel_id", index+1),
+				)
+			}
+
+			if len(alert.Conditions) == 0 {
+				errStrings = append(
+					errStrings,
+					fmt.Sprintf("Added alert rule item %d in configuration doesn't contain required field conditions", index+1),
+				)
+			}
+		}
+
+		if len(errStrings) != 0 {
+			return fmt.Errorf(strings.Join(errStrings, "\n"))
+		}
+	}
+
+	return nil
+}
+
+// validateUniquePanels makes sure that a panel has at most one provisioned alert rule.
+func validateUniquePanels(alerts []*alertsAsConfig) error {
+	seen := map[string]bool{}
+	for i := range alerts {
+		for _, alert := range alerts[i].Alerts {
+			key := fmt.Sprintf("%d/%s/%s/%d", alert.OrgID, alert.OrgName, alert.DashboardUID, alert.PanelID)
+			if seen[key] {
+				return fmt.Errorf("panel %d of dashboard %s has more than one provisioned alert rule", alert.PanelID, alert.DashboardUID)
+			}
+			seen[key] = true
+		}
+	}
+
+	return nil
+}
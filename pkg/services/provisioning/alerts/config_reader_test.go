@@ -0,0 +1,140 @@
+package alerts
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	_ "github.com/grafana/grafana/pkg/services/alerting/conditions"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	correctProperties = "./testdata/test-configs/correct-properties"
+	noRequiredFields  = "./testdata/test-configs/no-required-fields"
+	brokenYaml        = "./testdata/test-configs/broken-yaml"
+	emptyFolder       = "./testdata/test-configs/empty_folder"
+)
+
+func TestAlertsAsConfig(t *testing.T) {
+	logger := log.New("fake.log")
+
+	Convey("Testing alert rules as configuration", t, func() {
+		sqlstore.InitTestDB(t)
+
+		Convey("Can read correct properties", func() {
+			_ = os.Setenv("PERCENT", "5")
+			cfgProvider := &configReader{log: logger}
+			cfg, err := cfgProvider.readConfig(correctProperties)
+			_ = os.Unsetenv("PERCENT")
+			So(err, ShouldBeNil)
+			So(len(cfg), ShouldEqual, 1)
+			So(cfg[0].Path, ShouldEndWith, "correct-properties.yaml")
+
+			alerts := cfg[0].Alerts
+			So(len(alerts), ShouldEqual, 2)
+
+			alert := alerts[0]
+			So(alert.Name, ShouldEqual, "High error rate")
+			So(alert.OrgID, ShouldEqual, 1)
+			So(alert.DashboardUID, ShouldEqual, "backend")
+			So(alert.PanelID, ShouldEqual, 2)
+			So(alert.Message, ShouldEqual, "More than 5% of the requests fail")
+			So(alert.Frequency, ShouldEqual, "1m")
+			So(alert.For, ShouldEqual, "5m")
+			So(alert.NoDataState, ShouldEqual, "no_data")
+			So(alert.ExecutionErrorState, ShouldEqual, "alerting")
+			So(alert.Datasource, ShouldEqual, "Graphite")
+			So(alert.Notifications, ShouldResemble, []string{"notifier1", "notifier2"})
+			So(alert.Tags, ShouldResemble, map[string]string{"team": "backend"})
+			So(len(alert.Queries), ShouldEqual, 2)
+			So(alert.Queries[1].RefID, ShouldEqual, "B")
+			So(alert.Queries[1].Datasource, ShouldEqual, "graphite2")
+			So(alert.Queries[1].Model["target"], ShouldEqual, "sumSeries(app.requests.count)")
+			So(len(alert.Conditions), ShouldEqual, 1)
+			So(alert.Conditions[0]["expression"], ShouldEqual, "$A / $B * 100 > 5")
+
+			alert = alerts[1]
+			So(alert.OrgID, ShouldEqual, 0)
+			So(alert.OrgName, ShouldEqual, "Main Org.")
+		})
+
+		Convey("Missing required fields should return error", func() {
+			cfgProvider := &configReader{log: logger}
+			_, err := cfgProvider.readConfig(noRequiredFields)
+			So(err, ShouldNotBeNil)
+
+			errString := err.Error()
+			So(errString, ShouldContainSubstring, "Added alert rule item 1 in configuration doesn't contain required field dashboard_uid")
+			So(errString, ShouldContainSubstring, "Added alert rule item 1 in configuration doesn't contain required field panel_id")
+			So(errString, ShouldContainSubstring, "Added alert rule item 2 in configuration doesn't contain required field name")
+			So(errString, ShouldContainSubstring, "Added alert rule item 2 in configuration doesn't contain required field conditions")
+		})
+
+		Convey("Broken yaml should return error", func() {
+			reader := &configReader{log: logger}
+			_, err := reader.readConfig(brokenYaml)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Empty folder should return empty configuration", func() {
+			reader := &configReader{log: logger}
+			cfg, err := reader.readConfig(emptyFolder)
+			So(err, ShouldBeNil)
+			So(len(cfg), ShouldEqual, 0)
+		})
+
+		Convey("Provisioning alert rules", func() {
+			orgCmd := &models.CreateOrgCommand{Name: "Main Org."}
+			So(bus.Dispatch(orgCmd), ShouldBeNil)
+
+			for _, name := range []string{"Graphite", "graphite2"} {
+				dsCmd := &models.AddDataSourceCommand{OrgId: orgCmd.Result.Id, Name: name, Type: "graphite", Access: models.DS_ACCESS_PROXY}
+				So(bus.Dispatch(dsCmd), ShouldBeNil)
+			}
+
+			dashCmd := &models.SaveDashboardCommand{
+				OrgId: orgCmd.Result.Id,
+				Dashboard: simplejson.NewFromAny(map[string]interface{}{
+					"uid":   "backend",
+					"title": "Backend",
+				}),
+			}
+			So(bus.Dispatch(dashCmd), ShouldBeNil)
+
+			_ = os.Setenv("PERCENT", "5")
+			err := Provision(correctProperties)
+			_ = os.Unsetenv("PERCENT")
+			So(err, ShouldBeNil)
+
+			Convey("Should save the rules of existing dashboards", func() {
+				query := &models.GetProvisionedAlertsQuery{}
+				So(bus.Dispatch(query), ShouldBeNil)
+				So(len(query.Result), ShouldEqual, 1)
+
+				alert := query.Result[0]
+				So(alert.Name, ShouldEqual, "High error rate")
+				So(alert.DashboardId, ShouldEqual, dashCmd.Result.Id)
+				So(alert.PanelId, ShouldEqual, 2)
+				So(alert.Frequency, ShouldEqual, 60)
+
+				queries := alert.Settings.Get("conditions").GetIndex(0).Get("queries")
+				So(queries.GetIndex(0).Get("query").Get("model").Get("target").MustString(), ShouldEqual, "sumSeries(app.requests.errors)")
+				So(queries.GetIndex(1).Get("query").Get("datasourceId").MustInt64(), ShouldEqual, 2)
+			})
+
+			Convey("Should delete rules removed from the files", func() {
+				err := Provision(emptyFolder)
+				So(err, ShouldBeNil)
+
+				query := &models.GetProvisionedAlertsQuery{}
+				So(bus.Dispatch(query), ShouldBeNil)
+				So(len(query.Result), ShouldEqual, 0)
+			})
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package alerts
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+// alertsAsConfig is normalized data object for alert rules config data. Any config version should be mappable
+// to this type.
+type alertsAsConfig struct {
+	Path   string
+	Alerts []*alertFromConfig
+}
+
+type alertFromConfig struct {
+	OrgID               int64
+	OrgName             string
+	DashboardUID        string
+	PanelID             int64
+	Name                string
+	Message             string
+	Frequency           string
+	For                 string
+	NoDataState         string
+	ExecutionErrorState string
+	Datasource          string
+	Notifications       []string
+	Tags                map[string]string
+	Queries             []*queryFromConfig
+	Conditions          []map[string]interface{}
+}
+
+type queryFromConfig struct {
+	RefID      string
+	Datasource string
+	Model      map[string]interface{}
+}
+
+// alertsAsConfigV0 is mapping for zero version configs. This is mapped to its normalised version.
+type alertsAsConfigV0 struct {
+	Alerts []*alertFromConfigV0 `json:"alerts" yaml:"alerts"`
+}
+
+type alertFromConfigV0 struct {
+	OrgID               values.Int64Value     `json:"org_id" yaml:"org_id"`
+	OrgName             values.StringValue    `json:"org_name" yaml:"org_name"`
+	DashboardUID        values.StringValue    `json:"dashboard_uid" yaml:"dashboard_uid"`
+	PanelID             values.Int64Value     `json:"panel_id" yaml:"panel_id"`
+	Name                values.StringValue    `json:"name" yaml:"name"`
+	Message             values.StringValue    `json:"message" yaml:"message"`
+	Frequency           values.StringValue    `json:"frequency" yaml:"frequency"`
+	For                 values.StringValue    `json:"for" yaml:"for"`
+	NoDataState         values.StringValue    `json:"no_data_state" yaml:"no_data_state"`
+	ExecutionErrorState values.StringValue    `json:"execution_error_state" yaml:"execution_error_state"`
+	Datasource          values.StringValue    `json:"datasource" yaml:"datasource"`
+	Notifications       []values.StringValue  `json:"notifications" yaml:"notifications"`
+	Tags                values.StringMapValue `json:"tags" yaml:"tags"`
+	Queries             []*queryFromConfigV0  `json:"queries" yaml:"queries"`
+	Conditions          []values.JSONValue    `json:"conditions" yaml:"conditions"`
+}
+
+type queryFromConfigV0 struct {
+	RefID      values.StringValue `json:"ref_id" yaml:"ref_id"`
+	Datasource values.StringValue `json:"datasource" yaml:"datasource"`
+	Model      values.JSONValue   `json:"model" yaml:"model"`
+}
+
+// panelJSON returns the alert rule as a graph panel so that it can be
+// extracted and validated the same way as alert rules stored in dashboards.
+func (alert *alertFromConfig) panelJSON() *simplejson.Json {
+	targets := make([]interface{}, 0, len(alert.Queries))
+	for _, query := range alert.Queries {
+		target := simplejson.NewFromAny(copyMap(query.Model))
+		target.Set("refId", query.RefID)
+		if query.Datasource != "" {
+			target.Set("datasource", query.Datasource)
+		}
+		targets = append(targets, target.Interface())
+	}
+
+	conditions := make([]interface{}, 0, len(alert.Conditions))
+	for _, condition := range alert.Conditions {
+		conditions = append(conditions, condition)
+	}
+
+	notifications := make([]interface{}, 0, len(alert.Notifications))
+	for _, uid := range alert.Notifications {
+		notifications = append(notifications, map[string]interface{}{"uid": uid})
+	}
+
+	tags := make(map[string]interface{}, len(alert.Tags))
+	for k, v := range alert.Tags {
+		tags[k] = v
+	}
+
+	jsonAlert := simplejson.New()
+	jsonAlert.Set("name", alert.Name)
+	jsonAlert.Set("message", alert.Message)
+	jsonAlert.Set("frequency", alert.Frequency)
+	jsonAlert.Set("for", alert.For)
+	jsonAlert.Set("noDataState", alert.NoDataState)
+	jsonAlert.Set("executionErrorState", alert.ExecutionErrorState)
+	jsonAlert.Set("notifications", notifications)
+	jsonAlert.Set("alertRuleTags", tags)
+	jsonAlert.Set("conditions", conditions)
+
+	panel := simplejson.New()
+	panel.Set("id", alert.PanelID)
+	panel.Set("datasource", alert.Datasource)
+	panel.Set("targets", targets)
+	panel.Set("alert", jsonAlert.Interface())
+
+	return panel
+}
+
+func copyMap(in map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// mapToAlertsFromConfig maps config syntax to normalized alertsAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertsAsConfigV0) mapToAlertsFromConfig() *alertsAsConfig {
+	r := &alertsAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, alert := range cfg.Alerts {
+		notifications := make([]string, 0, len(alert.Notifications))
+		for _, uid := range alert.Notifications {
+			notifications = append(notifications, uid.Value())
+		}
+
+		queries := make([]*queryFromConfig, 0, len(alert.Queries))
+		for _, query := range alert.Queries {
+			queries = append(queries, &queryFromConfig{
+				RefID:      query.RefID.Value(),
+				Datasource: query.Datasource.Value(),
+				Model:      query.Model.Value(),
+			})
+		}
+
+		conditions := make([]map[string]interface{}, 0, len(alert.Conditions))
+		for _, condition := range alert.Conditions {
+			conditions = append(conditions, condition.Value())
+		}
+
+		r.Alerts = append(r.Alerts, &alertFromConfig{
+			OrgID:               alert.OrgID.Value(),
+			OrgName:             alert.OrgName.Value(),
+			DashboardUID:        alert.DashboardUID.Value(),
+			PanelID:             alert.PanelID.Value(),
+			Name:                alert.Name.Value(),
+			Message:             alert.Message.Value(),
+			Frequency:           alert.Frequency.Value(),
+			For:                 alert.For.Value(),
+			NoDataState:         alert.NoDataState.Value(),
+			ExecutionErrorState: alert.ExecutionErrorState.Value(),
+			Datasource:          alert.Datasource.Value(),
+			Notifications:       notifications,
+			Tags:                alert.Tags.Value(),
+			Queries:             queries,
+			Conditions:          conditions,
+		})
+	}
+
+	return r
+}
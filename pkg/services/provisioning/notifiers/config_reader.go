@@ -147,6 +147,10 @@ func validateNotifications(notifications []*notificationsAsConfig) error {
 		}
 
 		for _, notification := range notifications[i].Notifications {
+			if err := alerting.ValidateNotificationTemplates(notification.SettingsToJSON()); err != nil {
+				return err
+			}
+
 			_, err := alerting.InitNotifier(&models.AlertNotification{
 				Name:           notification.Name,
 				Settings:       notification.SettingsToJSON(),
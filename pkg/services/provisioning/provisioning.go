@@ -7,6 +7,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerts"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
@@ -20,6 +21,7 @@ type ProvisioningService interface {
 	ProvisionPlugins() error
 	ProvisionNotifications() error
 	ProvisionDashboards() error
+	ProvisionAlerts() error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 }
@@ -34,6 +36,8 @@ func init() {
 			notifiers.Provision,
 			datasources.Provision,
 			plugins.Provision,
+			alerts.Provision,
+			alerts.PollChanges,
 		),
 		InitPriority: registry.Low,
 	})
@@ -44,6 +48,8 @@ func NewProvisioningServiceImpl(
 	provisionNotifiers func(string) error,
 	provisionDatasources func(string) error,
 	provisionPlugins func(string) error,
+	provisionAlerts func(string) error,
+	pollAlertChanges func(context.Context, string, sync.Locker),
 ) *provisioningServiceImpl {
 	return &provisioningServiceImpl{
 		log:                     log.New("provisioning"),
@@ -51,6 +57,8 @@ func NewProvisioningServiceImpl(
 		provisionNotifiers:      provisionNotifiers,
 		provisionDatasources:    provisionDatasources,
 		provisionPlugins:        provisionPlugins,
+		provisionAlerts:         provisionAlerts,
+		pollAlertChanges:        pollAlertChanges,
 	}
 }
 
@@ -63,6 +71,8 @@ type provisioningServiceImpl struct {
 	provisionNotifiers      func(string) error
 	provisionDatasources    func(string) error
 	provisionPlugins        func(string) error
+	provisionAlerts         func(string) error
+	pollAlertChanges        func(context.Context, string, sync.Locker)
 	mutex                   sync.Mutex
 }
 
@@ -92,6 +102,16 @@ func (ps *provisioningServiceImpl) Run(ctx context.Context) error {
 		return err
 	}
 
+	// Alert rules are attached to dashboards, so they are provisioned after them.
+	err = ps.ProvisionAlerts()
+	if err != nil {
+		ps.log.Error("Failed to provision alert rules", "error", err)
+		return err
+	}
+	// Holding the mutex while alert rules are provisioned again keeps them from
+	// being provisioned while dashboards are.
+	go ps.pollAlertChanges(ctx, ps.alertsPath(), &ps.mutex)
+
 	for {
 		// Wait for unlock. This is tied to new dashboardProvisioner to be instantiated before we start polling.
 		ps.mutex.Lock()
@@ -132,6 +152,18 @@ func (ps *provisioningServiceImpl) ProvisionNotifications() error {
 	return errutil.Wrap("Alert notification provisioning error", err)
 }
 
+func (ps *provisioningServiceImpl) ProvisionAlerts() error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	err := ps.provisionAlerts(ps.alertsPath())
+	return errutil.Wrap("Alert rule provisioning error", err)
+}
+
+func (ps *provisioningServiceImpl) alertsPath() string {
+	return path.Join(ps.Cfg.ProvisioningPath, "alerts")
+}
+
 func (ps *provisioningServiceImpl) ProvisionDashboards() error {
 	dashboardPath := path.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(dashboardPath)
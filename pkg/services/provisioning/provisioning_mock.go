@@ -5,6 +5,7 @@ type Calls struct {
 	ProvisionPlugins                    []interface{}
 	ProvisionNotifications              []interface{}
 	ProvisionDashboards                 []interface{}
+	ProvisionAlerts                     []interface{}
 	GetDashboardProvisionerResolvedPath []interface{}
 	GetAllowUIUpdatesFromConfig         []interface{}
 }
@@ -15,6 +16,7 @@ type ProvisioningServiceMock struct {
 	ProvisionPluginsFunc                    func() error
 	ProvisionNotificationsFunc              func() error
 	ProvisionDashboardsFunc                 func() error
+	ProvisionAlertsFunc                     func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
 }
@@ -57,6 +59,14 @@ func (mock *ProvisioningServiceMock) ProvisionDashboards() error {
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionAlerts() error {
+	mock.Calls.ProvisionAlerts = append(mock.Calls.ProvisionAlerts, nil)
+	if mock.ProvisionAlertsFunc != nil {
+		return mock.ProvisionAlertsFunc()
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
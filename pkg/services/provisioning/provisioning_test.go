@@ -3,6 +3,7 @@ package provisioning
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -61,6 +62,19 @@ func TestProvisioningServiceImpl(t *testing.T) {
 		// Cancelling the root context and stopping the service
 		serviceTest.cancel()
 	})
+
+	t.Run("Failed alert rule provisioning stops service", func(t *testing.T) {
+		serviceTest := setup()
+		serviceTest.service.provisionAlerts = func(string) error {
+			return errors.New("Test error")
+		}
+
+		serviceTest.startService()
+		serviceTest.waitForStop()
+
+		assert.False(t, serviceTest.serviceRunning, "Service should not be running")
+		assert.EqualError(t, serviceTest.serviceError, "Alert rule provisioning error: Test error")
+	})
 }
 
 type serviceTestStruct struct {
@@ -97,6 +111,8 @@ func setup() *serviceTestStruct {
 		nil,
 		nil,
 		nil,
+		func(string) error { return nil },
+		func(context.Context, string, sync.Locker) {},
 	)
 	serviceTest.service.Cfg = setting.NewCfg()
 
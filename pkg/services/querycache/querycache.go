@@ -0,0 +1,299 @@
+package querycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+const cacheKeyPrefix = "query-cache:"
+
+func init() {
+	registry.RegisterService(&QueryCacheService{})
+}
+
+// QueryCacheService caches the responses of data source queries in the
+// remote cache, so that identical queries over the same time range are
+// only sent to the data source once per TTL.
+type QueryCacheService struct {
+	Cfg         *setting.Cfg             `inject:""`
+	RemoteCache *remotecache.RemoteCache `inject:""`
+
+	log        log.Logger
+	storage    remotecache.CacheStorage
+	defaultTTL time.Duration
+}
+
+// IsDisabled returns true if the query cache is disabled.
+func (s *QueryCacheService) IsDisabled() bool {
+	return !s.Cfg.QueryCache.Enabled
+}
+
+func (s *QueryCacheService) Init() error {
+	s.log = log.New("querycache")
+	s.storage = s.RemoteCache
+	s.defaultTTL = s.Cfg.QueryCache.TTL
+
+	tsdb.SetQueryCache(s)
+	return nil
+}
+
+// Get returns the cached response of the request.
+func (s *QueryCacheService) Get(ctx context.Context, dsInfo *models.DataSource, req *tsdb.TsdbQuery) (*tsdb.Response, bool) {
+	ttl := s.ttl(dsInfo)
+	if ttl <= 0 {
+		return nil, false
+	}
+
+	key, err := cacheKey(dsInfo, req, ttl)
+	if err != nil {
+		s.log.Warn("Failed to create query cache key", "datasource", dsInfo.Name, "error", err)
+		return nil, false
+	}
+
+	value, err := s.storage.Get(key)
+	if err != nil {
+		if err != remotecache.ErrCacheItemNotFound {
+			s.log.Warn("Failed to read query cache", "datasource", dsInfo.Name, "error", err)
+		}
+		metrics.MQueryCacheRequestTotal.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		metrics.MQueryCacheRequestTotal.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	resp, err := decodeResponse(data)
+	if err != nil {
+		s.log.Warn("Failed to decode cached query response", "datasource", dsInfo.Name, "error", err)
+		metrics.MQueryCacheRequestTotal.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	metrics.MQueryCacheRequestTotal.WithLabelValues("hit").Inc()
+	return resp, true
+}
+
+// Set caches the response of the request, unless one of its results has
+// an error.
+func (s *QueryCacheService) Set(ctx context.Context, dsInfo *models.DataSource, req *tsdb.TsdbQuery, resp *tsdb.Response) {
+	ttl := s.ttl(dsInfo)
+	if ttl <= 0 {
+		return
+	}
+
+	for _, result := range resp.Results {
+		if result.Error != nil || result.ErrorString != "" {
+			return
+		}
+	}
+
+	key, err := cacheKey(dsInfo, req, ttl)
+	if err != nil {
+		s.log.Warn("Failed to create query cache key", "datasource", dsInfo.Name, "error", err)
+		return
+	}
+
+	data, err := encodeResponse(resp)
+	if err != nil {
+		s.log.Warn("Failed to encode query response", "datasource", dsInfo.Name, "error", err)
+		return
+	}
+
+	if err := s.storage.Set(key, data, ttl); err != nil {
+		s.log.Warn("Failed to write query cache", "datasource", dsInfo.Name, "error", err)
+	}
+}
+
+// ttl returns how long the responses of the data source are cached. Data
+// sources can override the default TTL with queryCacheTTL in their json
+// data, where a TTL of zero disables caching. Responses of data sources
+// that forward the OAuth token of the user are never cached, as they can
+// differ between users.
+func (s *QueryCacheService) ttl(dsInfo *models.DataSource) time.Duration {
+	if dsInfo.JsonData == nil {
+		return s.defaultTTL
+	}
+
+	if dsInfo.JsonData.Get("oauthPassThru").MustBool() {
+		return 0
+	}
+
+	value := dsInfo.JsonData.Get("queryCacheTTL").MustString("")
+	if value == "" {
+		return s.defaultTTL
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		s.log.Warn("Invalid query cache TTL", "datasource", dsInfo.Name, "ttl", value, "error", err)
+		return s.defaultTTL
+	}
+
+	return ttl
+}
+
+type keyQuery struct {
+	RefId         string
+	DatasourceId  int64
+	Model         *simplejson.Json
+	MaxDataPoints int64
+	IntervalMs    int64
+	QueryType     string
+}
+
+type keyRequest struct {
+	DatasourceId      int64
+	DatasourceVersion int
+	From              int64
+	To                int64
+	IntervalDuration  time.Duration
+	Queries           []keyQuery
+	Headers           map[string]string
+	Debug             bool
+}
+
+// cacheKey returns the cache key of the request. The time range is rounded
+// down to the largest query interval, or to the TTL for queries without
+// an interval, so that requests made within the same interval share the
+// cached response.
+func cacheKey(dsInfo *models.DataSource, req *tsdb.TsdbQuery, ttl time.Duration) (string, error) {
+	key := keyRequest{
+		DatasourceId:      dsInfo.Id,
+		DatasourceVersion: dsInfo.Version,
+		Headers:           req.Headers,
+		Debug:             req.Debug,
+	}
+
+	var intervalMs int64
+	for _, query := range req.Queries {
+		kq := keyQuery{
+			RefId:         query.RefId,
+			Model:         query.Model,
+			MaxDataPoints: query.MaxDataPoints,
+			IntervalMs:    query.IntervalMs,
+			QueryType:     query.QueryType,
+		}
+		if query.DataSource != nil {
+			kq.DatasourceId = query.DataSource.Id
+		}
+		key.Queries = append(key.Queries, kq)
+
+		if query.IntervalMs > intervalMs {
+			intervalMs = query.IntervalMs
+		}
+	}
+
+	if intervalMs == 0 {
+		intervalMs = ttl.Milliseconds()
+	}
+
+	if req.TimeRange != nil {
+		key.From = roundDown(req.TimeRange.GetFromAsMsEpoch(), intervalMs)
+		key.To = roundDown(req.TimeRange.GetToAsMsEpoch(), intervalMs)
+		key.IntervalDuration = req.TimeRange.IntervalDuration()
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return cacheKeyPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+func roundDown(value, interval int64) int64 {
+	if interval <= 0 {
+		return value
+	}
+	return value - value%interval
+}
+
+// cachedResult is a query result as it is stored in the cache, with its
+// data frames encoded.
+type cachedResult struct {
+	RefId      string
+	Meta       *simplejson.Json
+	Series     tsdb.TimeSeriesSlice
+	Tables     []*tsdb.Table
+	Dataframes [][]byte
+}
+
+type cachedResponse struct {
+	Results map[string]*cachedResult
+	Message string
+}
+
+func encodeResponse(resp *tsdb.Response) ([]byte, error) {
+	cached := cachedResponse{
+		Results: make(map[string]*cachedResult, len(resp.Results)),
+		Message: resp.Message,
+	}
+
+	for refID, result := range resp.Results {
+		cr := &cachedResult{
+			RefId:  result.RefId,
+			Meta:   result.Meta,
+			Series: result.Series,
+			Tables: result.Tables,
+		}
+
+		if result.Dataframes != nil {
+			encoded, err := result.Dataframes.Encoded()
+			if err != nil {
+				return nil, err
+			}
+			cr.Dataframes = encoded
+		}
+
+		cached.Results[refID] = cr
+	}
+
+	return json.Marshal(cached)
+}
+
+// decodeResponse decodes a cached response. Every call returns a new
+// response, so callers are free to modify it.
+func decodeResponse(data []byte) (*tsdb.Response, error) {
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+
+	resp := &tsdb.Response{
+		Results: make(map[string]*tsdb.QueryResult, len(cached.Results)),
+		Message: cached.Message,
+	}
+
+	for refID, cr := range cached.Results {
+		result := &tsdb.QueryResult{
+			RefId:  cr.RefId,
+			Meta:   cr.Meta,
+			Series: cr.Series,
+			Tables: cr.Tables,
+		}
+
+		if cr.Dataframes != nil {
+			result.Dataframes = tsdb.NewEncodedDataFrames(cr.Dataframes)
+		}
+
+		resp.Results[refID] = result
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,196 @@
+package querycache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+type fakeCacheStorage struct {
+	items map[string]interface{}
+	ttls  map[string]time.Duration
+}
+
+func (s *fakeCacheStorage) Get(key string) (interface{}, error) {
+	item, ok := s.items[key]
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return item, nil
+}
+
+func (s *fakeCacheStorage) Set(key string, value interface{}, expire time.Duration) error {
+	s.items[key] = value
+	s.ttls[key] = expire
+	return nil
+}
+
+func (s *fakeCacheStorage) Delete(key string) error {
+	delete(s.items, key)
+	return nil
+}
+
+type countingEndpoint struct {
+	calls int
+	err   error
+}
+
+func (e *countingEndpoint) Query(ctx context.Context, ds *models.DataSource, query *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	e.calls++
+
+	result := &tsdb.QueryResult{
+		RefId:  "A",
+		Series: tsdb.TimeSeriesSlice{tsdb.NewTimeSeries("cpu", tsdb.TimeSeriesPoints{tsdb.NewTimePoint(null.FloatFrom(1), 1000)})},
+		Dataframes: tsdb.NewDecodedDataFrames(data.Frames{
+			data.NewFrame("frame", data.NewField("value", nil, []float64{1, 2})),
+		}),
+	}
+	if e.err != nil {
+		result.Error = e.err
+	}
+
+	return &tsdb.Response{Results: map[string]*tsdb.QueryResult{"A": result}}, nil
+}
+
+func TestQueryCache(t *testing.T) {
+	endpoint := &countingEndpoint{}
+	tsdb.RegisterTsdbQueryEndpoint("querycache-test", func(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+		return endpoint, nil
+	})
+
+	storage := &fakeCacheStorage{items: map[string]interface{}{}, ttls: map[string]time.Duration{}}
+	s := &QueryCacheService{
+		log:        log.New("test"),
+		storage:    storage,
+		defaultTTL: time.Minute,
+	}
+	tsdb.SetQueryCache(s)
+	defer tsdb.SetQueryCache(nil)
+
+	ds := &models.DataSource{Id: 1, Type: "querycache-test", JsonData: simplejson.New()}
+
+	newRequest := func(from, to string) *tsdb.TsdbQuery {
+		return &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange(from, to),
+			Queries: []*tsdb.Query{
+				{RefId: "A", DataSource: ds, IntervalMs: 10000, Model: simplejson.NewFromAny(map[string]interface{}{"expr": "up"})},
+			},
+		}
+	}
+
+	reset := func() {
+		endpoint.calls = 0
+		endpoint.err = nil
+		storage.items = map[string]interface{}{}
+		ds.JsonData = simplejson.New()
+	}
+
+	t.Run("serves identical request from cache", func(t *testing.T) {
+		reset()
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+		resp, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, endpoint.calls)
+		assert.Equal(t, time.Minute, storage.ttls[firstKey(storage)])
+
+		result := resp.Results["A"]
+		require.Len(t, result.Series, 1)
+		assert.Equal(t, "cpu", result.Series[0].Name)
+		assert.Equal(t, 1.0, result.Series[0].Points[0][0].Float64)
+
+		frames, err := result.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, "frame", frames[0].Name)
+	})
+
+	t.Run("rounds time range to query interval", func(t *testing.T) {
+		reset()
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+		_, err = tsdb.HandleRequest(context.Background(), ds, newRequest("105000", "205000"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, endpoint.calls)
+
+		_, err = tsdb.HandleRequest(context.Background(), ds, newRequest("110000", "210000"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, endpoint.calls)
+	})
+
+	t.Run("different query is not served from cache", func(t *testing.T) {
+		reset()
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+
+		req := newRequest("100000", "200000")
+		req.Queries[0].Model.Set("expr", "down")
+		_, err = tsdb.HandleRequest(context.Background(), ds, req)
+		require.NoError(t, err)
+		assert.Equal(t, 2, endpoint.calls)
+	})
+
+	t.Run("skips cache when requested", func(t *testing.T) {
+		reset()
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+
+		req := newRequest("100000", "200000")
+		req.SkipCache = true
+		_, err = tsdb.HandleRequest(context.Background(), ds, req)
+		require.NoError(t, err)
+		assert.Equal(t, 2, endpoint.calls)
+	})
+
+	t.Run("does not cache results with errors", func(t *testing.T) {
+		reset()
+		endpoint.err = errors.New("query failed")
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+		assert.Empty(t, storage.items)
+	})
+
+	t.Run("uses TTL of datasource", func(t *testing.T) {
+		reset()
+		ds.JsonData.Set("queryCacheTTL", "5m")
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Minute, storage.ttls[firstKey(storage)])
+	})
+
+	t.Run("zero TTL of datasource disables cache", func(t *testing.T) {
+		reset()
+		ds.JsonData.Set("queryCacheTTL", "0s")
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+		assert.Empty(t, storage.items)
+	})
+
+	t.Run("does not cache datasources forwarding OAuth token", func(t *testing.T) {
+		reset()
+		ds.JsonData.Set("oauthPassThru", true)
+		_, err := tsdb.HandleRequest(context.Background(), ds, newRequest("100000", "200000"))
+		require.NoError(t, err)
+		assert.Empty(t, storage.items)
+	})
+}
+
+func firstKey(storage *fakeCacheStorage) string {
+	for key := range storage.items {
+		return key
+	}
+	return ""
+}
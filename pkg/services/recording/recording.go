@@ -0,0 +1,363 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/alerting/conditions"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// seriesLabel is the label that tells apart the series of a rule whose
+// query returns series without tags.
+const seriesLabel = "series"
+
+// orgIDLabel is the label with the id of the organization of the rule, so
+// that rules of different organizations never write the same series.
+const orgIDLabel = "org_id"
+
+var invalidLabelCharRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+func init() {
+	registry.RegisterService(&RecordingRuleService{})
+}
+
+// RecordingRuleService evaluates the recording rules of all organizations
+// and writes their results to a Prometheus remote write endpoint.
+type RecordingRuleService struct {
+	Cfg               *setting.Cfg                  `inject:""`
+	ServerLockService *serverlock.ServerLockService `inject:""`
+
+	log           log.Logger
+	writer        *remoteWriteClient
+	handleRequest tsdb.HandleRequestFunc
+
+	mu      sync.Mutex
+	running map[int64]bool
+	// lastWritten is the timestamp of the last sample written for each
+	// series of each rule by this server. It is not persisted, so samples
+	// older than the previous evaluation of a rule are never written again.
+	lastWritten map[int64]map[string]int64
+}
+
+// IsDisabled returns true if recording rules are disabled.
+func (s *RecordingRuleService) IsDisabled() bool {
+	return !s.Cfg.RecordingRules.Enabled
+}
+
+func (s *RecordingRuleService) Init() error {
+	settings := s.Cfg.RecordingRules
+	if settings.RemoteWriteURL == "" {
+		return errors.New("recording rules require remote_write_url to be set")
+	}
+
+	s.log = log.New("recording")
+	s.writer = newRemoteWriteClient(settings.RemoteWriteURL, settings.RemoteWriteUser, settings.RemoteWritePassword, settings.RemoteWriteTimeout)
+	s.handleRequest = tsdb.HandleRequest
+	s.running = make(map[int64]bool)
+	s.lastWritten = make(map[int64]map[string]int64)
+	return nil
+}
+
+func (s *RecordingRuleService) Run(ctx context.Context) error {
+	ticker := alerting.NewTicker(time.Now(), 0, clock.New())
+
+	var rules []*models.RecordingRule
+	tickIndex := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tick := <-ticker.C:
+			// rules are reloaded every tenth tick, as alert rules are
+			if tickIndex%10 == 0 {
+				rules = s.fetchRules()
+			}
+			tickIndex++
+
+			for _, rule := range rules {
+				if rule.Paused || tick.Unix()%rule.Frequency != 0 || !s.start(rule.Id) {
+					continue
+				}
+
+				go s.run(ctx, rule, tick)
+			}
+		}
+	}
+}
+
+func (s *RecordingRuleService) fetchRules() []*models.RecordingRule {
+	query := &models.GetRecordingRulesQuery{}
+	if err := bus.Dispatch(query); err != nil {
+		s.log.Error("Could not load recording rules", "error", err)
+		return nil
+	}
+
+	ids := make(map[int64]bool, len(query.Result))
+	for _, rule := range query.Result {
+		ids[rule.Id] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.lastWritten {
+		if !ids[id] {
+			delete(s.lastWritten, id)
+		}
+	}
+
+	return query.Result
+}
+
+// start marks the rule as running, unless its previous evaluation is
+// still running.
+func (s *RecordingRuleService) start(ruleID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[ruleID] {
+		return false
+	}
+
+	s.running[ruleID] = true
+	return true
+}
+
+func (s *RecordingRuleService) run(ctx context.Context, rule *models.RecordingRule, now time.Time) {
+	defer func() {
+		if err := recover(); err != nil {
+			s.log.Error("Recording rule panic", "rule", rule.Name, "error", err, "stack", log.Stack(1))
+		}
+
+		s.mu.Lock()
+		delete(s.running, rule.Id)
+		s.mu.Unlock()
+	}()
+
+	frequency := time.Duration(rule.Frequency) * time.Second
+	ctx, cancel := context.WithTimeout(ctx, frequency)
+	defer cancel()
+
+	// in a high availability setup only one server evaluates the rule
+	lockName := fmt.Sprintf("recording rule %d", rule.Id)
+	err := s.ServerLockService.LockAndExecute(ctx, lockName, frequency/2, func() {
+		if err := s.record(ctx, rule, now); err != nil {
+			s.log.Error("Failed to evaluate recording rule", "rule", rule.Name, "orgId", rule.OrgId, "error", err)
+		}
+	})
+	if err != nil {
+		s.log.Error("Failed to lock recording rule", "rule", rule.Name, "error", err)
+	}
+}
+
+// record evaluates the rule and writes the samples that were not written
+// yet. Samples up to the previous evaluation of the rule were written by
+// it, also when it ran on another server or before a restart.
+func (s *RecordingRuleService) record(ctx context.Context, rule *models.RecordingRule, now time.Time) error {
+	series, err := s.evaluate(ctx, rule, now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	lastWritten := s.lastWritten[rule.Id]
+	s.mu.Unlock()
+
+	previous := now.Add(-time.Duration(rule.Frequency)*time.Second).UnixNano() / int64(time.Millisecond)
+
+	toWrite := make([]*timeSeries, 0, len(series))
+	written := make(map[string]int64, len(series))
+	for _, ts := range series {
+		key := labelsKey(ts.labels)
+
+		after := lastWritten[key]
+		if after < previous {
+			after = previous
+		}
+
+		samples := ts.samples[:0]
+		for _, smpl := range ts.samples {
+			if smpl.timestamp > after {
+				samples = append(samples, smpl)
+			}
+		}
+
+		if len(samples) == 0 {
+			continue
+		}
+
+		ts.samples = samples
+		toWrite = append(toWrite, ts)
+		written[key] = samples[len(samples)-1].timestamp
+	}
+
+	if len(toWrite) == 0 {
+		return nil
+	}
+
+	if err := s.writer.write(ctx, toWrite); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastWritten[rule.Id] == nil {
+		s.lastWritten[rule.Id] = make(map[string]int64)
+	}
+	for key, timestamp := range written {
+		s.lastWritten[rule.Id][key] = timestamp
+	}
+
+	return nil
+}
+
+// evaluate runs the query of the rule and returns its result as the
+// series to write, with their samples sorted by time.
+func (s *RecordingRuleService) evaluate(ctx context.Context, rule *models.RecordingRule, now time.Time) ([]*timeSeries, error) {
+	dsQuery := &models.GetDataSourceByIdQuery{Id: rule.DatasourceId, OrgId: rule.OrgId}
+	if err := bus.Dispatch(dsQuery); err != nil {
+		return nil, fmt.Errorf("could not find datasource: %v", err)
+	}
+
+	req := &tsdb.TsdbQuery{
+		TimeRange: tsdb.NewTimeRange(rule.QueryRange, "now"),
+		Queries: []*tsdb.Query{
+			{
+				RefId:      "A",
+				Model:      rule.Query,
+				DataSource: dsQuery.Result,
+			},
+		},
+	}
+
+	resp, err := s.handleRequest(ctx, dsQuery.Result, req)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := responseSeries(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]*timeSeries, 0, len(results))
+	for _, result := range results {
+		ts := &timeSeries{labels: seriesLabels(rule, result)}
+
+		if rule.Reducer != "" {
+			value := conditions.ReduceSeries(rule.Reducer, result)
+			if value.Valid {
+				ts.samples = append(ts.samples, sample{value: value.Float64, timestamp: now.UnixNano() / int64(time.Millisecond)})
+			}
+		} else {
+			for _, point := range result.Points {
+				if point[0].Valid && point[1].Valid {
+					ts.samples = append(ts.samples, sample{value: point[0].Float64, timestamp: int64(point[1].Float64)})
+				}
+			}
+			sort.Slice(ts.samples, func(i, j int) bool { return ts.samples[i].timestamp < ts.samples[j].timestamp })
+		}
+
+		series = append(series, ts)
+	}
+
+	return series, nil
+}
+
+// responseSeries returns the series of the response, converting the data
+// frames of data sources that return them.
+func responseSeries(resp *tsdb.Response) (tsdb.TimeSeriesSlice, error) {
+	result := make(tsdb.TimeSeriesSlice, 0)
+
+	for _, v := range resp.Results {
+		if v.Error != nil {
+			return nil, fmt.Errorf("query error: %v", v.Error)
+		}
+
+		if v.Dataframes == nil || len(v.Series) > 0 {
+			result = append(result, v.Series...)
+			continue
+		}
+
+		frames, err := v.Dataframes.Decoded()
+		if err != nil {
+			return nil, errutil.Wrap("failed to unmarshal dataframes", err)
+		}
+
+		for _, frame := range frames {
+			ss, err := tsdb.FrameToSeriesSlice(frame)
+			if err != nil {
+				return nil, errutil.Wrapf(err, "failed to convert dataframe %q to series", frame.Name)
+			}
+			result = append(result, ss...)
+		}
+	}
+
+	return result, nil
+}
+
+// seriesLabels returns the labels of a series written for the rule: the
+// tags of the series, the labels of the rule, the name of the rule as the
+// metric name and the id of its organization, which neither can override.
+func seriesLabels(rule *models.RecordingRule, series *tsdb.TimeSeries) []label {
+	labels := map[string]string{}
+	for name, value := range series.Tags {
+		labels[sanitizeLabelName(name)] = value
+	}
+
+	if len(series.Tags) == 0 && series.Name != "" {
+		labels[seriesLabel] = series.Name
+	}
+
+	for name, value := range rule.Labels {
+		labels[name] = value
+	}
+
+	labels["__name__"] = rule.Name
+	labels[orgIDLabel] = strconv.FormatInt(rule.OrgId, 10)
+
+	result := make([]label, 0, len(labels))
+	for name, value := range labels {
+		result = append(result, label{name: name, value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+// sanitizeLabelName replaces the characters Prometheus does not allow in
+// label names, as tags of other data sources can contain them.
+func sanitizeLabelName(name string) string {
+	name = invalidLabelCharRegex.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+func labelsKey(labels []label) string {
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, l.name+"="+strconv.Quote(l.value))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,213 @@
+package recording
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+func TestRecordingRuleService(t *testing.T) {
+	bus.AddHandler("test", func(query *models.GetDataSourceByIdQuery) error {
+		query.Result = &models.DataSource{Id: query.Id, OrgId: query.OrgId, Type: "test"}
+		return nil
+	})
+
+	var written [][]*timeSeries
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+
+		user, password, _ := r.BasicAuth()
+		assert.Equal(t, "writer", user)
+		assert.Equal(t, "secret", password)
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		data, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		written = append(written, decodeWriteRequest(t, data))
+	}))
+	defer server.Close()
+
+	points := tsdb.TimeSeriesPoints{
+		tsdb.NewTimePoint(null.FloatFrom(1), 1000),
+		tsdb.NewTimePoint(null.FloatFrom(3), 2000),
+		tsdb.NewTimePoint(null.FloatFromPtr(nil), 3000),
+	}
+
+	s := &RecordingRuleService{
+		log:         log.New("test"),
+		writer:      newRemoteWriteClient(server.URL, "writer", "secret", time.Second),
+		lastWritten: make(map[int64]map[string]int64),
+		handleRequest: func(ctx context.Context, ds *models.DataSource, req *tsdb.TsdbQuery) (*tsdb.Response, error) {
+			return &tsdb.Response{
+				Results: map[string]*tsdb.QueryResult{
+					"A": {
+						Series: tsdb.TimeSeriesSlice{
+							{Name: "api", Tags: map[string]string{"job": "api", "host.name": "a"}, Points: points},
+							{Name: "web", Points: points},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	rule := &models.RecordingRule{
+		Id:           1,
+		OrgId:        1,
+		Name:         "job:requests:rate5m",
+		DatasourceId: 1,
+		Query:        simplejson.New(),
+		QueryRange:   "5m",
+		Frequency:    60,
+		Labels:       map[string]string{"team": "backend", "org_id": "2"},
+	}
+
+	t.Run("writes all points of series", func(t *testing.T) {
+		written = nil
+		require.NoError(t, s.record(context.Background(), rule, time.Unix(3, 0)))
+
+		require.Len(t, written, 1)
+		require.Len(t, written[0], 2)
+		assert.Equal(t, []label{
+			{name: "__name__", value: "job:requests:rate5m"},
+			{name: "host_name", value: "a"},
+			{name: "job", value: "api"},
+			{name: "org_id", value: "1"},
+			{name: "team", value: "backend"},
+		}, written[0][0].labels)
+		assert.Equal(t, []sample{{value: 1, timestamp: 1000}, {value: 3, timestamp: 2000}}, written[0][0].samples)
+		assert.Equal(t, []label{
+			{name: "__name__", value: "job:requests:rate5m"},
+			{name: "org_id", value: "1"},
+			{name: "series", value: "web"},
+			{name: "team", value: "backend"},
+		}, written[0][1].labels)
+	})
+
+	t.Run("does not write points twice", func(t *testing.T) {
+		written = nil
+		points = append(points, tsdb.NewTimePoint(null.FloatFrom(5), 4000))
+		require.NoError(t, s.record(context.Background(), rule, time.Unix(5, 0)))
+
+		require.Len(t, written, 1)
+		require.Len(t, written[0], 2)
+		assert.Equal(t, []sample{{value: 5, timestamp: 4000}}, written[0][0].samples)
+
+		written = nil
+		require.NoError(t, s.record(context.Background(), rule, time.Unix(6, 0)))
+		assert.Empty(t, written)
+	})
+
+	t.Run("does not write points of previous evaluations after restart", func(t *testing.T) {
+		written = nil
+		restarted := &RecordingRuleService{
+			log:           s.log,
+			writer:        s.writer,
+			lastWritten:   make(map[int64]map[string]int64),
+			handleRequest: s.handleRequest,
+		}
+		require.NoError(t, restarted.record(context.Background(), rule, time.Unix(61, 0)))
+
+		require.Len(t, written, 1)
+		require.Len(t, written[0], 2)
+		assert.Equal(t, []sample{{value: 3, timestamp: 2000}, {value: 5, timestamp: 4000}}, written[0][0].samples)
+	})
+
+	t.Run("writes reduced value", func(t *testing.T) {
+		written = nil
+		reduced := *rule
+		reduced.Id = 2
+		reduced.Reducer = "max"
+		require.NoError(t, s.record(context.Background(), &reduced, time.Unix(60, 0)))
+
+		require.Len(t, written, 1)
+		require.Len(t, written[0], 2)
+		assert.Equal(t, []sample{{value: 5, timestamp: 60000}}, written[0][0].samples)
+	})
+}
+
+func TestRemoteWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "out of order sample", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := newRemoteWriteClient(server.URL, "", "", time.Second)
+	err := client.write(context.Background(), []*timeSeries{{labels: []label{{name: "__name__", value: "up"}}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "out of order sample")
+}
+
+// decodeWriteRequest decodes a remote write WriteRequest message.
+func decodeWriteRequest(t *testing.T, data []byte) []*timeSeries {
+	var result []*timeSeries
+	forEachField(t, data, func(num protowire.Number, value []byte) {
+		ts := &timeSeries{}
+		forEachField(t, value, func(num protowire.Number, value []byte) {
+			switch num {
+			case 1:
+				l := label{}
+				forEachField(t, value, func(num protowire.Number, value []byte) {
+					if num == 1 {
+						l.name = string(value)
+					} else {
+						l.value = string(value)
+					}
+				})
+				ts.labels = append(ts.labels, l)
+			case 2:
+				smpl := sample{}
+				for len(value) > 0 {
+					num, typ, n := protowire.ConsumeTag(value)
+					value = value[n:]
+					if typ == protowire.Fixed64Type {
+						v, n := protowire.ConsumeFixed64(value)
+						smpl.value = math.Float64frombits(v)
+						value = value[n:]
+					} else {
+						v, n := protowire.ConsumeVarint(value)
+						require.Equal(t, protowire.Number(2), num)
+						smpl.timestamp = int64(v)
+						value = value[n:]
+					}
+				}
+				ts.samples = append(ts.samples, smpl)
+			}
+		})
+		result = append(result, ts)
+	})
+	return result
+}
+
+func forEachField(t *testing.T, data []byte, fn func(num protowire.Number, value []byte)) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		require.True(t, n > 0)
+		require.Equal(t, protowire.BytesType, typ)
+		data = data[n:]
+
+		value, n := protowire.ConsumeBytes(data)
+		require.True(t, n > 0)
+		data = data[n:]
+
+		fn(num, value)
+	}
+}
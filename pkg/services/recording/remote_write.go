@@ -0,0 +1,117 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWriteMaxErrorMsgLen is how much of the body of a failed remote
+// write response is kept in the error.
+const remoteWriteMaxErrorMsgLen = 256
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	value float64
+	// timestamp in epoch milliseconds
+	timestamp int64
+}
+
+// timeSeries is a series to write, with its labels sorted by name.
+type timeSeries struct {
+	labels  []label
+	samples []sample
+}
+
+// remoteWriteClient writes series to a Prometheus remote write endpoint.
+type remoteWriteClient struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+}
+
+func newRemoteWriteClient(url, user, password string, timeout time.Duration) *remoteWriteClient {
+	return &remoteWriteClient{
+		url:      url,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *remoteWriteClient) write(ctx context.Context, series []*timeSeries) error {
+	body := snappy.Encode(nil, encodeWriteRequest(series))
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, remoteWriteMaxErrorMsgLen))
+		return fmt.Errorf("remote write returned HTTP status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// encodeWriteRequest encodes the series as a remote write WriteRequest
+// protobuf message.
+func encodeWriteRequest(series []*timeSeries) []byte {
+	var request []byte
+	for _, s := range series {
+		var ts []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.name)
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.value)
+
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, lb)
+		}
+
+		for _, smpl := range s.samples {
+			var sb []byte
+			sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+			sb = protowire.AppendFixed64(sb, math.Float64bits(smpl.value))
+			sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(smpl.timestamp))
+
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, sb)
+		}
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, ts)
+	}
+	return request
+}
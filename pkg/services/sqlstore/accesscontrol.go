@@ -0,0 +1,364 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateRole)
+	bus.AddHandler("sql", UpdateRole)
+	bus.AddHandler("sql", DeleteRole)
+	bus.AddHandler("sql", GetRoles)
+	bus.AddHandler("sql", GetRoleById)
+	bus.AddHandler("sql", AddUserRole)
+	bus.AddHandler("sql", RemoveUserRole)
+	bus.AddHandler("sql", GetUserRoles)
+	bus.AddHandler("sql", AddTeamRole)
+	bus.AddHandler("sql", RemoveTeamRole)
+	bus.AddHandler("sql", GetTeamRoles)
+	bus.AddHandler("sql", GetUserPermissions)
+}
+
+func CreateRole(cmd *models.CreateRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if err := checkRoleNameTaken(sess, cmd.OrgId, 0, cmd.Name); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		role := models.Role{
+			OrgId:       cmd.OrgId,
+			Name:        cmd.Name,
+			Description: cmd.Description,
+			Created:     now,
+			Updated:     now,
+		}
+
+		if _, err := sess.Insert(&role); err != nil {
+			return err
+		}
+
+		if err := savePermissions(sess, role.Id, cmd.Permissions, now); err != nil {
+			return err
+		}
+
+		result, err := getRoleById(sess, role.Id, role.OrgId)
+		cmd.Result = result
+		return err
+	})
+}
+
+func UpdateRole(cmd *models.UpdateRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getRole(sess, cmd.Id, cmd.OrgId); err != nil {
+			return err
+		}
+
+		if err := checkRoleNameTaken(sess, cmd.OrgId, cmd.Id, cmd.Name); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		role := models.Role{
+			Name:        cmd.Name,
+			Description: cmd.Description,
+			Updated:     now,
+		}
+
+		if _, err := sess.ID(cmd.Id).Cols("name", "description", "updated").Update(&role); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM permission WHERE role_id = ?", cmd.Id); err != nil {
+			return err
+		}
+
+		if err := savePermissions(sess, cmd.Id, cmd.Permissions, now); err != nil {
+			return err
+		}
+
+		result, err := getRoleById(sess, cmd.Id, cmd.OrgId)
+		cmd.Result = result
+		return err
+	})
+}
+
+func DeleteRole(cmd *models.DeleteRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getRole(sess, cmd.Id, cmd.OrgId); err != nil {
+			return err
+		}
+
+		deletes := []string{
+			"DELETE FROM permission WHERE role_id = ?",
+			"DELETE FROM user_role WHERE role_id = ?",
+			"DELETE FROM team_role WHERE role_id = ?",
+			"DELETE FROM role WHERE id = ?",
+		}
+
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, cmd.Id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func GetRoles(query *models.GetRolesQuery) error {
+	roles := make([]*models.Role, 0)
+	if err := x.Where("org_id = ?", query.OrgId).Asc("name").Find(&roles); err != nil {
+		return err
+	}
+
+	result, err := rolesWithPermissions(newSession(), roles)
+	query.Result = result
+	return err
+}
+
+func GetRoleById(query *models.GetRoleByIdQuery) error {
+	result, err := getRoleById(newSession(), query.Id, query.OrgId)
+	query.Result = result
+	return err
+}
+
+func AddUserRole(cmd *models.AddUserRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getRole(sess, cmd.RoleId, cmd.OrgId); err != nil {
+			return err
+		}
+
+		if exists, err := sess.Where("org_id = ? AND user_id = ?", cmd.OrgId, cmd.UserId).Get(&models.OrgUser{}); err != nil {
+			return err
+		} else if !exists {
+			return models.ErrOrgUserNotFound
+		}
+
+		if exists, err := sess.Where("role_id = ? AND user_id = ?", cmd.RoleId, cmd.UserId).Get(&models.UserRole{}); err != nil {
+			return err
+		} else if exists {
+			return models.ErrRoleAlreadyAdded
+		}
+
+		_, err := sess.Insert(&models.UserRole{
+			OrgId:   cmd.OrgId,
+			RoleId:  cmd.RoleId,
+			UserId:  cmd.UserId,
+			Created: time.Now(),
+		})
+		return err
+	})
+}
+
+func RemoveUserRole(cmd *models.RemoveUserRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM user_role WHERE org_id = ? AND role_id = ? AND user_id = ?", cmd.OrgId, cmd.RoleId, cmd.UserId)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return models.ErrRoleAssignmentMissing
+		}
+
+		return nil
+	})
+}
+
+func GetUserRoles(query *models.GetUserRolesQuery) error {
+	roles := make([]*models.Role, 0)
+	err := x.SQL(`SELECT role.* FROM role
+		INNER JOIN user_role ON user_role.role_id = role.id
+		WHERE user_role.org_id = ? AND user_role.user_id = ?
+		ORDER BY role.name ASC`, query.OrgId, query.UserId).Find(&roles)
+	if err != nil {
+		return err
+	}
+
+	result, err := rolesWithPermissions(newSession(), roles)
+	query.Result = result
+	return err
+}
+
+func AddTeamRole(cmd *models.AddTeamRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getRole(sess, cmd.RoleId, cmd.OrgId); err != nil {
+			return err
+		}
+
+		if exists, err := sess.Where("org_id = ? AND id = ?", cmd.OrgId, cmd.TeamId).Get(&models.Team{}); err != nil {
+			return err
+		} else if !exists {
+			return models.ErrTeamNotFound
+		}
+
+		if exists, err := sess.Where("role_id = ? AND team_id = ?", cmd.RoleId, cmd.TeamId).Get(&models.TeamRole{}); err != nil {
+			return err
+		} else if exists {
+			return models.ErrRoleAlreadyAdded
+		}
+
+		_, err := sess.Insert(&models.TeamRole{
+			OrgId:   cmd.OrgId,
+			RoleId:  cmd.RoleId,
+			TeamId:  cmd.TeamId,
+			Created: time.Now(),
+		})
+		return err
+	})
+}
+
+func RemoveTeamRole(cmd *models.RemoveTeamRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM team_role WHERE org_id = ? AND role_id = ? AND team_id = ?", cmd.OrgId, cmd.RoleId, cmd.TeamId)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return models.ErrRoleAssignmentMissing
+		}
+
+		return nil
+	})
+}
+
+func GetTeamRoles(query *models.GetTeamRolesQuery) error {
+	roles := make([]*models.Role, 0)
+	err := x.SQL(`SELECT role.* FROM role
+		INNER JOIN team_role ON team_role.role_id = role.id
+		WHERE team_role.org_id = ? AND team_role.team_id = ?
+		ORDER BY role.name ASC`, query.OrgId, query.TeamId).Find(&roles)
+	if err != nil {
+		return err
+	}
+
+	result, err := rolesWithPermissions(newSession(), roles)
+	query.Result = result
+	return err
+}
+
+func GetUserPermissions(query *models.GetUserPermissionsQuery) error {
+	query.Result = make([]*models.Permission, 0)
+
+	rawSql := `SELECT permission.* FROM permission
+		INNER JOIN role ON role.id = permission.role_id
+		WHERE role.org_id = ? AND (
+			role.id IN (SELECT role_id FROM user_role WHERE org_id = ? AND user_id = ?)
+			OR role.id IN (
+				SELECT team_role.role_id FROM team_role
+				INNER JOIN team_member ON team_member.team_id = team_role.team_id
+				WHERE team_role.org_id = ? AND team_member.user_id = ?
+			)
+		)`
+
+	return x.SQL(rawSql, query.OrgId, query.OrgId, query.UserId, query.OrgId, query.UserId).Find(&query.Result)
+}
+
+func checkRoleNameTaken(sess *DBSession, orgId int64, id int64, name string) error {
+	var role models.Role
+	exists, err := sess.Where("org_id = ? AND name = ?", orgId, name).Get(&role)
+	if err != nil {
+		return err
+	}
+
+	if exists && role.Id != id {
+		return models.ErrRoleNameTaken
+	}
+
+	return nil
+}
+
+func savePermissions(sess *DBSession, roleId int64, permissions []*models.PermissionDTO, now time.Time) error {
+	for _, p := range permissions {
+		permission := models.Permission{
+			RoleId:  roleId,
+			Action:  p.Action,
+			Scope:   p.Scope,
+			Created: now,
+			Updated: now,
+		}
+
+		if _, err := sess.Insert(&permission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func getRole(sess *DBSession, id int64, orgId int64) (*models.Role, error) {
+	var role models.Role
+	exists, err := sess.Where("id = ? AND org_id = ?", id, orgId).Get(&role)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, models.ErrRoleNotFound
+	}
+
+	return &role, nil
+}
+
+func getRoleById(sess *DBSession, id int64, orgId int64) (*models.RoleDTO, error) {
+	role, err := getRole(sess, id, orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := rolesWithPermissions(sess, []*models.Role{role})
+	if err != nil {
+		return nil, err
+	}
+
+	return result[0], nil
+}
+
+func rolesWithPermissions(sess *DBSession, roles []*models.Role) ([]*models.RoleDTO, error) {
+	result := make([]*models.RoleDTO, 0, len(roles))
+	if len(roles) == 0 {
+		return result, nil
+	}
+
+	roleIds := make([]int64, len(roles))
+	for i, role := range roles {
+		roleIds[i] = role.Id
+	}
+
+	permissions := make([]*models.Permission, 0)
+	if err := sess.In("role_id", roleIds).Asc("action", "scope").Find(&permissions); err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[int64][]*models.PermissionDTO)
+	for _, p := range permissions {
+		byRole[p.RoleId] = append(byRole[p.RoleId], &models.PermissionDTO{Action: p.Action, Scope: p.Scope})
+	}
+
+	for _, role := range roles {
+		rolePermissions := byRole[role.Id]
+		if rolePermissions == nil {
+			rolePermissions = make([]*models.PermissionDTO, 0)
+		}
+
+		result = append(result, &models.RoleDTO{
+			Id:          role.Id,
+			OrgId:       role.OrgId,
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: rolePermissions,
+			Created:     role.Created,
+			Updated:     role.Updated,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessControlDataAccess(t *testing.T) {
+	t.Run("Testing access control data access", func(t *testing.T) {
+		InitTestDB(t)
+
+		userCmd := models.CreateUserCommand{Email: "user@test.com", Login: "user", Name: "user"}
+		require.NoError(t, CreateUser(context.Background(), &userCmd))
+		user := userCmd.Result
+
+		otherCmd := models.CreateUserCommand{Email: "other@test.com", Login: "other", Name: "other", OrgId: user.OrgId}
+		require.NoError(t, CreateUser(context.Background(), &otherCmd))
+		other := otherCmd.Result
+
+		teamCmd := models.CreateTeamCommand{Name: "ops", OrgId: user.OrgId}
+		require.NoError(t, CreateTeam(&teamCmd))
+		team := teamCmd.Result
+		require.NoError(t, AddTeamMember(&models.AddTeamMemberCommand{UserId: other.Id, OrgId: user.OrgId, TeamId: team.Id}))
+
+		createCmd := models.CreateRoleCommand{
+			Name:  "alert channel managers",
+			OrgId: user.OrgId,
+			Permissions: []*models.PermissionDTO{
+				{Action: "alert.notifications:write", Scope: "alert.notifications:*"},
+				{Action: "alert.notifications:read", Scope: "alert.notifications:*"},
+			},
+		}
+		require.NoError(t, CreateRole(&createCmd))
+		role := createCmd.Result
+
+		t.Run("Should create role with permissions", func(t *testing.T) {
+			assert.Equal(t, "alert channel managers", role.Name)
+			require.Len(t, role.Permissions, 2)
+			assert.Equal(t, "alert.notifications:read", role.Permissions[0].Action)
+		})
+
+		t.Run("Should not allow duplicate names", func(t *testing.T) {
+			cmd := models.CreateRoleCommand{Name: "alert channel managers", OrgId: user.OrgId}
+			assert.Equal(t, models.ErrRoleNameTaken, CreateRole(&cmd))
+		})
+
+		t.Run("Should replace permissions on update", func(t *testing.T) {
+			cmd := models.UpdateRoleCommand{
+				Id:          role.Id,
+				OrgId:       user.OrgId,
+				Name:        "alert channel writers",
+				Permissions: []*models.PermissionDTO{{Action: "alert.notifications:write", Scope: "alert.notifications:*"}},
+			}
+			require.NoError(t, UpdateRole(&cmd))
+			assert.Equal(t, "alert channel writers", cmd.Result.Name)
+			assert.Len(t, cmd.Result.Permissions, 1)
+
+			query := models.GetRolesQuery{OrgId: user.OrgId}
+			require.NoError(t, GetRoles(&query))
+			require.Len(t, query.Result, 1)
+			assert.Len(t, query.Result[0].Permissions, 1)
+		})
+
+		t.Run("Should not find role in other org", func(t *testing.T) {
+			query := models.GetRoleByIdQuery{Id: role.Id, OrgId: user.OrgId + 1}
+			assert.Equal(t, models.ErrRoleNotFound, GetRoleById(&query))
+		})
+
+		t.Run("Should get permissions of user roles", func(t *testing.T) {
+			require.NoError(t, AddUserRole(&models.AddUserRoleCommand{RoleId: role.Id, UserId: user.Id, OrgId: user.OrgId}))
+			assert.Equal(t, models.ErrRoleAlreadyAdded, AddUserRole(&models.AddUserRoleCommand{RoleId: role.Id, UserId: user.Id, OrgId: user.OrgId}))
+
+			rolesQuery := models.GetUserRolesQuery{UserId: user.Id, OrgId: user.OrgId}
+			require.NoError(t, GetUserRoles(&rolesQuery))
+			assert.Len(t, rolesQuery.Result, 1)
+
+			query := models.GetUserPermissionsQuery{UserId: user.Id, OrgId: user.OrgId}
+			require.NoError(t, GetUserPermissions(&query))
+			require.Len(t, query.Result, 1)
+			assert.Equal(t, "alert.notifications:write", query.Result[0].Action)
+
+			otherOrgQuery := models.GetUserPermissionsQuery{UserId: user.Id, OrgId: user.OrgId + 1}
+			require.NoError(t, GetUserPermissions(&otherOrgQuery))
+			assert.Len(t, otherOrgQuery.Result, 0)
+
+			require.NoError(t, RemoveUserRole(&models.RemoveUserRoleCommand{RoleId: role.Id, UserId: user.Id, OrgId: user.OrgId}))
+			assert.Equal(t, models.ErrRoleAssignmentMissing, RemoveUserRole(&models.RemoveUserRoleCommand{RoleId: role.Id, UserId: user.Id, OrgId: user.OrgId}))
+		})
+
+		t.Run("Should get permissions of team roles", func(t *testing.T) {
+			require.NoError(t, AddTeamRole(&models.AddTeamRoleCommand{RoleId: role.Id, TeamId: team.Id, OrgId: user.OrgId}))
+
+			query := models.GetUserPermissionsQuery{UserId: other.Id, OrgId: user.OrgId}
+			require.NoError(t, GetUserPermissions(&query))
+			assert.Len(t, query.Result, 1)
+
+			userQuery := models.GetUserPermissionsQuery{UserId: user.Id, OrgId: user.OrgId}
+			require.NoError(t, GetUserPermissions(&userQuery))
+			assert.Len(t, userQuery.Result, 0)
+		})
+
+		t.Run("Should not add role to user of other org", func(t *testing.T) {
+			cmd := models.AddUserRoleCommand{RoleId: role.Id, UserId: 999, OrgId: user.OrgId}
+			assert.Equal(t, models.ErrOrgUserNotFound, AddUserRole(&cmd))
+		})
+
+		t.Run("Should remove assignments with role", func(t *testing.T) {
+			require.NoError(t, DeleteRole(&models.DeleteRoleCommand{Id: role.Id, OrgId: user.OrgId}))
+
+			query := models.GetTeamRolesQuery{TeamId: team.Id, OrgId: user.OrgId}
+			require.NoError(t, GetTeamRoles(&query))
+			assert.Len(t, query.Result, 0)
+
+			permissionsQuery := models.GetUserPermissionsQuery{UserId: other.Id, OrgId: user.OrgId}
+			require.NoError(t, GetUserPermissions(&permissionsQuery))
+			assert.Len(t, permissionsQuery.Result, 0)
+		})
+	})
+}
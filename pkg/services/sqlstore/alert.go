@@ -68,6 +68,14 @@ func deleteAlertByIdInternal(alertId int64, reason string, sess *DBSession) erro
 		return err
 	}
 
+	if _, err := sess.Exec("DELETE FROM alert_instance WHERE alert_id = ?", alertId); err != nil {
+		return err
+	}
+
+	if _, err := sess.Exec("DELETE FROM alert_provisioning WHERE alert_id = ?", alertId); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -173,6 +181,20 @@ func SaveAlerts(cmd *models.SaveAlertsCommand) error {
 			return err
 		}
 
+		provisionedAlerts, err := getProvisionedAlerts(cmd.DashboardId, sess)
+		if err != nil {
+			return err
+		}
+
+		// provisioned alert rules can only be changed by the provisioning,
+		// the alert rules of the other panels are saved
+		existingAlerts = withoutProvisionedPanels(existingAlerts, provisionedAlerts)
+		alerts := withoutProvisionedPanels(cmd.Alerts, provisionedAlerts)
+		if len(alerts) != len(cmd.Alerts) {
+			sqlog.Warn("Ignoring alert rules of provisioned panels", "dashboardId", cmd.DashboardId, "ignored", len(cmd.Alerts)-len(alerts))
+		}
+		cmd.Alerts = alerts
+
 		if err := updateAlerts(existingAlerts, cmd, sess); err != nil {
 			return err
 		}
@@ -185,6 +207,29 @@ func SaveAlerts(cmd *models.SaveAlertsCommand) error {
 	})
 }
 
+func withoutProvisionedPanels(alerts []*models.Alert, provisionedAlerts []*models.Alert) []*models.Alert {
+	if len(provisionedAlerts) == 0 {
+		return alerts
+	}
+
+	result := make([]*models.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		provisioned := false
+		for _, p := range provisionedAlerts {
+			if alert.PanelId == p.PanelId {
+				provisioned = true
+				break
+			}
+		}
+
+		if !provisioned {
+			result = append(result, alert)
+		}
+	}
+
+	return result
+}
+
 func updateAlerts(existingAlerts []*models.Alert, cmd *models.SaveAlertsCommand, sess *DBSession) error {
 	for _, alert := range cmd.Alerts {
 		update := false
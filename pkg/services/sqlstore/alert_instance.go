@@ -0,0 +1,77 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", GetAlertInstances)
+	bus.AddHandler("sql", SaveAlertInstances)
+}
+
+func GetAlertInstances(query *models.GetAlertInstancesQuery) error {
+	instances := make([]*models.AlertInstance, 0)
+	err := x.Where("org_id = ? AND alert_id = ?", query.OrgId, query.AlertId).Asc("id").Find(&instances)
+	if err != nil {
+		return err
+	}
+
+	query.Result = instances
+	return nil
+}
+
+func SaveAlertInstances(cmd *models.SaveAlertInstancesCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		existing := make([]*models.AlertInstance, 0)
+		if err := sess.Where("org_id = ? AND alert_id = ?", cmd.OrgId, cmd.AlertId).Find(&existing); err != nil {
+			return err
+		}
+
+		existingByKey := make(map[string]*models.AlertInstance, len(existing))
+		for _, instance := range existing {
+			existingByKey[instance.InstanceKey] = instance
+		}
+
+		now := timeNow()
+		keep := make(map[string]bool, len(cmd.Instances))
+
+		for _, instance := range cmd.Instances {
+			instance.OrgId = cmd.OrgId
+			instance.AlertId = cmd.AlertId
+			instance.Updated = now
+			keep[instance.InstanceKey] = true
+
+			if instance.NewStateDate.IsZero() {
+				instance.NewStateDate = now
+			}
+
+			if prev, ok := existingByKey[instance.InstanceKey]; ok {
+				instance.Id = prev.Id
+				instance.Created = prev.Created
+
+				if _, err := sess.ID(instance.Id).AllCols().Update(instance); err != nil {
+					return err
+				}
+				continue
+			}
+
+			instance.Created = now
+			if _, err := sess.Insert(instance); err != nil {
+				return err
+			}
+		}
+
+		for _, instance := range existing {
+			if keep[instance.InstanceKey] {
+				continue
+			}
+
+			if _, err := sess.Exec("DELETE FROM alert_instance WHERE id = ?", instance.Id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
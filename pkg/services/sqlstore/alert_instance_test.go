@@ -0,0 +1,89 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlertInstanceDataAccess(t *testing.T) {
+	Convey("Testing alert instance data access", t, func() {
+		InitTestDB(t)
+
+		host1 := &models.AlertInstance{
+			InstanceKey: models.AlertInstanceKey("cpu", map[string]string{"host": "host1"}),
+			Metric:      "cpu",
+			Tags:        map[string]string{"host": "host1"},
+			State:       models.AlertStatePending,
+		}
+		host2 := &models.AlertInstance{
+			InstanceKey: models.AlertInstanceKey("cpu", map[string]string{"host": "host2"}),
+			Metric:      "cpu",
+			Tags:        map[string]string{"host": "host2"},
+			State:       models.AlertStateAlerting,
+		}
+
+		err := SaveAlertInstances(&models.SaveAlertInstancesCommand{
+			OrgId:     1,
+			AlertId:   1,
+			Instances: []*models.AlertInstance{host1, host2},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("Can read saved instances", func() {
+			query := &models.GetAlertInstancesQuery{OrgId: 1, AlertId: 1}
+			err := GetAlertInstances(query)
+			So(err, ShouldBeNil)
+
+			So(len(query.Result), ShouldEqual, 2)
+			So(query.Result[0].Metric, ShouldEqual, "cpu")
+			So(query.Result[0].Tags["host"], ShouldEqual, "host1")
+			So(query.Result[0].State, ShouldEqual, models.AlertStatePending)
+			So(query.Result[1].State, ShouldEqual, models.AlertStateAlerting)
+		})
+
+		Convey("Instances are scoped by alert", func() {
+			query := &models.GetAlertInstancesQuery{OrgId: 1, AlertId: 2}
+			err := GetAlertInstances(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+
+		Convey("Saving updates existing and removes missing instances", func() {
+			updated := &models.AlertInstance{
+				InstanceKey: host1.InstanceKey,
+				Metric:      "cpu",
+				Tags:        map[string]string{"host": "host1"},
+				State:       models.AlertStateAlerting,
+			}
+
+			err := SaveAlertInstances(&models.SaveAlertInstancesCommand{
+				OrgId:     1,
+				AlertId:   1,
+				Instances: []*models.AlertInstance{updated},
+			})
+			So(err, ShouldBeNil)
+
+			query := &models.GetAlertInstancesQuery{OrgId: 1, AlertId: 1}
+			err = GetAlertInstances(query)
+			So(err, ShouldBeNil)
+
+			So(len(query.Result), ShouldEqual, 1)
+			So(query.Result[0].Id, ShouldEqual, host1.Id)
+			So(query.Result[0].State, ShouldEqual, models.AlertStateAlerting)
+		})
+
+		Convey("Deleting the alert removes its instances", func() {
+			err := inTransaction(func(sess *DBSession) error {
+				return deleteAlertByIdInternal(1, "test", sess)
+			})
+			So(err, ShouldBeNil)
+
+			query := &models.GetAlertInstancesQuery{OrgId: 1, AlertId: 1}
+			err = GetAlertInstances(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+	})
+}
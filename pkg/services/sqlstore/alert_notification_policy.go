@@ -0,0 +1,62 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", SaveAlertNotificationPolicy)
+	bus.AddHandler("sql", DeleteAlertNotificationPolicy)
+	bus.AddHandler("sql", GetAlertNotificationPolicy)
+}
+
+func SaveAlertNotificationPolicy(cmd *models.SaveAlertNotificationPolicyCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		policy := &models.AlertNotificationPolicy{}
+		exists, err := sess.Where("org_id = ?", cmd.OrgId).Get(policy)
+		if err != nil {
+			return err
+		}
+
+		policy.Policy = cmd.Policy
+		policy.Updated = timeNow()
+
+		if exists {
+			if _, err := sess.ID(policy.Id).AllCols().Update(policy); err != nil {
+				return err
+			}
+		} else {
+			policy.OrgId = cmd.OrgId
+			policy.Created = policy.Updated
+			if _, err := sess.Insert(policy); err != nil {
+				return err
+			}
+		}
+
+		cmd.Result = policy
+		return nil
+	})
+}
+
+func DeleteAlertNotificationPolicy(cmd *models.DeleteAlertNotificationPolicyCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_notification_policy WHERE org_id = ?", cmd.OrgId)
+		return err
+	})
+}
+
+func GetAlertNotificationPolicy(query *models.GetAlertNotificationPolicyQuery) error {
+	policy := &models.AlertNotificationPolicy{}
+	exists, err := x.Where("org_id = ?", query.OrgId).Get(policy)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return models.ErrAlertNotificationPolicyNotFound
+	}
+
+	query.Result = policy
+	return nil
+}
@@ -0,0 +1,70 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlertNotificationPolicyDataAccess(t *testing.T) {
+	Convey("Testing alert notification policy data access", t, func() {
+		InitTestDB(t)
+
+		Convey("Get returns not found without policy", func() {
+			query := &models.GetAlertNotificationPolicyQuery{OrgId: 1}
+			err := GetAlertNotificationPolicy(query)
+			So(err, ShouldEqual, models.ErrAlertNotificationPolicyNotFound)
+		})
+
+		Convey("Given saved policy", func() {
+			cmd := &models.SaveAlertNotificationPolicyCommand{
+				OrgId: 1,
+				Policy: &models.NotificationPolicy{
+					NotificationUids: []string{"default"},
+					RepeatInterval:   "4h",
+					Routes: []*models.NotificationPolicy{
+						{Tags: map[string]string{"team": "payments"}, NotificationUids: []string{"payments-oncall"}},
+					},
+				},
+			}
+			err := SaveAlertNotificationPolicy(cmd)
+			So(err, ShouldBeNil)
+			So(cmd.Result.Id, ShouldBeGreaterThan, 0)
+
+			Convey("Can get policy of org", func() {
+				query := &models.GetAlertNotificationPolicyQuery{OrgId: 1}
+				err := GetAlertNotificationPolicy(query)
+				So(err, ShouldBeNil)
+				So(query.Result.Policy, ShouldResemble, cmd.Policy)
+
+				err = GetAlertNotificationPolicy(&models.GetAlertNotificationPolicyQuery{OrgId: 2})
+				So(err, ShouldEqual, models.ErrAlertNotificationPolicyNotFound)
+			})
+
+			Convey("Saving again replaces policy", func() {
+				update := &models.SaveAlertNotificationPolicyCommand{
+					OrgId:  1,
+					Policy: &models.NotificationPolicy{NotificationUids: []string{"other"}},
+				}
+				err := SaveAlertNotificationPolicy(update)
+				So(err, ShouldBeNil)
+				So(update.Result.Id, ShouldEqual, cmd.Result.Id)
+
+				query := &models.GetAlertNotificationPolicyQuery{OrgId: 1}
+				err = GetAlertNotificationPolicy(query)
+				So(err, ShouldBeNil)
+				So(query.Result.Policy.NotificationUids, ShouldResemble, []string{"other"})
+				So(query.Result.Policy.Routes, ShouldBeEmpty)
+			})
+
+			Convey("Can delete policy", func() {
+				err := DeleteAlertNotificationPolicy(&models.DeleteAlertNotificationPolicyCommand{OrgId: 1})
+				So(err, ShouldBeNil)
+
+				err = GetAlertNotificationPolicy(&models.GetAlertNotificationPolicyQuery{OrgId: 1})
+				So(err, ShouldEqual, models.ErrAlertNotificationPolicyNotFound)
+			})
+		})
+	})
+}
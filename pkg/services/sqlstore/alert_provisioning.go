@@ -0,0 +1,89 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", SaveProvisionedAlert)
+	bus.AddHandler("sql", DeleteProvisionedAlert)
+	bus.AddHandler("sql", GetProvisionedAlerts)
+}
+
+// SaveProvisionedAlert inserts or updates the alert rule of a dashboard panel
+// and marks it as provisioned.
+func SaveProvisionedAlert(cmd *models.SaveProvisionedAlertCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		existingAlerts := make([]*models.Alert, 0)
+		err := sess.Where("dashboard_id = ? AND panel_id = ?", cmd.Alert.DashboardId, cmd.Alert.PanelId).Find(&existingAlerts)
+		if err != nil {
+			return err
+		}
+
+		saveCmd := &models.SaveAlertsCommand{
+			DashboardId: cmd.Alert.DashboardId,
+			OrgId:       cmd.Alert.OrgId,
+			Alerts:      []*models.Alert{cmd.Alert},
+		}
+
+		if err := updateAlerts(existingAlerts, saveCmd, sess); err != nil {
+			return err
+		}
+
+		provisioning := cmd.AlertProvisioning
+		provisioning.AlertId = cmd.Alert.Id
+		if provisioning.Updated == 0 {
+			provisioning.Updated = timeNow().Unix()
+		}
+
+		existing := &models.AlertProvisioning{}
+		exists, err := sess.Where("alert_id = ?", cmd.Alert.Id).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			provisioning.Id = existing.Id
+			_, err = sess.ID(provisioning.Id).AllCols().Update(provisioning)
+			return err
+		}
+
+		_, err = sess.Insert(provisioning)
+		return err
+	})
+}
+
+// DeleteProvisionedAlert removes a provisioned alert rule.
+func DeleteProvisionedAlert(cmd *models.DeleteProvisionedAlertCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		return deleteAlertByIdInternal(cmd.AlertId, "Removed from provisioning", sess)
+	})
+}
+
+func GetProvisionedAlerts(query *models.GetProvisionedAlertsQuery) error {
+	alerts, err := getProvisionedAlerts(query.DashboardId, newSession())
+	if err != nil {
+		return err
+	}
+
+	query.Result = alerts
+	return nil
+}
+
+func getProvisionedAlerts(dashboardId int64, sess *DBSession) ([]*models.Alert, error) {
+	alerts := make([]*models.Alert, 0)
+
+	sess.Table("alert").
+		Join("INNER", "alert_provisioning", "alert_provisioning.alert_id = alert.id")
+
+	if dashboardId != 0 {
+		sess.Where("alert.dashboard_id = ?", dashboardId)
+	}
+
+	if err := sess.Cols("alert.*").Find(&alerts); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,121 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlertProvisioningDataAccess(t *testing.T) {
+	Convey("Testing alert provisioning data access", t, func() {
+		InitTestDB(t)
+
+		testDash := insertTestDashboard("dashboard with provisioned alerts", 1, 0, false, "alert")
+
+		provisionedAlert := &models.Alert{
+			DashboardId: testDash.Id,
+			PanelId:     1,
+			OrgId:       1,
+			Name:        "Provisioned",
+			Settings:    simplejson.New(),
+		}
+
+		err := SaveProvisionedAlert(&models.SaveProvisionedAlertCommand{
+			Alert:             provisionedAlert,
+			AlertProvisioning: &models.AlertProvisioning{Name: "/etc/grafana/provisioning/alerts/sample.yaml"},
+		})
+		So(err, ShouldBeNil)
+		So(provisionedAlert.Id, ShouldBeGreaterThan, 0)
+
+		Convey("Can read provisioned alerts", func() {
+			query := &models.GetProvisionedAlertsQuery{}
+			err := GetProvisionedAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+			So(query.Result[0].Id, ShouldEqual, provisionedAlert.Id)
+			So(query.Result[0].Name, ShouldEqual, "Provisioned")
+
+			query = &models.GetProvisionedAlertsQuery{DashboardId: testDash.Id + 1}
+			err = GetProvisionedAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+
+		Convey("Saving again updates the alert", func() {
+			err := SaveProvisionedAlert(&models.SaveProvisionedAlertCommand{
+				Alert: &models.Alert{
+					DashboardId: testDash.Id,
+					PanelId:     1,
+					OrgId:       1,
+					Name:        "Provisioned and updated",
+					Settings:    simplejson.New(),
+				},
+				AlertProvisioning: &models.AlertProvisioning{Name: "/etc/grafana/provisioning/alerts/sample.yaml"},
+			})
+			So(err, ShouldBeNil)
+
+			query := &models.GetProvisionedAlertsQuery{}
+			err = GetProvisionedAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+			So(query.Result[0].Id, ShouldEqual, provisionedAlert.Id)
+			So(query.Result[0].Name, ShouldEqual, "Provisioned and updated")
+		})
+
+		Convey("Saving the dashboard alerts does not change provisioned alerts", func() {
+			err := SaveAlerts(&models.SaveAlertsCommand{
+				DashboardId: testDash.Id,
+				OrgId:       1,
+				Alerts: []*models.Alert{
+					{DashboardId: testDash.Id, PanelId: 1, OrgId: 1, Name: "From dashboard", Settings: simplejson.New()},
+					{DashboardId: testDash.Id, PanelId: 2, OrgId: 1, Name: "Other panel", Settings: simplejson.New()},
+				},
+			})
+			So(err, ShouldBeNil)
+
+			alerts, err := GetAlertsByDashboardId2(testDash.Id, newSession())
+			So(err, ShouldBeNil)
+			So(len(alerts), ShouldEqual, 2)
+			So(alerts[0].Name, ShouldEqual, "Provisioned")
+			So(alerts[1].Name, ShouldEqual, "Other panel")
+
+			err = SaveAlerts(&models.SaveAlertsCommand{DashboardId: testDash.Id, OrgId: 1})
+			So(err, ShouldBeNil)
+
+			alerts, err = GetAlertsByDashboardId2(testDash.Id, newSession())
+			So(err, ShouldBeNil)
+			So(len(alerts), ShouldEqual, 1)
+			So(alerts[0].Name, ShouldEqual, "Provisioned")
+		})
+
+		Convey("Deleting the dashboard deletes provisioned alerts", func() {
+			err := DeleteDashboard(&models.DeleteDashboardCommand{Id: testDash.Id, OrgId: 1})
+			So(err, ShouldBeNil)
+
+			query := &models.GetProvisionedAlertsQuery{}
+			err = GetProvisionedAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+
+			count, err := x.Table("alert_provisioning").Count()
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+		})
+
+		Convey("Can delete provisioned alert", func() {
+			err := DeleteProvisionedAlert(&models.DeleteProvisionedAlertCommand{AlertId: provisionedAlert.Id})
+			So(err, ShouldBeNil)
+
+			query := &models.GetProvisionedAlertsQuery{}
+			err = GetProvisionedAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+
+			alerts, err := GetAlertsByDashboardId2(testDash.Id, newSession())
+			So(err, ShouldBeNil)
+			So(len(alerts), ShouldEqual, 0)
+		})
+	})
+}
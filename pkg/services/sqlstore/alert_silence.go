@@ -0,0 +1,168 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/robfig/cron/v3"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateAlertSilence)
+	bus.AddHandler("sql", UpdateAlertSilence)
+	bus.AddHandler("sql", DeleteAlertSilence)
+	bus.AddHandler("sql", GetAlertSilenceById)
+	bus.AddHandler("sql", GetAlertSilences)
+}
+
+func CreateAlertSilence(cmd *models.CreateAlertSilenceCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		repeatDuration, err := validateAlertSilence(cmd.StartsAt, cmd.EndsAt, cmd.Cron, cmd.RepeatDuration)
+		if err != nil {
+			return err
+		}
+
+		silence := &models.AlertSilence{
+			OrgId:          cmd.OrgId,
+			RuleName:       cmd.RuleName,
+			DashboardUid:   cmd.DashboardUid,
+			FolderUid:      cmd.FolderUid,
+			Tags:           cmd.Tags,
+			StartsAt:       cmd.StartsAt,
+			EndsAt:         cmd.EndsAt,
+			Cron:           cmd.Cron,
+			RepeatDuration: repeatDuration,
+			Comment:        cmd.Comment,
+			CreatedBy:      cmd.CreatedBy,
+			Created:        timeNow(),
+			Updated:        timeNow(),
+		}
+
+		if !silence.HasMatchers() {
+			return models.ErrAlertSilenceMissingMatcher
+		}
+
+		if _, err := sess.Insert(silence); err != nil {
+			return err
+		}
+
+		cmd.Result = silence
+		return nil
+	})
+}
+
+func UpdateAlertSilence(cmd *models.UpdateAlertSilenceCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		repeatDuration, err := validateAlertSilence(cmd.StartsAt, cmd.EndsAt, cmd.Cron, cmd.RepeatDuration)
+		if err != nil {
+			return err
+		}
+
+		silence := &models.AlertSilence{}
+		exists, err := sess.Where("id = ? AND org_id = ?", cmd.Id, cmd.OrgId).Get(silence)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return models.ErrAlertSilenceNotFound
+		}
+
+		silence.RuleName = cmd.RuleName
+		silence.DashboardUid = cmd.DashboardUid
+		silence.FolderUid = cmd.FolderUid
+		silence.Tags = cmd.Tags
+		silence.StartsAt = cmd.StartsAt
+		silence.EndsAt = cmd.EndsAt
+		silence.Cron = cmd.Cron
+		silence.RepeatDuration = repeatDuration
+		silence.Comment = cmd.Comment
+		silence.Updated = timeNow()
+
+		if !silence.HasMatchers() {
+			return models.ErrAlertSilenceMissingMatcher
+		}
+
+		if _, err := sess.ID(silence.Id).AllCols().Update(silence); err != nil {
+			return err
+		}
+
+		cmd.Result = silence
+		return nil
+	})
+}
+
+// validateAlertSilence validates the period and schedule of a silence and
+// returns its parsed repeat duration.
+func validateAlertSilence(startsAt, endsAt time.Time, cronSpec string, rawRepeatDuration string) (time.Duration, error) {
+	if !endsAt.After(startsAt) {
+		return 0, models.ErrAlertSilenceInvalidPeriod
+	}
+
+	if cronSpec == "" {
+		return 0, nil
+	}
+
+	if _, err := cron.ParseStandard(cronSpec); err != nil {
+		return 0, models.ErrAlertSilenceInvalidCron
+	}
+
+	if rawRepeatDuration == "" {
+		return 0, models.ErrAlertSilenceMissingRepeat
+	}
+
+	repeatDuration, err := time.ParseDuration(rawRepeatDuration)
+	if err != nil {
+		return 0, err
+	}
+
+	if repeatDuration <= 0 {
+		return 0, models.ErrAlertSilenceMissingRepeat
+	}
+
+	return repeatDuration, nil
+}
+
+func DeleteAlertSilence(cmd *models.DeleteAlertSilenceCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_silence WHERE id = ? AND org_id = ?", cmd.Id, cmd.OrgId)
+		return err
+	})
+}
+
+func GetAlertSilenceById(query *models.GetAlertSilenceByIdQuery) error {
+	silence := &models.AlertSilence{}
+	exists, err := x.Where("id = ? AND org_id = ?", query.Id, query.OrgId).Get(silence)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return models.ErrAlertSilenceNotFound
+	}
+
+	query.Result = silence
+	return nil
+}
+
+func GetAlertSilences(query *models.GetAlertSilencesQuery) error {
+	silences := make([]*models.AlertSilence, 0)
+
+	if err := x.Where("org_id = ?", query.OrgId).Asc("id").Find(&silences); err != nil {
+		return err
+	}
+
+	if query.ActiveAt.IsZero() {
+		query.Result = silences
+		return nil
+	}
+
+	query.Result = make([]*models.AlertSilence, 0, len(silences))
+	for _, silence := range silences {
+		if !silence.StartsAt.After(query.ActiveAt) && silence.EndsAt.After(query.ActiveAt) {
+			query.Result = append(query.Result, silence)
+		}
+	}
+	return nil
+}
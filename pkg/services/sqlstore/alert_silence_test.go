@@ -0,0 +1,121 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlertSilenceDataAccess(t *testing.T) {
+	Convey("Testing alert silence data access", t, func() {
+		InitTestDB(t)
+
+		now := time.Now()
+		cmd := &models.CreateAlertSilenceCommand{
+			OrgId:     1,
+			RuleName:  "High CPU",
+			Tags:      map[string]string{"team": "backend"},
+			StartsAt:  now.Add(-time.Hour),
+			EndsAt:    now.Add(time.Hour),
+			Comment:   "deploy",
+			CreatedBy: 1,
+		}
+		err := CreateAlertSilence(cmd)
+		So(err, ShouldBeNil)
+		So(cmd.Result.Id, ShouldBeGreaterThan, 0)
+
+		Convey("Can get silence by id", func() {
+			query := &models.GetAlertSilenceByIdQuery{Id: cmd.Result.Id, OrgId: 1}
+			err := GetAlertSilenceById(query)
+			So(err, ShouldBeNil)
+			So(query.Result.RuleName, ShouldEqual, "High CPU")
+			So(query.Result.Tags, ShouldResemble, map[string]string{"team": "backend"})
+			So(query.Result.Comment, ShouldEqual, "deploy")
+
+			query = &models.GetAlertSilenceByIdQuery{Id: cmd.Result.Id, OrgId: 2}
+			err = GetAlertSilenceById(query)
+			So(err, ShouldEqual, models.ErrAlertSilenceNotFound)
+		})
+
+		Convey("Can list active silences", func() {
+			err := CreateAlertSilence(&models.CreateAlertSilenceCommand{
+				OrgId:    1,
+				RuleName: "Disk full",
+				StartsAt: now.Add(2 * time.Hour),
+				EndsAt:   now.Add(3 * time.Hour),
+			})
+			So(err, ShouldBeNil)
+
+			query := &models.GetAlertSilencesQuery{OrgId: 1}
+			So(GetAlertSilences(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+
+			query = &models.GetAlertSilencesQuery{OrgId: 1, ActiveAt: now}
+			So(GetAlertSilences(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+			So(query.Result[0].Id, ShouldEqual, cmd.Result.Id)
+		})
+
+		Convey("Can update silence", func() {
+			updateCmd := &models.UpdateAlertSilenceCommand{
+				Id:             cmd.Result.Id,
+				OrgId:          1,
+				DashboardUid:   "abc",
+				StartsAt:       cmd.StartsAt,
+				EndsAt:         cmd.EndsAt,
+				Cron:           "0 2 * * *",
+				RepeatDuration: "2h",
+			}
+			err := UpdateAlertSilence(updateCmd)
+			So(err, ShouldBeNil)
+
+			query := &models.GetAlertSilenceByIdQuery{Id: cmd.Result.Id, OrgId: 1}
+			So(GetAlertSilenceById(query), ShouldBeNil)
+			So(query.Result.RuleName, ShouldEqual, "")
+			So(query.Result.DashboardUid, ShouldEqual, "abc")
+			So(query.Result.Cron, ShouldEqual, "0 2 * * *")
+			So(query.Result.RepeatDuration, ShouldEqual, 2*time.Hour)
+		})
+
+		Convey("Should not update silence of other org", func() {
+			err := UpdateAlertSilence(&models.UpdateAlertSilenceCommand{
+				Id:       cmd.Result.Id,
+				OrgId:    2,
+				RuleName: "High CPU",
+				StartsAt: cmd.StartsAt,
+				EndsAt:   cmd.EndsAt,
+			})
+			So(err, ShouldEqual, models.ErrAlertSilenceNotFound)
+		})
+
+		Convey("Should validate period and schedule", func() {
+			err := CreateAlertSilence(&models.CreateAlertSilenceCommand{OrgId: 1, StartsAt: now, EndsAt: now})
+			So(err, ShouldEqual, models.ErrAlertSilenceInvalidPeriod)
+
+			err = CreateAlertSilence(&models.CreateAlertSilenceCommand{OrgId: 1, StartsAt: now, EndsAt: now.Add(time.Hour), Cron: "0 2 * * *"})
+			So(err, ShouldEqual, models.ErrAlertSilenceMissingRepeat)
+
+			err = CreateAlertSilence(&models.CreateAlertSilenceCommand{OrgId: 1, StartsAt: now, EndsAt: now.Add(time.Hour), Cron: "not a cron", RepeatDuration: "1h"})
+			So(err, ShouldEqual, models.ErrAlertSilenceInvalidCron)
+		})
+
+		Convey("Should require a matcher", func() {
+			err := CreateAlertSilence(&models.CreateAlertSilenceCommand{OrgId: 1, StartsAt: now, EndsAt: now.Add(time.Hour)})
+			So(err, ShouldEqual, models.ErrAlertSilenceMissingMatcher)
+
+			err = UpdateAlertSilence(&models.UpdateAlertSilenceCommand{Id: cmd.Result.Id, OrgId: 1, StartsAt: now, EndsAt: now.Add(time.Hour)})
+			So(err, ShouldEqual, models.ErrAlertSilenceMissingMatcher)
+		})
+
+		Convey("Can delete silence", func() {
+			err := DeleteAlertSilence(&models.DeleteAlertSilenceCommand{Id: cmd.Result.Id, OrgId: 1})
+			So(err, ShouldBeNil)
+
+			query := &models.GetAlertSilencesQuery{OrgId: 1}
+			So(GetAlertSilences(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 0)
+		})
+	})
+}
@@ -0,0 +1,91 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const defaultAlertStateHistoryPerPage = 100
+
+func init() {
+	bus.AddHandler("sql", SaveAlertStateHistory)
+	bus.AddHandler("sql", GetAlertStateHistory)
+	bus.AddHandler("sql", DeleteExpiredAlertStateHistory)
+}
+
+func SaveAlertStateHistory(cmd *models.SaveAlertStateHistoryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if cmd.History.Notifications == nil {
+			cmd.History.Notifications = models.AlertStateHistoryNotifications{}
+		}
+
+		_, err := sess.Insert(cmd.History)
+		return err
+	})
+}
+
+func GetAlertStateHistory(query *models.GetAlertStateHistoryQuery) error {
+	if query.PerPage <= 0 {
+		query.PerPage = defaultAlertStateHistoryPerPage
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	filter := SqlBuilder{}
+	filter.Write(" FROM alert_state_history")
+	if query.User != nil && query.User.OrgRole != models.ROLE_ADMIN {
+		filter.Write(" INNER JOIN dashboard ON dashboard.id = alert_state_history.dashboard_id")
+	}
+
+	filter.Write(" WHERE alert_state_history.org_id = ?", query.OrgId)
+	if query.AlertId != 0 {
+		filter.Write(" AND alert_state_history.alert_id = ?", query.AlertId)
+	}
+	if query.From > 0 {
+		filter.Write(" AND alert_state_history.epoch >= ?", query.From)
+	}
+	if query.To > 0 {
+		filter.Write(" AND alert_state_history.epoch <= ?", query.To)
+	}
+	if query.State != "" {
+		filter.Write(" AND alert_state_history.new_state = ?", query.State)
+	}
+	if query.User != nil {
+		filter.writeDashboardPermissionFilter(query.User, models.PERMISSION_VIEW)
+	}
+
+	var count int64
+	if _, err := x.SQL("SELECT COUNT(*)"+filter.GetSqlString(), filter.params...).Get(&count); err != nil {
+		return err
+	}
+
+	offset := query.PerPage * (query.Page - 1)
+	sql := "SELECT alert_state_history.*" + filter.GetSqlString() +
+		" ORDER BY alert_state_history.epoch DESC, alert_state_history.id DESC" + dialect.LimitOffset(int64(query.PerPage), int64(offset))
+
+	history := make([]*models.AlertStateHistory, 0)
+	if err := x.SQL(sql, filter.params...).Find(&history); err != nil {
+		return err
+	}
+
+	query.Result = models.AlertStateHistoryResult{
+		TotalCount: count,
+		History:    history,
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	}
+	return nil
+}
+
+func DeleteExpiredAlertStateHistory(cmd *models.DeleteExpiredAlertStateHistoryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_state_history WHERE epoch < ?", cmd.OlderThan)
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = res.RowsAffected()
+		return err
+	})
+}
@@ -0,0 +1,101 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlertStateHistoryDataAccess(t *testing.T) {
+	Convey("Testing alert state history data access", t, func() {
+		InitTestDB(t)
+
+		save := func(orgID, alertID int64, state models.AlertStateType, epoch int64) {
+			err := SaveAlertStateHistory(&models.SaveAlertStateHistoryCommand{
+				History: &models.AlertStateHistory{
+					OrgId:       orgID,
+					AlertId:     alertID,
+					RuleName:    "rule",
+					PrevState:   models.AlertStateOK,
+					NewState:    state,
+					EvalMatches: simplejson.NewFromAny([]interface{}{map[string]interface{}{"metric": "cpu", "value": 95}}),
+					ChangedInstances: simplejson.NewFromAny([]interface{}{
+						map[string]interface{}{"metric": "cpu", "state": "alerting", "prevState": "ok"},
+					}),
+					Notifications: models.AlertStateHistoryNotifications{
+						{Uid: "oncall", Type: "pagerduty", Sent: true},
+					},
+					Epoch: epoch,
+				},
+			})
+			So(err, ShouldBeNil)
+		}
+
+		save(1, 1, models.AlertStateAlerting, 1000)
+		save(1, 1, models.AlertStateOK, 2000)
+		save(1, 2, models.AlertStateAlerting, 3000)
+		save(2, 3, models.AlertStateAlerting, 4000)
+
+		Convey("Can get org history most recent first", func() {
+			query := &models.GetAlertStateHistoryQuery{OrgId: 1}
+			err := GetAlertStateHistory(query)
+			So(err, ShouldBeNil)
+			So(query.Result.TotalCount, ShouldEqual, 3)
+			So(len(query.Result.History), ShouldEqual, 3)
+			So(query.Result.History[0].AlertId, ShouldEqual, 2)
+			So(query.Result.History[2].Notifications[0].Uid, ShouldEqual, "oncall")
+			So(query.Result.History[2].EvalMatches.GetIndex(0).Get("metric").MustString(), ShouldEqual, "cpu")
+			So(query.Result.History[2].ChangedInstances.GetIndex(0).Get("state").MustString(), ShouldEqual, "alerting")
+		})
+
+		Convey("Can page and filter history", func() {
+			query := &models.GetAlertStateHistoryQuery{OrgId: 1, AlertId: 1, Page: 2, PerPage: 1}
+			err := GetAlertStateHistory(query)
+			So(err, ShouldBeNil)
+			So(query.Result.TotalCount, ShouldEqual, 2)
+			So(len(query.Result.History), ShouldEqual, 1)
+			So(query.Result.History[0].Epoch, ShouldEqual, 1000)
+
+			query = &models.GetAlertStateHistoryQuery{OrgId: 1, State: models.AlertStateAlerting, From: 2000}
+			err = GetAlertStateHistory(query)
+			So(err, ShouldBeNil)
+			So(query.Result.TotalCount, ShouldEqual, 1)
+			So(query.Result.History[0].Epoch, ShouldEqual, 3000)
+		})
+
+		Convey("Filters history by dashboard permissions of user", func() {
+			dash := insertTestDashboard("alerts", 1, 0, false)
+			err := SaveAlertStateHistory(&models.SaveAlertStateHistoryCommand{
+				History: &models.AlertStateHistory{OrgId: 1, AlertId: 4, DashboardId: dash.Id, NewState: models.AlertStateAlerting, Epoch: 5000},
+			})
+			So(err, ShouldBeNil)
+
+			viewer := &models.SignedInUser{OrgId: 1, UserId: 10, OrgRole: models.ROLE_VIEWER}
+			query := &models.GetAlertStateHistoryQuery{OrgId: 1, User: viewer}
+			err = GetAlertStateHistory(query)
+			So(err, ShouldBeNil)
+			So(query.Result.TotalCount, ShouldEqual, 1)
+			So(query.Result.History[0].AlertId, ShouldEqual, 4)
+
+			admin := &models.SignedInUser{OrgId: 1, UserId: 1, OrgRole: models.ROLE_ADMIN}
+			query = &models.GetAlertStateHistoryQuery{OrgId: 1, User: admin}
+			err = GetAlertStateHistory(query)
+			So(err, ShouldBeNil)
+			So(query.Result.TotalCount, ShouldEqual, 4)
+		})
+
+		Convey("Can delete expired history", func() {
+			cmd := &models.DeleteExpiredAlertStateHistoryCommand{OlderThan: 2500}
+			err := DeleteExpiredAlertStateHistory(cmd)
+			So(err, ShouldBeNil)
+			So(cmd.DeletedRows, ShouldEqual, 2)
+
+			query := &models.GetAlertStateHistoryQuery{OrgId: 1}
+			err = GetAlertStateHistory(query)
+			So(err, ShouldBeNil)
+			So(query.Result.TotalCount, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,59 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", SaveAlertingNodeHeartbeat)
+	bus.AddHandler("sql", GetAlertingNodes)
+	bus.AddHandler("sql", DeleteAlertingNode)
+	bus.AddHandler("sql", DeleteExpiredAlertingNodes)
+}
+
+func SaveAlertingNodeHeartbeat(cmd *models.SaveAlertingNodeHeartbeatCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		node := &models.AlertingNode{}
+		exists, err := sess.Where("node_id = ?", cmd.NodeId).Get(node)
+		if err != nil {
+			return err
+		}
+
+		node.NodeId = cmd.NodeId
+		node.Heartbeat = cmd.Heartbeat
+
+		if exists {
+			_, err = sess.ID(node.Id).Cols("heartbeat").Update(node)
+			return err
+		}
+
+		_, err = sess.Insert(node)
+		return err
+	})
+}
+
+func GetAlertingNodes(query *models.GetAlertingNodesQuery) error {
+	nodes := make([]*models.AlertingNode, 0)
+	if err := x.Where("heartbeat >= ?", query.ActiveSince).Asc("node_id").Find(&nodes); err != nil {
+		return err
+	}
+
+	query.Result = nodes
+	return nil
+}
+
+func DeleteAlertingNode(cmd *models.DeleteAlertingNodeCommand) error {
+	_, err := x.Where("node_id = ?", cmd.NodeId).Delete(&models.AlertingNode{})
+	return err
+}
+
+func DeleteExpiredAlertingNodes(cmd *models.DeleteExpiredAlertingNodesCommand) error {
+	deleted, err := x.Where("heartbeat < ?", cmd.OlderThan).Delete(&models.AlertingNode{})
+	if err != nil {
+		return err
+	}
+
+	cmd.DeletedRows = deleted
+	return nil
+}
@@ -0,0 +1,60 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAlertingNodeDataAccess(t *testing.T) {
+	Convey("Testing alerting node data access", t, func() {
+		InitTestDB(t)
+
+		for _, cmd := range []*models.SaveAlertingNodeHeartbeatCommand{
+			{NodeId: "grafana-b", Heartbeat: 100},
+			{NodeId: "grafana-a", Heartbeat: 100},
+			{NodeId: "grafana-c", Heartbeat: 50},
+		} {
+			err := SaveAlertingNodeHeartbeat(cmd)
+			So(err, ShouldBeNil)
+		}
+
+		Convey("Can get active nodes", func() {
+			query := &models.GetAlertingNodesQuery{ActiveSince: 90}
+			err := GetAlertingNodes(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+			So(query.Result[0].NodeId, ShouldEqual, "grafana-a")
+			So(query.Result[1].NodeId, ShouldEqual, "grafana-b")
+		})
+
+		Convey("Heartbeat of existing node updates it", func() {
+			err := SaveAlertingNodeHeartbeat(&models.SaveAlertingNodeHeartbeatCommand{NodeId: "grafana-c", Heartbeat: 110})
+			So(err, ShouldBeNil)
+
+			query := &models.GetAlertingNodesQuery{ActiveSince: 90}
+			err = GetAlertingNodes(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 3)
+			So(query.Result[2].Heartbeat, ShouldEqual, 110)
+		})
+
+		Convey("Can delete node", func() {
+			err := DeleteAlertingNode(&models.DeleteAlertingNodeCommand{NodeId: "grafana-a"})
+			So(err, ShouldBeNil)
+
+			query := &models.GetAlertingNodesQuery{}
+			err = GetAlertingNodes(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+		})
+
+		Convey("Can delete expired nodes", func() {
+			cmd := &models.DeleteExpiredAlertingNodesCommand{OlderThan: 90}
+			err := DeleteExpiredAlertingNodes(cmd)
+			So(err, ShouldBeNil)
+			So(cmd.DeletedRows, ShouldEqual, 1)
+		})
+	})
+}
@@ -16,11 +16,13 @@ func init() {
 	bus.AddHandler("sql", AddApiKey)
 }
 
+// GetApiKeys returns the api keys of an org, without the tokens of its
+// service accounts.
 func GetApiKeys(query *models.GetApiKeysQuery) error {
-	sess := x.Limit(100, 0).Where("org_id=? and ( expires IS NULL or expires >= ?)",
+	sess := x.Limit(100, 0).Where("org_id=? and service_account_id IS NULL and ( expires IS NULL or expires >= ?)",
 		query.OrgId, timeNow().Unix()).Asc("name")
 	if query.IncludeExpired {
-		sess = x.Limit(100, 0).Where("org_id=?", query.OrgId).Asc("name")
+		sess = x.Limit(100, 0).Where("org_id=? and service_account_id IS NULL", query.OrgId).Asc("name")
 	}
 
 	query.Result = make([]*models.ApiKey, 0)
@@ -59,6 +61,8 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 			Created: updated,
 			Updated: updated,
 			Expires: expires,
+
+			ServiceAccountId: cmd.ServiceAccountId,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
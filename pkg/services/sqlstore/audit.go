@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", AddAuditEntry)
+	bus.AddHandler("sql", SearchAuditEntries)
+	bus.AddHandler("sql", DeleteExpiredAuditEntries)
+}
+
+func AddAuditEntry(cmd *models.AddAuditEntryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if cmd.Entry.Created.IsZero() {
+			cmd.Entry.Created = time.Now()
+		}
+
+		_, err := sess.Insert(cmd.Entry)
+		return err
+	})
+}
+
+func SearchAuditEntries(query *models.SearchAuditEntriesQuery) error {
+	query.Result = models.SearchAuditEntriesQueryResult{
+		Entries: make([]*models.AuditEntryDTO, 0),
+	}
+
+	whereConditions := make([]string, 0)
+	whereParams := make([]interface{}, 0)
+
+	if query.OrgId > 0 {
+		whereConditions = append(whereConditions, "org_id = ?")
+		whereParams = append(whereParams, query.OrgId)
+	}
+
+	if query.ActorId > 0 {
+		whereConditions = append(whereConditions, "actor_id = ?")
+		whereParams = append(whereParams, query.ActorId)
+	}
+
+	if query.ActorLogin != "" {
+		whereConditions = append(whereConditions, "actor_login = ?")
+		whereParams = append(whereParams, query.ActorLogin)
+	}
+
+	if query.Action != "" {
+		whereConditions = append(whereConditions, "action = ?")
+		whereParams = append(whereParams, query.Action)
+	}
+
+	if query.ResourceType != "" {
+		whereConditions = append(whereConditions, "resource_type = ?")
+		whereParams = append(whereParams, query.ResourceType)
+	}
+
+	if query.ResourceId != "" {
+		whereConditions = append(whereConditions, "resource_id = ?")
+		whereParams = append(whereParams, query.ResourceId)
+	}
+
+	if !query.From.IsZero() {
+		whereConditions = append(whereConditions, "created >= ?")
+		whereParams = append(whereParams, query.From)
+	}
+
+	if !query.To.IsZero() {
+		whereConditions = append(whereConditions, "created <= ?")
+		whereParams = append(whereParams, query.To)
+	}
+
+	where := strings.Join(whereConditions, " AND ")
+
+	sess := x.Table("audit_entry")
+	if where != "" {
+		sess.Where(where, whereParams...)
+	}
+
+	offset := query.Limit * (query.Page - 1)
+	sess.Limit(query.Limit, offset)
+	sess.Desc("created", "id")
+
+	entries := make([]*models.AuditEntry, 0)
+	if err := sess.Find(&entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		query.Result.Entries = append(query.Result.Entries, entry.ToDTO())
+	}
+
+	countSess := x.Table("audit_entry")
+	if where != "" {
+		countSess.Where(where, whereParams...)
+	}
+
+	count, err := countSess.Count(&models.AuditEntry{})
+	query.Result.TotalCount = count
+	return err
+}
+
+func DeleteExpiredAuditEntries(cmd *models.DeleteExpiredAuditEntriesCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM audit_entry WHERE created < ?", cmd.OlderThan)
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = res.RowsAffected()
+		return err
+	})
+}
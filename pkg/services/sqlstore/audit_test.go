@@ -0,0 +1,80 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditDataAccess(t *testing.T) {
+	InitTestDB(t)
+
+	now := time.Now()
+	add := func(orgId int64, login, action, resourceType, resourceId string, created time.Time) {
+		require.NoError(t, AddAuditEntry(&models.AddAuditEntryCommand{Entry: &models.AuditEntry{
+			OrgId:        orgId,
+			ActorLogin:   login,
+			Action:       action,
+			ResourceType: resourceType,
+			ResourceId:   resourceId,
+			AfterJson:    `{"name":"prod"}`,
+			Created:      created,
+		}}))
+	}
+
+	add(1, "admin", "create", "datasource", "1", now.Add(-3*time.Hour))
+	add(1, "admin", "update", "datasource", "1", now.Add(-2*time.Hour))
+	add(1, "editor", "create", "api-key", "4", now.Add(-time.Hour))
+	add(2, "admin", "create", "team", "2", now)
+
+	search := func(query models.SearchAuditEntriesQuery) models.SearchAuditEntriesQueryResult {
+		query.Page = 1
+		if query.Limit == 0 {
+			query.Limit = 100
+		}
+		require.NoError(t, SearchAuditEntries(&query))
+		return query.Result
+	}
+
+	t.Run("Should return most recent entries first", func(t *testing.T) {
+		result := search(models.SearchAuditEntriesQuery{})
+		require.Len(t, result.Entries, 4)
+		assert.Equal(t, int64(4), result.TotalCount)
+		assert.Equal(t, "team", result.Entries[0].ResourceType)
+		assert.JSONEq(t, `{"name":"prod"}`, string(result.Entries[0].After))
+		assert.Nil(t, result.Entries[0].Before)
+	})
+
+	t.Run("Should filter entries", func(t *testing.T) {
+		result := search(models.SearchAuditEntriesQuery{OrgId: 1, ResourceType: "datasource", ResourceId: "1"})
+		require.Len(t, result.Entries, 2)
+		assert.Equal(t, "update", result.Entries[0].Action)
+
+		result = search(models.SearchAuditEntriesQuery{ActorLogin: "admin", Action: "create"})
+		assert.Len(t, result.Entries, 2)
+
+		result = search(models.SearchAuditEntriesQuery{From: now.Add(-150 * time.Minute), To: now.Add(-30 * time.Minute)})
+		assert.Len(t, result.Entries, 2)
+	})
+
+	t.Run("Should page entries", func(t *testing.T) {
+		query := models.SearchAuditEntriesQuery{Page: 2, Limit: 3}
+		require.NoError(t, SearchAuditEntries(&query))
+		require.Len(t, query.Result.Entries, 1)
+		assert.Equal(t, int64(4), query.Result.TotalCount)
+		assert.Equal(t, "create", query.Result.Entries[0].Action)
+		assert.Equal(t, "datasource", query.Result.Entries[0].ResourceType)
+	})
+
+	t.Run("Should delete expired entries", func(t *testing.T) {
+		cmd := models.DeleteExpiredAuditEntriesCommand{OlderThan: now.Add(-90 * time.Minute)}
+		require.NoError(t, DeleteExpiredAuditEntries(&cmd))
+		assert.Equal(t, int64(2), cmd.DeletedRows)
+
+		result := search(models.SearchAuditEntriesQuery{})
+		assert.Len(t, result.Entries, 2)
+	})
+}
@@ -1,6 +1,7 @@
 package sqlstore
 
 import (
+	"context"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -962,13 +963,13 @@ func permissionScenario(desc string, canSave bool, fn dashboardPermissionScenari
 
 func callSaveWithResult(cmd models.SaveDashboardCommand) *models.Dashboard {
 	dto := toSaveDashboardDto(cmd)
-	res, _ := dashboards.NewService().SaveDashboard(&dto, false)
+	res, _ := dashboards.NewService().SaveDashboard(context.Background(), &dto, false)
 	return res
 }
 
 func callSaveWithError(cmd models.SaveDashboardCommand) error {
 	dto := toSaveDashboardDto(cmd)
-	_, err := dashboards.NewService().SaveDashboard(&dto, false)
+	_, err := dashboards.NewService().SaveDashboard(context.Background(), &dto, false)
 	return err
 }
 
@@ -992,7 +993,7 @@ func saveTestDashboard(title string, orgId int64, folderId int64) *models.Dashbo
 		},
 	}
 
-	res, err := dashboards.NewService().SaveDashboard(&dto, false)
+	res, err := dashboards.NewService().SaveDashboard(context.Background(), &dto, false)
 	So(err, ShouldBeNil)
 
 	return res
@@ -1018,7 +1019,7 @@ func saveTestFolder(title string, orgId int64) *models.Dashboard {
 		},
 	}
 
-	res, err := dashboards.NewService().SaveDashboard(&dto, false)
+	res, err := dashboards.NewService().SaveDashboard(context.Background(), &dto, false)
 	So(err, ShouldBeNil)
 
 	return res
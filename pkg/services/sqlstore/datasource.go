@@ -75,19 +75,32 @@ func DeleteDataSourceById(cmd *models.DeleteDataSourceByIdCommand) error {
 	return inTransaction(func(sess *DBSession) error {
 		var rawSql = "DELETE FROM data_source WHERE id=? and org_id=?"
 		result, err := sess.Exec(rawSql, cmd.Id, cmd.OrgId)
+		if err != nil {
+			return err
+		}
 		affected, _ := result.RowsAffected()
 		cmd.DeletedDatasourcesCount = affected
+
+		_, err = sess.Exec("DELETE FROM data_source_acl WHERE data_source_id=? and org_id=?", cmd.Id, cmd.OrgId)
 		return err
 	})
 }
 
 func DeleteDataSourceByName(cmd *models.DeleteDataSourceByNameCommand) error {
 	return inTransaction(func(sess *DBSession) error {
+		_, err := sess.Exec("DELETE FROM data_source_acl WHERE data_source_id IN (SELECT id FROM data_source WHERE name=? and org_id=?)", cmd.Name, cmd.OrgId)
+		if err != nil {
+			return err
+		}
+
 		var rawSql = "DELETE FROM data_source WHERE name=? and org_id=?"
 		result, err := sess.Exec(rawSql, cmd.Name, cmd.OrgId)
+		if err != nil {
+			return err
+		}
 		affected, _ := result.RowsAffected()
 		cmd.DeletedDatasourcesCount = affected
-		return err
+		return nil
 	})
 }
 
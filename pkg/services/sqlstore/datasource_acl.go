@@ -0,0 +1,224 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", EnableDataSourcePermissions)
+	bus.AddHandler("sql", DisableDataSourcePermissions)
+	bus.AddHandler("sql", AddDataSourcePermission)
+	bus.AddHandler("sql", RemoveDataSourcePermission)
+	bus.AddHandler("sql", GetDataSourcePermissions)
+	bus.AddHandler("sql", GetDataSourcePermissionForUser)
+	bus.AddHandler("sql", FilterDataSourcesByPermission)
+}
+
+func EnableDataSourcePermissions(cmd *models.EnableDataSourcePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		return setDataSourcePermissionsEnabled(sess, cmd.DataSourceId, cmd.OrgId, true)
+	})
+}
+
+func DisableDataSourcePermissions(cmd *models.DisableDataSourcePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if err := setDataSourcePermissionsEnabled(sess, cmd.DataSourceId, cmd.OrgId, false); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("DELETE FROM data_source_acl WHERE data_source_id = ? AND org_id = ?", cmd.DataSourceId, cmd.OrgId)
+		return err
+	})
+}
+
+func setDataSourcePermissionsEnabled(sess *DBSession, dataSourceId int64, orgId int64, enabled bool) error {
+	res, err := sess.Exec("UPDATE data_source SET permissions_enabled = ?, updated = ? WHERE id = ? AND org_id = ?", enabled, time.Now(), dataSourceId, orgId)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return models.ErrDataSourceNotFound
+	}
+
+	return nil
+}
+
+func AddDataSourcePermission(cmd *models.AddDataSourcePermissionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if (cmd.UserId == 0) == (cmd.TeamId == 0) {
+			return models.ErrDataSourcePermissionUserOrTeam
+		}
+
+		if !cmd.Permission.IsValid() {
+			return models.ErrDataSourcePermissionInvalid
+		}
+
+		ds := models.DataSource{}
+		if exists, err := sess.Where("id = ? AND org_id = ?", cmd.DataSourceId, cmd.OrgId).Get(&ds); err != nil {
+			return err
+		} else if !exists {
+			return models.ErrDataSourceNotFound
+		}
+
+		if !ds.PermissionsEnabled {
+			return models.ErrDataSourcePermissionsNotEnabled
+		}
+
+		var target interface{} = &models.OrgUser{}
+		targetSql := "org_id = ? AND user_id = ?"
+		targetId := cmd.UserId
+		if cmd.TeamId != 0 {
+			target = &models.Team{}
+			targetSql = "org_id = ? AND id = ?"
+			targetId = cmd.TeamId
+		}
+
+		if exists, err := sess.Where(targetSql, cmd.OrgId, targetId).Get(target); err != nil {
+			return err
+		} else if !exists {
+			return models.ErrDataSourcePermissionTargetNotFound
+		}
+
+		now := time.Now()
+		acl := models.DataSourceAcl{}
+		exists, err := sess.Where("data_source_id = ? AND user_id = ? AND team_id = ?", cmd.DataSourceId, cmd.UserId, cmd.TeamId).Get(&acl)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			_, err = sess.ID(acl.Id).Cols("permission", "updated").Update(&models.DataSourceAcl{Permission: cmd.Permission, Updated: now})
+			return err
+		}
+
+		_, err = sess.Insert(&models.DataSourceAcl{
+			OrgId:        cmd.OrgId,
+			DataSourceId: cmd.DataSourceId,
+			UserId:       cmd.UserId,
+			TeamId:       cmd.TeamId,
+			Permission:   cmd.Permission,
+			Created:      now,
+			Updated:      now,
+		})
+		return err
+	})
+}
+
+func RemoveDataSourcePermission(cmd *models.RemoveDataSourcePermissionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM data_source_acl WHERE id = ? AND data_source_id = ? AND org_id = ?", cmd.Id, cmd.DataSourceId, cmd.OrgId)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return models.ErrDataSourcePermissionNotFound
+		}
+
+		return nil
+	})
+}
+
+func GetDataSourcePermissions(query *models.GetDataSourcePermissionsQuery) error {
+	query.Result = make([]*models.DataSourceAclInfoDTO, 0)
+
+	rawSQL := `SELECT
+			da.id,
+			da.data_source_id,
+			da.user_id,
+			da.team_id,
+			da.permission,
+			da.created,
+			da.updated,
+			u.login AS user_login,
+			u.email AS user_email,
+			team.name AS team,
+			team.email AS team_email
+		FROM data_source_acl AS da
+			LEFT OUTER JOIN ` + dialect.Quote("user") + ` AS u ON u.id = da.user_id
+			LEFT OUTER JOIN team ON team.id = da.team_id
+		WHERE da.data_source_id = ? AND da.org_id = ?
+		ORDER BY da.id ASC`
+
+	if err := x.SQL(rawSQL, query.DataSourceId, query.OrgId).Find(&query.Result); err != nil {
+		return err
+	}
+
+	for _, p := range query.Result {
+		p.PermissionName = p.Permission.String()
+	}
+
+	return nil
+}
+
+func GetDataSourcePermissionForUser(query *models.GetDataSourcePermissionForUserQuery) error {
+	var permission struct {
+		Permission models.DsPermissionType
+	}
+
+	rawSQL := `SELECT COALESCE(MAX(permission), 0) AS permission FROM data_source_acl
+		WHERE data_source_id = ? AND org_id = ? AND (
+			user_id = ? OR team_id IN (SELECT team_id FROM team_member WHERE user_id = ?)
+		)`
+
+	if _, err := x.SQL(rawSQL, query.DataSourceId, query.OrgId, query.UserId, query.UserId).Get(&permission); err != nil {
+		return err
+	}
+
+	query.Result = permission.Permission
+	return nil
+}
+
+// FilterDataSourcesByPermission keeps the data sources a user can query.
+// Data sources without permissions enabled can be queried by everyone in the
+// org, the others by org admins and the users and teams of their acl.
+// Without a user the query runs for the server itself, which can query all
+// of them.
+func FilterDataSourcesByPermission(query *models.DatasourcesPermissionFilterQuery) error {
+	if query.User == nil {
+		query.Result = query.Datasources
+		return nil
+	}
+
+	query.Result = make([]*models.DataSource, 0, len(query.Datasources))
+
+	restricted := make([]int64, 0)
+	for _, ds := range query.Datasources {
+		if ds.PermissionsEnabled && query.User.OrgRole != models.ROLE_ADMIN {
+			restricted = append(restricted, ds.Id)
+		}
+	}
+
+	allowed := make(map[int64]bool)
+	if len(restricted) > 0 && query.User.UserId != 0 {
+		ids := make([]int64, 0)
+		err := x.Table("data_source_acl").
+			Where("org_id = ? AND (user_id = ? OR team_id IN (SELECT team_id FROM team_member WHERE user_id = ?))", query.User.OrgId, query.User.UserId, query.User.UserId).
+			In("data_source_id", restricted).
+			Cols("data_source_id").
+			Find(&ids)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			allowed[id] = true
+		}
+	}
+
+	for _, ds := range query.Datasources {
+		if !ds.PermissionsEnabled || query.User.OrgRole == models.ROLE_ADMIN || allowed[ds.Id] {
+			query.Result = append(query.Result, ds)
+		}
+	}
+
+	return nil
+}
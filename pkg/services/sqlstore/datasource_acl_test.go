@@ -0,0 +1,155 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceAclDataAccess(t *testing.T) {
+	t.Run("Testing data source acl data access", func(t *testing.T) {
+		InitTestDB(t)
+
+		userCmd := models.CreateUserCommand{Email: "user@test.com", Login: "user", Name: "user"}
+		require.NoError(t, CreateUser(context.Background(), &userCmd))
+		user := userCmd.Result
+
+		memberCmd := models.CreateUserCommand{Email: "member@test.com", Login: "member", Name: "member", OrgId: user.OrgId}
+		require.NoError(t, CreateUser(context.Background(), &memberCmd))
+		member := memberCmd.Result
+
+		otherCmd := models.CreateUserCommand{Email: "other@test.com", Login: "other", Name: "other", OrgId: user.OrgId}
+		require.NoError(t, CreateUser(context.Background(), &otherCmd))
+		other := otherCmd.Result
+
+		teamCmd := models.CreateTeamCommand{Name: "ops", OrgId: user.OrgId}
+		require.NoError(t, CreateTeam(&teamCmd))
+		team := teamCmd.Result
+		require.NoError(t, AddTeamMember(&models.AddTeamMemberCommand{UserId: member.Id, OrgId: user.OrgId, TeamId: team.Id}))
+
+		dsCmd := models.AddDataSourceCommand{OrgId: user.OrgId, Name: "prod", Type: models.DS_GRAPHITE, Access: models.DS_ACCESS_PROXY, Url: "http://test"}
+		require.NoError(t, AddDataSource(&dsCmd))
+		ds := dsCmd.Result
+
+		openCmd := models.AddDataSourceCommand{OrgId: user.OrgId, Name: "open", Type: models.DS_GRAPHITE, Access: models.DS_ACCESS_PROXY, Url: "http://test"}
+		require.NoError(t, AddDataSource(&openCmd))
+		open := openCmd.Result
+
+		filter := func(u models.User, role models.RoleType) []string {
+			q := models.DatasourcesPermissionFilterQuery{
+				User:        &models.SignedInUser{UserId: u.Id, OrgId: user.OrgId, OrgRole: role},
+				Datasources: getDataSources(t, user.OrgId),
+			}
+			require.NoError(t, FilterDataSourcesByPermission(&q))
+
+			names := make([]string, 0)
+			for _, d := range q.Result {
+				names = append(names, d.Name)
+			}
+			return names
+		}
+
+		t.Run("Should not add permissions before they are enabled", func(t *testing.T) {
+			cmd := models.AddDataSourcePermissionCommand{DataSourceId: ds.Id, OrgId: user.OrgId, UserId: user.Id, Permission: models.DsPermissionQuery}
+			assert.Equal(t, models.ErrDataSourcePermissionsNotEnabled, AddDataSourcePermission(&cmd))
+		})
+
+		require.NoError(t, EnableDataSourcePermissions(&models.EnableDataSourcePermissionsCommand{DataSourceId: ds.Id, OrgId: user.OrgId}))
+
+		t.Run("Should only let admins query before permissions are added", func(t *testing.T) {
+			assert.Equal(t, []string{"open"}, filter(user, models.ROLE_EDITOR))
+			assert.ElementsMatch(t, []string{"open", "prod"}, filter(user, models.ROLE_ADMIN))
+		})
+
+		t.Run("Should validate permissions", func(t *testing.T) {
+			both := models.AddDataSourcePermissionCommand{DataSourceId: ds.Id, OrgId: user.OrgId, UserId: user.Id, TeamId: team.Id, Permission: models.DsPermissionQuery}
+			assert.Equal(t, models.ErrDataSourcePermissionUserOrTeam, AddDataSourcePermission(&both))
+
+			invalid := models.AddDataSourcePermissionCommand{DataSourceId: ds.Id, OrgId: user.OrgId, UserId: user.Id, Permission: 5}
+			assert.Equal(t, models.ErrDataSourcePermissionInvalid, AddDataSourcePermission(&invalid))
+
+			otherOrg := models.AddDataSourcePermissionCommand{DataSourceId: ds.Id, OrgId: user.OrgId, TeamId: team.Id + 100, Permission: models.DsPermissionQuery}
+			assert.Equal(t, models.ErrDataSourcePermissionTargetNotFound, AddDataSourcePermission(&otherOrg))
+		})
+
+		require.NoError(t, AddDataSourcePermission(&models.AddDataSourcePermissionCommand{
+			DataSourceId: ds.Id, OrgId: user.OrgId, UserId: user.Id, Permission: models.DsPermissionQuery,
+		}))
+		require.NoError(t, AddDataSourcePermission(&models.AddDataSourcePermissionCommand{
+			DataSourceId: ds.Id, OrgId: user.OrgId, TeamId: team.Id, Permission: models.DsPermissionQuery,
+		}))
+
+		t.Run("Should let users and team members in the acl query", func(t *testing.T) {
+			assert.ElementsMatch(t, []string{"open", "prod"}, filter(user, models.ROLE_VIEWER))
+			assert.ElementsMatch(t, []string{"open", "prod"}, filter(member, models.ROLE_VIEWER))
+			assert.Equal(t, []string{"open"}, filter(other, models.ROLE_EDITOR))
+		})
+
+		t.Run("Should replace the permission of a user", func(t *testing.T) {
+			require.NoError(t, AddDataSourcePermission(&models.AddDataSourcePermissionCommand{
+				DataSourceId: ds.Id, OrgId: user.OrgId, UserId: user.Id, Permission: models.DsPermissionAdmin,
+			}))
+
+			query := models.GetDataSourcePermissionsQuery{DataSourceId: ds.Id, OrgId: user.OrgId}
+			require.NoError(t, GetDataSourcePermissions(&query))
+			require.Len(t, query.Result, 2)
+			assert.Equal(t, "user", query.Result[0].UserLogin)
+			assert.Equal(t, models.DsPermissionAdmin, query.Result[0].Permission)
+			assert.Equal(t, "Admin", query.Result[0].PermissionName)
+			assert.Equal(t, "ops", query.Result[1].Team)
+		})
+
+		t.Run("Should get the highest permission of a user", func(t *testing.T) {
+			query := models.GetDataSourcePermissionForUserQuery{DataSourceId: ds.Id, OrgId: user.OrgId, UserId: user.Id}
+			require.NoError(t, GetDataSourcePermissionForUser(&query))
+			assert.Equal(t, models.DsPermissionAdmin, query.Result)
+
+			query = models.GetDataSourcePermissionForUserQuery{DataSourceId: ds.Id, OrgId: user.OrgId, UserId: member.Id}
+			require.NoError(t, GetDataSourcePermissionForUser(&query))
+			assert.Equal(t, models.DsPermissionQuery, query.Result)
+
+			query = models.GetDataSourcePermissionForUserQuery{DataSourceId: ds.Id, OrgId: user.OrgId, UserId: other.Id}
+			require.NoError(t, GetDataSourcePermissionForUser(&query))
+			assert.Equal(t, models.DsPermissionType(0), query.Result)
+		})
+
+		t.Run("Should let the server query without a user", func(t *testing.T) {
+			q := models.DatasourcesPermissionFilterQuery{Datasources: getDataSources(t, user.OrgId)}
+			require.NoError(t, FilterDataSourcesByPermission(&q))
+			assert.Len(t, q.Result, 2)
+		})
+
+		t.Run("Should remove permission", func(t *testing.T) {
+			query := models.GetDataSourcePermissionsQuery{DataSourceId: ds.Id, OrgId: user.OrgId}
+			require.NoError(t, GetDataSourcePermissions(&query))
+
+			cmd := models.RemoveDataSourcePermissionCommand{Id: query.Result[1].Id, DataSourceId: ds.Id, OrgId: user.OrgId}
+			require.NoError(t, RemoveDataSourcePermission(&cmd))
+			assert.Equal(t, models.ErrDataSourcePermissionNotFound, RemoveDataSourcePermission(&cmd))
+			assert.Equal(t, []string{"open"}, filter(member, models.ROLE_VIEWER))
+		})
+
+		t.Run("Should remove acl when disabling permissions", func(t *testing.T) {
+			require.NoError(t, DisableDataSourcePermissions(&models.DisableDataSourcePermissionsCommand{DataSourceId: ds.Id, OrgId: user.OrgId}))
+
+			query := models.GetDataSourcePermissionsQuery{DataSourceId: ds.Id, OrgId: user.OrgId}
+			require.NoError(t, GetDataSourcePermissions(&query))
+			assert.Empty(t, query.Result)
+			assert.ElementsMatch(t, []string{"open", "prod"}, filter(other, models.ROLE_VIEWER))
+		})
+
+		t.Run("Should not enable permissions of unknown data source", func(t *testing.T) {
+			cmd := models.EnableDataSourcePermissionsCommand{DataSourceId: open.Id + 100, OrgId: user.OrgId}
+			assert.Equal(t, models.ErrDataSourceNotFound, EnableDataSourcePermissions(&cmd))
+		})
+	})
+}
+
+func getDataSources(t *testing.T, orgId int64) []*models.DataSource {
+	query := models.GetDataSourcesQuery{OrgId: orgId}
+	require.NoError(t, GetDataSources(&query))
+	return query.Result
+}
@@ -0,0 +1,83 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", SaveExternalAlerts)
+	bus.AddHandler("sql", GetExternalAlerts)
+	bus.AddHandler("sql", GetExternalAlertOrgIds)
+	bus.AddHandler("sql", DeleteExpiredExternalAlerts)
+}
+
+func SaveExternalAlerts(cmd *models.SaveExternalAlertsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		for _, alert := range cmd.Alerts {
+			alert.OrgId = cmd.OrgId
+			alert.Fingerprint = models.ExternalAlertFingerprint(alert.Labels)
+			alert.Updated = timeNow()
+
+			existing := &models.ExternalAlert{}
+			exists, err := sess.Where("org_id = ? AND fingerprint = ?", alert.OrgId, alert.Fingerprint).Get(existing)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				if _, err := sess.Insert(alert); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// an alert that is still firing keeps the time it started at
+			if existing.IsFiring(alert.StartsAt) && existing.StartsAt.Before(alert.StartsAt) {
+				alert.StartsAt = existing.StartsAt
+			}
+
+			alert.Id = existing.Id
+			if _, err := sess.ID(alert.Id).AllCols().Update(alert); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func GetExternalAlerts(query *models.GetExternalAlertsQuery) error {
+	sess := x.Asc("id")
+	if query.OrgId != 0 {
+		sess.Where("org_id = ?", query.OrgId)
+	}
+	if !query.EndedAfter.IsZero() {
+		sess.And("ends_at >= ?", query.EndedAfter)
+	}
+
+	alerts := make([]*models.ExternalAlert, 0)
+	if err := sess.Find(&alerts); err != nil {
+		return err
+	}
+
+	query.Result = alerts
+	return nil
+}
+
+func GetExternalAlertOrgIds(query *models.GetExternalAlertOrgIdsQuery) error {
+	query.Result = make([]int64, 0)
+	return x.SQL("SELECT DISTINCT org_id FROM external_alert WHERE ends_at >= ? ORDER BY org_id", query.EndedAfter).Find(&query.Result)
+}
+
+func DeleteExpiredExternalAlerts(cmd *models.DeleteExpiredExternalAlertsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM external_alert WHERE ends_at < ?", cmd.OlderThan)
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = res.RowsAffected()
+		return err
+	})
+}
@@ -0,0 +1,124 @@
+package sqlstore
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExternalAlertDataAccess(t *testing.T) {
+	Convey("Testing external alert data access", t, func() {
+		InitTestDB(t)
+
+		now := time.Now().Truncate(time.Second)
+		cmd := &models.SaveExternalAlertsCommand{
+			OrgId: 1,
+			Alerts: []*models.ExternalAlert{
+				{
+					Labels:      map[string]string{"alertname": "HighLatency", "service": "api"},
+					Annotations: map[string]string{"summary": "latency is high"},
+					StartsAt:    now.Add(-time.Minute),
+					EndsAt:      now.Add(5 * time.Minute),
+				},
+				{
+					Labels:   map[string]string{"alertname": "DiskFull"},
+					StartsAt: now.Add(-48 * time.Hour),
+					EndsAt:   now.Add(-47 * time.Hour),
+				},
+			},
+		}
+		err := SaveExternalAlerts(cmd)
+		So(err, ShouldBeNil)
+
+		Convey("Can get alerts of org", func() {
+			query := &models.GetExternalAlertsQuery{OrgId: 1}
+			err := GetExternalAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+			So(query.Result[0].Labels, ShouldResemble, map[string]string{"alertname": "HighLatency", "service": "api"})
+			So(query.Result[0].Fingerprint, ShouldEqual, models.ExternalAlertFingerprint(query.Result[0].Labels))
+
+			query = &models.GetExternalAlertsQuery{OrgId: 2}
+			err = GetExternalAlerts(query)
+			So(err, ShouldBeNil)
+			So(query.Result, ShouldBeEmpty)
+		})
+
+		Convey("Can leave out alerts that ended before a time", func() {
+			query := &models.GetExternalAlertsQuery{OrgId: 1, EndedAfter: now.Add(-time.Hour)}
+			err := GetExternalAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+			So(query.Result[0].Labels["alertname"], ShouldEqual, "HighLatency")
+		})
+
+		Convey("Can get orgs with alerts that ended after a time", func() {
+			err := SaveExternalAlerts(&models.SaveExternalAlertsCommand{
+				OrgId:  3,
+				Alerts: []*models.ExternalAlert{{Labels: map[string]string{"alertname": "Old"}, StartsAt: now.Add(-48 * time.Hour), EndsAt: now.Add(-47 * time.Hour)}},
+			})
+			So(err, ShouldBeNil)
+
+			query := &models.GetExternalAlertOrgIdsQuery{EndedAfter: now.Add(-time.Hour)}
+			err = GetExternalAlertOrgIds(query)
+			So(err, ShouldBeNil)
+			So(query.Result, ShouldResemble, []int64{1})
+		})
+
+		Convey("Saving alert with same labels updates it and keeps its start", func() {
+			err := SaveExternalAlerts(&models.SaveExternalAlertsCommand{
+				OrgId: 1,
+				Alerts: []*models.ExternalAlert{
+					{
+						Labels:      map[string]string{"service": "api", "alertname": "HighLatency"},
+						Annotations: map[string]string{"summary": "latency is very high"},
+						StartsAt:    now,
+						EndsAt:      now.Add(10 * time.Minute),
+					},
+				},
+			})
+			So(err, ShouldBeNil)
+
+			query := &models.GetExternalAlertsQuery{OrgId: 1}
+			err = GetExternalAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+			So(query.Result[0].Annotations["summary"], ShouldEqual, "latency is very high")
+			So(query.Result[0].StartsAt.Equal(now.Add(-time.Minute)), ShouldBeTrue)
+			So(query.Result[0].EndsAt.Equal(now.Add(10*time.Minute)), ShouldBeTrue)
+		})
+
+		Convey("Can delete alerts that ended long ago", func() {
+			cmd := &models.DeleteExpiredExternalAlertsCommand{OlderThan: now.Add(-24 * time.Hour)}
+			err := DeleteExpiredExternalAlerts(cmd)
+			So(err, ShouldBeNil)
+			So(cmd.DeletedRows, ShouldEqual, 1)
+
+			query := &models.GetExternalAlertsQuery{}
+			err = GetExternalAlerts(query)
+			So(err, ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+		})
+
+		Convey("Can delete more expired alerts than the database allows bind variables", func() {
+			expired := make([]*models.ExternalAlert, 0, 1200)
+			for i := 0; i < 1200; i++ {
+				expired = append(expired, &models.ExternalAlert{
+					Labels:   map[string]string{"alertname": "Flapping", "instance": strconv.Itoa(i)},
+					StartsAt: now.Add(-72 * time.Hour),
+					EndsAt:   now.Add(-71 * time.Hour),
+				})
+			}
+			err := SaveExternalAlerts(&models.SaveExternalAlertsCommand{OrgId: 1, Alerts: expired})
+			So(err, ShouldBeNil)
+
+			cmd := &models.DeleteExpiredExternalAlertsCommand{OlderThan: now.Add(-24 * time.Hour)}
+			err = DeleteExpiredExternalAlerts(cmd)
+			So(err, ShouldBeNil)
+			So(cmd.DeletedRows, ShouldEqual, 1201)
+		})
+	})
+}
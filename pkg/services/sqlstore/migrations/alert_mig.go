@@ -171,4 +171,178 @@ func addAlertMigrations(mg *Migrator) {
 	mg.AddMigration("Add column secure_settings in alert_notification", NewAddColumnMigration(alert_notification, &Column{
 		Name: "secure_settings", Type: DB_Text, Nullable: true,
 	}))
+
+	alert_instance := Table{
+		Name: "alert_instance",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "alert_id", Type: DB_BigInt, Nullable: false},
+			{Name: "instance_key", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "metric", Type: DB_Text, Nullable: false},
+			{Name: "tags", Type: DB_Text, Nullable: true},
+			{Name: "state", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "new_state_date", Type: DB_DateTime, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "alert_id", "instance_key"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_instance table v1", NewAddTableMigration(alert_instance))
+	mg.AddMigration("add unique index alert_instance org_id & alert_id & instance_key", NewAddIndexMigration(alert_instance, alert_instance.Indices[0]))
+
+	alert_provisioning := Table{
+		Name: "alert_provisioning",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "alert_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"alert_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_provisioning table v1", NewAddTableMigration(alert_provisioning))
+	mg.AddMigration("add unique index alert_provisioning alert_id", NewAddIndexMigration(alert_provisioning, alert_provisioning.Indices[0]))
+
+	alert_silence := Table{
+		Name: "alert_silence",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "rule_name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "folder_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "tags", Type: DB_Text, Nullable: true},
+			{Name: "starts_at", Type: DB_DateTime, Nullable: false},
+			{Name: "ends_at", Type: DB_DateTime, Nullable: false},
+			{Name: "cron", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "repeat_duration", Type: DB_BigInt, Nullable: false},
+			{Name: "comment", Type: DB_Text, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create alert_silence table v1", NewAddTableMigration(alert_silence))
+	mg.AddMigration("add index alert_silence org_id", NewAddIndexMigration(alert_silence, alert_silence.Indices[0]))
+
+	alert_notification_policy := Table{
+		Name: "alert_notification_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "policy", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_policy table v1", NewAddTableMigration(alert_notification_policy))
+	mg.AddMigration("add unique index alert_notification_policy org_id", NewAddIndexMigration(alert_notification_policy, alert_notification_policy.Indices[0]))
+
+	alert_state_history := Table{
+		Name: "alert_state_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "alert_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "rule_name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "prev_state", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "new_state", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "eval_matches", Type: DB_Text, Nullable: true},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "notifications", Type: DB_Text, Nullable: true},
+			{Name: "epoch", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "epoch"}},
+			{Cols: []string{"org_id", "alert_id", "epoch"}},
+			{Cols: []string{"epoch"}},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table v1", NewAddTableMigration(alert_state_history))
+	addTableIndicesMigrations(mg, "v1", alert_state_history)
+	mg.AddMigration("Add column changed_instances to alert_state_history", NewAddColumnMigration(alert_state_history, &Column{
+		Name: "changed_instances", Type: DB_Text, Nullable: true,
+	}))
+
+	external_alert := Table{
+		Name: "external_alert",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "fingerprint", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: DB_Text, Nullable: false},
+			{Name: "annotations", Type: DB_Text, Nullable: true},
+			{Name: "starts_at", Type: DB_DateTime, Nullable: false},
+			{Name: "ends_at", Type: DB_DateTime, Nullable: false},
+			{Name: "generator_url", Type: DB_Text, Nullable: true},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "fingerprint"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create external_alert table v1", NewAddTableMigration(external_alert))
+	addTableIndicesMigrations(mg, "v1", external_alert)
+	mg.AddMigration("add index external_alert org_id & ends_at", NewAddIndexMigration(external_alert, &Index{
+		Cols: []string{"org_id", "ends_at"},
+	}))
+
+	alerting_node := Table{
+		Name: "alerting_node",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "node_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "heartbeat", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"node_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alerting_node table v1", NewAddTableMigration(alerting_node))
+	addTableIndicesMigrations(mg, "v1", alerting_node)
+
+	recording_rule := Table{
+		Name: "recording_rule",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "datasource_id", Type: DB_BigInt, Nullable: false},
+			{Name: "query", Type: DB_Text, Nullable: false},
+			{Name: "query_range", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "frequency", Type: DB_BigInt, Nullable: false},
+			{Name: "reducer", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: DB_Text, Nullable: true},
+			{Name: "paused", Type: DB_Bool, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create recording_rule table v1", NewAddTableMigration(recording_rule))
+	addTableIndicesMigrations(mg, "v1", recording_rule)
 }
@@ -151,4 +151,31 @@ func addDataSourceMigration(mg *Migrator) {
 	mg.AddMigration("Add unique index datasource_org_id_uid", NewAddIndexMigration(tableV2, &Index{
 		Cols: []string{"org_id", "uid"}, Type: UniqueIndex,
 	}))
+
+	mg.AddMigration("Add permissions_enabled column", NewAddColumnMigration(tableV2, &Column{
+		Name: "permissions_enabled", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	dataSourceAclV1 := Table{
+		Name: "data_source_acl",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "data_source_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: true},
+			{Name: "team_id", Type: DB_BigInt, Nullable: true},
+			{Name: "permission", Type: DB_SmallInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"data_source_id"}},
+			{Cols: []string{"data_source_id", "user_id", "team_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "user_id"}},
+			{Cols: []string{"org_id", "team_id"}},
+		},
+	}
+
+	mg.AddMigration("create data_source_acl table", NewAddTableMigration(dataSourceAclV1))
+	addTableIndicesMigrations(mg, "v1", dataSourceAclV1)
 }
@@ -34,6 +34,8 @@ func AddMigrations(mg *Migrator) {
 	addServerlockMigrations(mg)
 	addUserAuthTokenMigrations(mg)
 	addCacheMigration(mg)
+	addAccessControlMigrations(mg)
+	addAuditMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
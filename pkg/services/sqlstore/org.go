@@ -202,7 +202,12 @@ func DeleteOrg(cmd *models.DeleteOrgCommand) error {
 			"DELETE FROM dashboard WHERE org_id = ?",
 			"DELETE FROM api_key WHERE org_id = ?",
 			"DELETE FROM data_source WHERE org_id = ?",
+			"DELETE FROM data_source_acl WHERE org_id = ?",
 			"DELETE FROM org_user WHERE org_id = ?",
+			"DELETE FROM permission WHERE EXISTS (SELECT 1 FROM role WHERE org_id = ? AND permission.role_id = role.id)",
+			"DELETE FROM role WHERE org_id = ?",
+			"DELETE FROM user_role WHERE org_id = ?",
+			"DELETE FROM team_role WHERE org_id = ?",
 			"DELETE FROM org WHERE id = ?",
 			"DELETE FROM temp_user WHERE org_id = ?",
 		}
@@ -106,6 +106,10 @@ func GetOrgUsers(query *models.GetOrgUsersQuery) error {
 	whereConditions = append(whereConditions, "org_user.org_id = ?")
 	whereParams = append(whereParams, query.OrgId)
 
+	// service accounts are listed by the service accounts api
+	whereConditions = append(whereConditions, "is_service_account = ?")
+	whereParams = append(whereParams, false)
+
 	if query.Query != "" {
 		queryWithWildcards := "%" + query.Query + "%"
 		whereConditions = append(whereConditions, "(email "+dialect.LikeStr()+" ? OR name "+dialect.LikeStr()+" ? OR login "+dialect.LikeStr()+" ?)")
@@ -156,6 +160,8 @@ func RemoveOrgUser(cmd *models.RemoveOrgUserCommand) error {
 			"DELETE FROM org_user WHERE org_id=? and user_id=?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and user_id = ?",
 			"DELETE FROM team_member WHERE org_id=? and user_id = ?",
+			"DELETE FROM user_role WHERE org_id=? and user_id = ?",
+			"DELETE FROM data_source_acl WHERE org_id=? and user_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -0,0 +1,202 @@
+package sqlstore
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	metricNameRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRegex  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+const (
+	defaultRecordingRuleRange = "5m"
+	minRecordingRuleFrequency = 10 * time.Second
+)
+
+// reservedRecordingRuleLabels are the labels set by the recording rule
+// service on the recorded series, which rules cannot set.
+var reservedRecordingRuleLabels = map[string]bool{"__name__": true, "org_id": true}
+
+func init() {
+	bus.AddHandler("sql", CreateRecordingRule)
+	bus.AddHandler("sql", UpdateRecordingRule)
+	bus.AddHandler("sql", DeleteRecordingRule)
+	bus.AddHandler("sql", GetRecordingRuleById)
+	bus.AddHandler("sql", GetRecordingRules)
+}
+
+func CreateRecordingRule(cmd *models.CreateRecordingRuleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		frequency, queryRange, err := validateRecordingRule(cmd.Name, cmd.Labels, cmd.Frequency, cmd.QueryRange, cmd.Reducer)
+		if err != nil {
+			return err
+		}
+
+		if err := checkRecordingRuleName(sess, cmd.OrgId, cmd.Name, 0); err != nil {
+			return err
+		}
+
+		rule := &models.RecordingRule{
+			OrgId:        cmd.OrgId,
+			Name:         cmd.Name,
+			DatasourceId: cmd.DatasourceId,
+			Query:        cmd.Query,
+			QueryRange:   queryRange,
+			Frequency:    frequency,
+			Reducer:      cmd.Reducer,
+			Labels:       cmd.Labels,
+			Paused:       cmd.Paused,
+			CreatedBy:    cmd.CreatedBy,
+			Created:      timeNow(),
+			Updated:      timeNow(),
+		}
+
+		if _, err := sess.Insert(rule); err != nil {
+			return err
+		}
+
+		cmd.Result = rule
+		return nil
+	})
+}
+
+func UpdateRecordingRule(cmd *models.UpdateRecordingRuleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		frequency, queryRange, err := validateRecordingRule(cmd.Name, cmd.Labels, cmd.Frequency, cmd.QueryRange, cmd.Reducer)
+		if err != nil {
+			return err
+		}
+
+		rule := &models.RecordingRule{}
+		exists, err := sess.Where("id = ? AND org_id = ?", cmd.Id, cmd.OrgId).Get(rule)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return models.ErrRecordingRuleNotFound
+		}
+
+		if err := checkRecordingRuleName(sess, cmd.OrgId, cmd.Name, cmd.Id); err != nil {
+			return err
+		}
+
+		rule.Name = cmd.Name
+		rule.DatasourceId = cmd.DatasourceId
+		rule.Query = cmd.Query
+		rule.QueryRange = queryRange
+		rule.Frequency = frequency
+		rule.Reducer = cmd.Reducer
+		rule.Labels = cmd.Labels
+		rule.Paused = cmd.Paused
+		rule.Updated = timeNow()
+
+		if _, err := sess.ID(rule.Id).AllCols().Update(rule); err != nil {
+			return err
+		}
+
+		cmd.Result = rule
+		return nil
+	})
+}
+
+// validateRecordingRule validates the settings of a recording rule and
+// returns its frequency in seconds and its range, which defaults to 5m.
+func validateRecordingRule(name string, labels map[string]string, rawFrequency, queryRange, reducer string) (int64, string, error) {
+	if !metricNameRegex.MatchString(name) {
+		return 0, "", models.ErrRecordingRuleInvalidName
+	}
+
+	for labelName := range labels {
+		if !labelNameRegex.MatchString(labelName) || reservedRecordingRuleLabels[labelName] {
+			return 0, "", models.ErrRecordingRuleInvalidLabel
+		}
+	}
+
+	frequency, err := gtime.ParseInterval(rawFrequency)
+	if err != nil || frequency < minRecordingRuleFrequency {
+		return 0, "", models.ErrRecordingRuleInvalidFrequency
+	}
+
+	if queryRange == "" {
+		queryRange = defaultRecordingRuleRange
+	}
+
+	if parsedRange, err := gtime.ParseInterval(queryRange); err != nil || parsedRange <= 0 {
+		return 0, "", models.ErrRecordingRuleInvalidRange
+	}
+
+	if reducer != "" && !isRecordingRuleReducer(reducer) {
+		return 0, "", models.ErrRecordingRuleInvalidReducer
+	}
+
+	return int64(frequency / time.Second), queryRange, nil
+}
+
+// checkRecordingRuleName returns ErrRecordingRuleNameTaken if another
+// rule of the organization has the name, as rules write to the series of
+// their name.
+func checkRecordingRuleName(sess *DBSession, orgID int64, name string, ruleID int64) error {
+	exists, err := sess.Where("org_id = ? AND name = ? AND id <> ?", orgID, name, ruleID).Get(&models.RecordingRule{})
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return models.ErrRecordingRuleNameTaken
+	}
+
+	return nil
+}
+
+func isRecordingRuleReducer(reducer string) bool {
+	for _, r := range models.RecordingRuleReducers {
+		if r == reducer {
+			return true
+		}
+	}
+	return false
+}
+
+func DeleteRecordingRule(cmd *models.DeleteRecordingRuleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		_, err := sess.Exec("DELETE FROM recording_rule WHERE id = ? AND org_id = ?", cmd.Id, cmd.OrgId)
+		return err
+	})
+}
+
+func GetRecordingRuleById(query *models.GetRecordingRuleByIdQuery) error {
+	rule := &models.RecordingRule{}
+	exists, err := x.Where("id = ? AND org_id = ?", query.Id, query.OrgId).Get(rule)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return models.ErrRecordingRuleNotFound
+	}
+
+	query.Result = rule
+	return nil
+}
+
+func GetRecordingRules(query *models.GetRecordingRulesQuery) error {
+	sess := x.Asc("id")
+	if query.OrgId != 0 {
+		sess.Where("org_id = ?", query.OrgId)
+	}
+
+	rules := make([]*models.RecordingRule, 0)
+	if err := sess.Find(&rules); err != nil {
+		return err
+	}
+
+	query.Result = rules
+	return nil
+}
@@ -0,0 +1,129 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecordingRuleDataAccess(t *testing.T) {
+	Convey("Testing recording rule data access", t, func() {
+		InitTestDB(t)
+
+		cmd := &models.CreateRecordingRuleCommand{
+			OrgId:        1,
+			Name:         "job:http_requests:rate5m",
+			DatasourceId: 3,
+			Query:        simplejson.NewFromAny(map[string]interface{}{"expr": "sum(rate(http_requests_total[5m])) by (job)"}),
+			Frequency:    "1m",
+			Reducer:      "last",
+			Labels:       map[string]string{"team": "backend"},
+			CreatedBy:    1,
+		}
+		err := CreateRecordingRule(cmd)
+		So(err, ShouldBeNil)
+		So(cmd.Result.Id, ShouldBeGreaterThan, 0)
+
+		Convey("Can get rule by id", func() {
+			query := &models.GetRecordingRuleByIdQuery{Id: cmd.Result.Id, OrgId: 1}
+			err := GetRecordingRuleById(query)
+			So(err, ShouldBeNil)
+			So(query.Result.Name, ShouldEqual, "job:http_requests:rate5m")
+			So(query.Result.Frequency, ShouldEqual, 60)
+			So(query.Result.QueryRange, ShouldEqual, "5m")
+			So(query.Result.Query.Get("expr").MustString(), ShouldEqual, "sum(rate(http_requests_total[5m])) by (job)")
+			So(query.Result.Labels, ShouldResemble, map[string]string{"team": "backend"})
+
+			query = &models.GetRecordingRuleByIdQuery{Id: cmd.Result.Id, OrgId: 2}
+			err = GetRecordingRuleById(query)
+			So(err, ShouldEqual, models.ErrRecordingRuleNotFound)
+		})
+
+		Convey("Can list rules of all orgs", func() {
+			err := CreateRecordingRule(&models.CreateRecordingRuleCommand{
+				OrgId:        2,
+				Name:         "instance:cpu:avg",
+				DatasourceId: 4,
+				Query:        simplejson.New(),
+				Frequency:    "30s",
+			})
+			So(err, ShouldBeNil)
+
+			query := &models.GetRecordingRulesQuery{OrgId: 1}
+			So(GetRecordingRules(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 1)
+
+			query = &models.GetRecordingRulesQuery{}
+			So(GetRecordingRules(query), ShouldBeNil)
+			So(len(query.Result), ShouldEqual, 2)
+		})
+
+		Convey("Requires unique names per org", func() {
+			duplicate := &models.CreateRecordingRuleCommand{OrgId: 1, Name: cmd.Name, Query: simplejson.New(), Frequency: "1m"}
+			So(CreateRecordingRule(duplicate), ShouldEqual, models.ErrRecordingRuleNameTaken)
+
+			duplicate.OrgId = 2
+			So(CreateRecordingRule(duplicate), ShouldBeNil)
+
+			other := &models.CreateRecordingRuleCommand{OrgId: 1, Name: "instance:cpu:avg", Query: simplejson.New(), Frequency: "1m"}
+			So(CreateRecordingRule(other), ShouldBeNil)
+
+			update := &models.UpdateRecordingRuleCommand{Id: other.Result.Id, OrgId: 1, Name: cmd.Name, Query: simplejson.New(), Frequency: "1m"}
+			So(UpdateRecordingRule(update), ShouldEqual, models.ErrRecordingRuleNameTaken)
+
+			update.Id = cmd.Result.Id
+			So(UpdateRecordingRule(update), ShouldBeNil)
+		})
+
+		Convey("Can update rule", func() {
+			update := &models.UpdateRecordingRuleCommand{
+				Id:           cmd.Result.Id,
+				OrgId:        1,
+				Name:         "job:http_requests:rate1h",
+				DatasourceId: 3,
+				Query:        cmd.Query,
+				QueryRange:   "1h",
+				Frequency:    "5m",
+				Paused:       true,
+			}
+			err := UpdateRecordingRule(update)
+			So(err, ShouldBeNil)
+
+			query := &models.GetRecordingRuleByIdQuery{Id: cmd.Result.Id, OrgId: 1}
+			So(GetRecordingRuleById(query), ShouldBeNil)
+			So(query.Result.Name, ShouldEqual, "job:http_requests:rate1h")
+			So(query.Result.Frequency, ShouldEqual, 300)
+			So(query.Result.QueryRange, ShouldEqual, "1h")
+			So(query.Result.Reducer, ShouldEqual, "")
+			So(query.Result.Paused, ShouldBeTrue)
+
+			update.OrgId = 2
+			So(UpdateRecordingRule(update), ShouldEqual, models.ErrRecordingRuleNotFound)
+		})
+
+		Convey("Can delete rule", func() {
+			err := DeleteRecordingRule(&models.DeleteRecordingRuleCommand{Id: cmd.Result.Id, OrgId: 1})
+			So(err, ShouldBeNil)
+
+			query := &models.GetRecordingRuleByIdQuery{Id: cmd.Result.Id, OrgId: 1}
+			So(GetRecordingRuleById(query), ShouldEqual, models.ErrRecordingRuleNotFound)
+		})
+
+		Convey("Validates rule", func() {
+			invalid := func(change func(cmd *models.CreateRecordingRuleCommand)) error {
+				cmd := &models.CreateRecordingRuleCommand{OrgId: 1, Name: "valid_name", Query: simplejson.New(), Frequency: "1m"}
+				change(cmd)
+				return CreateRecordingRule(cmd)
+			}
+
+			So(invalid(func(cmd *models.CreateRecordingRuleCommand) { cmd.Name = "http requests" }), ShouldEqual, models.ErrRecordingRuleInvalidName)
+			So(invalid(func(cmd *models.CreateRecordingRuleCommand) { cmd.Labels = map[string]string{"__name__": "x"} }), ShouldEqual, models.ErrRecordingRuleInvalidLabel)
+			So(invalid(func(cmd *models.CreateRecordingRuleCommand) { cmd.Labels = map[string]string{"org_id": "2"} }), ShouldEqual, models.ErrRecordingRuleInvalidLabel)
+			So(invalid(func(cmd *models.CreateRecordingRuleCommand) { cmd.Frequency = "5s" }), ShouldEqual, models.ErrRecordingRuleInvalidFrequency)
+			So(invalid(func(cmd *models.CreateRecordingRuleCommand) { cmd.QueryRange = "soon" }), ShouldEqual, models.ErrRecordingRuleInvalidRange)
+			So(invalid(func(cmd *models.CreateRecordingRuleCommand) { cmd.Reducer = "p99" }), ShouldEqual, models.ErrRecordingRuleInvalidReducer)
+		})
+	})
+}
@@ -0,0 +1,247 @@
+package sqlstore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateServiceAccount)
+	bus.AddHandler("sql", UpdateServiceAccount)
+	bus.AddHandler("sql", DeleteServiceAccount)
+	bus.AddHandler("sql", GetServiceAccounts)
+	bus.AddHandler("sql", GetServiceAccountById)
+	bus.AddHandler("sql", AddServiceAccountToken)
+	bus.AddHandler("sql", GetServiceAccountTokens)
+	bus.AddHandler("sql", DeleteServiceAccountToken)
+}
+
+// serviceAccountLogin returns the login of a service account, which is
+// unique across orgs like the logins of users.
+func serviceAccountLogin(orgId int64, name string) string {
+	slug := models.SlugifyTitle(name)
+	if slug == "" {
+		slug = util.GenerateShortUID()
+	}
+	return fmt.Sprintf("%s%d-%s", models.ServiceAccountLoginPrefix, orgId, slug)
+}
+
+// CreateServiceAccount creates a user which cannot log in, and is a member of
+// the org with the role of the service account.
+func CreateServiceAccount(cmd *models.CreateServiceAccountCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		login := serviceAccountLogin(cmd.OrgId, cmd.Name)
+
+		if exists, err := sess.Where("org_id=? AND is_service_account=? AND (name=? OR login=?)", cmd.OrgId, true, cmd.Name, login).Get(&models.User{}); err != nil {
+			return err
+		} else if exists {
+			return models.ErrServiceAccountNameTaken
+		}
+
+		if res, err := sess.Query("SELECT 1 from org WHERE id=?", cmd.OrgId); err != nil {
+			return err
+		} else if len(res) != 1 {
+			return models.ErrOrgNotFound
+		}
+
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return err
+		}
+		rands, err := util.GetRandomString(10)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user := models.User{
+			Email:            login,
+			Name:             cmd.Name,
+			Login:            login,
+			Salt:             salt,
+			Rands:            rands,
+			OrgId:            cmd.OrgId,
+			IsServiceAccount: true,
+			Created:          now,
+			Updated:          now,
+			LastSeenAt:       now.AddDate(-10, 0, 0),
+		}
+
+		if _, err := sess.Insert(&user); err != nil {
+			return err
+		}
+
+		orgUser := models.OrgUser{
+			OrgId:   cmd.OrgId,
+			UserId:  user.Id,
+			Role:    cmd.Role,
+			Created: now,
+			Updated: now,
+		}
+
+		if _, err := sess.Insert(&orgUser); err != nil {
+			return err
+		}
+
+		cmd.Result = &models.ServiceAccountDTO{
+			Id:      user.Id,
+			OrgId:   user.OrgId,
+			Name:    user.Name,
+			Login:   user.Login,
+			Role:    orgUser.Role,
+			Created: user.Created,
+			Updated: user.Updated,
+		}
+		return nil
+	})
+}
+
+// UpdateServiceAccount renames a service account or changes its role. The
+// login of a service account does not change, so that audit trails can
+// follow it.
+func UpdateServiceAccount(cmd *models.UpdateServiceAccountCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		user, err := getServiceAccount(sess, cmd.Id, cmd.OrgId)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		if cmd.Name != "" && cmd.Name != user.Name {
+			if exists, err := sess.Where("org_id=? AND is_service_account=? AND name=?", cmd.OrgId, true, cmd.Name).Get(&models.User{}); err != nil {
+				return err
+			} else if exists {
+				return models.ErrServiceAccountNameTaken
+			}
+
+			if _, err := sess.ID(user.Id).Cols("name", "updated").Update(&models.User{Name: cmd.Name, Updated: now}); err != nil {
+				return err
+			}
+		}
+
+		if cmd.Role != "" {
+			if _, err := sess.Exec("UPDATE org_user SET role=?, updated=? WHERE org_id=? AND user_id=?", cmd.Role, now, cmd.OrgId, user.Id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteServiceAccount deletes a service account with its tokens, team
+// memberships and permissions.
+func DeleteServiceAccount(cmd *models.DeleteServiceAccountCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getServiceAccount(sess, cmd.Id, cmd.OrgId); err != nil {
+			return err
+		}
+
+		return deleteUserInTransaction(sess, &models.DeleteUserCommand{UserId: cmd.Id})
+	})
+}
+
+func getServiceAccount(sess *DBSession, id int64, orgId int64) (*models.User, error) {
+	var user models.User
+	exists, err := sess.Where("id=? AND org_id=? AND is_service_account=?", id, orgId, true).Get(&user)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, models.ErrServiceAccountNotFound
+	}
+
+	return &user, nil
+}
+
+const serviceAccountSelect = `SELECT
+		u.id      as id,
+		u.org_id  as org_id,
+		u.name    as name,
+		u.login   as login,
+		u.created as created,
+		u.updated as updated,
+		org_user.role as role,
+		(SELECT COUNT(*) FROM api_key WHERE api_key.service_account_id = u.id) as tokens
+	FROM `
+
+func GetServiceAccounts(query *models.GetServiceAccountsQuery) error {
+	query.Result = make([]*models.ServiceAccountDTO, 0)
+
+	rawSql := serviceAccountSelect + dialect.Quote("user") + ` as u
+		INNER JOIN org_user ON org_user.org_id = u.org_id AND org_user.user_id = u.id
+		WHERE u.org_id = ? AND u.is_service_account = ?
+		ORDER BY u.name ASC`
+
+	return x.SQL(rawSql, query.OrgId, true).Find(&query.Result)
+}
+
+func GetServiceAccountById(query *models.GetServiceAccountByIdQuery) error {
+	rawSql := serviceAccountSelect + dialect.Quote("user") + ` as u
+		INNER JOIN org_user ON org_user.org_id = u.org_id AND org_user.user_id = u.id
+		WHERE u.id = ? AND u.org_id = ? AND u.is_service_account = ?`
+
+	var serviceAccount models.ServiceAccountDTO
+	exists, err := x.SQL(rawSql, query.Id, query.OrgId, true).Get(&serviceAccount)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return models.ErrServiceAccountNotFound
+	}
+
+	query.Result = &serviceAccount
+	return nil
+}
+
+// AddServiceAccountToken adds an api key which authenticates as the service
+// account. The role of the key is unused, since requests get the role of the
+// service account. The key is stored under models.ServiceAccountTokenKeyName.
+func AddServiceAccountToken(cmd *models.AddServiceAccountTokenCommand) error {
+	query := models.GetServiceAccountByIdQuery{Id: cmd.ServiceAccountId, OrgId: cmd.OrgId}
+	if err := GetServiceAccountById(&query); err != nil {
+		return err
+	}
+
+	addKeyCmd := &models.AddApiKeyCommand{
+		Name:             models.ServiceAccountTokenKeyName(cmd.ServiceAccountId, cmd.Name),
+		Role:             query.Result.Role,
+		OrgId:            cmd.OrgId,
+		Key:              cmd.Key,
+		SecondsToLive:    cmd.SecondsToLive,
+		ServiceAccountId: &cmd.ServiceAccountId,
+	}
+	if err := AddApiKey(addKeyCmd); err != nil {
+		return err
+	}
+
+	cmd.Result = addKeyCmd.Result
+	return nil
+}
+
+func GetServiceAccountTokens(query *models.GetServiceAccountTokensQuery) error {
+	query.Result = make([]*models.ApiKey, 0)
+	return x.Where("org_id=? AND service_account_id=?", query.OrgId, query.ServiceAccountId).Asc("name").Find(&query.Result)
+}
+
+func DeleteServiceAccountToken(cmd *models.DeleteServiceAccountTokenCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM api_key WHERE id=? AND org_id=? AND service_account_id=?", cmd.Id, cmd.OrgId, cmd.ServiceAccountId)
+		if err != nil {
+			return err
+		}
+
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return models.ErrInvalidApiKey
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,131 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAccountDataAccess(t *testing.T) {
+	t.Run("Testing service account data access", func(t *testing.T) {
+		InitTestDB(t)
+
+		userCmd := models.CreateUserCommand{Email: "admin@test.com", Login: "admin", Name: "admin"}
+		require.NoError(t, CreateUser(context.Background(), &userCmd))
+		orgId := userCmd.Result.OrgId
+
+		createCmd := models.CreateServiceAccountCommand{Name: "CI deploy", Role: models.ROLE_EDITOR, OrgId: orgId}
+		require.NoError(t, CreateServiceAccount(&createCmd))
+		sa := createCmd.Result
+
+		t.Run("Should create service account user", func(t *testing.T) {
+			assert.Equal(t, "sa-1-ci-deploy", sa.Login)
+			assert.Equal(t, models.ROLE_EDITOR, sa.Role)
+
+			query := models.GetSignedInUserQuery{UserId: sa.Id, OrgId: orgId}
+			require.NoError(t, GetSignedInUser(&query))
+			assert.True(t, query.Result.IsServiceAccount)
+			assert.Equal(t, models.ROLE_EDITOR, query.Result.OrgRole)
+		})
+
+		t.Run("Should not allow duplicate names", func(t *testing.T) {
+			cmd := models.CreateServiceAccountCommand{Name: "CI deploy", Role: models.ROLE_VIEWER, OrgId: orgId}
+			assert.Equal(t, models.ErrServiceAccountNameTaken, CreateServiceAccount(&cmd))
+		})
+
+		t.Run("Should update role and name", func(t *testing.T) {
+			cmd := models.UpdateServiceAccountCommand{Id: sa.Id, OrgId: orgId, Name: "CI", Role: models.ROLE_VIEWER}
+			require.NoError(t, UpdateServiceAccount(&cmd))
+
+			query := models.GetServiceAccountByIdQuery{Id: sa.Id, OrgId: orgId}
+			require.NoError(t, GetServiceAccountById(&query))
+			assert.Equal(t, "CI", query.Result.Name)
+			assert.Equal(t, sa.Login, query.Result.Login)
+			assert.Equal(t, models.ROLE_VIEWER, query.Result.Role)
+		})
+
+		t.Run("Should not find service account in other org", func(t *testing.T) {
+			query := models.GetServiceAccountByIdQuery{Id: sa.Id, OrgId: orgId + 1}
+			assert.Equal(t, models.ErrServiceAccountNotFound, GetServiceAccountById(&query))
+		})
+
+		t.Run("Should exclude service accounts from users", func(t *testing.T) {
+			searchQuery := models.SearchUsersQuery{Limit: 10}
+			require.NoError(t, SearchUsers(&searchQuery))
+			assert.Len(t, searchQuery.Result.Users, 1)
+			assert.Equal(t, int64(1), searchQuery.Result.TotalCount)
+
+			orgUsersQuery := models.GetOrgUsersQuery{OrgId: orgId}
+			require.NoError(t, GetOrgUsers(&orgUsersQuery))
+			assert.Len(t, orgUsersQuery.Result, 1)
+		})
+
+		t.Run("Given service account token", func(t *testing.T) {
+			tokenCmd := models.AddServiceAccountTokenCommand{Name: "deploy", Key: "secret", OrgId: orgId, ServiceAccountId: sa.Id}
+			require.NoError(t, AddServiceAccountToken(&tokenCmd))
+			require.NotNil(t, tokenCmd.Result.ServiceAccountId)
+			assert.Equal(t, sa.Id, *tokenCmd.Result.ServiceAccountId)
+
+			t.Run("Should list tokens of service account", func(t *testing.T) {
+				query := models.GetServiceAccountTokensQuery{OrgId: orgId, ServiceAccountId: sa.Id}
+				require.NoError(t, GetServiceAccountTokens(&query))
+				assert.Len(t, query.Result, 1)
+
+				listQuery := models.GetServiceAccountsQuery{OrgId: orgId}
+				require.NoError(t, GetServiceAccounts(&listQuery))
+				require.Len(t, listQuery.Result, 1)
+				assert.Equal(t, int64(1), listQuery.Result[0].Tokens)
+			})
+
+			t.Run("Should exclude tokens from api keys", func(t *testing.T) {
+				query := models.GetApiKeysQuery{OrgId: orgId}
+				require.NoError(t, GetApiKeys(&query))
+				assert.Len(t, query.Result, 0)
+			})
+
+			t.Run("Should allow same token name for other service accounts and api keys", func(t *testing.T) {
+				otherCmd := models.CreateServiceAccountCommand{Name: "Backup", Role: models.ROLE_VIEWER, OrgId: orgId}
+				require.NoError(t, CreateServiceAccount(&otherCmd))
+				other := otherCmd.Result
+
+				otherTokenCmd := models.AddServiceAccountTokenCommand{Name: "deploy", Key: "other-secret", OrgId: orgId, ServiceAccountId: other.Id}
+				require.NoError(t, AddServiceAccountToken(&otherTokenCmd))
+				assert.Equal(t, models.ServiceAccountTokenKeyName(other.Id, "deploy"), otherTokenCmd.Result.Name)
+				assert.NotEqual(t, tokenCmd.Result.Name, otherTokenCmd.Result.Name)
+
+				keyCmd := models.AddApiKeyCommand{Name: "deploy", Key: "key-secret", OrgId: orgId, Role: models.ROLE_VIEWER}
+				require.NoError(t, AddApiKey(&keyCmd))
+
+				duplicateCmd := models.AddServiceAccountTokenCommand{Name: "deploy", Key: "duplicate-secret", OrgId: orgId, ServiceAccountId: other.Id}
+				assert.Equal(t, models.ErrDuplicateApiKey, AddServiceAccountToken(&duplicateCmd))
+
+				query := models.GetServiceAccountTokensQuery{OrgId: orgId, ServiceAccountId: other.Id}
+				require.NoError(t, GetServiceAccountTokens(&query))
+				require.Len(t, query.Result, 1)
+				assert.Equal(t, "deploy", models.ServiceAccountTokenName(other.Id, query.Result[0].Name))
+
+				require.NoError(t, DeleteServiceAccount(&models.DeleteServiceAccountCommand{Id: other.Id, OrgId: orgId}))
+				require.NoError(t, DeleteApiKeyCtx(context.Background(), &models.DeleteApiKeyCommand{Id: keyCmd.Result.Id, OrgId: orgId}))
+			})
+
+			t.Run("Should not delete token of other service account", func(t *testing.T) {
+				cmd := models.DeleteServiceAccountTokenCommand{Id: tokenCmd.Result.Id, OrgId: orgId, ServiceAccountId: sa.Id + 1}
+				assert.Equal(t, models.ErrInvalidApiKey, DeleteServiceAccountToken(&cmd))
+			})
+
+			t.Run("Should delete tokens with service account", func(t *testing.T) {
+				require.NoError(t, DeleteServiceAccount(&models.DeleteServiceAccountCommand{Id: sa.Id, OrgId: orgId}))
+
+				query := models.GetServiceAccountTokensQuery{OrgId: orgId, ServiceAccountId: sa.Id}
+				require.NoError(t, GetServiceAccountTokens(&query))
+				assert.Len(t, query.Result, 0)
+
+				byIdQuery := models.GetServiceAccountByIdQuery{Id: sa.Id, OrgId: orgId}
+				assert.Equal(t, models.ErrServiceAccountNotFound, GetServiceAccountById(&byIdQuery))
+			})
+		})
+	})
+}
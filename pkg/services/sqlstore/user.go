@@ -386,6 +386,7 @@ func GetSignedInUser(query *models.GetSignedInUserQuery) error {
 		u.name           as name,
 		u.help_flags1    as help_flags1,
 		u.last_seen_at   as last_seen_at,
+		u.is_service_account as is_service_account,
 		(SELECT COUNT(*) FROM org_user where org_user.user_id = u.id) as org_count,
 		org.name         as org_name,
 		org_user.role    as org_role,
@@ -456,6 +457,10 @@ func SearchUsers(query *models.SearchUsersQuery) error {
 		whereParams = append(whereParams, query.OrgId)
 	}
 
+	// service accounts are listed by the service accounts api
+	whereConditions = append(whereConditions, "is_service_account = ?")
+	whereParams = append(whereParams, false)
+
 	if query.Query != "" {
 		whereConditions = append(whereConditions, "(email "+dialect.LikeStr()+" ? OR name "+dialect.LikeStr()+" ? OR login "+dialect.LikeStr()+" ?)")
 		whereParams = append(whereParams, queryWithWildcards, queryWithWildcards, queryWithWildcards)
@@ -575,6 +580,9 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM api_key WHERE service_account_id = ?",
+		"DELETE FROM user_role WHERE user_id = ?",
+		"DELETE FROM data_source_acl WHERE user_id = ?",
 	}
 
 	for _, sql := range deletes {
@@ -203,6 +203,9 @@ var (
 	AlertingMaxAttempts         int
 	AlertingMinInterval         int64
 
+	AlertingHASharding          bool
+	AlertingHAHeartbeatInterval time.Duration
+
 	// Explore UI
 	ExploreEnabled bool
 
@@ -248,6 +251,15 @@ type Cfg struct {
 	// SMTP email settings
 	Smtp SmtpSettings
 
+	// Recording rules
+	RecordingRules RecordingRulesSettings
+
+	// Query cache
+	QueryCache QueryCacheSettings
+
+	// Audit log
+	Audit AuditSettings
+
 	// Rendering
 	ImagesDir                      string
 	RendererUrl                    string
@@ -276,6 +288,9 @@ type Cfg struct {
 	// Dashboards
 	DefaultHomeDashboardPath string
 
+	// Alerting
+	AlertingStateHistoryRetention time.Duration
+
 	// Auth
 	LoginCookieName              string
 	LoginMaxInactiveLifetimeDays int
@@ -288,6 +303,9 @@ type Cfg struct {
 	// SAML Auth
 	SAMLEnabled bool
 
+	// JWT Auth
+	JWTAuth JWTAuthSettings
+
 	// Dataproxy
 	SendUserHeader bool
 
@@ -996,6 +1014,11 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	AlertingNotificationTimeout = time.Second * time.Duration(notificationTimeoutSeconds)
 	AlertingMaxAttempts = alerting.Key("max_attempts").MustInt(3)
 	AlertingMinInterval = alerting.Key("min_interval_seconds").MustInt64(1)
+	stateHistoryRetentionDays := alerting.Key("state_history_retention_days").MustInt64(30)
+	cfg.AlertingStateHistoryRetention = time.Hour * 24 * time.Duration(stateHistoryRetentionDays)
+	AlertingHASharding = alerting.Key("ha_sharding").MustBool(false)
+	haHeartbeatIntervalSeconds := alerting.Key("ha_heartbeat_interval_seconds").MustInt64(10)
+	AlertingHAHeartbeatInterval = time.Second * time.Duration(haHeartbeatIntervalSeconds)
 
 	explore := iniFile.Section("explore")
 	ExploreEnabled = explore.Key("enabled").MustBool(true)
@@ -1034,6 +1057,10 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.readSessionConfig()
 	cfg.readSmtpSettings()
 	cfg.readQuotaSettings()
+	cfg.readRecordingRulesSettings()
+	cfg.readQueryCacheSettings()
+	cfg.readAuditSettings()
+	cfg.readJWTAuthSettings()
 
 	if VerifyEmailEnabled && !cfg.Smtp.Enabled {
 		log.Warnf("require_email_validation is enabled but smtp is disabled")
@@ -0,0 +1,22 @@
+package setting
+
+import "time"
+
+type AuditSettings struct {
+	Enabled bool
+
+	// Retention is how long entries are kept, or 0 to keep them forever.
+	Retention time.Duration
+
+	// FilePath is a file entries are also written to as JSON lines.
+	// Relative paths are relative to the logs path.
+	FilePath string
+}
+
+func (cfg *Cfg) readAuditSettings() {
+	sec := cfg.Raw.Section("audit")
+	cfg.Audit.Enabled = sec.Key("enabled").MustBool(false)
+	retentionDays := sec.Key("retention_days").MustInt64(90)
+	cfg.Audit.Retention = time.Hour * 24 * time.Duration(retentionDays)
+	cfg.Audit.FilePath = sec.Key("file_path").String()
+}
@@ -0,0 +1,52 @@
+package setting
+
+import "time"
+
+type JWTAuthSettings struct {
+	Enabled bool
+
+	// HeaderName is the header holding the token. Tokens in the
+	// Authorization header are read after the Bearer prefix.
+	HeaderName string
+
+	// JWKSetFile and KeyFile hold the keys tokens are verified with, as a
+	// JSON Web Key Set or as PEM encoded public keys and certificates.
+	JWKSetFile string
+	KeyFile    string
+
+	ExpectedIssuer   string
+	ExpectedAudience []string
+
+	LoginClaim string
+	EmailClaim string
+	NameClaim  string
+	RoleClaim  string
+
+	AutoSignUp bool
+
+	// AllowNoExpiry accepts tokens without an exp claim. Such tokens never
+	// expire, so they are rejected unless it is set.
+	AllowNoExpiry bool
+
+	// CacheTTL is how long the user of a token is cached before it is synced
+	// again, at most until the token expires.
+	CacheTTL time.Duration
+}
+
+func (cfg *Cfg) readJWTAuthSettings() {
+	sec := cfg.Raw.Section("auth.jwt")
+	cfg.JWTAuth.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.JWTAuth.HeaderName = sec.Key("header_name").MustString("Authorization")
+	cfg.JWTAuth.JWKSetFile = sec.Key("jwk_set_file").String()
+	cfg.JWTAuth.KeyFile = sec.Key("key_file").String()
+	cfg.JWTAuth.ExpectedIssuer = sec.Key("expected_issuer").String()
+	cfg.JWTAuth.ExpectedAudience = sec.Key("expected_audience").Strings(",")
+	cfg.JWTAuth.LoginClaim = sec.Key("login_claim").MustString("sub")
+	cfg.JWTAuth.EmailClaim = sec.Key("email_claim").MustString("email")
+	cfg.JWTAuth.NameClaim = sec.Key("name_claim").MustString("name")
+	cfg.JWTAuth.RoleClaim = sec.Key("role_claim").String()
+	cfg.JWTAuth.AutoSignUp = sec.Key("auto_sign_up").MustBool(true)
+	cfg.JWTAuth.AllowNoExpiry = sec.Key("allow_tokens_without_expiry").MustBool(false)
+	cacheTTLMinutes := sec.Key("cache_ttl").MustInt(60)
+	cfg.JWTAuth.CacheTTL = time.Minute * time.Duration(cacheTTLMinutes)
+}
@@ -0,0 +1,17 @@
+package setting
+
+import "time"
+
+type QueryCacheSettings struct {
+	Enabled bool
+	// TTL is how long responses are cached for data sources that do not set
+	// their own TTL.
+	TTL time.Duration
+}
+
+func (cfg *Cfg) readQueryCacheSettings() {
+	sec := cfg.Raw.Section("query_cache")
+	cfg.QueryCache.Enabled = sec.Key("enabled").MustBool(false)
+	ttlSeconds := sec.Key("ttl_seconds").MustInt64(60)
+	cfg.QueryCache.TTL = time.Second * time.Duration(ttlSeconds)
+}
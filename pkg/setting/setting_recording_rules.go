@@ -0,0 +1,22 @@
+package setting
+
+import "time"
+
+type RecordingRulesSettings struct {
+	Enabled bool
+
+	RemoteWriteURL      string
+	RemoteWriteUser     string
+	RemoteWritePassword string
+	RemoteWriteTimeout  time.Duration
+}
+
+func (cfg *Cfg) readRecordingRulesSettings() {
+	sec := cfg.Raw.Section("recording_rules")
+	cfg.RecordingRules.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.RecordingRules.RemoteWriteURL = sec.Key("remote_write_url").String()
+	cfg.RecordingRules.RemoteWriteUser = sec.Key("remote_write_user").String()
+	cfg.RecordingRules.RemoteWritePassword = sec.Key("remote_write_password").String()
+	timeoutSeconds := sec.Key("remote_write_timeout_seconds").MustInt64(30)
+	cfg.RecordingRules.RemoteWriteTimeout = time.Second * time.Duration(timeoutSeconds)
+}
@@ -38,6 +38,8 @@ type Client interface {
 	GetMinInterval(queryInterval string) (time.Duration, error)
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	ExecuteSQL(r *SQLRequest) (*SQLResponse, error)
+	CloseSQLCursor(cursor string) error
 	EnableDebug()
 }
 
@@ -316,6 +318,78 @@ func (c *baseClientImpl) getMultiSearchQueryParameters() string {
 	return ""
 }
 
+// getSQLPath returns the path of the SQL api, which was part of x-pack before
+// version 7.0 and is not available before version 6.3.
+func (c *baseClientImpl) getSQLPath() (string, error) {
+	switch {
+	case c.version >= 70:
+		return "_sql", nil
+	case c.version >= 60:
+		return "_xpack/sql", nil
+	}
+
+	return "", fmt.Errorf("elasticsearch version=%d does not support sql queries", c.version)
+}
+
+func (c *baseClientImpl) ExecuteSQL(r *SQLRequest) (*SQLResponse, error) {
+	sqlPath, err := c.getSQLPath()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	clientLog.Debug("Executing sql query", "cursor", r.Cursor != "")
+
+	clientRes, err := c.executeRequest(http.MethodPost, sqlPath, "format=json", body)
+	if err != nil {
+		return nil, err
+	}
+	res := clientRes.httpResponse
+	defer res.Body.Close()
+
+	clientLog.Debug("Received sql response", "code", res.StatusCode, "status", res.Status, "content-length", res.ContentLength)
+
+	var sr SQLResponse
+	dec := json.NewDecoder(res.Body)
+	err = dec.Decode(&sr)
+	if err != nil {
+		return nil, err
+	}
+
+	sr.Status = res.StatusCode
+
+	return &sr, nil
+}
+
+func (c *baseClientImpl) CloseSQLCursor(cursor string) error {
+	sqlPath, err := c.getSQLPath()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"cursor": cursor})
+	if err != nil {
+		return err
+	}
+
+	clientRes, err := c.executeRequest(http.MethodPost, path.Join(sqlPath, "close"), "", body)
+	if err != nil {
+		return err
+	}
+	res := clientRes.httpResponse
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to close sql cursor, status=%d", res.StatusCode)
+	}
+
+	return nil
+}
+
 func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder(c.GetVersion())
 }
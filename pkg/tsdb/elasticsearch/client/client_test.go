@@ -365,6 +365,50 @@ func TestClient(t *testing.T) {
 					So(res.Responses, ShouldHaveLength, 1)
 				})
 			})
+
+			Convey("When executing sql query", func() {
+				sc.responseBody = `{
+					"columns": [{ "name": "@timestamp", "type": "datetime" }, { "name": "value", "type": "long" }],
+					"rows": [["2018-05-15T17:50:00.000Z", 10]],
+					"cursor": "abc"
+				}`
+
+				filter, err := NewQueryBuilder().Bool().Filter().AddDateRangeFilter("@timestamp", "2", "1", DateFormatEpochMS).Build()
+				So(err, ShouldBeNil)
+				res, err := sc.client.ExecuteSQL(&SQLRequest{
+					Query:     "SELECT * FROM metrics",
+					FetchSize: 100,
+					Filter:    &Query{Bool: &BoolQuery{Filters: filter}},
+				})
+				So(err, ShouldBeNil)
+
+				Convey("Should send correct request and payload", func() {
+					So(sc.request.Method, ShouldEqual, http.MethodPost)
+					So(sc.request.URL.Path, ShouldEqual, "/_sql")
+					So(sc.request.URL.RawQuery, ShouldEqual, "format=json")
+
+					jBody, err := simplejson.NewJson(sc.requestBody.Bytes())
+					So(err, ShouldBeNil)
+					So(jBody.Get("query").MustString(), ShouldEqual, "SELECT * FROM metrics")
+					So(jBody.Get("fetch_size").MustInt(), ShouldEqual, 100)
+					So(jBody.GetPath("filter", "bool", "filter", "range", "@timestamp", "gte").MustString(), ShouldEqual, "1")
+				})
+
+				Convey("Should parse response", func() {
+					So(res.Status, ShouldEqual, 200)
+					So(res.Columns, ShouldHaveLength, 2)
+					So(res.Columns[1].Type, ShouldEqual, "long")
+					So(res.Rows, ShouldHaveLength, 1)
+					So(res.Cursor, ShouldEqual, "abc")
+				})
+			})
+
+			Convey("When closing sql cursor", func() {
+				sc.responseBody = `{ "succeeded": true }`
+				err := sc.client.CloseSQLCursor("abc")
+				So(err, ShouldBeNil)
+				So(sc.request.URL.Path, ShouldEqual, "/_sql/close")
+			})
 		})
 	})
 }
@@ -36,6 +36,8 @@ type SearchRequest struct {
 	Interval    tsdb.Interval
 	Size        int
 	Sort        map[string]interface{}
+	SortFields  []string
+	SearchAfter []interface{}
 	Query       *Query
 	Aggs        AggArray
 	CustomProps map[string]interface{}
@@ -46,10 +48,21 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	root := make(map[string]interface{})
 
 	root["size"] = r.Size
-	if len(r.Sort) > 0 {
+	if len(r.Sort) > 1 && len(r.SortFields) == len(r.Sort) {
+		// the order of multiple sorts matters, which needs an array
+		sorts := make([]map[string]interface{}, 0, len(r.SortFields))
+		for _, field := range r.SortFields {
+			sorts = append(sorts, map[string]interface{}{field: r.Sort[field]})
+		}
+		root["sort"] = sorts
+	} else if len(r.Sort) > 0 {
 		root["sort"] = r.Sort
 	}
 
+	if len(r.SearchAfter) > 0 {
+		root["search_after"] = r.SearchAfter
+	}
+
 	for key, value := range r.CustomProps {
 		root[key] = value
 	}
@@ -266,6 +279,45 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// CompositeAggregation represents a composite aggregation, which pages
+// through all buckets of its sources with the after key of the previous page
+type CompositeAggregation struct {
+	Size    int                    `json:"size"`
+	Sources []*CompositeSource     `json:"sources"`
+	After   map[string]interface{} `json:"after,omitempty"`
+}
+
+// CompositeSource represents a terms value source of a composite aggregation
+type CompositeSource struct {
+	Key           string
+	Field         string
+	Order         string
+	MissingBucket bool
+}
+
+// MarshalJSON returns the JSON encoding of the composite source
+func (s *CompositeSource) MarshalJSON() ([]byte, error) {
+	terms := map[string]interface{}{
+		"field": s.Field,
+	}
+
+	if s.Order != "" {
+		terms["order"] = s.Order
+	}
+
+	if s.MissingBucket {
+		terms["missing_bucket"] = true
+	}
+
+	root := map[string]interface{}{
+		s.Key: map[string]interface{}{
+			"terms": terms,
+		},
+	}
+
+	return json.Marshal(root)
+}
+
 // ExtendedBounds represents extended bounds
 type ExtendedBounds struct {
 	Min string `json:"min"`
@@ -319,3 +371,51 @@ func (a *PipelineAggregation) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(root)
 }
+
+// SQLRequest represents a request of the SQL api, which either runs a query
+// or fetches the next page of a previous query by its cursor
+type SQLRequest struct {
+	Query     string
+	FetchSize int
+	Filter    *Query
+	Cursor    string
+}
+
+// MarshalJSON returns the JSON encoding of the SQL request.
+func (r *SQLRequest) MarshalJSON() ([]byte, error) {
+	if r.Cursor != "" {
+		return json.Marshal(map[string]interface{}{
+			"cursor": r.Cursor,
+		})
+	}
+
+	root := map[string]interface{}{
+		"query": r.Query,
+	}
+
+	if r.FetchSize > 0 {
+		root["fetch_size"] = r.FetchSize
+	}
+
+	if r.Filter != nil {
+		root["filter"] = r.Filter
+	}
+
+	return json.Marshal(root)
+}
+
+// SQLColumn represents a column of a SQL response
+type SQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SQLResponse represents a SQL response. Only the first page of a query
+// has columns, the following pages fetched by cursor only have rows.
+type SQLResponse struct {
+	Status  int                    `json:"-"`
+	Error   map[string]interface{} `json:"error"`
+	Columns []SQLColumn            `json:"columns"`
+	Rows    [][]interface{}        `json:"rows"`
+	Cursor  string                 `json:"cursor"`
+}
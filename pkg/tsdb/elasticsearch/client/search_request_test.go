@@ -101,6 +101,27 @@ func TestSearchRequest(t *testing.T) {
 				})
 			})
 
+			Convey("When adding multiple sorts and search after", func() {
+				b.SortDesc(timeField, "boolean")
+				b.SortAsc("_doc", "")
+				b.SearchAfter([]interface{}{1526406600000, 10})
+
+				Convey("When marshal to JSON should generate sorts in order", func() {
+					sr, err := b.Build()
+					So(err, ShouldBeNil)
+					body, err := json.Marshal(sr)
+					So(err, ShouldBeNil)
+					json, err := simplejson.NewJson(body)
+					So(err, ShouldBeNil)
+
+					So(json.Get("sort").MustArray(), ShouldHaveLength, 2)
+					So(json.Get("sort").GetIndex(0).GetPath(timeField, "order").MustString(), ShouldEqual, "desc")
+					So(json.Get("sort").GetIndex(1).GetPath("_doc", "order").MustString(), ShouldEqual, "asc")
+					So(json.Get("search_after").MustArray(), ShouldHaveLength, 2)
+					So(json.Get("search_after").GetIndex(0).MustInt64(), ShouldEqual, 1526406600000)
+				})
+			})
+
 			Convey("When adding doc value field", func() {
 				b.AddDocValueField(timeField)
 
@@ -387,6 +408,36 @@ func TestSearchRequest(t *testing.T) {
 					})
 				})
 			})
+			Convey("and adding composite agg with child agg", func() {
+				aggBuilder := b.Agg()
+				aggBuilder.Composite("1", func(a *CompositeAggregation, ib AggBuilder) {
+					a.Size = 100
+					a.Sources = append(a.Sources,
+						&CompositeSource{Key: "1", Field: "@hostname", Order: "asc"},
+						&CompositeSource{Key: "3", Field: "@region", MissingBucket: true},
+					)
+					a.After = map[string]interface{}{"1": "server1", "3": nil}
+					ib.DateHistogram("2", "@timestamp", nil)
+				})
+
+				Convey("When marshal to JSON should generate correct json", func() {
+					sr, err := b.Build()
+					So(err, ShouldBeNil)
+					body, err := json.Marshal(sr)
+					So(err, ShouldBeNil)
+					json, err := simplejson.NewJson(body)
+					So(err, ShouldBeNil)
+
+					compositeAgg := json.GetPath("aggs", "1", "composite")
+					So(compositeAgg.Get("size").MustInt(), ShouldEqual, 100)
+					So(compositeAgg.Get("sources").MustArray(), ShouldHaveLength, 2)
+					So(compositeAgg.Get("sources").GetIndex(0).GetPath("1", "terms", "field").MustString(), ShouldEqual, "@hostname")
+					So(compositeAgg.Get("sources").GetIndex(0).GetPath("1", "terms", "order").MustString(), ShouldEqual, "asc")
+					So(compositeAgg.Get("sources").GetIndex(1).GetPath("3", "terms", "missing_bucket").MustBool(), ShouldBeTrue)
+					So(compositeAgg.GetPath("after", "1").MustString(), ShouldEqual, "server1")
+					So(json.GetPath("aggs", "1", "aggs", "2", "date_histogram", "field").MustString(), ShouldEqual, "@timestamp")
+				})
+			})
 		})
 
 		Convey("Given new search request builder for es version 2", func() {
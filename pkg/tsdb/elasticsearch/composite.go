@@ -0,0 +1,215 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"sort"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	// compositePageSize is the number of buckets of each page of a composite aggregation
+	compositePageSize = 1000
+	// compositeMaxPages limits the number of pages of a composite aggregation
+	compositeMaxPages = 100
+)
+
+// compositeAgg is a composite aggregation which replaces the leading terms
+// aggregations of a query, and collects its buckets page by page.
+type compositeAgg struct {
+	sources []*BucketAgg
+	agg     *es.CompositeAggregation
+	buckets []map[string]interface{}
+}
+
+// compositeSources returns the leading terms aggregations of a query, which
+// can be sources of a composite aggregation.
+func compositeSources(bucketAggs []*BucketAgg) []*BucketAgg {
+	sources := make([]*BucketAgg, 0)
+	for _, bucketAgg := range bucketAggs {
+		if bucketAgg.Type != termsType {
+			break
+		}
+		sources = append(sources, bucketAgg)
+	}
+	return sources
+}
+
+func addCompositeAgg(aggBuilder es.AggBuilder, composite *compositeAgg) es.AggBuilder {
+	aggBuilder.Composite(composite.key(), func(a *es.CompositeAggregation, b es.AggBuilder) {
+		a.Size = compositePageSize
+
+		for _, bucketAgg := range composite.sources {
+			source := &es.CompositeSource{
+				Key:   bucketAgg.ID,
+				Field: bucketAgg.Field,
+			}
+
+			// composite aggregations can only be ordered by the terms
+			if orderBy := bucketAgg.Settings.Get("orderBy").MustString(); orderBy == "_term" || orderBy == "_key" {
+				source.Order = bucketAgg.Settings.Get("order").MustString("asc")
+			}
+
+			if _, err := bucketAgg.Settings.Get("missing").String(); err == nil {
+				source.MissingBucket = true
+			}
+
+			a.Sources = append(a.Sources, source)
+		}
+
+		composite.agg = a
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+func (c *compositeAgg) key() string {
+	return c.sources[0].ID
+}
+
+// collect adds the buckets of a page and returns true if there is a next
+// page, in which case the after key of the aggregation is set to request it.
+func (c *compositeAgg) collect(res *es.SearchResponse) bool {
+	agg, _ := res.Aggregations[c.key()].(map[string]interface{})
+	buckets, _ := agg["buckets"].([]interface{})
+	for _, b := range buckets {
+		if bucket, ok := b.(map[string]interface{}); ok {
+			c.buckets = append(c.buckets, bucket)
+		}
+	}
+
+	if len(buckets) < c.agg.Size {
+		return false
+	}
+
+	// versions before 6.3 have no after key, which is the key of the last bucket
+	afterKey, ok := agg["after_key"].(map[string]interface{})
+	if !ok {
+		afterKey, ok = c.buckets[len(c.buckets)-1]["key"].(map[string]interface{})
+	}
+	if !ok {
+		return false
+	}
+
+	c.agg.After = afterKey
+	return true
+}
+
+// pageCompositeAggs requests the next pages of the composite aggregations of
+// the queries until all buckets are fetched, and then replaces their buckets
+// in the responses with the nested terms buckets the response parser reads.
+func (e *timeSeriesQuery) pageCompositeAggs(req *es.MultiSearchRequest, res *es.MultiSearchResponse, composites map[int]*compositeAgg) error {
+	pending := make([]int, 0, len(composites))
+	for i, composite := range composites {
+		if i >= len(res.Responses) || res.Responses[i].Error != nil {
+			delete(composites, i)
+			continue
+		}
+		if composite.collect(res.Responses[i]) {
+			pending = append(pending, i)
+		}
+	}
+	sort.Ints(pending)
+
+	for page := 1; len(pending) > 0; page++ {
+		if page >= compositeMaxPages {
+			for _, i := range pending {
+				res.Responses[i] = &es.SearchResponse{Error: map[string]interface{}{
+					"reason": fmt.Sprintf("composite aggregation has more than %d buckets", compositeMaxPages*compositePageSize),
+				}}
+				delete(composites, i)
+			}
+			break
+		}
+
+		requests := make([]*es.SearchRequest, 0, len(pending))
+		for _, i := range pending {
+			requests = append(requests, req.Requests[i])
+		}
+
+		pageRes, err := e.client.ExecuteMultisearch(&es.MultiSearchRequest{Requests: requests})
+		if err != nil {
+			return err
+		}
+		if len(pageRes.Responses) != len(requests) {
+			return fmt.Errorf("expected %d responses of composite aggregation page, got %d", len(requests), len(pageRes.Responses))
+		}
+
+		next := make([]int, 0, len(pending))
+		for j, i := range pending {
+			if pageRes.Responses[j].Error != nil {
+				res.Responses[i] = pageRes.Responses[j]
+				delete(composites, i)
+				continue
+			}
+			if composites[i].collect(pageRes.Responses[j]) {
+				next = append(next, i)
+			}
+		}
+		pending = next
+	}
+
+	for i, composite := range composites {
+		res.Responses[i].Aggregations = nestCompositeBuckets(composite.sources, composite.buckets)
+	}
+
+	return nil
+}
+
+// nestCompositeBuckets converts composite buckets, whose key has the value of
+// each source, to the nested buckets of the terms aggregations of the sources.
+func nestCompositeBuckets(sources []*BucketAgg, buckets []map[string]interface{}) map[string]interface{} {
+	source := sources[0]
+	nested := make([]interface{}, 0)
+	groupValues := make(map[string]interface{})
+	groups := make(map[string][]map[string]interface{})
+	groupKeys := make([]string, 0)
+
+	for _, b := range buckets {
+		keys, _ := b["key"].(map[string]interface{})
+		value := keys[source.ID]
+		if value == nil {
+			if missing, err := source.Settings.Get("missing").String(); err == nil {
+				value = missing
+			}
+		}
+
+		if len(sources) == 1 {
+			bucket := make(map[string]interface{}, len(b))
+			for k, v := range b {
+				bucket[k] = v
+			}
+			bucket["key"] = value
+			nested = append(nested, bucket)
+			continue
+		}
+
+		groupKey := fmt.Sprint(value)
+		if _, ok := groups[groupKey]; !ok {
+			groupKeys = append(groupKeys, groupKey)
+			groupValues[groupKey] = value
+		}
+		groups[groupKey] = append(groups[groupKey], b)
+	}
+
+	for _, groupKey := range groupKeys {
+		docCount := 0.0
+		for _, b := range groups[groupKey] {
+			if count, ok := b["doc_count"].(float64); ok {
+				docCount += count
+			}
+		}
+
+		bucket := nestCompositeBuckets(sources[1:], groups[groupKey])
+		bucket["key"] = groupValues[groupKey]
+		bucket["doc_count"] = docCount
+		nested = append(nested, bucket)
+	}
+
+	return map[string]interface{}{
+		source.ID: map[string]interface{}{
+			"buckets": nested,
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
@@ -13,6 +14,7 @@ import (
 type ElasticsearchExecutor struct{}
 
 var (
+	eslog              = log.New("tsdb.elasticsearch")
 	intervalCalculator tsdb.IntervalCalculator
 )
 
@@ -41,6 +43,46 @@ func (e *ElasticsearchExecutor) Query(ctx context.Context, dsInfo *models.DataSo
 		client.EnableDebug()
 	}
 
-	query := newTimeSeriesQuery(client, tsdbQuery, intervalCalculator)
-	return query.execute()
+	sqlQueries := make([]*tsdb.Query, 0)
+	timeSeriesQueries := make([]*tsdb.Query, 0)
+	for _, q := range tsdbQuery.Queries {
+		if isSQLQuery(q) {
+			sqlQueries = append(sqlQueries, q)
+		} else {
+			timeSeriesQueries = append(timeSeriesQueries, q)
+		}
+	}
+
+	if len(sqlQueries) == 0 {
+		query := newTimeSeriesQuery(client, tsdbQuery, intervalCalculator)
+		return query.execute()
+	}
+
+	result := &tsdb.Response{
+		Results: make(map[string]*tsdb.QueryResult),
+	}
+
+	if len(timeSeriesQueries) > 0 {
+		timeSeriesTsdbQuery := *tsdbQuery
+		timeSeriesTsdbQuery.Queries = timeSeriesQueries
+		res, err := newTimeSeriesQuery(client, &timeSeriesTsdbQuery, intervalCalculator).execute()
+		if err != nil {
+			return nil, err
+		}
+		for refID, queryRes := range res.Results {
+			result.Results[refID] = queryRes
+		}
+	}
+
+	sqlTsdbQuery := *tsdbQuery
+	sqlTsdbQuery.Queries = sqlQueries
+	res, err := newSQLQuery(client, &sqlTsdbQuery).execute()
+	if err != nil {
+		return nil, err
+	}
+	for refID, queryRes := range res.Results {
+		result.Results[refID] = queryRes
+	}
+
+	return result, nil
 }
@@ -11,8 +11,14 @@ type Query struct {
 	BucketAggs []*BucketAgg `json:"bucketAggs"`
 	Metrics    []*MetricAgg `json:"metrics"`
 	Alias      string       `json:"alias"`
+	Composite  bool         `json:"composite"`
 	Interval   string
 	RefID      string
+
+	// LogMessageField and LogLevelField are the fields of logs queries
+	// configured by the datasource
+	LogMessageField string
+	LogLevelField   string
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -49,6 +55,8 @@ var metricAggType = map[string]string{
 	"derivative":     "Derivative",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
 }
 
 var extendedStats = map[string]string{
@@ -87,6 +95,20 @@ func isPipelineAggWithMultipleBucketPaths(metricType string) bool {
 	return false
 }
 
+// isDocumentQuery returns true if the query returns documents rather than
+// aggregations, which is the case if its first metric is a document metric
+func isDocumentQuery(q *Query) bool {
+	return len(q.Metrics) > 0 && isDocumentMetric(q.Metrics[0].Type)
+}
+
+func isDocumentMetric(metricType string) bool {
+	switch metricType {
+	case rawDocumentType, rawDataType, logsType:
+		return true
+	}
+	return false
+}
+
 func describeMetric(metricType, field string) string {
 	text := metricAggType[metricType]
 	if metricType == countType {
@@ -1,12 +1,16 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -18,6 +22,9 @@ const (
 	countType         = "count"
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
+	rawDocumentType   = "raw_document"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -76,6 +83,15 @@ func (rp *responseParser) getTimeSeries() (*tsdb.Response, error) {
 		rp.nameSeries(&queryRes.Series, target)
 		rp.trimDatapoints(&queryRes.Series, target)
 
+		if isDocumentQuery(target) {
+			frames, err := rp.processDocuments(res, target, queryRes.Series)
+			if err != nil {
+				return nil, err
+			}
+			queryRes.Series = nil
+			queryRes.Dataframes = tsdb.NewDecodedDataFrames(frames)
+		}
+
 		if len(table.Rows) > 0 {
 			queryRes.Tables = append(queryRes.Tables, &table)
 		}
@@ -169,7 +185,7 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 		}
 
 		switch metric.Type {
-		case countType:
+		case countType, logsType:
 			newSeries := tsdb.TimeSeries{
 				Tags: make(map[string]string),
 			}
@@ -390,6 +406,212 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 	return nil
 }
 
+// processDocuments converts the hits of a document query to a frame with a
+// field per document field, where the fields of nested objects are named by
+// their path. The time field comes first and logs have the message field of
+// the datasource and a level field. The date histogram series of a logs
+// query are returned as frames of the logs volume.
+func (rp *responseParser) processDocuments(res *es.SearchResponse, target *Query, series tsdb.TimeSeriesSlice) (data.Frames, error) {
+	frames := data.Frames{}
+	isLogs := target.Metrics[0].Type == logsType
+
+	if res.Hits != nil && len(res.Hits.Hits) > 0 {
+		docs := make([]map[string]interface{}, 0, len(res.Hits.Hits))
+		propNames := make(map[string]bool)
+
+		for _, hit := range res.Hits.Hits {
+			doc := map[string]interface{}{
+				"_id":    hit["_id"],
+				"_type":  hit["_type"],
+				"_index": hit["_index"],
+			}
+
+			if source, ok := hit["_source"].(map[string]interface{}); ok {
+				flattenDocument(doc, "", source)
+				if isLogs {
+					doc["_source"] = source
+				}
+			}
+
+			if fields, ok := hit["fields"].(map[string]interface{}); ok {
+				for name, value := range fields {
+					if values, ok := value.([]interface{}); ok && len(values) == 1 {
+						value = values[0]
+					}
+					doc[name] = value
+				}
+			}
+
+			for name := range doc {
+				propNames[name] = true
+			}
+			docs = append(docs, doc)
+		}
+
+		times := make([]*time.Time, len(docs))
+		for i, doc := range docs {
+			times[i] = parseDocumentTime(doc[target.TimeField])
+		}
+		frame := data.NewFrame(target.RefID, data.NewField(target.TimeField, nil, times))
+		skip := map[string]bool{target.TimeField: true}
+
+		if isLogs {
+			if target.LogMessageField != "" {
+				frame.Fields = append(frame.Fields, documentStringField(target.LogMessageField, target.LogMessageField, docs))
+				skip[target.LogMessageField] = true
+			}
+			if target.LogLevelField != "" {
+				frame.Fields = append(frame.Fields, documentStringField("level", target.LogLevelField, docs))
+				skip["level"] = true
+			}
+		} else {
+			skip["_source"] = true
+		}
+
+		names := make([]string, 0, len(propNames))
+		for name := range propNames {
+			if !skip[name] {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			frame.Fields = append(frame.Fields, documentField(name, docs))
+		}
+
+		frame.Meta = &data.FrameMeta{}
+		if isLogs {
+			frame.Meta.PreferredVisualization = data.VisTypeLogs
+		}
+
+		// the sort values of the last hit can be used to query the next documents
+		if len(res.Hits.Hits) >= getDocumentSize(target.Metrics[0]) {
+			if sortValues, ok := res.Hits.Hits[len(res.Hits.Hits)-1]["sort"].([]interface{}); ok {
+				frame.Meta.Custom = map[string]interface{}{"searchAfter": sortValues}
+			}
+		}
+
+		if len(res.Hits.Hits) >= maxDocumentSize {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Query result is limited to %d documents", maxDocumentSize),
+			})
+		}
+
+		frames = append(frames, frame)
+	}
+
+	for _, s := range series {
+		frame, err := tsdb.SeriesToFrame(s)
+		if err != nil {
+			return nil, err
+		}
+		frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeGraph}
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+// flattenDocument adds the fields of a document source to the doc, with the
+// fields of nested objects named by their path.
+func flattenDocument(doc map[string]interface{}, prefix string, source map[string]interface{}) {
+	for name, value := range source {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenDocument(doc, name, nested)
+			continue
+		}
+		doc[name] = value
+	}
+}
+
+// documentField returns a field of the values of a document field, which is
+// a number or boolean field if all its values are, and otherwise a string
+// field with objects and arrays encoded as JSON.
+func documentField(name string, docs []map[string]interface{}) *data.Field {
+	isNumber, isBool := true, true
+	for _, doc := range docs {
+		switch doc[name].(type) {
+		case nil:
+		case float64:
+			isBool = false
+		case bool:
+			isNumber = false
+		default:
+			isNumber, isBool = false, false
+		}
+	}
+
+	switch {
+	case isNumber:
+		values := make([]*float64, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(float64); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	case isBool:
+		values := make([]*bool, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(bool); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	}
+
+	return documentStringField(name, name, docs)
+}
+
+func documentStringField(name, docName string, docs []map[string]interface{}) *data.Field {
+	values := make([]*string, len(docs))
+	for i, doc := range docs {
+		switch v := doc[docName].(type) {
+		case nil:
+		case string:
+			values[i] = &v
+		case map[string]interface{}, []interface{}:
+			if b, err := json.Marshal(v); err == nil {
+				s := string(b)
+				values[i] = &s
+			}
+		default:
+			s := fmt.Sprint(v)
+			values[i] = &s
+		}
+	}
+	return data.NewField(name, nil, values)
+}
+
+// parseDocumentTime parses the value of a date field, which is a date string
+// or epoch milliseconds as a number or string.
+func parseDocumentTime(value interface{}) *time.Time {
+	var ms float64
+	switch v := value.(type) {
+	case float64:
+		ms = v
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return &t
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil
+		}
+		ms = f
+	default:
+		return nil
+	}
+
+	t := time.Unix(0, int64(ms)*int64(time.Millisecond)).UTC()
+	return &t
+}
+
 func (rp *responseParser) trimDatapoints(series *tsdb.TimeSeriesSlice, target *Query) {
 	var histogram *BucketAgg
 	for _, bucketAgg := range target.BucketAggs {
@@ -3,9 +3,11 @@ package elasticsearch
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
@@ -939,58 +941,143 @@ func TestResponseParser(t *testing.T) {
 			So(queryRes.Tables[0].Rows[1][3].(null.Float).Float64, ShouldEqual, 12)
 			So(queryRes.Tables[0].Rows[1][4].(null.Float).Float64, ShouldEqual, 48)
 		})
-		// Convey("Raw documents query", func() {
-		// 	targets := map[string]string{
-		// 		"A": `{
-		// 			"timeField": "@timestamp",
-		// 			"metrics": [{ "type": "raw_document", "id": "1" }]
-		// 		}`,
-		// 	}
-		// 	response := `{
-		//     "responses": [
-		//       {
-		//         "hits": {
-		//           "total": 100,
-		//           "hits": [
-		//             {
-		//               "_id": "1",
-		//               "_type": "type",
-		//               "_index": "index",
-		//               "_source": { "sourceProp": "asd" },
-		//               "fields": { "fieldProp": "field" }
-		//             },
-		//             {
-		//               "_source": { "sourceProp": "asd2" },
-		//               "fields": { "fieldProp": "field2" }
-		//             }
-		//           ]
-		//         }
-		//       }
-		//     ]
-		// 	}`
-		// 	rp, err := newResponseParserForTest(targets, response)
-		// 	So(err, ShouldBeNil)
-		// 	result, err := rp.getTimeSeries()
-		// 	So(err, ShouldBeNil)
-		// 	So(result.Results, ShouldHaveLength, 1)
-
-		// 	queryRes := result.Results["A"]
-		// 	So(queryRes, ShouldNotBeNil)
-		// 	So(queryRes.Tables, ShouldHaveLength, 1)
-
-		// 	rows := queryRes.Tables[0].Rows
-		// 	So(rows, ShouldHaveLength, 1)
-		// 	cols := queryRes.Tables[0].Columns
-		// 	So(cols, ShouldHaveLength, 3)
-
-		// 	So(cols[0].Text, ShouldEqual, "host")
-		// 	So(cols[1].Text, ShouldEqual, "Average test")
-		// 	So(cols[2].Text, ShouldEqual, "Average test2")
-
-		// 	So(rows[0][0].(string), ShouldEqual, "server-1")
-		// 	So(rows[0][1].(null.Float).Float64, ShouldEqual, 1000)
-		// 	So(rows[0][2].(null.Float).Float64, ShouldEqual, 3000)
-		// })
+		Convey("Raw documents query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "raw_document", "id": "1", "settings": { "size": 2 } }]
+				}`,
+			}
+			response := `{
+				"responses": [
+					{
+						"hits": {
+							"total": 100,
+							"hits": [
+								{
+									"_id": "1",
+									"_type": "type",
+									"_index": "index",
+									"_source": { "@timestamp": "2018-05-15T17:51:00.000Z", "host": { "name": "server-1" }, "value": 10, "up": true },
+									"sort": [1526406660000, 1]
+								},
+								{
+									"_id": "2",
+									"_type": "type",
+									"_index": "index",
+									"_source": { "host": { "name": "server-2" }, "value": "n/a" },
+									"fields": { "@timestamp": ["1526406600000"] },
+									"sort": [1526406600000, 2]
+								}
+							]
+						}
+					}
+				]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes.Series, ShouldBeEmpty)
+			frames, err := queryRes.Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+
+			frame := frames[0]
+			So(frame.Rows(), ShouldEqual, 2)
+			fieldNames := make([]string, 0)
+			for _, f := range frame.Fields {
+				fieldNames = append(fieldNames, f.Name)
+			}
+			So(fieldNames, ShouldResemble, []string{"@timestamp", "_id", "_index", "_type", "host.name", "up", "value"})
+			So(*frame.Fields[0].At(0).(*time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC))
+			So(*frame.Fields[0].At(1).(*time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC))
+			So(*frame.Fields[4].At(1).(*string), ShouldEqual, "server-2")
+			So(*frame.Fields[5].At(0).(*bool), ShouldBeTrue)
+			So(frame.Fields[5].At(1), ShouldBeNil)
+			So(*frame.Fields[6].At(0).(*string), ShouldEqual, "10")
+			So(frame.Meta.Custom, ShouldResemble, map[string]interface{}{"searchAfter": []interface{}{1526406600000.0, 2.0}})
+		})
+
+		Convey("Raw documents query limited to the maximum size", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "raw_document", "id": "1", "settings": { "size": 1000000 } }]
+				}`,
+			}
+			hits := make([]string, maxDocumentSize)
+			for i := range hits {
+				hits[i] = fmt.Sprintf(`{ "_id": "%d", "_source": { "@timestamp": "2018-05-15T17:51:00.000Z" }, "sort": [1526406660000, "%d"] }`, i, i)
+			}
+			response := fmt.Sprintf(`{ "responses": [{ "hits": { "total": 1000000, "hits": [%s] } }] }`, strings.Join(hits, ","))
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			frames, err := result.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			So(frames[0].Rows(), ShouldEqual, maxDocumentSize)
+			So(frames[0].Meta.Notices, ShouldHaveLength, 1)
+			So(frames[0].Meta.Notices[0].Text, ShouldEqual, "Query result is limited to 100000 documents")
+		})
+
+		Convey("Logs query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "logs", "id": "1" }],
+					"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }]
+				}`,
+			}
+			response := `{
+				"responses": [
+					{
+						"aggregations": {
+							"2": {
+								"buckets": [
+									{ "doc_count": 1, "key": 1526406600000 }
+								]
+							}
+						},
+						"hits": {
+							"hits": [
+								{
+									"_id": "1",
+									"_source": { "@timestamp": "2018-05-15T17:50:00.000Z", "message": "hello", "fields": { "lvl": "info" } }
+								}
+							]
+						}
+					}
+				]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			rp.Targets[0].LogMessageField = "message"
+			rp.Targets[0].LogLevelField = "fields.lvl"
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			frames, err := result.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 2)
+
+			logs := frames[0]
+			So(logs.Meta.PreferredVisualization, ShouldEqual, data.VisTypeLogs)
+			So(logs.Fields[1].Name, ShouldEqual, "message")
+			So(*logs.Fields[1].At(0).(*string), ShouldEqual, "hello")
+			So(logs.Fields[2].Name, ShouldEqual, "level")
+			So(*logs.Fields[2].At(0).(*string), ShouldEqual, "info")
+
+			volume := frames[1]
+			So(volume.Meta.PreferredVisualization, ShouldEqual, data.VisTypeGraph)
+			So(volume.Rows(), ShouldEqual, 1)
+			So(*volume.Fields[1].At(0).(*float64), ShouldEqual, 1)
+		})
 	})
 }
 
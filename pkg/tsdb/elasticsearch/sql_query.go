@@ -0,0 +1,211 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	sqlQueryType = "sql"
+	// sqlFetchSize is the number of rows of each page of a sql query
+	sqlFetchSize = 1000
+	// sqlMaxRows limits the number of rows of a sql query
+	sqlMaxRows = 10000
+)
+
+type sqlQuery struct {
+	client    es.Client
+	tsdbQuery *tsdb.TsdbQuery
+}
+
+var newSQLQuery = func(client es.Client, tsdbQuery *tsdb.TsdbQuery) *sqlQuery {
+	return &sqlQuery{
+		client:    client,
+		tsdbQuery: tsdbQuery,
+	}
+}
+
+func isSQLQuery(query *tsdb.Query) bool {
+	return query.Model.Get("queryType").MustString() == sqlQueryType
+}
+
+func (e *sqlQuery) execute() (*tsdb.Response, error) {
+	result := &tsdb.Response{}
+	result.Results = make(map[string]*tsdb.QueryResult)
+
+	for _, q := range e.tsdbQuery.Queries {
+		queryRes, err := e.executeQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		result.Results[q.RefId] = queryRes
+	}
+
+	return result, nil
+}
+
+// executeQuery runs the sql query of the model, limited to the time range by
+// a filter on the time field, and fetches its rows page by page.
+func (e *sqlQuery) executeQuery(q *tsdb.Query) (*tsdb.QueryResult, error) {
+	rawSQL := strings.TrimSpace(q.Model.Get("query").MustString())
+	if rawSQL == "" {
+		return &tsdb.QueryResult{
+			RefId:       q.RefId,
+			Error:       fmt.Errorf("invalid query, missing sql"),
+			ErrorString: "invalid query, missing sql",
+		}, nil
+	}
+
+	from := fmt.Sprintf("%d", e.tsdbQuery.TimeRange.GetFromAsMsEpoch())
+	to := fmt.Sprintf("%d", e.tsdbQuery.TimeRange.GetToAsMsEpoch())
+
+	qb := es.NewQueryBuilder()
+	qb.Bool().Filter().AddDateRangeFilter(e.client.GetTimeField(), to, from, es.DateFormatEpochMS)
+	filter, err := qb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := e.client.ExecuteSQL(&es.SQLRequest{
+		Query:     rawSQL,
+		FetchSize: sqlFetchSize,
+		Filter:    filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	columns := res.Columns
+	rows := res.Rows
+	for res.Error == nil && res.Cursor != "" && len(rows) < sqlMaxRows {
+		res, err = e.client.ExecuteSQL(&es.SQLRequest{Cursor: res.Cursor})
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, res.Rows...)
+	}
+
+	if res.Error != nil {
+		queryRes := getErrorFromElasticResponse(&es.SearchResponse{Error: res.Error})
+		queryRes.RefId = q.RefId
+		queryRes.Error = errors.New(queryRes.ErrorString)
+		return queryRes, nil
+	}
+
+	truncated := len(rows) > sqlMaxRows
+	if res.Cursor != "" {
+		truncated = true
+		if err := e.client.CloseSQLCursor(res.Cursor); err != nil {
+			eslog.Warn("Failed to close sql cursor", "error", err)
+		}
+	}
+	if len(rows) > sqlMaxRows {
+		rows = rows[:sqlMaxRows]
+	}
+
+	frame, err := sqlResponseToFrame(q.RefId, columns, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	frame.Meta = &data.FrameMeta{ExecutedQueryString: rawSQL}
+	if truncated {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Query result is limited to %d rows", sqlMaxRows),
+		})
+	}
+
+	return &tsdb.QueryResult{
+		RefId:      q.RefId,
+		Dataframes: tsdb.NewDecodedDataFrames(data.Frames{frame}),
+	}, nil
+}
+
+// sqlResponseToFrame converts the rows of a sql response to a frame with a
+// field per column. Dates become time fields and numbers float fields, so
+// that a frame with a time column can be read as a time series.
+func sqlResponseToFrame(refID string, columns []es.SQLColumn, rows [][]interface{}) (*data.Frame, error) {
+	frame := data.NewFrame(refID)
+
+	for _, row := range rows {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("sql response has %d columns but a row of %d values", len(columns), len(row))
+		}
+	}
+
+	for i, column := range columns {
+		var field *data.Field
+
+		switch column.Type {
+		case "date", "datetime":
+			values := make([]*time.Time, len(rows))
+			for j, row := range rows {
+				v, err := sqlTimeValue(row[i])
+				if err != nil {
+					return nil, fmt.Errorf("invalid value of column %s: %w", column.Name, err)
+				}
+				values[j] = v
+			}
+			field = data.NewField(column.Name, nil, values)
+
+		case "byte", "short", "integer", "long", "unsigned_long", "double", "float", "half_float", "scaled_float":
+			values := make([]*float64, len(rows))
+			for j, row := range rows {
+				if v, ok := row[i].(float64); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(column.Name, nil, values)
+
+		case "boolean":
+			values := make([]*bool, len(rows))
+			for j, row := range rows {
+				if v, ok := row[i].(bool); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(column.Name, nil, values)
+
+		default:
+			values := make([]*string, len(rows))
+			for j, row := range rows {
+				if row[i] != nil {
+					v := fmt.Sprint(row[i])
+					values[j] = &v
+				}
+			}
+			field = data.NewField(column.Name, nil, values)
+		}
+
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	return frame, nil
+}
+
+// sqlTimeValue parses a date value, which is a ISO 8601 string or a number
+// of epoch milliseconds.
+func sqlTimeValue(value interface{}) (*time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		t := time.Unix(0, int64(v)*int64(time.Millisecond)).UTC()
+		return &t, nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	return nil, fmt.Errorf("unexpected date %v", value)
+}
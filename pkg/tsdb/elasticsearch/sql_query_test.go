@@ -0,0 +1,91 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExecuteSQLQuery(t *testing.T) {
+	Convey("Test execute sql query", t, func() {
+		c := newFakeClient(70)
+		tsdbQuery := &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1526406600000", "1526406900000"),
+			Queries: []*tsdb.Query{{
+				RefId: "A",
+				Model: simplejson.NewFromAny(map[string]interface{}{
+					"queryType": "sql",
+					"query":     "SELECT \"@timestamp\", host, value FROM metrics ORDER BY \"@timestamp\"",
+				}),
+			}},
+		}
+
+		columns := []es.SQLColumn{
+			{Name: "@timestamp", Type: "datetime"},
+			{Name: "host", Type: "keyword"},
+			{Name: "value", Type: "long"},
+		}
+
+		Convey("Should fetch all pages and return a frame", func() {
+			c.sqlResponses = []*es.SQLResponse{
+				{Columns: columns, Rows: [][]interface{}{{"2018-05-15T17:50:00.000Z", "server1", 10.0}}, Cursor: "page2"},
+				{Rows: [][]interface{}{{"2018-05-15T17:51:00.000Z", nil, nil}}},
+			}
+
+			res, err := newSQLQuery(c, tsdbQuery).execute()
+			So(err, ShouldBeNil)
+
+			So(c.sqlRequests, ShouldHaveLength, 2)
+			So(c.sqlRequests[0].FetchSize, ShouldEqual, sqlFetchSize)
+			rangeFilter := c.sqlRequests[0].Filter.Bool.Filters[0].(*es.RangeFilter)
+			So(rangeFilter.Key, ShouldEqual, "@timestamp")
+			So(rangeFilter.Gte, ShouldEqual, "1526406600000")
+			So(rangeFilter.Lte, ShouldEqual, "1526406900000")
+			So(c.sqlRequests[1].Cursor, ShouldEqual, "page2")
+			So(c.closedCursors, ShouldBeEmpty)
+
+			frames, err := res.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			frame := frames[0]
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Fields[0].Type(), ShouldEqual, data.FieldTypeNullableTime)
+			So(*frame.Fields[0].At(1).(*time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC))
+			So(*frame.Fields[1].At(0).(*string), ShouldEqual, "server1")
+			So(*frame.Fields[2].At(0).(*float64), ShouldEqual, 10)
+			So(frame.Fields[2].At(1), ShouldBeNil)
+		})
+
+		Convey("Should close the cursor of results over the row limit", func() {
+			rows := make([][]interface{}, sqlMaxRows)
+			for i := range rows {
+				rows[i] = []interface{}{"2018-05-15T17:50:00.000Z", "server1", float64(i)}
+			}
+			c.sqlResponses = []*es.SQLResponse{{Columns: columns, Rows: rows, Cursor: "more"}}
+
+			res, err := newSQLQuery(c, tsdbQuery).execute()
+			So(err, ShouldBeNil)
+			So(c.closedCursors, ShouldResemble, []string{"more"})
+
+			frames, err := res.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames[0].Rows(), ShouldEqual, sqlMaxRows)
+			So(frames[0].Meta.Notices, ShouldHaveLength, 1)
+		})
+
+		Convey("Should return error of response", func() {
+			c.sqlResponses = []*es.SQLResponse{{Status: 400, Error: map[string]interface{}{
+				"root_cause": []interface{}{map[string]interface{}{"reason": "Unknown index [metrics]"}},
+			}}}
+
+			res, err := newSQLQuery(c, tsdbQuery).execute()
+			So(err, ShouldBeNil)
+			So(res.Results["A"].ErrorString, ShouldEqual, "Unknown index [metrics]")
+		})
+	})
+}
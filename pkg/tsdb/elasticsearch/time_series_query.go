@@ -2,6 +2,7 @@ package elasticsearch
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
@@ -38,7 +39,10 @@ func (e *timeSeriesQuery) execute() (*tsdb.Response, error) {
 	from := fmt.Sprintf("%d", e.tsdbQuery.TimeRange.GetFromAsMsEpoch())
 	to := fmt.Sprintf("%d", e.tsdbQuery.TimeRange.GetToAsMsEpoch())
 
-	for _, q := range queries {
+	composites := make(map[int]*compositeAgg)
+	documents := make(map[int]int)
+
+	for i, q := range queries {
 		minInterval, err := e.client.GetMinInterval(q.Interval)
 		if err != nil {
 			return nil, err
@@ -54,26 +58,47 @@ func (e *timeSeriesQuery) execute() (*tsdb.Response, error) {
 			filters.AddQueryStringFilter(q.RawQuery, true)
 		}
 
+		if isDocumentQuery(q) {
+			documents[i] = addDocumentQuery(b, q.Metrics[0], e.client.GetTimeField(), e.client.GetVersion())
+
+			// logs queries can have a date histogram of the logs volume
+			if q.Metrics[0].Type != logsType || len(q.BucketAggs) == 0 {
+				continue
+			}
+		}
+
 		if len(q.BucketAggs) == 0 {
-			if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
+			result.Results[q.RefID] = &tsdb.QueryResult{
+				RefId:       q.RefID,
+				Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
+				ErrorString: "invalid query, missing metrics and aggregations",
+			}
+			continue
+		}
+
+		aggBuilder := b.Agg()
+		bucketAggs := q.BucketAggs
+
+		if q.Composite {
+			if e.client.GetVersion() < 60 {
 				result.Results[q.RefID] = &tsdb.QueryResult{
 					RefId:       q.RefID,
-					Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
-					ErrorString: "invalid query, missing metrics and aggregations",
+					Error:       fmt.Errorf("composite aggregation requires elasticsearch 6.x or later"),
+					ErrorString: "composite aggregation requires elasticsearch 6.x or later",
 				}
 				continue
 			}
-			metric := q.Metrics[0]
-			b.Size(metric.Settings.Get("size").MustInt(500))
-			b.SortDesc("@timestamp", "boolean")
-			b.AddDocValueField("@timestamp")
-			continue
-		}
 
-		aggBuilder := b.Agg()
+			if sources := compositeSources(q.BucketAggs); len(sources) > 0 {
+				composite := &compositeAgg{sources: sources}
+				aggBuilder = addCompositeAgg(aggBuilder, composite)
+				composites[i] = composite
+				bucketAggs = q.BucketAggs[len(sources):]
+			}
+		}
 
 		// iterate backwards to create aggregations bottom-down
-		for _, bucketAgg := range q.BucketAggs {
+		for _, bucketAgg := range bucketAggs {
 			switch bucketAgg.Type {
 			case dateHistType:
 				aggBuilder = addDateHistogramAgg(aggBuilder, bucketAgg, from, to)
@@ -90,7 +115,7 @@ func (e *timeSeriesQuery) execute() (*tsdb.Response, error) {
 
 		for _, m := range q.Metrics {
 			m := m
-			if m.Type == countType {
+			if m.Type == countType || isDocumentMetric(m.Type) {
 				continue
 			}
 
@@ -164,8 +189,158 @@ func (e *timeSeriesQuery) execute() (*tsdb.Response, error) {
 		return nil, err
 	}
 
+	if len(composites) > 0 {
+		if err := e.pageCompositeAggs(req, res, composites); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(documents) > 0 {
+		if err := e.pageDocuments(req, res, documents); err != nil {
+			return nil, err
+		}
+	}
+
 	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
-	return rp.getTimeSeries()
+	parsed, err := rp.getTimeSeries()
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the errors of invalid queries, whose searches were still sent
+	for refID, queryRes := range result.Results {
+		parsed.Results[refID] = queryRes
+	}
+
+	return parsed, nil
+}
+
+const (
+	// defaultDocumentSize is the number of documents of a document query without a size
+	defaultDocumentSize = 500
+	// documentPageSize is the number of documents of each page of a document
+	// query, which is the default limit of a search of elasticsearch
+	documentPageSize = 10000
+	// maxDocumentSize limits the number of documents of a document query
+	maxDocumentSize = 100000
+)
+
+// addDocumentQuery makes the search return the latest documents, sorted by
+// the time field and then by document id to page through them with
+// search_after, and returns the number of documents to fetch.
+func addDocumentQuery(b *es.SearchRequestBuilder, metric *MetricAgg, timeField string, version int) int {
+	size := getDocumentSize(metric)
+	pageSize := size
+	if pageSize > documentPageSize {
+		pageSize = documentPageSize
+	}
+
+	b.Size(pageSize)
+	b.SortDesc(timeField, "boolean")
+	b.SortAsc(getDocumentIDField(version), "")
+	b.AddDocValueField(timeField)
+
+	if searchAfter := metric.Settings.Get("searchAfter").MustArray(); len(searchAfter) > 0 {
+		b.SearchAfter(searchAfter)
+	}
+
+	return size
+}
+
+// getDocumentIDField returns the field to break ties between documents
+// with the same time. Unlike the index order of _doc, which is only unique
+// within a shard, the document id is the same on every page of the search.
+// Before version 6.0 the id can only be sorted on through _uid.
+func getDocumentIDField(version int) string {
+	if version < 60 {
+		return "_uid"
+	}
+	return "_id"
+}
+
+// getDocumentSize returns the number of documents of a document metric,
+// which is at most maxDocumentSize.
+func getDocumentSize(metric *MetricAgg) int {
+	size := defaultDocumentSize
+	if s, err := metric.Settings.Get("size").Int(); err == nil && s > 0 {
+		size = s
+	} else if s, err := strconv.Atoi(metric.Settings.Get("size").MustString()); err == nil && s > 0 {
+		size = s
+	}
+
+	if size > maxDocumentSize {
+		return maxDocumentSize
+	}
+	return size
+}
+
+// pageDocuments requests the next pages of the document queries, given by
+// the number of documents to fetch by index, until they have as many
+// documents or there are no more, and adds the hits to their responses.
+func (e *timeSeriesQuery) pageDocuments(req *es.MultiSearchRequest, res *es.MultiSearchResponse, documents map[int]int) error {
+	hasNextPage := func(i int, page *es.SearchResponse) bool {
+		if page.Hits == nil || len(page.Hits.Hits) < req.Requests[i].Size {
+			return false
+		}
+		if len(res.Responses[i].Hits.Hits) >= documents[i] {
+			return false
+		}
+
+		sortValues, ok := page.Hits.Hits[len(page.Hits.Hits)-1]["sort"].([]interface{})
+		if !ok {
+			return false
+		}
+
+		remaining := documents[i] - len(res.Responses[i].Hits.Hits)
+		if remaining < req.Requests[i].Size {
+			req.Requests[i].Size = remaining
+		}
+		req.Requests[i].SearchAfter = sortValues
+		// the aggregations of logs queries are already in the first page
+		req.Requests[i].Aggs = nil
+		return true
+	}
+
+	pending := make([]int, 0, len(documents))
+	for i := range documents {
+		if i < len(res.Responses) && res.Responses[i].Error == nil && hasNextPage(i, res.Responses[i]) {
+			pending = append(pending, i)
+		}
+	}
+	sort.Ints(pending)
+
+	for len(pending) > 0 {
+		requests := make([]*es.SearchRequest, 0, len(pending))
+		for _, i := range pending {
+			requests = append(requests, req.Requests[i])
+		}
+
+		pageRes, err := e.client.ExecuteMultisearch(&es.MultiSearchRequest{Requests: requests})
+		if err != nil {
+			return err
+		}
+		if len(pageRes.Responses) != len(requests) {
+			return fmt.Errorf("expected %d responses of document page, got %d", len(requests), len(pageRes.Responses))
+		}
+
+		next := make([]int, 0, len(pending))
+		for j, i := range pending {
+			page := pageRes.Responses[j]
+			if page.Error != nil {
+				res.Responses[i] = page
+				continue
+			}
+			if page.Hits != nil {
+				res.Responses[i].Hits.Hits = append(res.Responses[i].Hits.Hits, page.Hits.Hits...)
+			}
+			if hasNextPage(i, page) {
+				next = append(next, i)
+			}
+		}
+		pending = next
+	}
+
+	return nil
 }
 
 func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFrom, timeTo string) es.AggBuilder {
@@ -305,6 +480,12 @@ func (p *timeSeriesQueryParser) parse(tsdbQuery *tsdb.TsdbQuery) ([]*Query, erro
 			return nil, err
 		}
 		alias := model.Get("alias").MustString("")
+		logMessageField, logLevelField := "", ""
+		if q.DataSource != nil && q.DataSource.JsonData != nil {
+			logMessageField = q.DataSource.JsonData.Get("logMessageField").MustString()
+			logLevelField = q.DataSource.JsonData.Get("logLevelField").MustString()
+		}
+		composite := model.Get("composite").MustBool(false)
 		interval := strconv.FormatInt(q.IntervalMs, 10) + "ms"
 
 		queries = append(queries, &Query{
@@ -313,8 +494,12 @@ func (p *timeSeriesQueryParser) parse(tsdbQuery *tsdb.TsdbQuery) ([]*Query, erro
 			BucketAggs: bucketAggs,
 			Metrics:    metrics,
 			Alias:      alias,
+			Composite:  composite,
 			Interval:   interval,
 			RefID:      q.RefId,
+
+			LogMessageField: logMessageField,
+			LogLevelField:   logLevelField,
 		})
 	}
 
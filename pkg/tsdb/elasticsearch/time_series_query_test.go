@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -302,6 +303,85 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(sr.Size, ShouldEqual, 1337)
 		})
 
+		Convey("With raw document metric size over the maximum", func() {
+			c := newFakeClient(5)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_document", "settings": { "size": 1000000 }	}]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, documentPageSize)
+			So(sr.SortFields, ShouldResemble, []string{"@timestamp", "_uid"})
+			So(getDocumentSize(&MetricAgg{Settings: simplejson.NewFromAny(map[string]interface{}{"size": 1000000})}), ShouldEqual, maxDocumentSize)
+		})
+
+		Convey("With raw data metric sorted by time field", func() {
+			c := newFakeClient(70)
+			c.timeField = "timestamp"
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": "20", "searchAfter": [1526406600000, 3] } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 20)
+			So(sr.SortFields, ShouldResemble, []string{"timestamp", "_id"})
+			So(sr.SearchAfter, ShouldResemble, []interface{}{json.Number("1526406600000"), json.Number("3")})
+			So(sr.CustomProps["docvalue_fields"], ShouldResemble, []string{"timestamp"})
+		})
+
+		Convey("With raw data metric larger than a page", func() {
+			c := newFakeClient(70)
+			hits := func(n int, last float64) *es.MultiSearchResponse {
+				page := make([]map[string]interface{}, n)
+				for i := range page {
+					page[i] = map[string]interface{}{"_id": fmt.Sprint(i), "sort": []interface{}{last, float64(i)}}
+				}
+				return &es.MultiSearchResponse{Responses: []*es.SearchResponse{{Hits: &es.SearchResponseHits{Hits: page}}}}
+			}
+			c.multiSearchResponses = []*es.MultiSearchResponse{
+				hits(documentPageSize, 1000),
+				hits(10, 500),
+			}
+
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": 15000 } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(c.multisearchRequests, ShouldHaveLength, 2)
+			sr := c.multisearchRequests[1].Requests[0]
+			So(sr.Size, ShouldEqual, 5000)
+			So(sr.SearchAfter, ShouldResemble, []interface{}{1000.0, float64(documentPageSize - 1)})
+
+			frames, err := res.Results[""].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames[0].Rows(), ShouldEqual, documentPageSize+10)
+		})
+
+		Convey("With logs metric and date histogram agg", func() {
+			c := newFakeClient(70)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{ "id": "1", "type": "logs" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, defaultDocumentSize)
+			So(sr.Aggs, ShouldHaveLength, 1)
+			So(sr.Aggs[0].Aggregation.Type, ShouldEqual, "date_histogram")
+			So(sr.Aggs[0].Aggregation.Aggs, ShouldBeEmpty)
+		})
+
 		Convey("With date histogram agg", func() {
 			c := newFakeClient(5)
 			_, err := executeTsdbQuery(c, `{
@@ -720,16 +800,79 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 				"var1": "_count",
 			})
 		})
+
+		Convey("With composite terms aggs", func() {
+			c := newFakeClient(70)
+			page := func(afterKey map[string]interface{}, keys ...string) *es.MultiSearchResponse {
+				buckets := make([]interface{}, 0)
+				for _, key := range keys {
+					buckets = append(buckets, map[string]interface{}{
+						"key":       map[string]interface{}{"2": key, "3": "eu"},
+						"doc_count": 1.0,
+						"4": map[string]interface{}{
+							"buckets": []interface{}{map[string]interface{}{"key": 1000.0, "doc_count": 1.0}},
+						},
+					})
+				}
+				agg := map[string]interface{}{"buckets": buckets}
+				if afterKey != nil {
+					agg["after_key"] = afterKey
+				}
+				return &es.MultiSearchResponse{Responses: []*es.SearchResponse{{Aggregations: map[string]interface{}{"2": agg}}}}
+			}
+
+			firstPage := make([]string, compositePageSize)
+			for i := range firstPage {
+				firstPage[i] = fmt.Sprintf("host-%d", i)
+			}
+			c.multiSearchResponses = []*es.MultiSearchResponse{
+				page(map[string]interface{}{"2": "host-999", "3": "eu"}, firstPage...),
+				page(nil, "last"),
+			}
+
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"composite": true,
+				"bucketAggs": [
+					{ "type": "terms", "field": "@host", "id": "2", "settings": { "size": "10", "orderBy": "_term", "order": "desc" } },
+					{ "type": "terms", "field": "@region", "id": "3", "settings": { "missing": "unknown" } },
+					{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+				],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+
+			So(c.multisearchRequests, ShouldHaveLength, 2)
+			sr := c.multisearchRequests[0].Requests[0]
+			So(sr.Aggs[0].Key, ShouldEqual, "2")
+			So(sr.Aggs[0].Aggregation.Type, ShouldEqual, "composite")
+			compositeAgg := sr.Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+			So(compositeAgg.Size, ShouldEqual, compositePageSize)
+			So(compositeAgg.Sources, ShouldHaveLength, 2)
+			So(compositeAgg.Sources[0].Field, ShouldEqual, "@host")
+			So(compositeAgg.Sources[0].Order, ShouldEqual, "desc")
+			So(compositeAgg.Sources[1].MissingBucket, ShouldBeTrue)
+			So(compositeAgg.After, ShouldResemble, map[string]interface{}{"2": "host-999", "3": "eu"})
+			So(sr.Aggs[0].Aggregation.Aggs[0].Key, ShouldEqual, "4")
+
+			series := res.Results[""].Series
+			So(series, ShouldHaveLength, compositePageSize+1)
+			So(series[compositePageSize].Tags, ShouldResemble, map[string]string{"@host": "last", "@region": "eu"})
+		})
 	})
 }
 
 type fakeClient struct {
-	version             int
-	timeField           string
-	multiSearchResponse *es.MultiSearchResponse
-	multiSearchError    error
-	builder             *es.MultiSearchRequestBuilder
-	multisearchRequests []*es.MultiSearchRequest
+	version              int
+	timeField            string
+	multiSearchResponse  *es.MultiSearchResponse
+	multiSearchResponses []*es.MultiSearchResponse
+	multiSearchError     error
+	builder              *es.MultiSearchRequestBuilder
+	multisearchRequests  []*es.MultiSearchRequest
+	sqlResponses         []*es.SQLResponse
+	sqlRequests          []*es.SQLRequest
+	closedCursors        []string
 }
 
 func newFakeClient(version int) *fakeClient {
@@ -757,9 +900,26 @@ func (c *fakeClient) GetMinInterval(queryInterval string) (time.Duration, error)
 
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multiSearchResponses) > 0 {
+		res := c.multiSearchResponses[0]
+		c.multiSearchResponses = c.multiSearchResponses[1:]
+		return res, c.multiSearchError
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteSQL(r *es.SQLRequest) (*es.SQLResponse, error) {
+	c.sqlRequests = append(c.sqlRequests, r)
+	res := c.sqlResponses[0]
+	c.sqlResponses = c.sqlResponses[1:]
+	return res, nil
+}
+
+func (c *fakeClient) CloseSQLCursor(cursor string) error {
+	c.closedCursors = append(c.closedCursors, cursor)
+	return nil
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder(c.version)
 	return c.builder
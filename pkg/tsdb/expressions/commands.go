@@ -0,0 +1,329 @@
+package expressions
+
+import (
+	"math"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/components/mathexp"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// maxResamplePoints limits the number of points of a resampled series.
+const maxResamplePoints = 100000
+
+// command is the operation of an expression query.
+type command interface {
+	// inputs returns the refIds of the queries the command reads.
+	inputs() []string
+
+	// execute runs the command on the items of its inputs.
+	execute(refID string, timeRange *tsdb.TimeRange, vars map[string][]*item) ([]*item, error)
+}
+
+// newCommand parses the model of an expression query.
+func newCommand(refID string, model *simplejson.Json) (command, error) {
+	commandType := model.Get("type").MustString("")
+	expression := strings.TrimSpace(model.Get("expression").MustString(""))
+	if expression == "" {
+		return nil, errorf(refID, "missing expression")
+	}
+
+	switch commandType {
+	case "math":
+		expr, err := mathexp.Parse(expression)
+		if err != nil {
+			return nil, errorf(refID, "%v", err)
+		}
+		return &mathCommand{expr: expr}, nil
+
+	case "reduce":
+		reducer := model.Get("reducer").MustString("")
+		if _, ok := reducers[reducer]; !ok {
+			return nil, errorf(refID, "unsupported reducer %q", reducer)
+		}
+		return &reduceCommand{input: inputRefID(expression), reducer: reducer}, nil
+
+	case "resample":
+		rule := model.Get("rule").MustString("")
+		window, err := gtime.ParseInterval(rule)
+		if err != nil || window <= 0 {
+			return nil, errorf(refID, "invalid resample window %q", rule)
+		}
+
+		downsampler := model.Get("downsampler").MustString("last")
+		if _, ok := downsamplers[downsampler]; !ok {
+			return nil, errorf(refID, "unsupported downsampler %q", downsampler)
+		}
+
+		upsampler := model.Get("upsampler").MustString("fillna")
+		switch upsampler {
+		case "pad", "backfilling", "fillna":
+		default:
+			return nil, errorf(refID, "unsupported upsampler %q", upsampler)
+		}
+
+		return &resampleCommand{
+			input:       inputRefID(expression),
+			window:      window.Milliseconds(),
+			downsampler: downsampler,
+			upsampler:   upsampler,
+		}, nil
+	}
+
+	return nil, errorf(refID, "unsupported expression type %q", commandType)
+}
+
+// inputRefID returns the refId an expression such as `$A` or `A` refers to.
+func inputRefID(expression string) string {
+	return strings.TrimPrefix(expression, "$")
+}
+
+// mathCommand evaluates a math expression such as `$A + $B` on the items
+// of its inputs, see joinItems for how items are matched.
+type mathCommand struct {
+	expr *mathexp.Expression
+}
+
+func (c *mathCommand) inputs() []string {
+	return c.expr.Variables()
+}
+
+func (c *mathCommand) execute(refID string, timeRange *tsdb.TimeRange, vars map[string][]*item) ([]*item, error) {
+	names := c.expr.Variables()
+	if len(names) == 0 {
+		return []*item{newNumber("", nil, c.expr.Eval(nil))}, nil
+	}
+
+	result := make([]*item, 0)
+	for _, row := range joinItems(names, vars) {
+		result = append(result, c.evalRow(names, row))
+	}
+	return result, nil
+}
+
+// evalRow evaluates the expression on one set of joined items. The result
+// is a number when all items are numbers, and otherwise a series with a
+// point at each time all series have a point.
+func (c *mathCommand) evalRow(names []string, row map[string]*item) *item {
+	labels := make([]data.Labels, 0, len(row))
+	var series []*item
+	for _, name := range names {
+		i := row[name]
+		labels = append(labels, i.labels)
+		if !i.isNumber {
+			series = append(series, i)
+		}
+	}
+
+	result := &item{labels: mergeLabels(labels...)}
+	if len(series) == 0 {
+		values := make(map[string]null.Float, len(row))
+		for name, i := range row {
+			values[name] = i.number
+		}
+		result.isNumber = true
+		result.number = c.expr.Eval(values)
+		return result
+	}
+
+	result.points = make([]point, 0, len(series[0].points))
+	for _, p := range series[0].points {
+		values := make(map[string]null.Float, len(row))
+		complete := true
+		for name, i := range row {
+			if i.isNumber {
+				values[name] = i.number
+				continue
+			}
+
+			value, ok := i.valueAt(p.time)
+			if !ok {
+				complete = false
+				break
+			}
+			values[name] = value
+		}
+
+		if complete {
+			result.points = append(result.points, point{time: p.time, value: c.expr.Eval(values)})
+		}
+	}
+
+	return result
+}
+
+// reduceCommand reduces each series of its input to a number.
+type reduceCommand struct {
+	input   string
+	reducer string
+}
+
+func (c *reduceCommand) inputs() []string {
+	return []string{c.input}
+}
+
+func (c *reduceCommand) execute(refID string, timeRange *tsdb.TimeRange, vars map[string][]*item) ([]*item, error) {
+	reduce := reducers[c.reducer]
+
+	result := make([]*item, 0, len(vars[c.input]))
+	for _, i := range vars[c.input] {
+		if i.isNumber {
+			result = append(result, i)
+			continue
+		}
+
+		values := make([]float64, 0, len(i.points))
+		for _, p := range i.points {
+			if p.value.Valid {
+				values = append(values, p.value.Float64)
+			}
+		}
+		result = append(result, newNumber(i.name, i.labels, reduce(values)))
+	}
+
+	return result, nil
+}
+
+// reducers reduce the non null values of a series. All but count return
+// null for a series without values.
+var reducers = map[string]func(values []float64) null.Float{
+	"min": func(values []float64) null.Float {
+		if len(values) == 0 {
+			return null.FloatFromPtr(nil)
+		}
+		min := values[0]
+		for _, v := range values[1:] {
+			min = math.Min(min, v)
+		}
+		return null.FloatFrom(min)
+	},
+	"max": func(values []float64) null.Float {
+		if len(values) == 0 {
+			return null.FloatFromPtr(nil)
+		}
+		max := values[0]
+		for _, v := range values[1:] {
+			max = math.Max(max, v)
+		}
+		return null.FloatFrom(max)
+	},
+	"sum": func(values []float64) null.Float {
+		if len(values) == 0 {
+			return null.FloatFromPtr(nil)
+		}
+		return null.FloatFrom(sum(values))
+	},
+	"mean": func(values []float64) null.Float {
+		if len(values) == 0 {
+			return null.FloatFromPtr(nil)
+		}
+		return null.FloatFrom(sum(values) / float64(len(values)))
+	},
+	"count": func(values []float64) null.Float {
+		return null.FloatFrom(float64(len(values)))
+	},
+	"last": func(values []float64) null.Float {
+		if len(values) == 0 {
+			return null.FloatFromPtr(nil)
+		}
+		return null.FloatFrom(values[len(values)-1])
+	},
+}
+
+// downsamplers combine the values of a window, which has at least one
+// value.
+var downsamplers = map[string]func(values []float64) null.Float{
+	"min":  reducers["min"],
+	"max":  reducers["max"],
+	"sum":  reducers["sum"],
+	"mean": reducers["mean"],
+	"last": reducers["last"],
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// resampleCommand changes the interval of each series of its input to the
+// window. The windows are aligned to the epoch, and each point is the
+// downsampled value of the points in (t - window, t]. Windows without
+// points are filled by the upsampler: pad uses the previous value,
+// backfilling the next value and fillna null.
+type resampleCommand struct {
+	input       string
+	window      int64
+	downsampler string
+	upsampler   string
+}
+
+func (c *resampleCommand) inputs() []string {
+	return []string{c.input}
+}
+
+func (c *resampleCommand) execute(refID string, timeRange *tsdb.TimeRange, vars map[string][]*item) ([]*item, error) {
+	from := timeRange.GetFromAsMsEpoch()
+	to := timeRange.GetToAsMsEpoch()
+
+	start := (from + c.window - 1) / c.window * c.window
+	if (to-start)/c.window+1 > maxResamplePoints {
+		return nil, errorf(refID, "resample window is too small for the time range")
+	}
+
+	downsample := downsamplers[c.downsampler]
+
+	result := make([]*item, 0, len(vars[c.input]))
+	for _, i := range vars[c.input] {
+		if i.isNumber {
+			return nil, errorf(refID, "can not resample number %s", i.labels)
+		}
+
+		resampled := &item{name: i.name, labels: i.labels, points: make([]point, 0)}
+		idx := 0
+		previous := null.FloatFromPtr(nil)
+
+		for t := start; t <= to; t += c.window {
+			values := make([]float64, 0)
+			for ; idx < len(i.points) && i.points[idx].time <= t; idx++ {
+				p := i.points[idx]
+				if p.time > t-c.window && p.value.Valid {
+					values = append(values, p.value.Float64)
+				}
+			}
+
+			var value null.Float
+			switch {
+			case len(values) > 0:
+				value = downsample(values)
+			case c.upsampler == "pad":
+				value = previous
+			case c.upsampler == "backfilling":
+				value = nextValue(i.points[idx:])
+			}
+
+			resampled.points = append(resampled.points, point{time: t, value: value})
+			previous = value
+		}
+
+		result = append(result, resampled)
+	}
+
+	return result, nil
+}
+
+// nextValue returns the first non null value of the points.
+func nextValue(points []point) null.Float {
+	for _, p := range points {
+		if p.value.Valid {
+			return p.value
+		}
+	}
+	return null.FloatFromPtr(nil)
+}
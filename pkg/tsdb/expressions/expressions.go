@@ -0,0 +1,242 @@
+// Package expressions implements server-side expressions, which run math,
+// reduce and resample operations on the results of other queries of the
+// same request. It is registered as the __expr__ pseudo data source.
+package expressions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+const (
+	// DatasourceName is the name of the expression pseudo data source, which
+	// queries use as their datasource.
+	DatasourceName = "__expr__"
+
+	// DatasourceID is the id of the expression pseudo data source.
+	DatasourceID = -100
+)
+
+func init() {
+	tsdb.RegisterTsdbQueryEndpoint(DatasourceName, NewExecutor)
+}
+
+// DataSource returns the expression pseudo data source of the org.
+func DataSource(orgID int64) *models.DataSource {
+	return &models.DataSource{
+		Id:    DatasourceID,
+		OrgId: orgID,
+		Name:  DatasourceName,
+		Type:  DatasourceName,
+		// the input queries are cached by their own data sources
+		JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCacheTTL": "0s"}),
+	}
+}
+
+// IsExpression returns true if the query model is an expression.
+func IsExpression(model *simplejson.Json) bool {
+	return model.Get("datasource").MustString("") == DatasourceName || model.Get("datasourceId").MustInt64() == DatasourceID
+}
+
+// Inputs returns the refIds of the queries the expression reads.
+func Inputs(refID string, model *simplejson.Json) ([]string, error) {
+	cmd, err := newCommand(refID, model)
+	if err != nil {
+		return nil, err
+	}
+	return cmd.inputs(), nil
+}
+
+type Executor struct {
+	log log.Logger
+}
+
+func NewExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &Executor{log: log.New("tsdb.expressions")}, nil
+}
+
+// Query runs the input queries of the request against their data sources
+// and then evaluates the expressions in the order of their references.
+// The response has the results of the input queries and of the expressions,
+// except for the ones hidden by their model. An expression fails when one
+// of its inputs fails.
+func (e *Executor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	commands := make(map[string]command)
+	inputs := make([]*tsdb.Query, 0, len(tsdbQuery.Queries))
+
+	for _, query := range tsdbQuery.Queries {
+		if IsExpression(query.Model) {
+			cmd, err := newCommand(query.RefId, query.Model)
+			if err != nil {
+				return nil, err
+			}
+			commands[query.RefId] = cmd
+			continue
+		}
+
+		if query.DataSource == nil {
+			return nil, fmt.Errorf("query %s has no data source", query.RefId)
+		}
+		inputs = append(inputs, query)
+	}
+
+	order, err := executionOrder(tsdbQuery.Queries, commands)
+	if err != nil {
+		return nil, err
+	}
+
+	results := e.queryInputs(ctx, tsdbQuery, inputs)
+
+	vars := make(map[string][]*item, len(tsdbQuery.Queries))
+	errs := make(map[string]error)
+	for refID, result := range results {
+		if result.Error != nil {
+			errs[refID] = result.Error
+			continue
+		}
+
+		items, err := resultItems(result)
+		if err != nil {
+			errs[refID] = err
+			continue
+		}
+		vars[refID] = items
+	}
+
+	for _, refID := range order {
+		cmd := commands[refID]
+		for _, input := range cmd.inputs() {
+			if err := errs[input]; err != nil {
+				errs[refID] = errorf(refID, "input %s failed: %v", input, err)
+				break
+			}
+		}
+		if errs[refID] != nil {
+			continue
+		}
+
+		items, err := cmd.execute(refID, tsdbQuery.TimeRange, vars)
+		if err != nil {
+			errs[refID] = err
+			continue
+		}
+		vars[refID] = items
+	}
+
+	resp := &tsdb.Response{Results: make(map[string]*tsdb.QueryResult, len(tsdbQuery.Queries))}
+	for _, query := range tsdbQuery.Queries {
+		if query.Model.Get("hide").MustBool(false) {
+			continue
+		}
+
+		refID := query.RefId
+		switch {
+		case errs[refID] != nil && results[refID] == nil:
+			resp.Results[refID] = &tsdb.QueryResult{RefId: refID, Error: errs[refID]}
+		case results[refID] != nil:
+			resp.Results[refID] = results[refID]
+		default:
+			resp.Results[refID] = itemsResult(refID, vars[refID])
+		}
+	}
+
+	return resp, nil
+}
+
+// queryInputs runs the input queries, with one request per data source.
+func (e *Executor) queryInputs(ctx context.Context, tsdbQuery *tsdb.TsdbQuery, queries []*tsdb.Query) map[string]*tsdb.QueryResult {
+	groups := make(map[int64][]*tsdb.Query)
+	datasources := make([]*models.DataSource, 0)
+	for _, query := range queries {
+		if _, ok := groups[query.DataSource.Id]; !ok {
+			datasources = append(datasources, query.DataSource)
+		}
+		groups[query.DataSource.Id] = append(groups[query.DataSource.Id], query)
+	}
+
+	results := make(map[string]*tsdb.QueryResult, len(queries))
+	for _, ds := range datasources {
+		group := groups[ds.Id]
+		resp, err := tsdb.HandleRequest(ctx, ds, &tsdb.TsdbQuery{
+			TimeRange: tsdbQuery.TimeRange,
+			Queries:   group,
+			Headers:   tsdbQuery.Headers,
+			Debug:     tsdbQuery.Debug,
+			User:      tsdbQuery.User,
+			SkipCache: tsdbQuery.SkipCache,
+		})
+		if err != nil {
+			e.log.Debug("Input query failed", "datasource", ds.Name, "error", err)
+		}
+
+		for _, query := range group {
+			var result *tsdb.QueryResult
+			if err != nil {
+				result = &tsdb.QueryResult{RefId: query.RefId, Error: err}
+			} else if result = resp.Results[query.RefId]; result == nil {
+				result = &tsdb.QueryResult{RefId: query.RefId}
+			}
+			results[query.RefId] = result
+		}
+	}
+
+	return results
+}
+
+// executionOrder returns the refIds of the expressions in an order where
+// each expression comes after the expressions it reads.
+func executionOrder(queries []*tsdb.Query, commands map[string]command) ([]string, error) {
+	known := make(map[string]bool, len(queries))
+	for _, query := range queries {
+		known[query.RefId] = true
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(commands))
+	order := make([]string, 0, len(commands))
+
+	var visit func(refID string) error
+	visit = func(refID string) error {
+		cmd, ok := commands[refID]
+		if !ok {
+			return nil
+		}
+
+		switch state[refID] {
+		case visiting:
+			return errorf(refID, "circular reference")
+		case visited:
+			return nil
+		}
+
+		state[refID] = visiting
+		for _, input := range cmd.inputs() {
+			if !known[input] {
+				return errorf(refID, "reference to unknown query %s", input)
+			}
+			if err := visit(input); err != nil {
+				return err
+			}
+		}
+		state[refID] = visited
+
+		order = append(order, refID)
+		return nil
+	}
+
+	for _, query := range queries {
+		if err := visit(query.RefId); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,265 @@
+package expressions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeEndpoint returns the series of the refIds of its queries.
+type fakeEndpoint struct {
+	series   map[string]tsdb.TimeSeriesSlice
+	err      error
+	requests []*tsdb.TsdbQuery
+}
+
+func (e *fakeEndpoint) Query(ctx context.Context, ds *models.DataSource, query *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	e.requests = append(e.requests, query)
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	resp := &tsdb.Response{Results: map[string]*tsdb.QueryResult{}}
+	for _, q := range query.Queries {
+		resp.Results[q.RefId] = &tsdb.QueryResult{RefId: q.RefId, Series: e.series[q.RefId]}
+	}
+	return resp, nil
+}
+
+func series(name string, tags map[string]string, args ...float64) *tsdb.TimeSeries {
+	points := tsdb.TimeSeriesPoints{}
+	for i := 0; i < len(args); i += 2 {
+		points = append(points, tsdb.NewTimePoint(null.FloatFrom(args[i]), args[i+1]))
+	}
+	return &tsdb.TimeSeries{Name: name, Tags: tags, Points: points}
+}
+
+func expression(refID string, model map[string]interface{}) *tsdb.Query {
+	model["datasource"] = DatasourceName
+	return &tsdb.Query{RefId: refID, Model: simplejson.NewFromAny(model)}
+}
+
+func decodeFrames(result *tsdb.QueryResult) data.Frames {
+	frames, err := result.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+	return frames
+}
+
+func TestExpressions(t *testing.T) {
+	Convey("Server-side expressions", t, func() {
+		endpoint := &fakeEndpoint{series: map[string]tsdb.TimeSeriesSlice{
+			"A": {
+				series("cpu", map[string]string{"host": "a"}, 1, 1000, 2, 2000, 3, 3000),
+				series("cpu", map[string]string{"host": "b"}, 10, 1000, 20, 2000, 30, 3000),
+			},
+			"B": {
+				series("mem", map[string]string{"host": "a"}, 4, 1000, 5, 2000),
+				series("mem", map[string]string{"host": "b"}, 40, 1000, 50, 2000),
+			},
+			"C": {
+				series("total", nil, 100, 1000, 100, 2000, 100, 3000),
+			},
+		}}
+		tsdb.RegisterTsdbQueryEndpoint("expressions-test", func(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+			return endpoint, nil
+		})
+
+		ds := &models.DataSource{Id: 1, Type: "expressions-test", JsonData: simplejson.New()}
+		input := func(refID string) *tsdb.Query {
+			return &tsdb.Query{RefId: refID, DataSource: ds, Model: simplejson.NewFromAny(map[string]interface{}{"datasourceId": 1})}
+		}
+
+		run := func(queries ...*tsdb.Query) *tsdb.Response {
+			resp, err := tsdb.HandleRequest(context.Background(), DataSource(1), &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("1000", "4000"),
+				Queries:   queries,
+			})
+			So(err, ShouldBeNil)
+			return resp
+		}
+
+		Convey("Should query inputs of the same data source in one request", func() {
+			resp := run(input("A"), input("B"))
+			So(endpoint.requests, ShouldHaveLength, 1)
+			So(endpoint.requests[0].Queries, ShouldHaveLength, 2)
+			So(resp.Results["A"].Series, ShouldHaveLength, 2)
+		})
+
+		Convey("Should join series of math expression by labels and time", func() {
+			resp := run(input("A"), input("B"), expression("E", map[string]interface{}{"type": "math", "expression": "$A + $B"}))
+
+			frames := decodeFrames(resp.Results["E"])
+			So(frames, ShouldHaveLength, 2)
+			So(frames[0].Name, ShouldEqual, "E")
+			So(frames[0].Fields[1].Labels, ShouldResemble, data.Labels{"host": "a"})
+			So(frames[0].Rows(), ShouldEqual, 2)
+			So(*frames[0].Fields[1].At(0).(*float64), ShouldEqual, 5)
+			So(*frames[0].Fields[1].At(1).(*float64), ShouldEqual, 7)
+			So(frames[1].Fields[1].Labels, ShouldResemble, data.Labels{"host": "b"})
+			So(*frames[1].Fields[1].At(1).(*float64), ShouldEqual, 70)
+		})
+
+		Convey("Should match single series with every series", func() {
+			resp := run(input("A"), input("C"), expression("E", map[string]interface{}{"type": "math", "expression": "$A / $C * 100"}))
+
+			frames := decodeFrames(resp.Results["E"])
+			So(frames, ShouldHaveLength, 2)
+			So(*frames[1].Fields[1].At(2).(*float64), ShouldEqual, 30)
+		})
+
+		Convey("Should reduce series to numbers", func() {
+			resp := run(input("A"), expression("R", map[string]interface{}{"type": "reduce", "expression": "A", "reducer": "mean"}))
+
+			frames := decodeFrames(resp.Results["R"])
+			So(frames, ShouldHaveLength, 2)
+			So(frames[0].Fields, ShouldHaveLength, 1)
+			So(*frames[0].Fields[0].At(0).(*float64), ShouldEqual, 2)
+			So(*frames[1].Fields[0].At(0).(*float64), ShouldEqual, 20)
+		})
+
+		Convey("Should evaluate expressions that read other expressions", func() {
+			resp := run(
+				expression("E", map[string]interface{}{"type": "math", "expression": "$R > 10"}),
+				expression("R", map[string]interface{}{"type": "reduce", "expression": "$A", "reducer": "max"}),
+				input("A"),
+			)
+
+			frames := decodeFrames(resp.Results["E"])
+			So(frames, ShouldHaveLength, 2)
+			So(*frames[0].Fields[0].At(0).(*float64), ShouldEqual, 0)
+			So(*frames[1].Fields[0].At(0).(*float64), ShouldEqual, 1)
+		})
+
+		Convey("Should not return hidden queries", func() {
+			hidden := input("A")
+			hidden.Model.Set("hide", true)
+			resp := run(hidden, expression("R", map[string]interface{}{"type": "reduce", "expression": "$A", "reducer": "sum"}))
+
+			So(resp.Results, ShouldNotContainKey, "A")
+			So(resp.Results, ShouldContainKey, "R")
+		})
+
+		Convey("Should fail expressions whose input failed", func() {
+			endpoint.err = errors.New("query failed")
+			resp := run(input("A"), expression("R", map[string]interface{}{"type": "reduce", "expression": "$A", "reducer": "sum"}))
+
+			So(resp.Results["A"].Error, ShouldNotBeNil)
+			So(resp.Results["R"].Error, ShouldNotBeNil)
+		})
+
+		Convey("Should return error for circular references", func() {
+			_, err := tsdb.HandleRequest(context.Background(), DataSource(1), &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("1000", "4000"),
+				Queries: []*tsdb.Query{
+					expression("E", map[string]interface{}{"type": "math", "expression": "$F + 1"}),
+					expression("F", map[string]interface{}{"type": "math", "expression": "$E + 1"}),
+				},
+			})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Should return error for unknown references", func() {
+			_, err := tsdb.HandleRequest(context.Background(), DataSource(1), &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("1000", "4000"),
+				Queries:   []*tsdb.Query{expression("E", map[string]interface{}{"type": "math", "expression": "$X + 1"})},
+			})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestResample(t *testing.T) {
+	Convey("Resampling series", t, func() {
+		vars := map[string][]*item{
+			"A": {seriesItem(&tsdb.TimeSeries{Name: "cpu", Points: tsdb.TimeSeriesPoints{
+				tsdb.NewTimePoint(null.FloatFrom(1), 1000),
+				tsdb.NewTimePoint(null.FloatFrom(3), 1500),
+				tsdb.NewTimePoint(null.FloatFrom(5), 4000),
+			}})},
+		}
+		timeRange := tsdb.NewTimeRange("1000", "4000")
+
+		resample := func(downsampler, upsampler string) []point {
+			cmd, err := newCommand("R", simplejson.NewFromAny(map[string]interface{}{
+				"type": "resample", "expression": "$A", "rule": "1s", "downsampler": downsampler, "upsampler": upsampler,
+			}))
+			So(err, ShouldBeNil)
+
+			items, err := cmd.execute("R", timeRange, vars)
+			So(err, ShouldBeNil)
+			So(items, ShouldHaveLength, 1)
+			return items[0].points
+		}
+
+		Convey("Should downsample points of each window", func() {
+			points := resample("mean", "fillna")
+			So(points, ShouldResemble, []point{
+				{time: 1000, value: null.FloatFrom(1)},
+				{time: 2000, value: null.FloatFrom(3)},
+				{time: 3000, value: null.FloatFromPtr(nil)},
+				{time: 4000, value: null.FloatFrom(5)},
+			})
+		})
+
+		Convey("Should pad windows without points", func() {
+			points := resample("max", "pad")
+			So(points[2], ShouldResemble, point{time: 3000, value: null.FloatFrom(3)})
+		})
+
+		Convey("Should backfill windows without points", func() {
+			points := resample("max", "backfilling")
+			So(points[2], ShouldResemble, point{time: 3000, value: null.FloatFrom(5)})
+		})
+
+		Convey("Should reject unknown downsampler", func() {
+			_, err := newCommand("R", simplejson.NewFromAny(map[string]interface{}{
+				"type": "resample", "expression": "$A", "rule": "1s", "downsampler": "median",
+			}))
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestJoinItems(t *testing.T) {
+	Convey("Joining items by labels", t, func() {
+		a := []*item{
+			{labels: data.Labels{"host": "a", "dc": "x"}},
+			{labels: data.Labels{"host": "b", "dc": "x"}},
+			{labels: data.Labels{"host": "c", "dc": "y"}},
+		}
+
+		Convey("Should match subset labels", func() {
+			b := []*item{
+				{labels: data.Labels{"dc": "x"}},
+				{labels: data.Labels{"dc": "y"}},
+			}
+			rows := joinItems([]string{"A", "B"}, map[string][]*item{"A": a, "B": b})
+			So(rows, ShouldHaveLength, 3)
+			So(rows[0]["B"], ShouldEqual, b[0])
+			So(rows[1]["B"], ShouldEqual, b[0])
+			So(rows[2]["B"], ShouldEqual, b[1])
+		})
+
+		Convey("Should drop items without a match", func() {
+			b := []*item{
+				{labels: data.Labels{"host": "a", "dc": "x"}},
+				{labels: data.Labels{"host": "z"}},
+			}
+			rows := joinItems([]string{"A", "B"}, map[string][]*item{"A": a, "B": b})
+			So(rows, ShouldHaveLength, 1)
+			So(rows[0]["A"], ShouldEqual, a[0])
+		})
+
+		Convey("Should return no rows when a variable has no items", func() {
+			rows := joinItems([]string{"A", "B"}, map[string][]*item{"A": a})
+			So(rows, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package expressions
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// joinItems matches the items of the variables of a math expression by
+// their labels and returns one row of items per match. The variable with
+// the most items drives the join, and each of its items is matched with
+// the item of every other variable that has
+//
+//   - the same labels, or else
+//   - the only labels that are a subset or superset of its labels, or else
+//   - no other items, as a single item is matched with every item.
+//
+// Items without a match are dropped.
+func joinItems(names []string, vars map[string][]*item) []map[string]*item {
+	driver := ""
+	for _, name := range names {
+		if len(vars[name]) == 0 {
+			return nil
+		}
+		if driver == "" || len(vars[name]) > len(vars[driver]) {
+			driver = name
+		}
+	}
+
+	rows := make([]map[string]*item, 0, len(vars[driver]))
+	for _, d := range vars[driver] {
+		row := map[string]*item{driver: d}
+		for _, name := range names {
+			if _, ok := row[name]; ok {
+				continue
+			}
+
+			match := matchItem(vars[name], d.labels)
+			if match == nil {
+				row = nil
+				break
+			}
+			row[name] = match
+		}
+
+		if row != nil {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+func matchItem(items []*item, labels data.Labels) *item {
+	for _, i := range items {
+		if i.labels.Equals(labels) {
+			return i
+		}
+	}
+
+	var match *item
+	for _, i := range items {
+		if isSubset(i.labels, labels) || isSubset(labels, i.labels) {
+			if match != nil {
+				// ambiguous
+				match = nil
+				break
+			}
+			match = i
+		}
+	}
+	if match != nil {
+		return match
+	}
+
+	if len(items) == 1 {
+		return items[0]
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package expressions
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// item is a labeled series or number, the unit expressions work on.
+type item struct {
+	name   string
+	labels data.Labels
+
+	// points of a series, sorted by time
+	points []point
+
+	// number is the value of a number
+	number   null.Float
+	isNumber bool
+}
+
+type point struct {
+	// time in epoch milliseconds
+	time  int64
+	value null.Float
+}
+
+func newNumber(name string, labels data.Labels, value null.Float) *item {
+	return &item{name: name, labels: labels, number: value, isNumber: true}
+}
+
+// valueAt returns the value of a series at the time, if it has a point at
+// that time.
+func (i *item) valueAt(time int64) (null.Float, bool) {
+	idx := sort.Search(len(i.points), func(j int) bool { return i.points[j].time >= time })
+	if idx < len(i.points) && i.points[idx].time == time {
+		return i.points[idx].value, true
+	}
+	return null.Float{}, false
+}
+
+// resultItems converts the result of a query to items.
+func resultItems(result *tsdb.QueryResult) ([]*item, error) {
+	items := make([]*item, 0, len(result.Series))
+
+	for _, series := range result.Series {
+		items = append(items, seriesItem(series))
+	}
+
+	if result.Dataframes == nil {
+		return items, nil
+	}
+
+	frames, err := result.Dataframes.Decoded()
+	if err != nil {
+		return nil, errutil.Wrap("failed to decode data frames", err)
+	}
+
+	for _, frame := range frames {
+		seriesSlice, err := tsdb.FrameToSeriesSlice(frame)
+		if err != nil {
+			return nil, errutil.Wrapf(err, "failed to convert data frame %q", frame.Name)
+		}
+
+		for _, series := range seriesSlice {
+			if len(series.Points) == 1 && !series.Points[0][1].Valid {
+				items = append(items, newNumber(series.Name, data.Labels(series.Tags), nanToNull(series.Points[0][0])))
+				continue
+			}
+			items = append(items, seriesItem(series))
+		}
+	}
+
+	return items, nil
+}
+
+func seriesItem(series *tsdb.TimeSeries) *item {
+	i := &item{name: series.Name, labels: data.Labels(series.Tags), points: make([]point, 0, len(series.Points))}
+	for _, p := range series.Points {
+		if !p[1].Valid {
+			continue
+		}
+		i.points = append(i.points, point{time: int64(p[1].Float64), value: nanToNull(p[0])})
+	}
+
+	sort.SliceStable(i.points, func(a, b int) bool { return i.points[a].time < i.points[b].time })
+	return i
+}
+
+// itemsResult converts the items of an expression to a query result of
+// data frames, with one frame per item named after the expression.
+func itemsResult(refID string, items []*item) *tsdb.QueryResult {
+	frames := make(data.Frames, 0, len(items))
+
+	for _, i := range items {
+		name := i.name
+		if name == "" {
+			name = refID
+		}
+
+		if i.isNumber {
+			frames = append(frames, data.NewFrame(refID,
+				data.NewField(name, i.labels, []*float64{floatPointer(i.number)}),
+			))
+			continue
+		}
+
+		times := make([]time.Time, len(i.points))
+		values := make([]*float64, len(i.points))
+		for idx, p := range i.points {
+			times[idx] = time.Unix(0, p.time*int64(time.Millisecond)).UTC()
+			values[idx] = floatPointer(p.value)
+		}
+
+		frames = append(frames, data.NewFrame(refID,
+			data.NewField("Time", nil, times),
+			data.NewField(name, i.labels, values),
+		))
+	}
+
+	return &tsdb.QueryResult{RefId: refID, Dataframes: tsdb.NewDecodedDataFrames(frames)}
+}
+
+// nanToNull converts NaN, which data frames use for null values of nullable
+// fields, to null.
+func nanToNull(value null.Float) null.Float {
+	if value.Valid && math.IsNaN(value.Float64) {
+		return null.FloatFromPtr(nil)
+	}
+	return value
+}
+
+func floatPointer(value null.Float) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	v := value.Float64
+	return &v
+}
+
+// isSubset returns true if all labels of a are also labels of b.
+func isSubset(a, b data.Labels) bool {
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLabels returns the union of the labels.
+func mergeLabels(labels ...data.Labels) data.Labels {
+	result := data.Labels{}
+	for _, l := range labels {
+		for name, value := range l {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+func errorf(refID string, format string, args ...interface{}) error {
+	return fmt.Errorf("expression %s: %s", refID, fmt.Sprintf(format, args...))
+}
@@ -1,7 +1,9 @@
 package tsdb
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -40,10 +42,14 @@ func convertTSDBTimePoint(point TimePoint) (t *time.Time, f *float64) {
 }
 
 // FrameToSeriesSlice converts a frame that is a valid time series as per data.TimeSeriesSchema()
-// to a TimeSeriesSlice.
+// to a TimeSeriesSlice. A frame of a single row without a time field, such as the
+// result of a reduce expression, is converted to series of one point without a time.
 func FrameToSeriesSlice(frame *data.Frame) (TimeSeriesSlice, error) {
 	tsSchema := frame.TimeSeriesSchema()
 	if tsSchema.Type == data.TimeSeriesTypeNot {
+		if isNumberFrame(frame) {
+			return numberFrameToSeriesSlice(frame)
+		}
 		return nil, fmt.Errorf("input frame is not recognized as a time series")
 	}
 	// If Long, make wide
@@ -93,3 +99,93 @@ func FrameToSeriesSlice(frame *data.Frame) (TimeSeriesSlice, error) {
 
 	return seriesSlice, nil
 }
+
+// isNumberFrame returns true if the frame has a single row of numeric fields.
+func isNumberFrame(frame *data.Frame) bool {
+	if len(frame.Fields) == 0 {
+		return false
+	}
+	for _, field := range frame.Fields {
+		if field.Len() != 1 || !field.Type().Numeric() {
+			return false
+		}
+	}
+	return true
+}
+
+func numberFrameToSeriesSlice(frame *data.Frame) (TimeSeriesSlice, error) {
+	seriesSlice := make(TimeSeriesSlice, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		val, err := field.FloatAt(0)
+		if err != nil {
+			return nil, errutil.Wrapf(err, "failed to convert frame to tsdb.series, can not convert value %v to float", field.At(0))
+		}
+
+		seriesSlice = append(seriesSlice, &TimeSeries{
+			Name:   field.Name,
+			Tags:   field.Labels.Copy(),
+			Points: TimeSeriesPoints{TimePoint{null.FloatFrom(val), null.Float{}}},
+		})
+	}
+	return seriesSlice, nil
+}
+
+// VisTypeTrace indicates a frame of spans should be visualized as a trace.
+const VisTypeTrace data.VisType = "trace"
+
+// TraceSpan is a span of a trace of a tracing data source.
+type TraceSpan struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	OperationName string
+	ServiceName   string
+	StartTime     time.Time
+	Duration      time.Duration
+	Tags          map[string]interface{}
+}
+
+// SpansToFrame converts spans to a frame with a row per span, ordered by
+// their start time. The duration is in milliseconds and the tags are a JSON
+// object.
+func SpansToFrame(name string, spans []*TraceSpan) (*data.Frame, error) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartTime.Before(spans[j].StartTime)
+	})
+
+	frame := data.NewFrame(name,
+		data.NewField("traceID", nil, make([]string, 0, len(spans))),
+		data.NewField("spanID", nil, make([]string, 0, len(spans))),
+		data.NewField("parentSpanID", nil, make([]string, 0, len(spans))),
+		data.NewField("operationName", nil, make([]string, 0, len(spans))),
+		data.NewField("serviceName", nil, make([]string, 0, len(spans))),
+		data.NewField("startTime", nil, make([]time.Time, 0, len(spans))),
+		data.NewField("duration", nil, make([]float64, 0, len(spans))),
+		data.NewField("tags", nil, make([]string, 0, len(spans))),
+	)
+
+	for _, span := range spans {
+		tags := span.Tags
+		if tags == nil {
+			tags = map[string]interface{}{}
+		}
+		encodedTags, err := json.Marshal(tags)
+		if err != nil {
+			return nil, errutil.Wrapf(err, "failed to encode tags of span %s", span.SpanID)
+		}
+
+		frame.AppendRow(
+			span.TraceID,
+			span.SpanID,
+			span.ParentSpanID,
+			span.OperationName,
+			span.ServiceName,
+			span.StartTime.UTC(),
+			float64(span.Duration)/float64(time.Millisecond),
+			string(encodedTags),
+		)
+	}
+
+	frame.Meta = &data.FrameMeta{PreferredVisualization: VisTypeTrace}
+	return frame, nil
+}
@@ -126,6 +126,20 @@ func TestFrameToSeriesSlice(t *testing.T) {
 			},
 			Err: require.NoError,
 		},
+		{
+			name: "single row without time field",
+			frame: data.NewFrame("B",
+				data.NewField("B", data.Labels{"host": "a"}, []*float64{pointer.Float64(3)}),
+			),
+			seriesSlice: TimeSeriesSlice{
+				&TimeSeries{
+					Name:   "B",
+					Tags:   map[string]string{"host": "a"},
+					Points: TimeSeriesPoints{TimePoint{null.FloatFrom(3), null.Float{}}},
+				},
+			},
+			Err: require.NoError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -137,3 +151,38 @@ func TestFrameToSeriesSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestSpansToFrame(t *testing.T) {
+	start := time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC)
+	frame, err := SpansToFrame("abc", []*TraceSpan{
+		{
+			TraceID:       "abc",
+			SpanID:        "2",
+			ParentSpanID:  "1",
+			OperationName: "query",
+			ServiceName:   "db",
+			StartTime:     start.Add(time.Millisecond),
+			Duration:      1500 * time.Microsecond,
+			Tags:          map[string]interface{}{"error": true},
+		},
+		{
+			TraceID:       "abc",
+			SpanID:        "1",
+			OperationName: "GET /api",
+			ServiceName:   "api",
+			StartTime:     start,
+			Duration:      5 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, VisTypeTrace, frame.Meta.PreferredVisualization)
+	require.Equal(t, "1", frame.Fields[1].At(0))
+	require.Equal(t, "", frame.Fields[2].At(0))
+	require.Equal(t, "1", frame.Fields[2].At(1))
+	require.Equal(t, start, frame.Fields[5].At(0))
+	require.Equal(t, 1.5, frame.Fields[6].At(1))
+	require.Equal(t, "{}", frame.Fields[7].At(0))
+	require.Equal(t, `{"error":true}`, frame.Fields[7].At(1))
+}
@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -31,6 +33,13 @@ func NewGraphiteExecutor(datasource *models.DataSource) (tsdb.TsdbQueryEndpoint,
 
 var glog = log.New("tsdb.graphite")
 
+// maxDataPoints is the number of points Graphite consolidates series to.
+const maxDataPoints = 500
+
+// unsafeIncrementalFunctions matches the functions whose result depends on
+// the start of the time range or on the series over the whole range.
+var unsafeIncrementalFunctions = regexp.MustCompile(`\b(integral|derivative|nonNegativeDerivative|perSecond|summarize|smartSummarize|hitcount|keepLastValue|interpolate|changed|delay|timeSlice|highest\w*|lowest\w*|sortBy\w*|limit|averageAbove|averageBelow|currentAbove|currentBelow|maximumAbove|maximumBelow|minimumAbove|minimumBelow|mostDeviant|removeAbovePercentile|removeBelowPercentile|nPercentile|filterSeries|aggregateLine|linearRegression)\(`)
+
 func init() {
 	tsdb.RegisterTsdbQueryEndpoint("graphite", NewGraphiteExecutor)
 }
@@ -40,13 +49,19 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 
 	from := "-" + formatTimeRange(tsdbQuery.TimeRange.From)
 	until := formatTimeRange(tsdbQuery.TimeRange.To)
+	if fromSeconds, ok := epochSeconds(tsdbQuery.TimeRange.From); ok {
+		from = fromSeconds
+	}
+	if untilSeconds, ok := epochSeconds(tsdbQuery.TimeRange.To); ok {
+		until = untilSeconds
+	}
 	var target string
 
 	formData := url.Values{
 		"from":          []string{from},
 		"until":         []string{until},
 		"format":        []string{"json"},
-		"maxDataPoints": []string{"500"},
+		"maxDataPoints": []string{strconv.FormatInt(rangeMaxDataPoints(tsdbQuery.TimeRange), 10)},
 	}
 
 	emptyQueries := make([]string, 0)
@@ -174,6 +189,36 @@ func (e *GraphiteExecutor) createRequest(dsInfo *models.DataSource, data url.Val
 	return req, err
 }
 
+// IsIncrementalQuerySafe returns true if the target does not use functions
+// whose result depends on the start of the time range.
+func (e *GraphiteExecutor) IsIncrementalQuerySafe(query *tsdb.Query) bool {
+	target := query.Model.Get("targetFull").MustString(query.Model.Get("target").MustString())
+	return target != "" && !unsafeIncrementalFunctions.MatchString(target)
+}
+
+// rangeMaxDataPoints returns the number of points to consolidate the range
+// to. The tail of an incremental query gets its share of the points of the
+// whole range, so that it is consolidated to about the same interval.
+func rangeMaxDataPoints(timeRange *tsdb.TimeRange) int64 {
+	full := timeRange.IntervalDuration()
+	actual := timeRange.MustGetTo().Sub(timeRange.MustGetFrom())
+	if full <= 0 || actual >= full {
+		return maxDataPoints
+	}
+
+	return int64(math.Ceil(maxDataPoints * float64(actual) / float64(full)))
+}
+
+// epochSeconds converts a time in epoch milliseconds to the epoch seconds
+// Graphite accepts for absolute times.
+func epochSeconds(input string) (string, bool) {
+	ms, err := strconv.ParseInt(input, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatInt(ms/1000, 10), true
+}
+
 func formatTimeRange(input string) string {
 	if input == "now" {
 		return input
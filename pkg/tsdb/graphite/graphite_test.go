@@ -3,6 +3,9 @@ package graphite
 import (
 	"testing"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -42,5 +45,30 @@ func TestGraphiteFunctions(t *testing.T) {
 			timeRange := fixIntervalFormat("app.grafana.*.dashboards.views.1m.count")
 			So(timeRange, ShouldEqual, "app.grafana.*.dashboards.views.1m.count")
 		})
+
+		Convey("formatting epoch time as seconds", func() {
+			seconds, ok := epochSeconds("1600000000123")
+			So(ok, ShouldBeTrue)
+			So(seconds, ShouldEqual, "1600000000")
+
+			_, ok = epochSeconds("now-1m")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("max data points of whole range", func() {
+			So(rangeMaxDataPoints(tsdb.NewTimeRange("1600000000000", "1600086400000")), ShouldEqual, 500)
+		})
+	})
+
+	Convey("Testing incremental query safety", t, func() {
+		executor := &GraphiteExecutor{}
+		query := func(target string) *tsdb.Query {
+			return &tsdb.Query{Model: simplejson.NewFromAny(map[string]interface{}{"target": target})}
+		}
+
+		So(executor.IsIncrementalQuerySafe(query("aliasByNode(app.*.requests.count, 1)")), ShouldBeTrue)
+		So(executor.IsIncrementalQuerySafe(query("nonNegativeDerivative(app.*.requests.count)")), ShouldBeFalse)
+		So(executor.IsIncrementalQuerySafe(query("highestAverage(app.*.requests.count, 5)")), ShouldBeFalse)
+		So(executor.IsIncrementalQuerySafe(query("")), ShouldBeFalse)
 	})
 }
@@ -0,0 +1,473 @@
+package tsdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	// incrementalResultExpiration is how long the result of an incremental
+	// query is kept after the last request for it.
+	incrementalResultExpiration = 10 * time.Minute
+
+	// defaultIncrementalQueryOverlap is how much of the end of the previous
+	// result is fetched again, as the latest points of a series can still
+	// change when late samples arrive.
+	defaultIncrementalQueryOverlap = 10 * time.Minute
+
+	// maxIncrementalResults is the maximum number of incremental query
+	// results kept in memory.
+	maxIncrementalResults = 1000
+
+	// maxIncrementalResultPoints is the maximum number of points and rows
+	// of a result kept in memory. Larger results are fetched in full.
+	maxIncrementalResultPoints = 100000
+)
+
+// IncrementalQueryEndpoint is implemented by query endpoints that support
+// incremental querying, where only the tail of the time range of a query
+// is fetched and merged with the previous result of the query.
+type IncrementalQueryEndpoint interface {
+	TsdbQueryEndpoint
+
+	// IsIncrementalQuerySafe returns true if the result of the query over a
+	// time range equals its result over the start of the range followed by
+	// its result over the tail of the range, when both use the interval of
+	// the whole range.
+	IsIncrementalQuerySafe(query *Query) bool
+}
+
+var incrementalResults = localcache.New(incrementalResultExpiration, time.Minute)
+
+// incrementalResult is the merged result of the previous request of an
+// incremental query, in epoch milliseconds.
+type incrementalResult struct {
+	from     int64
+	to       int64
+	response *Response
+}
+
+// isIncrementalQuery returns true if the request can be run as an
+// incremental query. Data sources enable incremental querying with the
+// incrementalQuerying field of their json data. Data sources that forward
+// the OAuth token of the user are never queried incrementally, as their
+// results can differ between users.
+func isIncrementalQuery(endpoint TsdbQueryEndpoint, dsInfo *models.DataSource, req *TsdbQuery) bool {
+	if req.SkipCache || req.TimeRange == nil || len(req.Queries) == 0 {
+		return false
+	}
+
+	if dsInfo.JsonData == nil || !dsInfo.JsonData.Get("incrementalQuerying").MustBool(false) {
+		return false
+	}
+
+	if dsInfo.JsonData.Get("oauthPassThru").MustBool() {
+		return false
+	}
+
+	incremental, ok := endpoint.(IncrementalQueryEndpoint)
+	if !ok {
+		return false
+	}
+
+	for _, query := range req.Queries {
+		if !incremental.IsIncrementalQuerySafe(query) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// incrementalQuery runs the request, only fetching the points after the
+// previous result of the same queries when the previous result covers the
+// start of the time range.
+func incrementalQuery(ctx context.Context, endpoint TsdbQueryEndpoint, dsInfo *models.DataSource, req *TsdbQuery) (*Response, error) {
+	key, err := incrementalQueryKey(dsInfo, req)
+	if err != nil {
+		return query(ctx, endpoint, dsInfo, req)
+	}
+
+	from := req.TimeRange.GetFromAsMsEpoch()
+	to := req.TimeRange.GetToAsMsEpoch()
+
+	var previous *incrementalResult
+	if item, ok := incrementalResults.Get(key); ok {
+		previous = item.(*incrementalResult)
+	}
+
+	tailFrom, ok := incrementalTailStart(previous, from, to, incrementalQueryOverlap(dsInfo))
+	if !ok {
+		resp, err := query(ctx, endpoint, dsInfo, req)
+		if err != nil {
+			return nil, err
+		}
+		storeIncrementalResult(key, from, to, resp)
+		return resp, nil
+	}
+
+	tailReq := *req
+	tailReq.TimeRange = &TimeRange{
+		From:             strconv.FormatInt(tailFrom, 10),
+		To:               strconv.FormatInt(to, 10),
+		now:              req.TimeRange.now,
+		intervalDuration: time.Duration(to-from) * time.Millisecond,
+	}
+
+	tail, err := query(ctx, endpoint, dsInfo, &tailReq)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := mergeIncrementalResponse(previous.response, tail, from, tailFrom, to)
+	storeIncrementalResult(key, from, to, resp)
+	return resp, nil
+}
+
+// incrementalTailStart returns the start of the tail to fetch, which is
+// the time of the last point of the previous result before the overlap.
+// Starting at a point of the previous result keeps the points of the tail
+// aligned with the previous points. It returns false when the previous
+// result cannot be reused.
+func incrementalTailStart(previous *incrementalResult, from, to int64, overlap time.Duration) (int64, bool) {
+	if previous == nil {
+		return 0, false
+	}
+
+	// a different duration gives a different interval, and the previous
+	// result has to cover the start of the range
+	if previous.to-previous.from != to-from || previous.from > from || previous.to < from || previous.to > to {
+		return 0, false
+	}
+
+	boundary := previous.to - overlap.Milliseconds()
+	tailFrom := int64(-1)
+	for _, result := range previous.response.Results {
+		for _, series := range result.Series {
+			for _, point := range series.Points {
+				timestamp := int64(point[1].Float64)
+				if timestamp <= boundary && timestamp > tailFrom {
+					tailFrom = timestamp
+				}
+			}
+		}
+
+		for _, frame := range decodedFrames(result) {
+			for row := 0; row < frame.Rows(); row++ {
+				if timestamp, ok := frameTime(frame, row); ok && timestamp <= boundary && timestamp > tailFrom {
+					tailFrom = timestamp
+				}
+			}
+		}
+	}
+
+	if tailFrom <= from {
+		return 0, false
+	}
+
+	return tailFrom, true
+}
+
+// mergeIncrementalResponse merges the points of the previous response
+// between from and tailFrom with the tail response.
+func mergeIncrementalResponse(previous, tail *Response, from, tailFrom, to int64) *Response {
+	resp := &Response{
+		Results: make(map[string]*QueryResult, len(tail.Results)),
+		Message: tail.Message,
+	}
+
+	for refID, tailResult := range tail.Results {
+		result := &QueryResult{
+			RefId:       tailResult.RefId,
+			Meta:        tailResult.Meta,
+			Error:       tailResult.Error,
+			ErrorString: tailResult.ErrorString,
+			Tables:      tailResult.Tables,
+			Dataframes:  tailResult.Dataframes,
+			Series:      make(TimeSeriesSlice, 0),
+		}
+		resp.Results[refID] = result
+
+		if previousResult, ok := previous.Results[refID]; ok && tailResult.Error == nil && tailResult.Dataframes != nil {
+			frames := mergeFrames(decodedFrames(previousResult), decodedFrames(tailResult), from, tailFrom, to)
+			result.Dataframes = NewDecodedDataFrames(frames)
+		}
+
+		tailSeries := make(map[string]*TimeSeries, len(tailResult.Series))
+		for _, series := range tailResult.Series {
+			tailSeries[seriesKey(series)] = series
+		}
+
+		merged := make(map[string]bool)
+		if previousResult, ok := previous.Results[refID]; ok && tailResult.Error == nil {
+			for _, series := range previousResult.Series {
+				key := seriesKey(series)
+				points := trimPoints(series.Points, from, tailFrom-1)
+				if tail, ok := tailSeries[key]; ok {
+					points = append(points, trimPoints(tail.Points, tailFrom, to)...)
+					merged[key] = true
+				}
+
+				if len(points) > 0 {
+					result.Series = append(result.Series, &TimeSeries{Name: series.Name, Tags: series.Tags, Points: points})
+				}
+			}
+		}
+
+		for _, series := range tailResult.Series {
+			if !merged[seriesKey(series)] {
+				result.Series = append(result.Series, series)
+			}
+		}
+	}
+
+	return resp
+}
+
+// trimPoints returns a copy of the points between from and to.
+func trimPoints(points TimeSeriesPoints, from, to int64) TimeSeriesPoints {
+	result := make(TimeSeriesPoints, 0, len(points))
+	for _, point := range points {
+		timestamp := int64(point[1].Float64)
+		if timestamp >= from && timestamp <= to {
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+func seriesKey(series *TimeSeries) string {
+	names := make([]string, 0, len(series.Tags))
+	for name := range series.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{strconv.Quote(series.Name)}
+	for _, name := range names {
+		parts = append(parts, name+"="+strconv.Quote(series.Tags[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// storeIncrementalResult keeps the response for the next request of the
+// same queries, unless it contains errors, tables or data frames without a
+// time field, or when it or the number of kept results is too large.
+func storeIncrementalResult(key string, from, to int64, resp *Response) {
+	points := 0
+	for _, result := range resp.Results {
+		if result.Error != nil || result.ErrorString != "" || len(result.Tables) > 0 {
+			incrementalResults.Delete(key)
+			return
+		}
+
+		for _, series := range result.Series {
+			points += len(series.Points)
+		}
+
+		if result.Dataframes == nil {
+			continue
+		}
+
+		frames, err := result.Dataframes.Decoded()
+		if err != nil {
+			incrementalResults.Delete(key)
+			return
+		}
+
+		for _, frame := range frames {
+			if !hasTimeField(frame) {
+				incrementalResults.Delete(key)
+				return
+			}
+			points += frame.Rows()
+		}
+	}
+
+	if points > maxIncrementalResultPoints {
+		incrementalResults.Delete(key)
+		return
+	}
+
+	if _, ok := incrementalResults.Get(key); !ok && incrementalResults.ItemCount() >= maxIncrementalResults {
+		return
+	}
+
+	incrementalResults.SetDefault(key, &incrementalResult{from: from, to: to, response: resp})
+}
+
+// mergeFrames merges the rows of the previous frames between from and
+// tailFrom with the rows of the tail frames. Frames are matched by their
+// name and the names, types and labels of their fields.
+func mergeFrames(previous, tail data.Frames, from, tailFrom, to int64) data.Frames {
+	tailFrames := make(map[string]*data.Frame, len(tail))
+	for _, frame := range tail {
+		tailFrames[frameKey(frame)] = frame
+	}
+
+	result := make(data.Frames, 0, len(tail))
+	merged := make(map[string]bool)
+	for _, frame := range previous {
+		if !hasTimeField(frame) {
+			continue
+		}
+
+		key := frameKey(frame)
+		mergedFrame := frame.EmptyCopy()
+		mergedFrame.Meta = frame.Meta
+		copyFieldConfigs(frame, mergedFrame)
+		appendRows(mergedFrame, frame, from, tailFrom-1)
+
+		if tailFrame, ok := tailFrames[key]; ok {
+			mergedFrame.Meta = tailFrame.Meta
+			copyFieldConfigs(tailFrame, mergedFrame)
+			appendRows(mergedFrame, tailFrame, tailFrom, to)
+			merged[key] = true
+		}
+
+		if mergedFrame.Rows() > 0 {
+			result = append(result, mergedFrame)
+		}
+	}
+
+	for _, frame := range tail {
+		if !merged[frameKey(frame)] {
+			result = append(result, frame)
+		}
+	}
+
+	return result
+}
+
+// appendRows appends the rows of the frame between from and to.
+func appendRows(dst, src *data.Frame, from, to int64) {
+	for row := 0; row < src.Rows(); row++ {
+		timestamp, ok := frameTime(src, row)
+		if !ok || timestamp < from || timestamp > to {
+			continue
+		}
+
+		for i, field := range src.Fields {
+			dst.Fields[i].Append(field.At(row))
+		}
+	}
+}
+
+func copyFieldConfigs(src, dst *data.Frame) {
+	for i, field := range src.Fields {
+		dst.Fields[i].Config = field.Config
+	}
+}
+
+// hasTimeField returns true if the first field of the frame is a time.
+func hasTimeField(frame *data.Frame) bool {
+	if len(frame.Fields) == 0 {
+		return false
+	}
+
+	fieldType := frame.Fields[0].Type()
+	return fieldType == data.FieldTypeTime || fieldType == data.FieldTypeNullableTime
+}
+
+// frameTime returns the time of a row of a frame in epoch milliseconds.
+func frameTime(frame *data.Frame, row int) (int64, bool) {
+	if !hasTimeField(frame) {
+		return 0, false
+	}
+
+	switch value := frame.Fields[0].At(row).(type) {
+	case time.Time:
+		return value.UnixNano() / int64(time.Millisecond), true
+	case *time.Time:
+		if value == nil {
+			return 0, false
+		}
+		return value.UnixNano() / int64(time.Millisecond), true
+	}
+	return 0, false
+}
+
+func frameKey(frame *data.Frame) string {
+	parts := []string{strconv.Quote(frame.Name)}
+	for _, field := range frame.Fields {
+		parts = append(parts, strconv.Quote(field.Name)+":"+field.Type().ItemTypeString()+field.Labels.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodedFrames returns the data frames of the result, or none if they
+// cannot be decoded.
+func decodedFrames(result *QueryResult) data.Frames {
+	if result.Dataframes == nil {
+		return nil
+	}
+
+	frames, err := result.Dataframes.Decoded()
+	if err != nil {
+		return nil
+	}
+	return frames
+}
+
+// incrementalQueryOverlap returns how much of the previous result is
+// fetched again, which data sources can set with the
+// incrementalQueryOverlap field of their json data.
+func incrementalQueryOverlap(dsInfo *models.DataSource) time.Duration {
+	value := dsInfo.JsonData.Get("incrementalQueryOverlap").MustString("")
+	if value == "" {
+		return defaultIncrementalQueryOverlap
+	}
+
+	overlap, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultIncrementalQueryOverlap
+	}
+	return overlap
+}
+
+type incrementalKeyQuery struct {
+	RefId         string
+	Model         *simplejson.Json
+	MaxDataPoints int64
+	IntervalMs    int64
+	QueryType     string
+}
+
+// incrementalQueryKey identifies the queries of the request independently
+// of its time range.
+func incrementalQueryKey(dsInfo *models.DataSource, req *TsdbQuery) (string, error) {
+	queries := make([]incrementalKeyQuery, 0, len(req.Queries))
+	for _, query := range req.Queries {
+		queries = append(queries, incrementalKeyQuery{
+			RefId:         query.RefId,
+			Model:         query.Model,
+			MaxDataPoints: query.MaxDataPoints,
+			IntervalMs:    query.IntervalMs,
+			QueryType:     query.QueryType,
+		})
+	}
+
+	data, err := json.Marshal(struct {
+		DatasourceId      int64
+		DatasourceVersion int
+		Queries           []incrementalKeyQuery
+		Headers           map[string]string
+	}{dsInfo.Id, dsInfo.Version, queries, req.Headers})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
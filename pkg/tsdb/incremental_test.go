@@ -0,0 +1,233 @@
+package tsdb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeIncrementalEndpoint returns a series with a point every 10 seconds,
+// whose value is its timestamp.
+type fakeIncrementalEndpoint struct {
+	safe      bool
+	ranges    [][2]int64
+	durations []time.Duration
+}
+
+func (e *fakeIncrementalEndpoint) Query(ctx context.Context, ds *models.DataSource, query *TsdbQuery) (*Response, error) {
+	from := query.TimeRange.GetFromAsMsEpoch()
+	to := query.TimeRange.GetToAsMsEpoch()
+	e.ranges = append(e.ranges, [2]int64{from, to})
+	e.durations = append(e.durations, query.TimeRange.IntervalDuration())
+
+	return &Response{Results: map[string]*QueryResult{"A": {RefId: "A", Series: TimeSeriesSlice{pointsBetween(from, to)}}}}, nil
+}
+
+func (e *fakeIncrementalEndpoint) IsIncrementalQuerySafe(query *Query) bool {
+	return e.safe
+}
+
+func pointsBetween(from, to int64) *TimeSeries {
+	series := &TimeSeries{Name: "cpu", Points: TimeSeriesPoints{}}
+	for ts := (from + 9999) / 10000 * 10000; ts <= to; ts += 10000 {
+		series.Points = append(series.Points, NewTimePoint(null.FloatFrom(float64(ts)), float64(ts)))
+	}
+	return series
+}
+
+func TestIncrementalQuery(t *testing.T) {
+	Convey("Incremental query", t, func() {
+		endpoint := &fakeIncrementalEndpoint{safe: true}
+		RegisterTsdbQueryEndpoint("incremental-test", func(dsInfo *models.DataSource) (TsdbQueryEndpoint, error) {
+			return endpoint, nil
+		})
+		incrementalResults.Flush()
+
+		ds := &models.DataSource{Id: 1, Type: "incremental-test", JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"incrementalQuerying": true,
+		})}
+
+		hour := int64(3600000)
+		start := int64(1600000000000)
+		request := func(from, to int64) *TsdbQuery {
+			return &TsdbQuery{
+				TimeRange: NewTimeRange(strconv.FormatInt(from, 10), strconv.FormatInt(to, 10)),
+				Queries:   []*Query{{RefId: "A", DataSource: ds, Model: simplejson.New()}},
+			}
+		}
+
+		_, err := HandleRequest(context.Background(), ds, request(start, start+hour))
+		So(err, ShouldBeNil)
+		So(endpoint.ranges, ShouldHaveLength, 1)
+
+		Convey("Should only fetch the tail of the next request", func() {
+			res, err := HandleRequest(context.Background(), ds, request(start+60000, start+hour+60000))
+			So(err, ShouldBeNil)
+			So(endpoint.ranges, ShouldHaveLength, 2)
+			So(endpoint.ranges[1], ShouldResemble, [2]int64{start + hour - 600000, start + hour + 60000})
+
+			expected := pointsBetween(start+60000, start+hour+60000)
+			So(res.Results["A"].Series, ShouldHaveLength, 1)
+			So(res.Results["A"].Series[0].Points, ShouldResemble, expected.Points)
+		})
+
+		Convey("Should calculate the interval of the tail from the whole range", func() {
+			_, err := HandleRequest(context.Background(), ds, request(start+60000, start+hour+60000))
+			So(err, ShouldBeNil)
+
+			So(endpoint.durations[1], ShouldEqual, time.Hour)
+		})
+
+		Convey("Should fetch the whole range when its duration changes", func() {
+			_, err := HandleRequest(context.Background(), ds, request(start+60000, start+2*hour))
+			So(err, ShouldBeNil)
+			So(endpoint.ranges[1], ShouldResemble, [2]int64{start + 60000, start + 2*hour})
+		})
+
+		Convey("Should fetch the whole range when the previous result is too old", func() {
+			_, err := HandleRequest(context.Background(), ds, request(start+2*hour, start+3*hour))
+			So(err, ShouldBeNil)
+			So(endpoint.ranges[1], ShouldResemble, [2]int64{start + 2*hour, start + 3*hour})
+		})
+
+		Convey("Should fetch the whole range when skipping the cache", func() {
+			req := request(start+60000, start+hour+60000)
+			req.SkipCache = true
+			_, err := HandleRequest(context.Background(), ds, req)
+			So(err, ShouldBeNil)
+			So(endpoint.ranges[1], ShouldResemble, [2]int64{start + 60000, start + hour + 60000})
+		})
+
+		Convey("Should fetch the whole range when the query is not safe", func() {
+			endpoint.safe = false
+			_, err := HandleRequest(context.Background(), ds, request(start+60000, start+hour+60000))
+			So(err, ShouldBeNil)
+			So(endpoint.ranges[1], ShouldResemble, [2]int64{start + 60000, start + hour + 60000})
+		})
+
+		Convey("Should fetch the whole range when the data source forwards the OAuth token of the user", func() {
+			ds.JsonData.Set("oauthPassThru", true)
+			_, err := HandleRequest(context.Background(), ds, request(start+60000, start+hour+60000))
+			So(err, ShouldBeNil)
+			So(endpoint.ranges[1], ShouldResemble, [2]int64{start + 60000, start + hour + 60000})
+		})
+
+		Convey("Should not keep more results than the limit", func() {
+			for i := incrementalResults.ItemCount(); i < maxIncrementalResults; i++ {
+				incrementalResults.SetDefault(strconv.Itoa(i), &incrementalResult{})
+			}
+
+			storeIncrementalResult("new", start, start+hour, &Response{Results: map[string]*QueryResult{}})
+			_, ok := incrementalResults.Get("new")
+			So(ok, ShouldBeFalse)
+			So(incrementalResults.ItemCount(), ShouldEqual, maxIncrementalResults)
+		})
+
+		Convey("Should not keep results with too many points", func() {
+			storeIncrementalResult("large", start, start+hour, &Response{Results: map[string]*QueryResult{
+				"A": {RefId: "A", Series: TimeSeriesSlice{pointsBetween(0, 10000*maxIncrementalResultPoints)}},
+			}})
+			_, ok := incrementalResults.Get("large")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("Should fetch the whole range when the data source does not enable it", func() {
+			ds.JsonData.Set("incrementalQuerying", false)
+			_, err := HandleRequest(context.Background(), ds, request(start+60000, start+hour+60000))
+			So(err, ShouldBeNil)
+			So(endpoint.ranges[1], ShouldResemble, [2]int64{start + 60000, start + hour + 60000})
+		})
+	})
+}
+
+func TestMergeIncrementalResponse(t *testing.T) {
+	Convey("Merging incremental response", t, func() {
+		previous := &Response{Results: map[string]*QueryResult{
+			"A": {RefId: "A", Series: TimeSeriesSlice{
+				{Name: "a", Points: NewTimeSeriesPointsFromArgs(1, 1000, 2, 2000, 3, 3000)},
+				{Name: "gone", Points: NewTimeSeriesPointsFromArgs(1, 1000, 2, 2000)},
+			}},
+		}}
+		tail := &Response{Results: map[string]*QueryResult{
+			"A": {RefId: "A", Series: TimeSeriesSlice{
+				{Name: "a", Points: NewTimeSeriesPointsFromArgs(30, 3000, 4, 4000)},
+				{Name: "new", Points: NewTimeSeriesPointsFromArgs(4, 4000)},
+			}},
+		}}
+
+		res := mergeIncrementalResponse(previous, tail, 2000, 3000, 4000)
+		series := res.Results["A"].Series
+
+		So(series, ShouldHaveLength, 3)
+		So(series[0].Name, ShouldEqual, "a")
+		So(series[0].Points, ShouldResemble, NewTimeSeriesPointsFromArgs(2, 2000, 30, 3000, 4, 4000))
+		So(series[1].Name, ShouldEqual, "gone")
+		So(series[1].Points, ShouldResemble, NewTimeSeriesPointsFromArgs(2, 2000))
+		So(series[2].Name, ShouldEqual, "new")
+	})
+}
+
+func TestMergeIncrementalFrames(t *testing.T) {
+	Convey("Merging incremental frames", t, func() {
+		seconds := func(values ...int64) []time.Time {
+			times := make([]time.Time, 0, len(values))
+			for _, v := range values {
+				times = append(times, time.Unix(v, 0))
+			}
+			return times
+		}
+
+		previous := &Response{Results: map[string]*QueryResult{
+			"A": {RefId: "A", Dataframes: NewDecodedDataFrames(data.Frames{
+				data.NewFrame("a",
+					data.NewField("Time", nil, seconds(1, 2, 3)),
+					data.NewField("a", data.Labels{"job": "api"}, []float64{1, 2, 3}),
+				),
+				data.NewFrame("gone",
+					data.NewField("Time", nil, seconds(1, 2)),
+					data.NewField("gone", nil, []float64{1, 2}),
+				),
+			})},
+		}}
+		tail := &Response{Results: map[string]*QueryResult{
+			"A": {RefId: "A", Dataframes: NewDecodedDataFrames(data.Frames{
+				data.NewFrame("a",
+					data.NewField("Time", nil, seconds(3, 4)),
+					data.NewField("a", data.Labels{"job": "api"}, []float64{30, 4}),
+				).SetMeta(&data.FrameMeta{Custom: "tail"}),
+				data.NewFrame("new",
+					data.NewField("Time", nil, seconds(4)),
+					data.NewField("new", nil, []float64{4}),
+				),
+			})},
+		}}
+
+		Convey("Should start the tail at the last point before the overlap", func() {
+			tailFrom, ok := incrementalTailStart(&incrementalResult{from: 1000, to: 3000, response: previous}, 1500, 3500, time.Second)
+			So(ok, ShouldBeTrue)
+			So(tailFrom, ShouldEqual, 2000)
+		})
+
+		res := mergeIncrementalResponse(previous, tail, 2000, 3000, 4000)
+		frames, err := res.Results["A"].Dataframes.Decoded()
+		So(err, ShouldBeNil)
+
+		So(frames, ShouldHaveLength, 3)
+		So(frames[0].Name, ShouldEqual, "a")
+		So(frames[0].Rows(), ShouldEqual, 3)
+		So(frames[0].Fields[0].At(0), ShouldEqual, time.Unix(2, 0))
+		So(frames[0].Fields[1].At(1), ShouldEqual, 30.0)
+		So(frames[0].Fields[1].Labels, ShouldResemble, data.Labels{"job": "api"})
+		So(frames[0].Meta.Custom, ShouldEqual, "tail")
+		So(frames[1].Name, ShouldEqual, "gone")
+		So(frames[1].Rows(), ShouldEqual, 1)
+		So(frames[2].Name, ShouldEqual, "new")
+	})
+}
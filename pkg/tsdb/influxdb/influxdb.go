@@ -108,6 +108,25 @@ func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 	return result, nil
 }
 
+// IsIncrementalQuerySafe returns true for InfluxQL queries grouped by time
+// that do not fill empty intervals with the previous value.
+func (e *InfluxDBExecutor) IsIncrementalQuerySafe(query *tsdb.Query) bool {
+	if query.DataSource == nil {
+		return false
+	}
+
+	if query.DataSource.JsonData != nil && query.DataSource.JsonData.Get("version").MustString("") == "Flux" {
+		return false
+	}
+
+	parsed, err := e.QueryParser.Parse(query.Model, query.DataSource)
+	if err != nil {
+		return false
+	}
+
+	return parsed.isIncrementalQuerySafe()
+}
+
 func (e *InfluxDBExecutor) getQuery(dsInfo *models.DataSource, queries []*tsdb.Query, context *tsdb.TsdbQuery) (*Query, error) {
 	// The model supports multiple queries, but right now this is only used from
 	// alerting so we only needed to support batch executing 1 query at a time.
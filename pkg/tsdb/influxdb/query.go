@@ -12,6 +12,8 @@ import (
 var (
 	regexpOperatorPattern    = regexp.MustCompile(`^\/.*\/$`)
 	regexpMeasurementPattern = regexp.MustCompile(`^\/.*\/$`)
+	regexpGroupByTime        = regexp.MustCompile(`(?i)group\s+by\s+(.*,\s*)?time\(`)
+	regexpFillPrevious       = regexp.MustCompile(`(?i)fill\(\s*previous\s*\)`)
 )
 
 func (query *Query) Build(queryContext *tsdb.TsdbQuery) (string, error) {
@@ -78,7 +80,38 @@ func (query *Query) renderTags() []string {
 	return res
 }
 
+// isIncrementalQuerySafe returns true if the query is grouped by time and
+// does not fill empty intervals with the previous value, which the first
+// interval of the tail of an incremental query does not have.
+func (query *Query) isIncrementalQuerySafe() bool {
+	if query.UseRawQuery {
+		return regexpGroupByTime.MatchString(query.RawQuery) && !regexpFillPrevious.MatchString(query.RawQuery)
+	}
+
+	groupedByTime := false
+	for _, group := range query.GroupBy {
+		switch group.Type {
+		case "time":
+			groupedByTime = true
+		case "fill":
+			if len(group.Params) > 0 && group.Params[0] == "previous" {
+				return false
+			}
+		}
+	}
+
+	return groupedByTime
+}
+
 func (query *Query) renderTimeFilter(queryContext *tsdb.TsdbQuery) string {
+	// absolute time ranges in epoch milliseconds, as the tail of an
+	// incremental query
+	fromMs, fromErr := strconv.ParseInt(queryContext.TimeRange.From, 10, 64)
+	toMs, toErr := strconv.ParseInt(queryContext.TimeRange.To, 10, 64)
+	if fromErr == nil && toErr == nil {
+		return fmt.Sprintf("time >= %dms and time <= %dms", fromMs, toMs)
+	}
+
 	from := "now() - " + queryContext.TimeRange.From
 	to := ""
 
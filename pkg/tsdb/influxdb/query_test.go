@@ -122,6 +122,20 @@ func TestInfluxdbQueryBuilder(t *testing.T) {
 				queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("10m", "now")}
 				So(query.renderTimeFilter(queryContext), ShouldEqual, "time > now() - 10m")
 			})
+
+			Convey("render epoch time range", func() {
+				queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1600000000000", "1600000600000")}
+				So(query.renderTimeFilter(queryContext), ShouldEqual, "time >= 1600000000000ms and time <= 1600000600000ms")
+			})
+		})
+
+		Convey("can tell if query is safe for incremental querying", func() {
+			So((&Query{GroupBy: []*QueryPart{groupBy1}}).isIncrementalQuerySafe(), ShouldBeTrue)
+			So((&Query{GroupBy: []*QueryPart{groupBy1, groupBy3}}).isIncrementalQuerySafe(), ShouldBeTrue)
+			So((&Query{}).isIncrementalQuerySafe(), ShouldBeFalse)
+			So((&Query{UseRawQuery: true, RawQuery: `SELECT mean("value") FROM "cpu" WHERE $timeFilter GROUP BY time($__interval)`}).isIncrementalQuerySafe(), ShouldBeTrue)
+			So((&Query{UseRawQuery: true, RawQuery: `SELECT mean("value") FROM "cpu" WHERE $timeFilter GROUP BY time($__interval) fill(previous)`}).isIncrementalQuerySafe(), ShouldBeFalse)
+			So((&Query{UseRawQuery: true, RawQuery: `SELECT last("value") FROM "cpu" WHERE $timeFilter`}).isIncrementalQuerySafe(), ShouldBeFalse)
 		})
 
 		Convey("can build query from raw query", func() {
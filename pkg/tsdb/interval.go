@@ -54,9 +54,7 @@ func (i *Interval) Milliseconds() int64 {
 }
 
 func (ic *intervalCalculator) Calculate(timerange *TimeRange, minInterval time.Duration) Interval {
-	to := timerange.MustGetTo().UnixNano()
-	from := timerange.MustGetFrom().UnixNano()
-	interval := time.Duration((to - from) / defaultRes)
+	interval := timerange.IntervalDuration() / time.Duration(defaultRes)
 
 	if interval < minInterval {
 		return Interval{Text: FormatDuration(minInterval), Value: minInterval}
@@ -0,0 +1,265 @@
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+const (
+	searchQueryType = "search"
+	// defaultLimit is the number of traces of a search query which sets none.
+	defaultLimit = 20
+)
+
+type JaegerExecutor struct {
+}
+
+func NewJaegerExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &JaegerExecutor{}, nil
+}
+
+var plog log.Logger
+
+func init() {
+	plog = log.New("tsdb.jaeger")
+	tsdb.RegisterTsdbQueryEndpoint("jaeger", NewJaegerExecutor)
+}
+
+// Query fetches a trace by its id, or searches the traces of a service, and
+// returns a frame of spans per trace.
+func (e *JaegerExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	result := &tsdb.Response{
+		Results: map[string]*tsdb.QueryResult{},
+	}
+
+	queries, err := parseQuery(tsdbQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, query := range queries {
+		queryRes := &tsdb.QueryResult{RefId: query.RefId}
+		result.Results[query.RefId] = queryRes
+
+		if !query.Search && query.TraceID == "" {
+			queryRes.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{})
+			continue
+		}
+
+		req, err := createRequest(dsInfo, query)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := ctxhttp.Do(ctx, httpClient, req)
+		if err != nil {
+			return nil, err
+		}
+
+		traces, err := parseResponse(res)
+		if err != nil {
+			queryRes.Error = err
+			queryRes.ErrorString = err.Error()
+			continue
+		}
+
+		frames := make(data.Frames, 0, len(traces))
+		for _, t := range traces {
+			frame, err := traceToFrame(t)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		}
+		queryRes.Dataframes = tsdb.NewDecodedDataFrames(frames)
+	}
+
+	return result, nil
+}
+
+func parseQuery(tsdbQuery *tsdb.TsdbQuery) ([]*JaegerQuery, error) {
+	qs := []*JaegerQuery{}
+	for _, queryModel := range tsdbQuery.Queries {
+		model := queryModel.Model
+		query := &JaegerQuery{
+			RefId:   queryModel.RefId,
+			TraceID: strings.TrimSpace(model.Get("query").MustString("")),
+			Search:  model.Get("queryType").MustString("") == searchQueryType,
+		}
+
+		if query.Search {
+			query.Service = model.Get("service").MustString("")
+			if query.Service == "" {
+				return nil, fmt.Errorf("search query %s has no service", query.RefId)
+			}
+			query.Operation = model.Get("operation").MustString("")
+			query.Limit = model.Get("limit").MustInt64(defaultLimit)
+
+			query.Tags = map[string]string{}
+			for k, v := range model.Get("tags").MustMap() {
+				query.Tags[k] = fmt.Sprint(v)
+			}
+
+			var err error
+			if query.MinDuration, err = parseDuration(model.Get("minDuration").MustString("")); err != nil {
+				return nil, err
+			}
+			if query.MaxDuration, err = parseDuration(model.Get("maxDuration").MustString("")); err != nil {
+				return nil, err
+			}
+
+			if query.Start, err = tsdbQuery.TimeRange.ParseFrom(); err != nil {
+				return nil, err
+			}
+			if query.End, err = tsdbQuery.TimeRange.ParseTo(); err != nil {
+				return nil, err
+			}
+		}
+
+		qs = append(qs, query)
+	}
+
+	return qs, nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	return d, nil
+}
+
+// createRequest creates a request to the API the Jaeger UI uses, which the
+// query service serves next to its gRPC API.
+func createRequest(dsInfo *models.DataSource, query *JaegerQuery) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Search {
+		u.Path = path.Join(u.Path, "api/traces")
+
+		params := url.Values{}
+		params.Set("service", query.Service)
+		if query.Operation != "" {
+			params.Set("operation", query.Operation)
+		}
+		if len(query.Tags) > 0 {
+			tags, err := json.Marshal(query.Tags)
+			if err != nil {
+				return nil, err
+			}
+			params.Set("tags", string(tags))
+		}
+		if query.MinDuration > 0 {
+			params.Set("minDuration", query.MinDuration.String())
+		}
+		if query.MaxDuration > 0 {
+			params.Set("maxDuration", query.MaxDuration.String())
+		}
+		params.Set("start", strconv.FormatInt(query.Start.UnixNano()/int64(time.Microsecond), 10))
+		params.Set("end", strconv.FormatInt(query.End.UnixNano()/int64(time.Microsecond), 10))
+		params.Set("limit", strconv.FormatInt(query.Limit, 10))
+		u.RawQuery = params.Encode()
+	} else {
+		u.Path = path.Join(u.Path, "api/traces", url.PathEscape(query.TraceID))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	return req, nil
+}
+
+func parseResponse(res *http.Response) ([]trace, error) {
+	body, err := ioutil.ReadAll(res.Body)
+	defer res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &response{}
+	if err := json.Unmarshal(body, response); err != nil && res.StatusCode/100 == 2 {
+		return nil, fmt.Errorf("failed to unmarshal Jaeger response: %w", err)
+	}
+
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("Jaeger returned error: %s", response.Errors[0].Msg)
+	}
+	if res.StatusCode/100 != 2 {
+		plog.Info("Request failed", "status", res.Status, "body", string(body))
+		return nil, fmt.Errorf("Request failed status: %v", res.Status)
+	}
+
+	return response.Data, nil
+}
+
+func traceToFrame(t trace) (*data.Frame, error) {
+	spans := make([]*tsdb.TraceSpan, 0, len(t.Spans))
+	for _, s := range t.Spans {
+		tags := make(map[string]interface{}, len(s.Tags))
+		for _, tag := range s.Tags {
+			tags[tag.Key] = tag.Value
+		}
+
+		spans = append(spans, &tsdb.TraceSpan{
+			TraceID:       s.TraceID,
+			SpanID:        s.SpanID,
+			ParentSpanID:  parentSpanID(s),
+			OperationName: s.OperationName,
+			ServiceName:   t.Processes[s.ProcessID].ServiceName,
+			StartTime:     time.Unix(0, s.StartTime*int64(time.Microsecond)),
+			Duration:      time.Duration(s.Duration) * time.Microsecond,
+			Tags:          tags,
+		})
+	}
+
+	return tsdb.SpansToFrame(t.TraceID, spans)
+}
+
+// parentSpanID returns the span a span is a child of, or else the span it
+// follows from.
+func parentSpanID(s span) string {
+	for _, ref := range s.References {
+		if ref.RefType == "CHILD_OF" && ref.TraceID == s.TraceID {
+			return ref.SpanID
+		}
+	}
+	for _, ref := range s.References {
+		if ref.TraceID == s.TraceID {
+			return ref.SpanID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package jaeger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const traceResponse = `{"data":[{
+	"traceID":"abc",
+	"spans":[
+		{"traceID":"abc","spanID":"2","operationName":"query","references":[{"refType":"CHILD_OF","traceID":"abc","spanID":"1"}],
+		 "startTime":1600000000001000,"duration":1500,"tags":[{"key":"error","type":"bool","value":true}],"processID":"p2"},
+		{"traceID":"abc","spanID":"1","operationName":"GET /api","references":[],
+		 "startTime":1600000000000000,"duration":5000,"tags":[],"processID":"p1"}
+	],
+	"processes":{"p1":{"serviceName":"api"},"p2":{"serviceName":"db"}}
+}]}`
+
+func TestJaeger(t *testing.T) {
+	Convey("Jaeger", t, func() {
+		var requestPath, username string
+		var params url.Values
+		body := traceResponse
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestPath = r.URL.Path
+			params = r.URL.Query()
+			username, _, _ = r.BasicAuth()
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		defer server.Close()
+
+		dsInfo := &models.DataSource{
+			Id:            1,
+			Url:           server.URL,
+			BasicAuth:     true,
+			BasicAuthUser: "user",
+			JsonData:      simplejson.New(),
+		}
+		executor, err := NewJaegerExecutor(dsInfo)
+		So(err, ShouldBeNil)
+
+		tsdbQuery := &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1600000000000", "1600003600000"),
+			Queries: []*tsdb.Query{{
+				RefId: "A",
+				Model: simplejson.NewFromAny(map[string]interface{}{"query": "abc"}),
+			}},
+		}
+
+		Convey("fetches trace by id", func() {
+			res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+			So(err, ShouldBeNil)
+			So(requestPath, ShouldEqual, "/api/traces/abc")
+			So(username, ShouldEqual, "user")
+
+			frames, err := res.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+
+			frame := frames[0]
+			So(frame.Name, ShouldEqual, "abc")
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Fields[1].At(0), ShouldEqual, "1")
+			So(frame.Fields[2].At(1), ShouldEqual, "1")
+			So(frame.Fields[3].At(1), ShouldEqual, "query")
+			So(frame.Fields[4].At(1), ShouldEqual, "db")
+			So(frame.Fields[5].At(0), ShouldEqual, time.Unix(1600000000, 0).UTC())
+			So(frame.Fields[6].At(1), ShouldEqual, 1.5)
+			So(frame.Fields[7].At(1), ShouldEqual, `{"error":true}`)
+		})
+
+		Convey("searches traces", func() {
+			tsdbQuery.Queries[0].Model = simplejson.NewFromAny(map[string]interface{}{
+				"queryType":   "search",
+				"service":     "api",
+				"operation":   "GET /api",
+				"tags":        map[string]interface{}{"http.status_code": 500},
+				"minDuration": "100ms",
+			})
+
+			res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+			So(err, ShouldBeNil)
+			So(requestPath, ShouldEqual, "/api/traces")
+			So(params.Get("service"), ShouldEqual, "api")
+			So(params.Get("operation"), ShouldEqual, "GET /api")
+			So(params.Get("tags"), ShouldEqual, `{"http.status_code":"500"}`)
+			So(params.Get("minDuration"), ShouldEqual, "100ms")
+			So(params.Get("maxDuration"), ShouldEqual, "")
+			So(params.Get("start"), ShouldEqual, "1600000000000000")
+			So(params.Get("end"), ShouldEqual, "1600003600000000")
+			So(params.Get("limit"), ShouldEqual, "20")
+
+			frames, err := res.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+		})
+
+		Convey("returns error of trace not found", func() {
+			status = http.StatusNotFound
+			body = `{"data":null,"errors":[{"code":404,"msg":"trace not found"}]}`
+
+			res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+			So(err, ShouldBeNil)
+			So(res.Results["A"].ErrorString, ShouldEqual, "Jaeger returned error: trace not found")
+		})
+
+		Convey("returns no frames without trace id", func() {
+			tsdbQuery.Queries[0].Model = simplejson.New()
+
+			res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+			So(err, ShouldBeNil)
+			So(requestPath, ShouldEqual, "")
+
+			frames, err := res.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldBeEmpty)
+		})
+	})
+}
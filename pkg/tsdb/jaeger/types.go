@@ -0,0 +1,65 @@
+package jaeger
+
+import "time"
+
+type JaegerQuery struct {
+	RefId   string
+	TraceID string
+
+	// Search queries find the traces of a service which match the other
+	// criteria, instead of fetching a trace by its id.
+	Search      bool
+	Service     string
+	Operation   string
+	Tags        map[string]string
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	Limit       int64
+	Start       time.Time
+	End         time.Time
+}
+
+// response is the response of the trace endpoints of the Jaeger query service.
+type response struct {
+	Data   []trace `json:"data"`
+	Errors []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"errors"`
+}
+
+type trace struct {
+	TraceID   string             `json:"traceID"`
+	Spans     []span             `json:"spans"`
+	Processes map[string]process `json:"processes"`
+}
+
+// span is a span of a trace, whose start time and duration are in
+// microseconds.
+type span struct {
+	TraceID       string      `json:"traceID"`
+	SpanID        string      `json:"spanID"`
+	OperationName string      `json:"operationName"`
+	References    []reference `json:"references"`
+	StartTime     int64       `json:"startTime"`
+	Duration      int64       `json:"duration"`
+	Tags          []keyValue  `json:"tags"`
+	ProcessID     string      `json:"processID"`
+}
+
+type reference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type process struct {
+	ServiceName string     `json:"serviceName"`
+	Tags        []keyValue `json:"tags"`
+}
+
+type keyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
@@ -0,0 +1,31 @@
+package tsdb
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+var legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
+
+// FormatLegend returns the name of a Prometheus style series, replacing the
+// {{label}} patterns of the legend format with the values of the labels. The
+// series is named after its metric and labels without a legend format.
+func FormatLegend(metric model.Metric, format string) string {
+	if format == "" {
+		return metric.String()
+	}
+
+	result := legendFormat.ReplaceAllFunc([]byte(format), func(in []byte) []byte {
+		labelName := strings.Replace(string(in), "{{", "", 1)
+		labelName = strings.Replace(labelName, "}}", "", 1)
+		labelName = strings.TrimSpace(labelName)
+		if val, exists := metric[model.LabelName(labelName)]; exists {
+			return []byte(val)
+		}
+		return []byte{}
+	})
+
+	return string(result)
+}
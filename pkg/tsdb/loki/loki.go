@@ -0,0 +1,340 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/common/model"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+const (
+	// defaultMaxLines is the number of log lines of a query when neither the
+	// query nor the data source sets one.
+	defaultMaxLines = 1000
+)
+
+type LokiExecutor struct {
+	Transport http.RoundTripper
+}
+
+func NewLokiExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	transport, err := dsInfo.GetHttpTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LokiExecutor{
+		Transport: transport,
+	}, nil
+}
+
+var (
+	plog               log.Logger
+	intervalCalculator tsdb.IntervalCalculator
+)
+
+func init() {
+	plog = log.New("tsdb.loki")
+	tsdb.RegisterTsdbQueryEndpoint("loki", NewLokiExecutor)
+	intervalCalculator = tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: time.Second * 1})
+}
+
+func (e *LokiExecutor) getClient(dsInfo *models.DataSource) *http.Client {
+	return &http.Client{Transport: tsdb.BasicAuthTransport(dsInfo, e.Transport)}
+}
+
+func (e *LokiExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	result := &tsdb.Response{
+		Results: map[string]*tsdb.QueryResult{},
+	}
+
+	client := e.getClient(dsInfo)
+
+	queries, err := parseQuery(dsInfo, tsdbQuery.Queries, tsdbQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, query := range queries {
+		plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "instant", query.Instant, "query", query.Expr)
+
+		span, ctx := opentracing.StartSpanFromContext(ctx, "loki.query")
+		span.SetTag("expr", query.Expr)
+		span.SetTag("start_unixnano", query.Start.UnixNano())
+		span.SetTag("stop_unixnano", query.End.UnixNano())
+		defer span.Finish()
+
+		res, err := execute(ctx, client, dsInfo, query)
+		if err != nil {
+			return nil, err
+		}
+
+		frames, err := parseResponse(res, query)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Results[query.RefId] = &tsdb.QueryResult{
+			RefId:      query.RefId,
+			Dataframes: tsdb.NewDecodedDataFrames(frames),
+		}
+	}
+
+	return result, nil
+}
+
+// execute sends a query to the range or instant query endpoint of Loki.
+func execute(ctx context.Context, client *http.Client, dsInfo *models.DataSource, query *LokiQuery) (*response, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("query", query.Expr)
+	params.Set("limit", strconv.FormatInt(query.MaxLines, 10))
+	params.Set("direction", "backward")
+
+	if query.Instant {
+		u.Path = path.Join(u.Path, "loki/api/v1/query")
+		params.Set("time", strconv.FormatInt(query.End.UnixNano(), 10))
+	} else {
+		u.Path = path.Join(u.Path, "loki/api/v1/query_range")
+		params.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+		params.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+		params.Set("step", strconv.FormatFloat(query.Step.Seconds(), 'f', -1, 64))
+	}
+	u.RawQuery = params.Encode()
+
+	res, err := ctxhttp.Get(ctx, client, u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			plog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Loki returns the errors of queries as plain text
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("Loki returned %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	resp := &response{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Loki response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("Loki query failed: %s", resp.Error)
+	}
+
+	return resp, nil
+}
+
+func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *tsdb.TsdbQuery) ([]*LokiQuery, error) {
+	qs := []*LokiQuery{}
+	for _, queryModel := range queries {
+		expr, err := queryModel.Model.Get("expr").String()
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := queryContext.TimeRange.ParseFrom()
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := queryContext.TimeRange.ParseTo()
+		if err != nil {
+			return nil, err
+		}
+
+		dsInterval, err := tsdb.GetIntervalFrom(dsInfo, queryModel.Model, time.Second)
+		if err != nil {
+			return nil, err
+		}
+
+		interval := intervalCalculator.Calculate(queryContext.TimeRange, dsInterval)
+
+		qs = append(qs, &LokiQuery{
+			Expr:         expr,
+			Step:         interval.Value,
+			LegendFormat: queryModel.Model.Get("legendFormat").MustString(""),
+			Start:        start,
+			End:          end,
+			RefId:        queryModel.RefId,
+			MaxLines:     maxLines(dsInfo, queryModel),
+			Instant:      queryModel.Model.Get("instant").MustBool(false),
+		})
+	}
+
+	return qs, nil
+}
+
+// maxLines returns the limit of log lines of the query, which defaults to
+// the one of the data source.
+func maxLines(dsInfo *models.DataSource, query *tsdb.Query) int64 {
+	if limit := query.Model.Get("maxLines").MustInt64(0); limit > 0 {
+		return limit
+	}
+
+	// the data source stores the limit as text
+	if dsInfo.JsonData != nil {
+		if limit, err := strconv.ParseInt(dsInfo.JsonData.Get("maxLines").MustString(""), 10, 64); err == nil && limit > 0 {
+			return limit
+		}
+	}
+
+	return defaultMaxLines
+}
+
+// parseResponse converts the result of a metric query to a frame per series,
+// with a time and a value field named after the series, and the result of a
+// log query to a frame per stream, with a time and a line field.
+func parseResponse(res *response, query *LokiQuery) (data.Frames, error) {
+	switch res.Data.ResultType {
+	case "matrix":
+		var matrix model.Matrix
+		if err := json.Unmarshal(res.Data.Result, &matrix); err != nil {
+			return nil, err
+		}
+
+		frames := make(data.Frames, 0, len(matrix))
+		for _, series := range matrix {
+			times := make([]time.Time, 0, len(series.Values))
+			values := make([]float64, 0, len(series.Values))
+			for _, pair := range series.Values {
+				times = append(times, pair.Timestamp.Time().UTC())
+				values = append(values, float64(pair.Value))
+			}
+			frames = append(frames, newSeriesFrame(series.Metric, query, times, values))
+		}
+		return frames, nil
+
+	case "vector":
+		var vector model.Vector
+		if err := json.Unmarshal(res.Data.Result, &vector); err != nil {
+			return nil, err
+		}
+
+		frames := make(data.Frames, 0, len(vector))
+		for _, sample := range vector {
+			frames = append(frames, newSeriesFrame(sample.Metric, query,
+				[]time.Time{sample.Timestamp.Time().UTC()}, []float64{float64(sample.Value)}))
+		}
+		return frames, nil
+
+	case "scalar":
+		scalar := &model.Scalar{}
+		if err := json.Unmarshal(res.Data.Result, scalar); err != nil {
+			return nil, err
+		}
+
+		return data.Frames{newSeriesFrame(model.Metric{}, query,
+			[]time.Time{scalar.Timestamp.Time().UTC()}, []float64{float64(scalar.Value)})}, nil
+
+	case "streams":
+		var streams []stream
+		if err := json.Unmarshal(res.Data.Result, &streams); err != nil {
+			return nil, err
+		}
+
+		frames := make(data.Frames, 0, len(streams))
+		for _, s := range streams {
+			frame, err := newStreamFrame(s, query)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		}
+		return frames, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported result format: %s", res.Data.ResultType)
+}
+
+func newSeriesFrame(metric model.Metric, query *LokiQuery, times []time.Time, values []float64) *data.Frame {
+	name := tsdb.FormatLegend(metric, query.LegendFormat)
+	if len(metric) == 0 && query.LegendFormat == "" {
+		name = query.Expr
+	}
+
+	labels := make(data.Labels, len(metric))
+	for k, v := range metric {
+		labels[string(k)] = string(v)
+	}
+
+	return data.NewFrame(name,
+		data.NewField("Time", nil, times),
+		data.NewField(name, labels, values),
+	)
+}
+
+// newStreamFrame converts a log stream to a frame whose line field has the
+// labels of the stream, in the order of the lines in the response.
+func newStreamFrame(s stream, query *LokiQuery) (*data.Frame, error) {
+	times := make([]time.Time, 0, len(s.Values))
+	lines := make([]string, 0, len(s.Values))
+	for _, value := range s.Values {
+		ns, err := strconv.ParseInt(value[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp of log line: %w", err)
+		}
+		times = append(times, time.Unix(0, ns).UTC())
+		lines = append(lines, value[1])
+	}
+
+	labels := make(data.Labels, len(s.Stream))
+	for k, v := range s.Stream {
+		labels[k] = v
+	}
+
+	frame := data.NewFrame(formatStreamLabels(s.Stream),
+		data.NewField("ts", nil, times),
+		data.NewField("line", labels, lines),
+	)
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString:    query.Expr,
+		PreferredVisualization: data.VisTypeLogs,
+	}
+
+	return frame, nil
+}
+
+// formatStreamLabels returns the labels of a stream as a selector, such as
+// {app="api", level="error"}.
+func formatStreamLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -0,0 +1,191 @@
+package loki
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	p "github.com/prometheus/common/model"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoki(t *testing.T) {
+	Convey("Loki", t, func() {
+		dsInfo := &models.DataSource{
+			JsonData: simplejson.New(),
+		}
+
+		Convey("converting metric name", func() {
+			metric := p.Metric{
+				p.LabelName("app"):   p.LabelValue("backend"),
+				p.LabelName("level"): p.LabelValue("error"),
+			}
+
+			query := &LokiQuery{
+				LegendFormat: "legend {{app}} {{ level }} {{broken}}",
+			}
+
+			So(tsdb.FormatLegend(metric, query.LegendFormat), ShouldEqual, "legend backend error ")
+			So(tsdb.FormatLegend(metric, ""), ShouldEqual, `{app="backend", level="error"}`)
+		})
+
+		Convey("parsing query model", func() {
+			queryContext := &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("12h", "now"),
+			}
+			query := &tsdb.Query{
+				RefId: "A",
+				Model: simplejson.NewFromAny(map[string]interface{}{
+					"expr":         `rate({app="api"}[5m])`,
+					"legendFormat": "{{app}}",
+				}),
+			}
+
+			Convey("with step of time range", func() {
+				models, err := parseQuery(dsInfo, []*tsdb.Query{query}, queryContext)
+				So(err, ShouldBeNil)
+				So(models[0].Step, ShouldEqual, time.Second*30)
+				So(models[0].MaxLines, ShouldEqual, defaultMaxLines)
+			})
+
+			Convey("with interval of the query", func() {
+				query.Model.Set("interval", "5m")
+				models, err := parseQuery(dsInfo, []*tsdb.Query{query}, queryContext)
+				So(err, ShouldBeNil)
+				So(models[0].Step, ShouldEqual, time.Minute*5)
+			})
+
+			Convey("with max lines of the data source", func() {
+				dsInfo.JsonData.Set("maxLines", "200")
+				models, err := parseQuery(dsInfo, []*tsdb.Query{query}, queryContext)
+				So(err, ShouldBeNil)
+				So(models[0].MaxLines, ShouldEqual, 200)
+
+				Convey("overridden by the query", func() {
+					query.Model.Set("maxLines", 50)
+					models, err := parseQuery(dsInfo, []*tsdb.Query{query}, queryContext)
+					So(err, ShouldBeNil)
+					So(models[0].MaxLines, ShouldEqual, 50)
+				})
+			})
+		})
+
+		Convey("querying Loki", func() {
+			var requestPath, username, password string
+			var params url.Values
+			body := ""
+			status := http.StatusOK
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestPath = r.URL.Path
+				params = r.URL.Query()
+				username, password, _ = r.BasicAuth()
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			dsInfo.Url = server.URL
+			dsInfo.BasicAuth = true
+			dsInfo.BasicAuthUser = "user"
+			dsInfo.BasicAuthPassword = "pass"
+
+			executor, err := NewLokiExecutor(dsInfo)
+			So(err, ShouldBeNil)
+
+			tsdbQuery := &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("1600000000000", "1600003600000"),
+				Queries: []*tsdb.Query{{
+					RefId: "A",
+					Model: simplejson.NewFromAny(map[string]interface{}{
+						"expr":         `sum by (level) (rate({app="api"}[1m]))`,
+						"legendFormat": "{{level}}",
+					}),
+				}},
+			}
+
+			Convey("converts matrix result to frames", func() {
+				body = `{"status":"success","data":{"resultType":"matrix","result":[
+					{"metric":{"level":"error"},"values":[[1600000000,"1.5"],[1600000060,"2"]]}
+				]}}`
+
+				res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+				So(err, ShouldBeNil)
+
+				So(requestPath, ShouldEqual, "/loki/api/v1/query_range")
+				So(params.Get("query"), ShouldEqual, `sum by (level) (rate({app="api"}[1m]))`)
+				So(params.Get("start"), ShouldEqual, "1600000000000000000")
+				So(params.Get("end"), ShouldEqual, "1600003600000000000")
+				So(params.Get("step"), ShouldEqual, "2")
+				So(params.Get("limit"), ShouldEqual, "1000")
+				So(username, ShouldEqual, "user")
+				So(password, ShouldEqual, "pass")
+
+				frames, err := res.Results["A"].Dataframes.Decoded()
+				So(err, ShouldBeNil)
+				So(frames, ShouldHaveLength, 1)
+				So(frames[0].Name, ShouldEqual, "error")
+				So(frames[0].Rows(), ShouldEqual, 2)
+				So(frames[0].Fields[0].At(1), ShouldEqual, time.Unix(1600000060, 0).UTC())
+				So(frames[0].Fields[1].At(0), ShouldEqual, 1.5)
+				So(frames[0].Fields[1].Labels, ShouldResemble, data.Labels{"level": "error"})
+
+				series, err := tsdb.FrameToSeriesSlice(frames[0])
+				So(err, ShouldBeNil)
+				So(series, ShouldHaveLength, 1)
+				So(series[0].Points, ShouldHaveLength, 2)
+			})
+
+			Convey("sends instant queries to the query endpoint", func() {
+				tsdbQuery.Queries[0].Model.Set("instant", true)
+				body = `{"status":"success","data":{"resultType":"vector","result":[
+					{"metric":{"level":"error"},"value":[1600003600,"3"]}
+				]}}`
+
+				res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+				So(err, ShouldBeNil)
+
+				So(requestPath, ShouldEqual, "/loki/api/v1/query")
+				So(params.Get("time"), ShouldEqual, "1600003600000000000")
+
+				frames, err := res.Results["A"].Dataframes.Decoded()
+				So(err, ShouldBeNil)
+				So(frames[0].Fields[1].At(0), ShouldEqual, 3.0)
+			})
+
+			Convey("converts streams result to log frames", func() {
+				body = `{"status":"success","data":{"resultType":"streams","result":[
+					{"stream":{"app":"api","level":"error"},"values":[["1600000060000000001","second"],["1600000000000000000","first"]]}
+				]}}`
+
+				res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+				So(err, ShouldBeNil)
+				So(params.Get("direction"), ShouldEqual, "backward")
+
+				frames, err := res.Results["A"].Dataframes.Decoded()
+				So(err, ShouldBeNil)
+				So(frames, ShouldHaveLength, 1)
+				So(frames[0].Name, ShouldEqual, `{app="api", level="error"}`)
+				So(frames[0].Meta.PreferredVisualization, ShouldEqual, data.VisTypeLogs)
+				So(frames[0].Fields[0].At(0), ShouldEqual, time.Unix(1600000060, 1).UTC())
+				So(frames[0].Fields[1].At(1), ShouldEqual, "first")
+				So(frames[0].Fields[1].Labels, ShouldResemble, data.Labels{"app": "api", "level": "error"})
+			})
+
+			Convey("returns error of query", func() {
+				status = http.StatusBadRequest
+				body = "parse error at line 1, col 5\n"
+
+				_, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "parse error at line 1, col 5")
+			})
+		})
+	})
+}
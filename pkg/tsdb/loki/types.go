@@ -0,0 +1,36 @@
+package loki
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type LokiQuery struct {
+	Expr         string
+	Step         time.Duration
+	LegendFormat string
+	Start        time.Time
+	End          time.Time
+	RefId        string
+	MaxLines     int64
+
+	// Instant queries are evaluated at End only.
+	Instant bool
+}
+
+// response is the envelope of the responses of the query endpoints.
+type response struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+	Error string `json:"error"`
+}
+
+// stream is a log stream of a streams result, whose values are pairs of a
+// timestamp in nanoseconds and a log line.
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
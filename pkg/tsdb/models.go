@@ -16,6 +16,8 @@ type TsdbQuery struct {
 	Headers   map[string]string
 	Debug     bool
 	User      *models.SignedInUser
+	// SkipCache bypasses the query cache for this request.
+	SkipCache bool
 }
 
 type Query struct {
@@ -132,3 +132,18 @@ func (m *msSqlMacroEngine) evaluateMacro(name string, args []string) (string, er
 		return "", fmt.Errorf("Unknown macro %v", name)
 	}
 }
+
+// mssqlQueryDialect is the dialect of the query builder. Microsoft SQL Server quotes identifiers with brackets, binds arguments to named parameters and limits rows with TOP.
+type mssqlQueryDialect struct{}
+
+func (d mssqlQueryDialect) QuoteIdentifier(name string) string {
+	return "[" + strings.Replace(name, "]", "]]", -1) + "]"
+}
+
+func (d mssqlQueryDialect) Placeholder(n int) string {
+	return fmt.Sprintf("@p%d", n)
+}
+
+func (d mssqlQueryDialect) Limit(sql string, limit int64) string {
+	return strings.Replace(sql, "SELECT ", fmt.Sprintf("SELECT TOP %d ", limit), 1)
+}
@@ -38,6 +38,7 @@ func newMssqlQueryEndpoint(datasource *models.DataSource) (tsdb.TsdbQueryEndpoin
 		ConnectionString:  cnnstr,
 		Datasource:        datasource,
 		MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
+		QueryDialect:      mssqlQueryDialect{},
 	}
 
 	queryResultTransformer := mssqlQueryResultTransformer{
@@ -138,7 +138,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(err, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				column := sqleng.TableRows(queryResult)[0]
 
 				So(column[0].(bool), ShouldEqual, true)
 
@@ -930,7 +930,7 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -953,7 +953,7 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -980,8 +980,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(float64), ShouldEqual, float64(dt.UnixNano()/1e6))
@@ -1010,8 +1010,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -1040,8 +1040,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -1070,8 +1070,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(float64), ShouldEqual, float64(dt.Unix()*1000))
@@ -1098,8 +1098,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1126,8 +1126,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
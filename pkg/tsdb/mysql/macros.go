@@ -138,3 +138,18 @@ func (m *mySqlMacroEngine) evaluateMacro(name string, args []string) (string, er
 		return "", fmt.Errorf("Unknown macro %v", name)
 	}
 }
+
+// mySqlQueryDialect is the dialect of the query builder. MySQL quotes identifiers with backticks and binds arguments to question marks.
+type mySqlQueryDialect struct{}
+
+func (d mySqlQueryDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func (d mySqlQueryDialect) Placeholder(n int) string {
+	return "?"
+}
+
+func (d mySqlQueryDialect) Limit(sql string, limit int64) string {
+	return fmt.Sprintf("%s LIMIT %d", sql, limit)
+}
@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -186,3 +188,34 @@ func TestMacroEngine(t *testing.T) {
 		})
 	})
 }
+
+func TestQueryDialect(t *testing.T) {
+	Convey("Query builder dialect", t, func() {
+		engine := &mySqlMacroEngine{logger: log.New("test")}
+		query := &tsdb.Query{
+			Model: simplejson.NewFromAny(map[string]interface{}{
+				"queryMode": "builder",
+				"builder": map[string]interface{}{
+					"table":      "metrics",
+					"timeColumn": "time",
+					"columns":    []interface{}{map[string]interface{}{"column": "value", "aggregation": "max"}},
+					"where":      []interface{}{map[string]interface{}{"column": "host", "operator": "IN", "value": []interface{}{"a", "b"}}},
+					"interval":   "5m",
+					"limit":      100,
+				},
+			}),
+		}
+		from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+		timeRange := tsdb.NewFakeTimeRange("5m", "now", from.Add(5*time.Minute))
+
+		rawSQL, args, err := sqleng.BuildQuery(mySqlQueryDialect{}, query)
+		So(err, ShouldBeNil)
+		So(args, ShouldResemble, []interface{}{"a", "b"})
+
+		sql, err := engine.Interpolate(query, timeRange, rawSQL)
+		So(err, ShouldBeNil)
+		So(sql, ShouldEqual, "SELECT UNIX_TIMESTAMP(`time`) DIV 300 * 300 AS \"time\", max(`value`) AS `value` FROM `metrics` "+
+			"WHERE `time` BETWEEN FROM_UNIXTIME(1523556000) AND FROM_UNIXTIME(1523556300) AND `host` IN (?, ?) "+
+			"GROUP BY UNIX_TIMESTAMP(`time`) DIV 300 * 300 ORDER BY 1 LIMIT 100")
+	})
+}
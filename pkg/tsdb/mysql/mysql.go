@@ -67,6 +67,7 @@ func newMysqlQueryEndpoint(datasource *models.DataSource) (tsdb.TsdbQueryEndpoin
 		Datasource:        datasource,
 		TimeColumnNames:   []string{"time", "time_sec"},
 		MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+		QueryDialect:      mySqlQueryDialect{},
 	}
 
 	rowTransformer := mysqlQueryResultTransformer{
@@ -1,3 +1,4 @@
+//go:build integration
 // +build integration
 
 package mysql
@@ -140,23 +141,23 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				column := sqleng.TableRows(queryResult)[0]
 
-				So(*column[0].(*int8), ShouldEqual, 1)
+				So(column[0].(int8), ShouldEqual, 1)
 				So(column[1].(string), ShouldEqual, "abc")
 				So(column[2].(string), ShouldEqual, "def")
-				So(*column[3].(*int32), ShouldEqual, 1)
-				So(*column[4].(*int16), ShouldEqual, 10)
-				So(*column[5].(*int64), ShouldEqual, 100)
-				So(*column[6].(*int32), ShouldEqual, 1420070400)
+				So(column[3].(int32), ShouldEqual, 1)
+				So(column[4].(int16), ShouldEqual, 10)
+				So(column[5].(int64), ShouldEqual, 100)
+				So(column[6].(int32), ShouldEqual, 1420070400)
 				So(column[7].(float64), ShouldEqual, 1.11)
 				So(column[8].(float64), ShouldEqual, 2.22)
-				So(*column[9].(*float32), ShouldEqual, 3.33)
+				So(column[9].(float32), ShouldEqual, 3.33)
 				So(column[10].(time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
 				So(column[11].(time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
 				So(column[12].(string), ShouldEqual, "11:11:11")
 				So(column[13].(int64), ShouldEqual, 2018)
-				So(*column[14].(*[]byte), ShouldHaveSameTypeAs, []byte{1})
+				So(column[14].(string), ShouldHaveSameTypeAs, "")
 				So(column[15].(string), ShouldEqual, "tinytext")
 				So(column[16].(string), ShouldEqual, "tinyblob")
 				So(column[17].(string), ShouldEqual, "text")
@@ -835,7 +836,7 @@ func TestMySQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -858,7 +859,7 @@ func TestMySQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -885,8 +886,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(float64), ShouldEqual, float64(dt.Unix()*1000))
@@ -915,8 +916,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -945,8 +946,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -975,8 +976,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -1003,8 +1004,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1031,8 +1032,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -161,3 +161,18 @@ func (m *postgresMacroEngine) evaluateMacro(name string, args []string) (string,
 		return "", fmt.Errorf("Unknown macro %v", name)
 	}
 }
+
+// postgresQueryDialect is the dialect of the query builder. Postgres quotes identifiers with double quotes and binds arguments to numbered parameters.
+type postgresQueryDialect struct{}
+
+func (d postgresQueryDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func (d postgresQueryDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d postgresQueryDialect) Limit(sql string, limit int64) string {
+	return fmt.Sprintf("%s LIMIT %d", sql, limit)
+}
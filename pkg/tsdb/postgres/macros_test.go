@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -209,3 +211,34 @@ func TestMacroEngine(t *testing.T) {
 		})
 	})
 }
+
+func TestQueryDialect(t *testing.T) {
+	Convey("Query builder dialect", t, func() {
+		engine := newPostgresMacroEngine(false)
+		query := &tsdb.Query{
+			Model: simplejson.NewFromAny(map[string]interface{}{
+				"queryMode": "builder",
+				"builder": map[string]interface{}{
+					"table":      "metrics",
+					"timeColumn": "time",
+					"columns":    []interface{}{map[string]interface{}{"column": "value", "aggregation": "max"}},
+					"where":      []interface{}{map[string]interface{}{"column": "host", "operator": "IN", "value": []interface{}{"a", "b"}}},
+					"interval":   "5m",
+					"limit":      100,
+				},
+			}),
+		}
+		from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+		timeRange := tsdb.NewFakeTimeRange("5m", "now", from.Add(5*time.Minute))
+
+		rawSQL, args, err := sqleng.BuildQuery(postgresQueryDialect{}, query)
+		So(err, ShouldBeNil)
+		So(args, ShouldResemble, []interface{}{"a", "b"})
+
+		sql, err := engine.Interpolate(query, timeRange, rawSQL)
+		So(err, ShouldBeNil)
+		So(sql, ShouldEqual, `SELECT floor(extract(epoch from "time")/300)*300 AS "time", max("value") AS "value" FROM "metrics" `+
+			`WHERE "time" BETWEEN '2018-04-12T18:00:00Z' AND '2018-04-12T18:05:00Z' AND "host" IN ($1, $2) `+
+			`GROUP BY floor(extract(epoch from "time")/300)*300 ORDER BY 1 LIMIT 100`)
+	})
+}
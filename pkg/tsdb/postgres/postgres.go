@@ -38,6 +38,7 @@ func newPostgresQueryEndpoint(datasource *models.DataSource) (tsdb.TsdbQueryEndp
 		ConnectionString:  cnnstr,
 		Datasource:        datasource,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
+		QueryDialect:      postgresQueryDialect{},
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{
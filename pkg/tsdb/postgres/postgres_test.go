@@ -1,3 +1,4 @@
+//go:build integration
 // +build integration
 
 package postgres
@@ -219,7 +220,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				column := sqleng.TableRows(queryResult)[0]
 				So(column[0].(int64), ShouldEqual, 1)
 				So(column[1].(int64), ShouldEqual, 2)
 				So(column[2].(int64), ShouldEqual, 3)
@@ -859,7 +860,7 @@ func TestPostgres(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -882,7 +883,7 @@ func TestPostgres(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -909,8 +910,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(float64), ShouldEqual, float64(dt.UnixNano()/1e6))
@@ -939,8 +940,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -969,8 +970,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -999,8 +1000,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
@@ -1027,8 +1028,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1055,8 +1056,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(sqleng.TableRows(queryResult)), ShouldEqual, 1)
+				columns := sqleng.TableRows(queryResult)[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -0,0 +1,56 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	api "github.com/prometheus/client_golang/api"
+)
+
+// errEndpointNotFound is returned for API endpoints the Prometheus server
+// does not have, as older versions lack the metadata and exemplar APIs.
+var errEndpointNotFound = errors.New("endpoint not found")
+
+type apiResponse struct {
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+	Error  string          `json:"error"`
+}
+
+// get requests an endpoint of the Prometheus HTTP API, for the endpoints
+// the client library does not support, and decodes its data into result.
+func get(ctx context.Context, client api.Client, endpoint string, args map[string]string, result interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, client.URL(endpoint, nil).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	q := req.URL.Query()
+	for k, v := range args {
+		q.Set(k, v)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, body, err := client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errEndpointNotFound
+	}
+
+	var apiResp apiResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return fmt.Errorf("failed to decode response of %s, status %d: %w", endpoint, resp.StatusCode, err)
+	}
+
+	if apiResp.Status != "success" {
+		return fmt.Errorf("request to %s failed: %s", endpoint, apiResp.Error)
+	}
+
+	return json.Unmarshal(apiResp.Data, result)
+}
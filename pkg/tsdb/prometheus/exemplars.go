@@ -0,0 +1,160 @@
+package prometheus
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	api "github.com/prometheus/client_golang/api"
+)
+
+const (
+	epQueryExemplars = "/api/v1/query_exemplars"
+
+	// exemplarFrameName is the name of the frame of the exemplars of a
+	// query, which follows the frames of its series.
+	exemplarFrameName = "exemplar"
+)
+
+type exemplarSeries struct {
+	SeriesLabels map[string]string `json:"seriesLabels"`
+	Exemplars    []exemplar        `json:"exemplars"`
+}
+
+type exemplar struct {
+	Labels    map[string]string `json:"labels"`
+	Value     string            `json:"value"`
+	Timestamp float64           `json:"timestamp"`
+}
+
+// exemplarTraceIDDestination links an exemplar label holding a trace id to
+// a URL, such as the trace view of a tracing data source. The URL can use
+// the trace id as ${__value.raw}.
+type exemplarTraceIDDestination struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// queryExemplars returns a frame with the exemplars of the series of the
+// query. It returns nil when the server does not support exemplars or
+// there are none.
+func queryExemplars(ctx context.Context, client api.Client, dsInfo *models.DataSource, query *PrometheusQuery) (*data.Frame, error) {
+	args := map[string]string{
+		"query": query.Expr,
+		"start": formatTime(query.Start),
+		"end":   formatTime(query.End),
+	}
+
+	var result []exemplarSeries
+	if err := get(ctx, client, epQueryExemplars, args, &result); err != nil {
+		if err == errEndpointNotFound {
+			plog.Debug("Prometheus server does not support exemplars", "datasource", dsInfo.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return newExemplarFrame(result, exemplarTraceIDDestinations(dsInfo)), nil
+}
+
+// newExemplarFrame returns a frame with the time and value of each exemplar
+// and a field for each label of the exemplars and their series. Fields of
+// trace id labels link to their destination.
+func newExemplarFrame(result []exemplarSeries, destinations []exemplarTraceIDDestination) *data.Frame {
+	labelNames := make(map[string]bool)
+	count := 0
+	for _, series := range result {
+		for name := range series.SeriesLabels {
+			labelNames[name] = true
+		}
+		for _, e := range series.Exemplars {
+			for name := range e.Labels {
+				labelNames[name] = true
+			}
+		}
+		count += len(series.Exemplars)
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(labelNames))
+	for name := range labelNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	times := make([]time.Time, 0, count)
+	values := make([]float64, 0, count)
+	labels := make([][]string, len(names))
+
+	for _, series := range result {
+		for _, e := range series.Exemplars {
+			value, err := strconv.ParseFloat(e.Value, 64)
+			if err != nil {
+				value = math.NaN()
+			}
+
+			sec, frac := math.Modf(e.Timestamp)
+			times = append(times, time.Unix(int64(sec), int64(frac*1e9)).UTC())
+			values = append(values, value)
+
+			for i, name := range names {
+				label, ok := e.Labels[name]
+				if !ok {
+					label = series.SeriesLabels[name]
+				}
+				labels[i] = append(labels[i], label)
+			}
+		}
+	}
+
+	frame := data.NewFrame(exemplarFrameName,
+		data.NewField("Time", nil, times),
+		data.NewField("Value", nil, values),
+	)
+
+	for i, name := range names {
+		field := data.NewField(name, nil, labels[i])
+		for _, destination := range destinations {
+			if destination.Name == name && destination.URL != "" {
+				field.Config = &data.FieldConfig{Links: []data.DataLink{{Title: "View trace", URL: destination.URL}}}
+			}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	frame.SetMeta(&data.FrameMeta{Custom: map[string]interface{}{"resultType": "exemplar"}})
+	return frame
+}
+
+// exemplarTraceIDDestinations returns the exemplarTraceIdDestinations of the
+// json data of the data source.
+func exemplarTraceIDDestinations(dsInfo *models.DataSource) []exemplarTraceIDDestination {
+	destinations := make([]exemplarTraceIDDestination, 0)
+	if dsInfo.JsonData == nil {
+		return destinations
+	}
+
+	for _, item := range dsInfo.JsonData.Get("exemplarTraceIdDestinations").MustArray() {
+		destination, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := destination["name"].(string)
+		url, _ := destination["url"].(string)
+		destinations = append(destinations, exemplarTraceIDDestination{Name: name, URL: url})
+	}
+
+	return destinations
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
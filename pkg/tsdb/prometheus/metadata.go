@@ -0,0 +1,74 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	api "github.com/prometheus/client_golang/api"
+)
+
+const epMetadata = "/api/v1/metadata"
+
+// metadataCache keeps the metadata of metrics, so that it is only requested
+// once per metric and datasource every few minutes.
+var metadataCache = localcache.New(5*time.Minute, 10*time.Minute)
+
+// metricMetadata is the metadata Prometheus scrapes for a metric.
+type metricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+// addMetadata sets the type, help and unit of the metric of each frame as
+// custom frame meta. Metrics without metadata, and all metrics of servers
+// without the metadata API, are left unchanged.
+func addMetadata(ctx context.Context, client api.Client, dsInfo *models.DataSource, frames data.Frames) {
+	for _, frame := range frames {
+		if len(frame.Fields) < 2 {
+			continue
+		}
+
+		name := frame.Fields[1].Labels["__name__"]
+		if name == "" {
+			continue
+		}
+
+		metadata, err := getMetadata(ctx, client, dsInfo, name)
+		if err != nil {
+			plog.Debug("Failed to get metric metadata", "metric", name, "error", err)
+			if err == errEndpointNotFound {
+				return
+			}
+			continue
+		}
+
+		if metadata != nil {
+			frame.SetMeta(&data.FrameMeta{Custom: metadata})
+		}
+	}
+}
+
+func getMetadata(ctx context.Context, client api.Client, dsInfo *models.DataSource, name string) (*metricMetadata, error) {
+	key := fmt.Sprintf("%d-%d-%s", dsInfo.Id, dsInfo.Version, name)
+	if cached, ok := metadataCache.Get(key); ok {
+		return cached.(*metricMetadata), nil
+	}
+
+	var result map[string][]metricMetadata
+	if err := get(ctx, client, epMetadata, map[string]string{"metric": name, "limit": "1"}, &result); err != nil {
+		return nil, err
+	}
+
+	var metadata *metricMetadata
+	if entries := result[name]; len(entries) > 0 {
+		metadata = &entries[0]
+	}
+
+	metadataCache.SetDefault(key, metadata)
+	return metadata, nil
+}
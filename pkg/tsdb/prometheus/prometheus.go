@@ -3,15 +3,13 @@ package prometheus
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"net/http"
 
-	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -24,18 +22,6 @@ type PrometheusExecutor struct {
 	Transport http.RoundTripper
 }
 
-type basicAuthTransport struct {
-	Transport http.RoundTripper
-
-	username string
-	password string
-}
-
-func (bat basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.SetBasicAuth(bat.username, bat.password)
-	return bat.Transport.RoundTrip(req)
-}
-
 func NewPrometheusExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
 	transport, err := dsInfo.GetHttpTransport()
 	if err != nil {
@@ -49,37 +35,22 @@ func NewPrometheusExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, e
 
 var (
 	plog               log.Logger
-	legendFormat       *regexp.Regexp
 	intervalCalculator tsdb.IntervalCalculator
 )
 
 func init() {
 	plog = log.New("tsdb.prometheus")
 	tsdb.RegisterTsdbQueryEndpoint("prometheus", NewPrometheusExecutor)
-	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
 	intervalCalculator = tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: time.Second * 1})
 }
 
-func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, error) {
+func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (api.Client, error) {
 	cfg := api.Config{
 		Address:      dsInfo.Url,
-		RoundTripper: e.Transport,
-	}
-
-	if dsInfo.BasicAuth {
-		cfg.RoundTripper = basicAuthTransport{
-			Transport: e.Transport,
-			username:  dsInfo.BasicAuthUser,
-			password:  dsInfo.DecryptedBasicAuthPassword(),
-		}
+		RoundTripper: tsdb.BasicAuthTransport(dsInfo, e.Transport),
 	}
 
-	client, err := api.NewClient(cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	return apiv1.NewAPI(client), nil
+	return api.NewClient(cfg)
 }
 
 func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
@@ -91,20 +62,18 @@ func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 	if err != nil {
 		return nil, err
 	}
+	promAPI := apiv1.NewAPI(client)
 
 	queries, err := parseQuery(dsInfo, tsdbQuery.Queries, tsdbQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, query := range queries {
-		timeRange := apiv1.Range{
-			Start: query.Start,
-			End:   query.End,
-			Step:  query.Step,
-		}
+	// alerting only evaluates the series
+	fromAlert := tsdbQuery.Headers["FromAlert"] == "true"
 
-		plog.Debug("Sending query", "start", timeRange.Start, "end", timeRange.End, "step", timeRange.Step, "query", query.Expr)
+	for _, query := range queries {
+		plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "instant", query.Instant, "query", query.Expr)
 
 		span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
 		span.SetTag("expr", query.Expr)
@@ -112,38 +81,53 @@ func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		value, _, err := client.QueryRange(ctx, query.Expr, timeRange)
+		var value model.Value
+		if query.Instant {
+			value, _, err = promAPI.Query(ctx, query.Expr, query.End)
+		} else {
+			value, _, err = promAPI.QueryRange(ctx, query.Expr, apiv1.Range{
+				Start: query.Start,
+				End:   query.End,
+				Step:  query.Step,
+			})
+		}
 
 		if err != nil {
 			return nil, err
 		}
 
-		queryResult, err := parseResponse(value, query)
+		frames, err := parseResponse(value, query)
 		if err != nil {
 			return nil, err
 		}
-		result.Results[query.RefId] = queryResult
-	}
 
-	return result, nil
-}
+		if !fromAlert {
+			addMetadata(ctx, client, dsInfo, frames)
+
+			if query.Exemplar {
+				exemplars, err := queryExemplars(ctx, client, dsInfo, query)
+				if err != nil {
+					plog.Warn("Failed to query exemplars", "query", query.Expr, "error", err)
+				} else if exemplars != nil {
+					frames = append(frames, exemplars)
+				}
+			}
+		}
 
-func formatLegend(metric model.Metric, query *PrometheusQuery) string {
-	if query.LegendFormat == "" {
-		return metric.String()
+		result.Results[query.RefId] = &tsdb.QueryResult{
+			RefId:      query.RefId,
+			Dataframes: tsdb.NewDecodedDataFrames(frames),
+		}
 	}
 
-	result := legendFormat.ReplaceAllFunc([]byte(query.LegendFormat), func(in []byte) []byte {
-		labelName := strings.Replace(string(in), "{{", "", 1)
-		labelName = strings.Replace(labelName, "}}", "", 1)
-		labelName = strings.TrimSpace(labelName)
-		if val, exists := metric[model.LabelName(labelName)]; exists {
-			return []byte(val)
-		}
-		return []byte{}
-	})
+	return result, nil
+}
 
-	return string(result)
+// IsIncrementalQuerySafe returns true for range queries, as Prometheus
+// evaluates them at each step independently of the start and end of the
+// range.
+func (e *PrometheusExecutor) IsIncrementalQuerySafe(query *tsdb.Query) bool {
+	return !query.Model.Get("instant").MustBool(false)
 }
 
 func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *tsdb.TsdbQuery) ([]*PrometheusQuery, error) {
@@ -182,37 +166,60 @@ func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *
 			Start:        start,
 			End:          end,
 			RefId:        queryModel.RefId,
+			Instant:      queryModel.Model.Get("instant").MustBool(false),
+			Exemplar:     queryModel.Model.Get("exemplar").MustBool(false),
 		})
 	}
 
 	return qs, nil
 }
 
-func parseResponse(value model.Value, query *PrometheusQuery) (*tsdb.QueryResult, error) {
-	queryRes := tsdb.NewQueryResult()
-
-	data, ok := value.(model.Matrix)
-	if !ok {
-		return queryRes, fmt.Errorf("Unsupported result format: %s", value.Type().String())
-	}
-
-	for _, v := range data {
-		series := tsdb.TimeSeries{
-			Name:   formatLegend(v.Metric, query),
-			Tags:   make(map[string]string, len(v.Metric)),
-			Points: make([]tsdb.TimePoint, 0, len(v.Values)),
+// parseResponse converts the result of a range or instant query to a frame
+// per series, with a time and a value field named after the series.
+func parseResponse(value model.Value, query *PrometheusQuery) (data.Frames, error) {
+	switch v := value.(type) {
+	case model.Matrix:
+		frames := make(data.Frames, 0, len(v))
+		for _, stream := range v {
+			times := make([]time.Time, 0, len(stream.Values))
+			values := make([]float64, 0, len(stream.Values))
+			for _, pair := range stream.Values {
+				times = append(times, pair.Timestamp.Time().UTC())
+				values = append(values, float64(pair.Value))
+			}
+			frames = append(frames, newSeriesFrame(stream.Metric, query, times, values))
 		}
+		return frames, nil
 
-		for k, v := range v.Metric {
-			series.Tags[string(k)] = string(v)
+	case model.Vector:
+		frames := make(data.Frames, 0, len(v))
+		for _, sample := range v {
+			frames = append(frames, newSeriesFrame(sample.Metric, query,
+				[]time.Time{sample.Timestamp.Time().UTC()}, []float64{float64(sample.Value)}))
 		}
+		return frames, nil
 
-		for _, k := range v.Values {
-			series.Points = append(series.Points, tsdb.NewTimePoint(null.FloatFrom(float64(k.Value)), float64(k.Timestamp.Unix()*1000)))
-		}
+	case *model.Scalar:
+		return data.Frames{newSeriesFrame(model.Metric{}, query,
+			[]time.Time{v.Timestamp.Time().UTC()}, []float64{float64(v.Value)})}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported result format: %s", value.Type().String())
+}
+
+func newSeriesFrame(metric model.Metric, query *PrometheusQuery, times []time.Time, values []float64) *data.Frame {
+	name := tsdb.FormatLegend(metric, query.LegendFormat)
+	if len(metric) == 0 && query.LegendFormat == "" {
+		name = query.Expr
+	}
 
-		queryRes.Series = append(queryRes.Series, &series)
+	labels := make(data.Labels, len(metric))
+	for k, v := range metric {
+		labels[string(k)] = string(v)
 	}
 
-	return queryRes, nil
+	return data.NewFrame(name,
+		data.NewField("Time", nil, times),
+		data.NewField(name, labels, values),
+	)
 }
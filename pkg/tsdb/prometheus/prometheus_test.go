@@ -1,13 +1,18 @@
 package prometheus
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	api "github.com/prometheus/client_golang/api"
 	p "github.com/prometheus/common/model"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -28,7 +33,7 @@ func TestPrometheus(t *testing.T) {
 				LegendFormat: "legend {{app}} {{ device }} {{broken}}",
 			}
 
-			So(formatLegend(metric, query), ShouldEqual, "legend backend mobile ")
+			So(tsdb.FormatLegend(metric, query.LegendFormat), ShouldEqual, "legend backend mobile ")
 		})
 
 		Convey("build full series name", func() {
@@ -42,7 +47,7 @@ func TestPrometheus(t *testing.T) {
 				LegendFormat: "",
 			}
 
-			So(formatLegend(metric, query), ShouldEqual, `http_request_total{app="backend", device="mobile"}`)
+			So(tsdb.FormatLegend(metric, query.LegendFormat), ShouldEqual, `http_request_total{app="backend", device="mobile"}`)
 		})
 
 		Convey("parsing query model with step", func() {
@@ -155,5 +160,154 @@ func TestPrometheus(t *testing.T) {
 				})
 			})
 		})
+
+		Convey("parsing instant and exemplar query model", func() {
+			jsonModel, _ := simplejson.NewJson([]byte(`{
+				"expr": "go_goroutines",
+				"instant": true,
+				"exemplar": true,
+				"refId": "A"
+			}`))
+			queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+
+			models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+			So(err, ShouldBeNil)
+			So(models[0].Instant, ShouldBeTrue)
+			So(models[0].Exemplar, ShouldBeTrue)
+
+			Convey("instant query is not incremental query safe", func() {
+				executor := &PrometheusExecutor{}
+				So(executor.IsIncrementalQuerySafe(&tsdb.Query{Model: jsonModel}), ShouldBeFalse)
+			})
+		})
+
+		Convey("parsing matrix response", func() {
+			value := p.Matrix{
+				&p.SampleStream{
+					Metric: p.Metric{"__name__": "up", "job": "api"},
+					Values: []p.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 2000, Value: 0}},
+				},
+			}
+
+			frames, err := parseResponse(value, &PrometheusQuery{LegendFormat: "{{job}}"})
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			So(frames[0].Name, ShouldEqual, "api")
+			So(frames[0].Rows(), ShouldEqual, 2)
+			So(frames[0].Fields[0].At(1), ShouldEqual, time.Unix(2, 0).UTC())
+			So(frames[0].Fields[1].Name, ShouldEqual, "api")
+			So(frames[0].Fields[1].Labels, ShouldResemble, data.Labels{"__name__": "up", "job": "api"})
+			So(frames[0].Fields[1].At(1), ShouldEqual, 0.0)
+
+			Convey("frames convert to series", func() {
+				series, err := tsdb.FrameToSeriesSlice(frames[0])
+				So(err, ShouldBeNil)
+				So(series[0].Name, ShouldEqual, "api")
+				So(series[0].Points[1][1].Float64, ShouldEqual, 2000)
+			})
+		})
+
+		Convey("parsing vector response", func() {
+			value := p.Vector{
+				&p.Sample{Metric: p.Metric{"job": "api"}, Timestamp: 3000, Value: 5},
+				&p.Sample{Metric: p.Metric{"job": "db"}, Timestamp: 3000, Value: 7},
+			}
+
+			frames, err := parseResponse(value, &PrometheusQuery{})
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 2)
+			So(frames[1].Rows(), ShouldEqual, 1)
+			So(frames[1].Fields[1].At(0), ShouldEqual, 7.0)
+		})
+
+		Convey("parsing scalar response", func() {
+			frames, err := parseResponse(&p.Scalar{Timestamp: 3000, Value: 2}, &PrometheusQuery{Expr: "1 + 1"})
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			So(frames[0].Name, ShouldEqual, "1 + 1")
+		})
+
+		Convey("creating exemplar frame", func() {
+			result := []exemplarSeries{
+				{
+					SeriesLabels: map[string]string{"job": "api"},
+					Exemplars: []exemplar{
+						{Labels: map[string]string{"trace_id": "abc"}, Value: "0.5", Timestamp: 1600000000.5},
+						{Labels: map[string]string{"trace_id": "def"}, Value: "1.5", Timestamp: 1600000001},
+					},
+				},
+			}
+			destinations := []exemplarTraceIDDestination{{Name: "trace_id", URL: "http://jaeger/trace/${__value.raw}"}}
+
+			frame := newExemplarFrame(result, destinations)
+			So(frame.Name, ShouldEqual, exemplarFrameName)
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Fields, ShouldHaveLength, 4)
+			So(frame.Fields[0].At(0), ShouldEqual, time.Unix(1600000000, 500000000).UTC())
+			So(frame.Fields[1].At(1), ShouldEqual, 1.5)
+			So(frame.Fields[2].Name, ShouldEqual, "job")
+			So(frame.Fields[2].At(1), ShouldEqual, "api")
+			So(frame.Fields[3].Name, ShouldEqual, "trace_id")
+			So(frame.Fields[3].At(0), ShouldEqual, "abc")
+			So(frame.Fields[3].Config.Links[0].URL, ShouldEqual, "http://jaeger/trace/${__value.raw}")
+
+			Convey("without exemplars returns no frame", func() {
+				So(newExemplarFrame([]exemplarSeries{{SeriesLabels: map[string]string{"job": "api"}}}, destinations), ShouldBeNil)
+			})
+		})
+
+		Convey("querying metadata and exemplars", func() {
+			supported := true
+			var requestedMetric, requestedQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !supported {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				switch r.URL.Path {
+				case "/api/v1/metadata":
+					requestedMetric = r.URL.Query().Get("metric")
+					_, _ = w.Write([]byte(`{"status":"success","data":{"up":[{"type":"gauge","help":"Target is up.","unit":""}]}}`))
+				case "/api/v1/query_exemplars":
+					requestedQuery = r.URL.Query().Get("query")
+					_, _ = w.Write([]byte(`{"status":"success","data":[{"seriesLabels":{"job":"api"},"exemplars":[{"labels":{"trace_id":"abc"},"value":"1","timestamp":1600000000}]}]}`))
+				}
+			}))
+			defer server.Close()
+
+			client, err := api.NewClient(api.Config{Address: server.URL})
+			So(err, ShouldBeNil)
+			metadataCache.Flush()
+
+			ds := &models.DataSource{Id: 5, JsonData: simplejson.New()}
+			query := &PrometheusQuery{Expr: "up", Start: time.Unix(1600000000, 0), End: time.Unix(1600003600, 0)}
+			frames, err := parseResponse(p.Vector{&p.Sample{Metric: p.Metric{"__name__": "up"}, Timestamp: 1000, Value: 1}}, query)
+			So(err, ShouldBeNil)
+
+			Convey("adds metric metadata to frame meta", func() {
+				addMetadata(context.Background(), client, ds, frames)
+				So(requestedMetric, ShouldEqual, "up")
+				So(frames[0].Meta, ShouldNotBeNil)
+				So(frames[0].Meta.Custom, ShouldResemble, &metricMetadata{Type: "gauge", Help: "Target is up."})
+			})
+
+			Convey("returns exemplar frame", func() {
+				frame, err := queryExemplars(context.Background(), client, ds, query)
+				So(err, ShouldBeNil)
+				So(requestedQuery, ShouldEqual, "up")
+				So(frame.Rows(), ShouldEqual, 1)
+			})
+
+			Convey("ignores servers without metadata and exemplar API", func() {
+				supported = false
+				addMetadata(context.Background(), client, ds, frames)
+				So(frames[0].Meta, ShouldBeNil)
+
+				frame, err := queryExemplars(context.Background(), client, ds, query)
+				So(err, ShouldBeNil)
+				So(frame, ShouldBeNil)
+			})
+		})
 	})
 }
@@ -9,4 +9,10 @@ type PrometheusQuery struct {
 	Start        time.Time
 	End          time.Time
 	RefId        string
+
+	// Instant queries are evaluated at End only.
+	Instant bool
+
+	// Exemplar queries also return the exemplars of the series.
+	Exemplar bool
 }
@@ -0,0 +1,26 @@
+package tsdb
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// QueryCache caches the responses of HandleRequest.
+type QueryCache interface {
+	// Get returns the cached response of the request, if any.
+	Get(ctx context.Context, dsInfo *models.DataSource, req *TsdbQuery) (*Response, bool)
+
+	// Set caches the response of the request. Responses that should not be
+	// cached, such as responses with errors, are ignored.
+	Set(ctx context.Context, dsInfo *models.DataSource, req *TsdbQuery, resp *Response)
+}
+
+// queryCache is nil unless the query cache is enabled.
+var queryCache QueryCache
+
+// SetQueryCache sets the cache used by HandleRequest. Passing nil disables
+// caching.
+func SetQueryCache(cache QueryCache) {
+	queryCache = cache
+}
@@ -14,5 +14,30 @@ func HandleRequest(ctx context.Context, dsInfo *models.DataSource, req *TsdbQuer
 		return nil, err
 	}
 
-	return endpoint.Query(ctx, dsInfo, req)
+	if isIncrementalQuery(endpoint, dsInfo, req) {
+		return incrementalQuery(ctx, endpoint, dsInfo, req)
+	}
+
+	return query(ctx, endpoint, dsInfo, req)
+}
+
+// query runs the request against the endpoint, unless its response is in
+// the query cache.
+func query(ctx context.Context, endpoint TsdbQueryEndpoint, dsInfo *models.DataSource, req *TsdbQuery) (*Response, error) {
+	cache := queryCache
+	if cache == nil || req.SkipCache {
+		return endpoint.Query(ctx, dsInfo, req)
+	}
+
+	if resp, ok := cache.Get(ctx, dsInfo, req); ok {
+		return resp, nil
+	}
+
+	resp, err := endpoint.Query(ctx, dsInfo, req)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Set(ctx, dsInfo, req, resp)
+	return resp, nil
 }
@@ -0,0 +1,330 @@
+package sqleng
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// builderQueryMode is the query mode of queries which are built from the
+// structured model of the query builder instead of raw sql.
+const builderQueryMode = "builder"
+
+// SqlQueryDialect has the syntax of a database that the query builder needs
+// to compile a structured query.
+type SqlQueryDialect interface {
+	// QuoteIdentifier quotes a table or column name, whose parts have been
+	// checked to only contain letters, digits and underscores.
+	QuoteIdentifier(name string) string
+	// Placeholder returns the bind parameter of the nth argument of a query,
+	// starting at 1.
+	Placeholder(n int) string
+	// Limit limits the number of rows of a select statement.
+	Limit(sql string, limit int64) string
+}
+
+// builderQuery is the structured model of a query of the query builder.
+// Tables and columns are identifiers and filter values are bound as
+// arguments of the query, so template variables in them cannot change the
+// statement.
+type builderQuery struct {
+	Table        string          `json:"table"`
+	TimeColumn   string          `json:"timeColumn"`
+	MetricColumn string          `json:"metricColumn"`
+	Columns      []builderColumn `json:"columns"`
+	Where        []builderFilter `json:"where"`
+	GroupBy      []string        `json:"groupBy"`
+	Interval     string          `json:"interval"`
+	Fill         string          `json:"fill"`
+	Limit        int64           `json:"limit"`
+}
+
+type builderColumn struct {
+	Column      string `json:"column"`
+	Aggregation string `json:"aggregation"`
+	Alias       string `json:"alias"`
+}
+
+type builderFilter struct {
+	Column   string      `json:"column"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+}
+
+var (
+	identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+	aliasRegex      = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_ ]*$`)
+	intervalRegex   = regexp.MustCompile(`^(\$__interval|[0-9]+(ms|s|m|h|d|w|y))$`)
+	fillRegex       = regexp.MustCompile(`^(NULL|previous|-?[0-9]+(\.[0-9]+)?)$`)
+
+	builderAggregations = map[string]bool{
+		"avg":   true,
+		"count": true,
+		"max":   true,
+		"min":   true,
+		"sum":   true,
+	}
+)
+
+// isBuilderQuery returns true if the query is built by the query builder.
+func isBuilderQuery(query *tsdb.Query) bool {
+	return query.Model.Get("queryMode").MustString() == builderQueryMode
+}
+
+func parseBuilderQuery(model *simplejson.Json) (*builderQuery, error) {
+	encoded, err := model.Get("builder").Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &builderQuery{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(q); err != nil {
+		return nil, fmt.Errorf("invalid query builder model: %w", err)
+	}
+
+	return q, nil
+}
+
+// BuildQuery compiles the structured model of a query to sql of the dialect
+// and the arguments of its bind parameters. Time series queries group rows
+// by the time column with the $__timeGroup macros and filter them with the
+// $__timeFilter macro, which the macro engine of the data source expands.
+func BuildQuery(dialect SqlQueryDialect, query *tsdb.Query) (string, []interface{}, error) {
+	q, err := parseBuilderQuery(query.Model)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b := &sqlBuilder{dialect: dialect}
+	timeSeries := query.Model.Get("format").MustString("time_series") == "time_series"
+	return b.build(q, timeSeries)
+}
+
+type sqlBuilder struct {
+	dialect SqlQueryDialect
+	args    []interface{}
+}
+
+func (b *sqlBuilder) build(q *builderQuery, timeSeries bool) (string, []interface{}, error) {
+	if q.Table == "" {
+		return "", nil, fmt.Errorf("query builder needs a table")
+	}
+	table, err := b.identifier(q.Table)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if timeSeries && q.TimeColumn == "" {
+		return "", nil, fmt.Errorf("query builder needs a time column for time series")
+	}
+
+	aggregated := false
+	for _, c := range q.Columns {
+		if c.Aggregation != "" {
+			aggregated = true
+		}
+	}
+
+	selects := make([]string, 0, len(q.Columns)+2)
+	groups := make([]string, 0, len(q.GroupBy)+2)
+	filters := make([]string, 0, len(q.Where)+1)
+	// grouping by position is not supported by all databases, and the
+	// postgres macro engine aliases a $__timeGroup followed by a comma
+	timeGroup := ""
+
+	if q.TimeColumn != "" {
+		timeColumn, err := b.identifier(q.TimeColumn)
+		if err != nil {
+			return "", nil, err
+		}
+		filters = append(filters, fmt.Sprintf("$__timeFilter(%s)", timeColumn))
+
+		if timeSeries {
+			if aggregated {
+				if timeGroup, err = b.timeGroup(timeColumn, q); err != nil {
+					return "", nil, err
+				}
+				selects = append(selects, fmt.Sprintf("$__timeGroupAlias(%s)", timeGroup))
+			} else {
+				selects = append(selects, fmt.Sprintf("%s AS %s", timeColumn, b.dialect.QuoteIdentifier("time")))
+			}
+		}
+	}
+
+	if timeSeries && q.MetricColumn != "" {
+		metricColumn, err := b.identifier(q.MetricColumn)
+		if err != nil {
+			return "", nil, err
+		}
+		selects = append(selects, fmt.Sprintf("%s AS %s", metricColumn, b.dialect.QuoteIdentifier("metric")))
+		if aggregated {
+			groups = append(groups, metricColumn)
+		}
+	}
+
+	if len(q.Columns) == 0 {
+		return "", nil, fmt.Errorf("query builder needs at least one column")
+	}
+	for _, c := range q.Columns {
+		column, err := b.column(c)
+		if err != nil {
+			return "", nil, err
+		}
+		selects = append(selects, column)
+	}
+
+	for _, f := range q.Where {
+		filter, err := b.filter(f)
+		if err != nil {
+			return "", nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	for _, g := range q.GroupBy {
+		group, err := b.identifier(g)
+		if err != nil {
+			return "", nil, err
+		}
+		groups = append(groups, group)
+	}
+	if timeGroup != "" {
+		groups = append(groups, fmt.Sprintf("$__timeGroup(%s)", timeGroup))
+	}
+
+	sql := "SELECT " + strings.Join(selects, ", ") + " FROM " + table
+	if len(filters) > 0 {
+		sql += " WHERE " + strings.Join(filters, " AND ")
+	}
+	if len(groups) > 0 {
+		sql += " GROUP BY " + strings.Join(groups, ", ")
+	}
+	if timeSeries {
+		sql += " ORDER BY 1"
+	}
+	if q.Limit > 0 {
+		sql = b.dialect.Limit(sql, q.Limit)
+	}
+
+	return sql, b.args, nil
+}
+
+// identifier quotes a table or column name after checking that it is a
+// plain, optionally qualified, name.
+func (b *sqlBuilder) identifier(name string) (string, error) {
+	if !identifierRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid identifier %q", name)
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = b.dialect.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// timeGroup returns the arguments of the time group macros.
+func (b *sqlBuilder) timeGroup(timeColumn string, q *builderQuery) (string, error) {
+	interval := q.Interval
+	if interval == "" {
+		interval = "$__interval"
+	}
+	if !intervalRegex.MatchString(interval) {
+		return "", fmt.Errorf("invalid interval %q", interval)
+	}
+
+	if q.Fill == "" {
+		return fmt.Sprintf("%s, %s", timeColumn, interval), nil
+	}
+	if !fillRegex.MatchString(q.Fill) {
+		return "", fmt.Errorf("invalid fill %q", q.Fill)
+	}
+	return fmt.Sprintf("%s, %s, %s", timeColumn, interval, q.Fill), nil
+}
+
+func (b *sqlBuilder) column(c builderColumn) (string, error) {
+	var column string
+	var err error
+
+	if c.Column == "*" && c.Aggregation == "count" {
+		column = "*"
+	} else if column, err = b.identifier(c.Column); err != nil {
+		return "", err
+	}
+
+	if c.Aggregation != "" {
+		if !builderAggregations[c.Aggregation] {
+			return "", fmt.Errorf("invalid aggregation %q", c.Aggregation)
+		}
+		column = fmt.Sprintf("%s(%s)", c.Aggregation, column)
+	}
+
+	// aggregations are named after their column, like the column itself
+	alias := c.Alias
+	if alias == "" && c.Aggregation != "" && c.Column != "*" {
+		alias = c.Column[strings.LastIndex(c.Column, ".")+1:]
+	}
+	if alias == "" {
+		return column, nil
+	}
+	if !aliasRegex.MatchString(alias) {
+		return "", fmt.Errorf("invalid alias %q", alias)
+	}
+	return fmt.Sprintf("%s AS %s", column, b.dialect.QuoteIdentifier(alias)), nil
+}
+
+func (b *sqlBuilder) filter(f builderFilter) (string, error) {
+	column, err := b.identifier(f.Column)
+	if err != nil {
+		return "", err
+	}
+
+	operator := strings.ToUpper(strings.TrimSpace(f.Operator))
+	switch operator {
+	case "=", "!=", "<>", "<", "<=", ">", ">=", "LIKE", "NOT LIKE":
+		if _, ok := f.Value.([]interface{}); ok || f.Value == nil {
+			return "", fmt.Errorf("filter on %s needs a single value", f.Column)
+		}
+		return fmt.Sprintf("%s %s %s", column, operator, b.bind(f.Value)), nil
+
+	case "IN", "NOT IN":
+		values, ok := f.Value.([]interface{})
+		if !ok {
+			values = []interface{}{f.Value}
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("filter on %s needs at least one value", f.Column)
+		}
+		placeholders := make([]string, 0, len(values))
+		for _, v := range values {
+			placeholders = append(placeholders, b.bind(v))
+		}
+		return fmt.Sprintf("%s %s (%s)", column, operator, strings.Join(placeholders, ", ")), nil
+
+	case "IS NULL", "IS NOT NULL":
+		return fmt.Sprintf("%s %s", column, operator), nil
+	}
+
+	return "", fmt.Errorf("invalid operator %q", f.Operator)
+}
+
+// bind adds a value to the arguments of the query and returns its bind
+// parameter.
+func (b *sqlBuilder) bind(value interface{}) string {
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			value = i
+		} else if f, err := number.Float64(); err == nil {
+			value = f
+		}
+	}
+	b.args = append(b.args, value)
+	return b.dialect.Placeholder(len(b.args))
+}
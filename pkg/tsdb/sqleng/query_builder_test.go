@@ -0,0 +1,127 @@
+package sqleng
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testQueryDialect struct{}
+
+func (d testQueryDialect) QuoteIdentifier(name string) string {
+	return `"` + name + `"`
+}
+
+func (d testQueryDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d testQueryDialect) Limit(sql string, limit int64) string {
+	return fmt.Sprintf("%s LIMIT %d", sql, limit)
+}
+
+func TestQueryBuilder(t *testing.T) {
+	Convey("Query builder", t, func() {
+		query := func(format string, builder map[string]interface{}) *tsdb.Query {
+			encoded, err := json.Marshal(map[string]interface{}{
+				"format":    format,
+				"queryMode": "builder",
+				"builder":   builder,
+			})
+			So(err, ShouldBeNil)
+			model, err := simplejson.NewJson(encoded)
+			So(err, ShouldBeNil)
+			return &tsdb.Query{Model: model}
+		}
+
+		Convey("builds aggregated time series query", func() {
+			sql, args, err := BuildQuery(testQueryDialect{}, query("time_series", map[string]interface{}{
+				"table":        "public.metrics",
+				"timeColumn":   "time",
+				"metricColumn": "host",
+				"columns":      []interface{}{map[string]interface{}{"column": "value", "aggregation": "avg"}},
+				"where": []interface{}{
+					map[string]interface{}{"column": "region", "operator": "=", "value": "eu' OR 1=1 --"},
+					map[string]interface{}{"column": "code", "operator": "in", "value": []interface{}{200, 500}},
+				},
+				"fill": "previous",
+			}))
+			So(err, ShouldBeNil)
+			So(sql, ShouldEqual, `SELECT $__timeGroupAlias("time", $__interval, previous), "host" AS "metric", avg("value") AS "value" `+
+				`FROM "public"."metrics" WHERE $__timeFilter("time") AND "region" = $1 AND "code" IN ($2, $3) GROUP BY "host", $__timeGroup("time", $__interval, previous) ORDER BY 1`)
+			So(args, ShouldResemble, []interface{}{"eu' OR 1=1 --", int64(200), int64(500)})
+		})
+
+		Convey("builds raw time series query", func() {
+			sql, args, err := BuildQuery(testQueryDialect{}, query("time_series", map[string]interface{}{
+				"table":      "metrics",
+				"timeColumn": "time",
+				"columns":    []interface{}{map[string]interface{}{"column": "value"}},
+				"where":      []interface{}{map[string]interface{}{"column": "value", "operator": "IS NOT NULL"}},
+			}))
+			So(err, ShouldBeNil)
+			So(sql, ShouldEqual, `SELECT "time" AS "time", "value" FROM "metrics" WHERE $__timeFilter("time") AND "value" IS NOT NULL ORDER BY 1`)
+			So(args, ShouldBeEmpty)
+		})
+
+		Convey("builds table query", func() {
+			sql, _, err := BuildQuery(testQueryDialect{}, query("table", map[string]interface{}{
+				"table": "metrics",
+				"columns": []interface{}{
+					map[string]interface{}{"column": "host"},
+					map[string]interface{}{"column": "*", "aggregation": "count", "alias": "requests"},
+				},
+				"groupBy": []interface{}{"host"},
+				"limit":   10,
+			}))
+			So(err, ShouldBeNil)
+			So(sql, ShouldEqual, `SELECT "host", count(*) AS "requests" FROM "metrics" GROUP BY "host" LIMIT 10`)
+		})
+
+		Convey("rejects", func() {
+			tcs := map[string]map[string]interface{}{
+				"invalid table":       {"table": "metrics; DROP TABLE users", "columns": []interface{}{map[string]interface{}{"column": "value"}}},
+				"invalid column":      {"table": "metrics", "columns": []interface{}{map[string]interface{}{"column": "value)"}}},
+				"invalid aggregation": {"table": "metrics", "columns": []interface{}{map[string]interface{}{"column": "value", "aggregation": "pg_sleep"}}},
+				"invalid operator": {"table": "metrics", "columns": []interface{}{map[string]interface{}{"column": "value"}},
+					"where": []interface{}{map[string]interface{}{"column": "host", "operator": "= 1 OR", "value": "a"}}},
+				"missing columns": {"table": "metrics"},
+			}
+
+			for name, builder := range tcs {
+				builder := builder
+				Convey(name, func() {
+					_, _, err := BuildQuery(testQueryDialect{}, query("table", builder))
+					So(err, ShouldNotBeNil)
+				})
+			}
+
+			_, _, err := BuildQuery(testQueryDialect{}, query("time_series", map[string]interface{}{
+				"table":      "metrics",
+				"timeColumn": "time",
+				"interval":   "1m) --",
+				"columns":    []interface{}{map[string]interface{}{"column": "value", "aggregation": "avg"}},
+			}))
+			So(err, ShouldNotBeNil)
+
+			_, _, err = BuildQuery(testQueryDialect{}, query("time_series", map[string]interface{}{
+				"table":   "metrics",
+				"columns": []interface{}{map[string]interface{}{"column": "value"}},
+			}))
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("time series query without previous fill is safe for incremental querying", func() {
+			endpoint := &sqlQueryEndpoint{}
+			q := query("time_series", map[string]interface{}{"table": "metrics", "timeColumn": "time"})
+			So(endpoint.IsIncrementalQuerySafe(q), ShouldBeTrue)
+
+			q.Model.SetPath([]string{"builder", "fill"}, "previous")
+			So(endpoint.IsIncrementalQuerySafe(q), ShouldBeFalse)
+		})
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"container/list"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"regexp"
@@ -12,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -65,10 +67,14 @@ const timeEndColumnName = "timeend"
 
 type sqlQueryEndpoint struct {
 	macroEngine            SqlMacroEngine
+	queryDialect           SqlQueryDialect
 	queryResultTransformer SqlQueryResultTransformer
 	engine                 *xorm.Engine
 	timeColumnNames        []string
 	metricColumnTypes      []string
+	rowLimit               int64
+	byteLimit              int64
+	queryTimeout           time.Duration
 	log                    log.Logger
 }
 
@@ -78,12 +84,16 @@ type SqlQueryEndpointConfiguration struct {
 	ConnectionString  string
 	TimeColumnNames   []string
 	MetricColumnTypes []string
+	// QueryDialect compiles the queries of the query builder, which are not
+	// supported without it.
+	QueryDialect SqlQueryDialect
 }
 
 var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResultTransformer SqlQueryResultTransformer, macroEngine SqlMacroEngine, log log.Logger) (tsdb.TsdbQueryEndpoint, error) {
 	queryEndpoint := sqlQueryEndpoint{
 		queryResultTransformer: queryResultTransformer,
 		macroEngine:            macroEngine,
+		queryDialect:           config.QueryDialect,
 		timeColumnNames:        []string{"time"},
 		log:                    log,
 	}
@@ -96,6 +106,8 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 		queryEndpoint.metricColumnTypes = config.MetricColumnTypes
 	}
 
+	queryEndpoint.setLimits(config.Datasource.JsonData)
+
 	engineCache.Lock()
 	defer engineCache.Unlock()
 
@@ -125,7 +137,29 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 	return &queryEndpoint, nil
 }
 
-const rowLimit = 1000000
+// setLimits sets the limits of the rows, size and duration of queries from
+// the settings of the data source. The query timeout is in seconds and
+// queries have no timeout unless it is set.
+func (e *sqlQueryEndpoint) setLimits(jsonData *simplejson.Json) {
+	if jsonData == nil {
+		jsonData = simplejson.New()
+	}
+
+	e.rowLimit = jsonData.Get("rowLimit").MustInt64(0)
+	if e.rowLimit <= 0 {
+		e.rowLimit = defaultRowLimit
+	}
+
+	e.byteLimit = jsonData.Get("byteLimit").MustInt64(0)
+	if e.byteLimit <= 0 {
+		e.byteLimit = defaultByteLimit
+	}
+
+	e.queryTimeout = 0
+	if queryTimeout := jsonData.Get("queryTimeout").MustInt(0); queryTimeout > 0 {
+		e.queryTimeout = time.Duration(queryTimeout) * time.Second
+	}
+}
 
 // Query is the main function for the SqlQueryEndpoint
 func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
@@ -137,13 +171,29 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 
 	for _, query := range tsdbQuery.Queries {
 		rawSQL := query.Model.Get("rawSql").MustString()
-		if rawSQL == "" {
+		if rawSQL == "" && !isBuilderQuery(query) {
 			continue
 		}
 
 		queryResult := &tsdb.QueryResult{Meta: simplejson.New(), RefId: query.RefId}
 		result.Results[query.RefId] = queryResult
 
+		// the values of filters of the query builder are bound as arguments
+		var args []interface{}
+		if isBuilderQuery(query) {
+			if e.queryDialect == nil {
+				queryResult.Error = fmt.Errorf("query builder is not supported by this data source")
+				continue
+			}
+
+			var err error
+			rawSQL, args, err = BuildQuery(e.queryDialect, query)
+			if err != nil {
+				queryResult.Error = err
+				continue
+			}
+		}
+
 		// global substitutions
 		rawSQL, err := Interpolate(query, tsdbQuery.TimeRange, rawSQL)
 		if err != nil {
@@ -162,15 +212,23 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 
 		wg.Add(1)
 
-		go func(rawSQL string, query *tsdb.Query, queryResult *tsdb.QueryResult) {
+		go func(rawSQL string, args []interface{}, query *tsdb.Query, queryResult *tsdb.QueryResult) {
 			defer wg.Done()
 			session := e.engine.NewSession()
 			defer session.Close()
 			db := session.DB()
 
-			rows, err := db.Query(rawSQL)
+			// the driver cancels the query when the timeout expires
+			queryCtx := ctx
+			if e.queryTimeout > 0 {
+				var cancel context.CancelFunc
+				queryCtx, cancel = context.WithTimeout(ctx, e.queryTimeout)
+				defer cancel()
+			}
+
+			rows, err := db.QueryContext(queryCtx, rawSQL, args...)
 			if err != nil {
-				queryResult.Error = e.queryResultTransformer.TransformQueryError(err)
+				queryResult.Error = e.queryError(queryCtx, e.queryResultTransformer.TransformQueryError(err))
 				return
 			}
 
@@ -182,23 +240,56 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 			case "time_series":
 				err := e.transformToTimeSeries(query, rows, queryResult, tsdbQuery)
 				if err != nil {
-					queryResult.Error = err
+					queryResult.Error = e.queryError(queryCtx, err)
 					return
 				}
 			case "table":
 				err := e.transformToTable(query, rows, queryResult, tsdbQuery)
 				if err != nil {
-					queryResult.Error = err
+					queryResult.Error = e.queryError(queryCtx, err)
 					return
 				}
 			}
-		}(rawSQL, query, queryResult)
+		}(rawSQL, args, query, queryResult)
 	}
 	wg.Wait()
 
 	return result, nil
 }
 
+// queryError returns a timeout error instead of the error of a query which
+// was cancelled by its timeout.
+func (e *sqlQueryEndpoint) queryError(queryCtx context.Context, err error) error {
+	if errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("query timed out after %s", e.queryTimeout)
+	}
+	return err
+}
+
+var (
+	timeFilterMacroRegex   = regexp.MustCompile(`\$__(timeFilter|unixEpochFilter|unixEpochNanoFilter|timeFrom|unixEpochFrom|unixEpochNanoFrom)\b`)
+	fillPreviousMacroRegex = regexp.MustCompile(`\$__(timeGroup|timeGroupAlias|unixEpochGroup|unixEpochGroupAlias)\([^)]*previous`)
+)
+
+// IsIncrementalQuerySafe returns true for time series queries that filter
+// rows by the time range and do not fill missing points with the previous
+// value, which the first point of the tail of an incremental query does not
+// have.
+func (e *sqlQueryEndpoint) IsIncrementalQuerySafe(query *tsdb.Query) bool {
+	if query.Model.Get("format").MustString("time_series") != "time_series" {
+		return false
+	}
+
+	// the query builder always filters time series by the time range
+	if isBuilderQuery(query) {
+		q, err := parseBuilderQuery(query.Model)
+		return err == nil && q.Fill != "previous"
+	}
+
+	rawSQL := query.Model.Get("rawSql").MustString()
+	return timeFilterMacroRegex.MatchString(rawSQL) && !fillPreviousMacroRegex.MatchString(rawSQL)
+}
+
 // global macros/substitutions for all sql datasources
 var Interpolate = func(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string) (string, error) {
 	minInterval, err := tsdb.GetIntervalFrom(query.DataSource, query.Model, time.Second*60)
@@ -215,26 +306,18 @@ var Interpolate = func(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string)
 	return sql, nil
 }
 
+// transformToTable converts the rows of a table query to a data frame as
+// they are read, until the result reaches a limit of the data source.
 func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult, tsdbQuery *tsdb.TsdbQuery) error {
 	columnNames, err := rows.Columns()
-	columnCount := len(columnNames)
-
 	if err != nil {
 		return err
 	}
 
-	rowCount := 0
 	timeIndex := -1
 	timeEndIndex := -1
 
-	table := &tsdb.Table{
-		Columns: make([]tsdb.TableColumn, columnCount),
-		Rows:    make([]tsdb.RowValues, 0),
-	}
-
 	for i, name := range columnNames {
-		table.Columns[i].Text = name
-
 		for _, tc := range e.timeColumnNames {
 			if name == tc {
 				timeIndex = i
@@ -253,29 +336,48 @@ func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows,
 		return err
 	}
 
-	for ; rows.Next(); rowCount++ {
-		if rowCount > rowLimit {
-			return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
-		}
+	limiter := newResultLimiter(e.rowLimit, e.byteLimit)
+	builder := newTableFrameBuilder(columnNames)
 
+	for rows.Next() {
 		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
 		if err != nil {
 			return err
 		}
 
+		if !limiter.add(values) {
+			break
+		}
+
 		// converts column named time and timeend to unix timestamp in milliseconds
 		// to make native mssql datetime types and epoch dates work in
 		// annotation and table queries.
 		ConvertSqlTimeColumnToEpochMs(values, timeIndex)
 		ConvertSqlTimeColumnToEpochMs(values, timeEndIndex)
-		table.Rows = append(table.Rows, values)
+
+		if err := builder.append(values); err != nil {
+			return err
+		}
 	}
 
-	result.Tables = append(result.Tables, table)
-	result.Meta.Set("rowCount", rowCount)
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	frame := builder.frame(query.RefId)
+	if limiter.truncated() {
+		frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: limiter.notice()})
+	}
+
+	result.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{frame})
+	result.Meta.Set("rowCount", limiter.rows)
+	limiter.setMeta(result.Meta)
 	return nil
 }
 
+// transformToTimeSeries converts the rows of a time series query to time
+// series until the result reaches a limit of the data source. Unlike table
+// queries, the result is not a data frame.
 func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult, tsdbQuery *tsdb.TsdbQuery) error {
 	pointsBySeries := make(map[string]*tsdb.TimeSeries)
 	seriesByQueryOrder := list.New()
@@ -348,20 +450,22 @@ func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.R
 		}
 	}
 
+	limiter := newResultLimiter(e.rowLimit, e.byteLimit)
+
 	for rows.Next() {
 		var timestamp float64
 		var value null.Float
 		var metric string
 
-		if rowCount > rowLimit {
-			return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
-		}
-
 		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
 		if err != nil {
 			return err
 		}
 
+		if !limiter.add(values) {
+			break
+		}
+
 		// converts column named time to unix timestamp in milliseconds to make
 		// native mysql datetime types and epoch dates work in
 		// annotation and table queries.
@@ -443,6 +547,10 @@ func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.R
 		}
 	}
 
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
 	for elem := seriesByQueryOrder.Front(); elem != nil; elem = elem.Next() {
 		key := elem.Value.(string)
 		result.Series = append(result.Series, pointsBySeries[key])
@@ -471,6 +579,7 @@ func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.R
 	}
 
 	result.Meta.Set("rowCount", rowCount)
+	limiter.setMeta(result.Meta)
 	return nil
 }
 
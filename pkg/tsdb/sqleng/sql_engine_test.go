@@ -1,14 +1,20 @@
 package sqleng
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
+	_ "github.com/mattn/go-sqlite3"
+	"xorm.io/core"
+	"xorm.io/xorm"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -331,5 +337,143 @@ func TestSqlEngine(t *testing.T) {
 				}
 			})
 		})
+
+		Convey("Given queries checked for incremental querying", func() {
+			endpoint := &sqlQueryEndpoint{}
+			query := func(format, rawSQL string) *tsdb.Query {
+				return &tsdb.Query{Model: simplejson.NewFromAny(map[string]interface{}{"format": format, "rawSql": rawSQL})}
+			}
+
+			Convey("time series query filtered by time range is safe", func() {
+				So(endpoint.IsIncrementalQuerySafe(query("time_series", "SELECT $__timeGroup(time, '1m'), avg(value) FROM metric WHERE $__timeFilter(time) GROUP BY 1")), ShouldBeTrue)
+			})
+
+			Convey("table query is not safe", func() {
+				So(endpoint.IsIncrementalQuerySafe(query("table", "SELECT * FROM metric WHERE $__timeFilter(time)")), ShouldBeFalse)
+			})
+
+			Convey("query without time filter is not safe", func() {
+				So(endpoint.IsIncrementalQuerySafe(query("time_series", "SELECT time, value FROM metric")), ShouldBeFalse)
+			})
+
+			Convey("query filling with previous value is not safe", func() {
+				So(endpoint.IsIncrementalQuerySafe(query("time_series", "SELECT $__timeGroup(time, '1m', previous), avg(value) FROM metric WHERE $__timeFilter(time) GROUP BY 1")), ShouldBeFalse)
+			})
+		})
+
+		Convey("Given an endpoint with limits", func() {
+			engine, err := xorm.NewEngine("sqlite3", ":memory:")
+			So(err, ShouldBeNil)
+			// every connection has its own in-memory database
+			engine.SetMaxOpenConns(1)
+			defer engine.Close()
+
+			_, err = engine.Exec("CREATE TABLE metric (time INTEGER, name TEXT, value REAL)")
+			So(err, ShouldBeNil)
+			_, err = engine.Exec("INSERT INTO metric VALUES (1521062406, 'a', 1.5), (1521062466, 'a', 2.5), (1521062526, 'b', NULL)")
+			So(err, ShouldBeNil)
+
+			jsonData := simplejson.New()
+			jsonData.Set("rowLimit", 2)
+			endpoint := &sqlQueryEndpoint{
+				macroEngine:            &testMacroEngine{},
+				queryResultTransformer: &testQueryResultTransformer{},
+				engine:                 engine,
+				timeColumnNames:        []string{"time"},
+				log:                    log.New("tsdb.sqleng.test"),
+			}
+			endpoint.setLimits(jsonData)
+			So(endpoint.rowLimit, ShouldEqual, 2)
+			So(endpoint.byteLimit, ShouldEqual, defaultByteLimit)
+			So(endpoint.queryTimeout, ShouldEqual, 0)
+
+			query := func(format, rawSQL string) *tsdb.TsdbQuery {
+				return &tsdb.TsdbQuery{
+					TimeRange: tsdb.NewTimeRange("1521062400000", "1521066000000"),
+					Queries: []*tsdb.Query{{
+						RefId:      "A",
+						DataSource: &models.DataSource{JsonData: jsonData},
+						Model:      simplejson.NewFromAny(map[string]interface{}{"format": format, "rawSql": rawSQL}),
+					}},
+				}
+			}
+
+			Convey("table query within the limits returns a data frame", func() {
+				resp, err := endpoint.Query(context.Background(), nil, query("table", "SELECT time, name, value FROM metric WHERE name = 'a'"))
+				So(err, ShouldBeNil)
+				queryResult := resp.Results["A"]
+				So(queryResult.Error, ShouldBeNil)
+				So(queryResult.Meta.Get("rowCount").MustInt(), ShouldEqual, 2)
+				So(queryResult.Meta.Get("truncated").Interface(), ShouldBeNil)
+
+				frames, err := queryResult.Dataframes.Decoded()
+				So(err, ShouldBeNil)
+				So(frames, ShouldHaveLength, 1)
+				So(frames[0].Rows(), ShouldEqual, 2)
+				So(*frames[0].Fields[0].At(0).(*int64), ShouldEqual, 1521062406000)
+				So(*frames[0].Fields[1].At(1).(*string), ShouldEqual, "a")
+				So(*frames[0].Fields[2].At(1).(*float64), ShouldEqual, 2.5)
+			})
+
+			Convey("table query over the row limit is truncated", func() {
+				resp, err := endpoint.Query(context.Background(), nil, query("table", "SELECT time, name, value FROM metric"))
+				So(err, ShouldBeNil)
+				queryResult := resp.Results["A"]
+				So(queryResult.Error, ShouldBeNil)
+				So(queryResult.Meta.Get("rowCount").MustInt(), ShouldEqual, 2)
+				So(queryResult.Meta.Get("truncated").MustBool(), ShouldBeTrue)
+				So(queryResult.Meta.Get("notices").GetIndex(0).Get("severity").MustString(), ShouldEqual, "warning")
+
+				frames, err := queryResult.Dataframes.Decoded()
+				So(err, ShouldBeNil)
+				So(frames[0].Rows(), ShouldEqual, 2)
+				So(frames[0].Meta.Notices, ShouldHaveLength, 1)
+			})
+
+			Convey("time series query over the row limit is truncated", func() {
+				resp, err := endpoint.Query(context.Background(), nil, query("time_series", "SELECT time, name AS metric, value FROM metric ORDER BY time"))
+				So(err, ShouldBeNil)
+				queryResult := resp.Results["A"]
+				So(queryResult.Error, ShouldBeNil)
+				So(queryResult.Meta.Get("truncated").MustBool(), ShouldBeTrue)
+				So(queryResult.Series, ShouldHaveLength, 1)
+				So(queryResult.Series[0].Points, ShouldHaveLength, 2)
+			})
+
+			Convey("query over the timeout is cancelled", func() {
+				endpoint.queryTimeout = 50 * time.Millisecond
+
+				resp, err := endpoint.Query(context.Background(), nil, query("table", "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c"))
+				So(err, ShouldBeNil)
+				So(resp.Results["A"].Error, ShouldNotBeNil)
+				So(resp.Results["A"].Error.Error(), ShouldEqual, "query timed out after 50ms")
+			})
+		})
 	})
 }
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+type testQueryResultTransformer struct{}
+
+func (t *testQueryResultTransformer) TransformQueryResult(columnTypes []*sql.ColumnType, rows *core.Rows) (tsdb.RowValues, error) {
+	values := make([]interface{}, len(columnTypes))
+	pointers := make([]interface{}, len(columnTypes))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (t *testQueryResultTransformer) TransformQueryError(err error) error {
+	return err
+}
@@ -0,0 +1,211 @@
+package sqleng
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+const (
+	// defaultRowLimit is the number of rows of a query result when the data
+	// source sets no row limit.
+	defaultRowLimit = 1000000
+	// defaultByteLimit is the estimated size of a query result when the data
+	// source sets no byte limit.
+	defaultByteLimit = 100 * 1024 * 1024
+)
+
+// resultLimiter counts the rows of a query result while they are read and
+// stops the reading at the row or byte limit of the data source.
+type resultLimiter struct {
+	rowLimit  int64
+	byteLimit int64
+	rows      int64
+	bytes     int64
+	// exceeded describes the limit which truncated the result, if any
+	exceeded string
+}
+
+func newResultLimiter(rowLimit, byteLimit int64) *resultLimiter {
+	return &resultLimiter{rowLimit: rowLimit, byteLimit: byteLimit}
+}
+
+// add counts a row of the result, unless it exceeds a limit.
+func (l *resultLimiter) add(values tsdb.RowValues) bool {
+	if l.rows >= l.rowLimit {
+		l.exceeded = fmt.Sprintf("row limit of %d rows", l.rowLimit)
+		return false
+	}
+
+	size := rowSize(values)
+	if l.bytes+size > l.byteLimit {
+		l.exceeded = fmt.Sprintf("size limit of %d bytes", l.byteLimit)
+		return false
+	}
+
+	l.rows++
+	l.bytes += size
+	return true
+}
+
+func (l *resultLimiter) truncated() bool {
+	return l.exceeded != ""
+}
+
+// notice explains the truncation of the result to the user.
+func (l *resultLimiter) notice() string {
+	return fmt.Sprintf("Query result was truncated after %d rows at the %s of the data source", l.rows, l.exceeded)
+}
+
+// setMeta marks a truncated result in the meta data of the query result,
+// where the panel shows the notice.
+func (l *resultLimiter) setMeta(meta *simplejson.Json) {
+	if !l.truncated() {
+		return
+	}
+
+	meta.Set("truncated", true)
+	meta.Set("notices", []interface{}{
+		map[string]interface{}{
+			"severity": "warning",
+			"text":     l.notice(),
+		},
+	})
+}
+
+// rowSize estimates the memory of the values of a row. Text has its length
+// and any other value the size of a number.
+func rowSize(values tsdb.RowValues) int64 {
+	var size int64
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			size += int64(len(v))
+		case *string:
+			if v != nil {
+				size += int64(len(*v))
+			}
+		case []byte:
+			size += int64(len(v))
+		default:
+			size += 8
+		}
+	}
+	return size
+}
+
+// tableFrameBuilder appends the rows of a table query to the fields of a
+// data frame as they are read. The type of a field is the nullable type of
+// the first value of its column which is not null.
+type tableFrameBuilder struct {
+	names  []string
+	fields []*data.Field
+	types  []reflect.Type
+	rows   int
+}
+
+func newTableFrameBuilder(columnNames []string) *tableFrameBuilder {
+	return &tableFrameBuilder{
+		names:  columnNames,
+		fields: make([]*data.Field, len(columnNames)),
+		types:  make([]reflect.Type, len(columnNames)),
+	}
+}
+
+func (b *tableFrameBuilder) append(values tsdb.RowValues) error {
+	for i, value := range values {
+		v := normalizeTableValue(value)
+
+		if b.fields[i] == nil {
+			if v == nil {
+				continue
+			}
+			b.types[i] = reflect.TypeOf(v)
+			b.fields[i] = data.NewField(b.names[i], nil, reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(b.types[i])), b.rows, b.rows).Interface())
+		}
+
+		if v == nil {
+			b.fields[i].Append(nil)
+			continue
+		}
+
+		p, err := b.pointerTo(i, v)
+		if err != nil {
+			return err
+		}
+		b.fields[i].Append(p)
+	}
+
+	b.rows++
+	return nil
+}
+
+// pointerTo returns a pointer to a value of the type of the field of a
+// column. Numbers of another type are converted for float fields, and any
+// value to text for text fields.
+func (b *tableFrameBuilder) pointerTo(i int, v interface{}) (interface{}, error) {
+	t := b.types[i]
+	switch {
+	case reflect.TypeOf(v) == t:
+	case t.Kind() == reflect.String:
+		v = fmt.Sprint(v)
+	case t.Kind() == reflect.Float64:
+		f, err := ConvertSqlValueColumnToFloat(b.names[i], v)
+		if err != nil {
+			return nil, err
+		}
+		v = f.Float64
+	default:
+		return nil, fmt.Errorf("column %s has values of type %s and %T", b.names[i], t, v)
+	}
+
+	p := reflect.New(t)
+	p.Elem().Set(reflect.ValueOf(v))
+	return p.Interface(), nil
+}
+
+// frame returns the data frame of the rows. Columns whose values are all
+// null become text fields.
+func (b *tableFrameBuilder) frame(name string) *data.Frame {
+	for i, field := range b.fields {
+		if field == nil {
+			b.fields[i] = data.NewField(b.names[i], nil, make([]*string, b.rows))
+		}
+	}
+
+	return data.NewFrame(name, b.fields...)
+}
+
+// normalizeTableValue dereferences a value and converts values of types
+// which data frames do not support to text.
+func normalizeTableValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		value = rv.Elem().Interface()
+	}
+
+	switch v := value.(type) {
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64, bool, string, time.Time:
+		return v
+	case int:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case []byte:
+		return string(v)
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,98 @@
+package sqleng
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTableFrame(t *testing.T) {
+	Convey("Table frame", t, func() {
+		Convey("Given rows of a table query", func() {
+			dt := time.Date(2018, 3, 14, 21, 20, 6, 0, time.UTC)
+			text := "abc"
+			builder := newTableFrameBuilder([]string{"time", "text", "value", "flag", "empty"})
+
+			So(builder.append(tsdb.RowValues{dt, nil, int64(1), true, nil}), ShouldBeNil)
+			So(builder.append(tsdb.RowValues{dt, &text, int64(5), nil, nil}), ShouldBeNil)
+			So(builder.append(tsdb.RowValues{nil, []byte("def"), int64(2), false, nil}), ShouldBeNil)
+
+			frame := builder.frame("A")
+
+			Convey("Should type fields by the first value which is not null", func() {
+				So(frame.Name, ShouldEqual, "A")
+				So(frame.Rows(), ShouldEqual, 3)
+				So(frame.Fields[0].Type(), ShouldEqual, data.FieldTypeNullableTime)
+				So(frame.Fields[1].Type(), ShouldEqual, data.FieldTypeNullableString)
+				So(frame.Fields[2].Type(), ShouldEqual, data.FieldTypeNullableInt64)
+				So(frame.Fields[3].Type(), ShouldEqual, data.FieldTypeNullableBool)
+				So(frame.Fields[4].Type(), ShouldEqual, data.FieldTypeNullableString)
+			})
+
+			Convey("Should keep nulls before and after the first value", func() {
+				So(frame.Fields[1].At(0), ShouldBeNil)
+				So(*frame.Fields[1].At(1).(*string), ShouldEqual, "abc")
+				So(*frame.Fields[1].At(2).(*string), ShouldEqual, "def")
+				So(frame.Fields[0].At(2), ShouldBeNil)
+				So(frame.Fields[4].At(2), ShouldBeNil)
+			})
+
+			Convey("Should fail for values of another type than the field", func() {
+				err := builder.append(tsdb.RowValues{dt, "ghi", "x", true, nil})
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("Given a float column with integer values", func() {
+			builder := newTableFrameBuilder([]string{"value"})
+			So(builder.append(tsdb.RowValues{2.5}), ShouldBeNil)
+			So(builder.append(tsdb.RowValues{int32(3)}), ShouldBeNil)
+
+			frame := builder.frame("A")
+			So(*frame.Fields[0].At(1).(*float64), ShouldEqual, 3.0)
+		})
+
+		Convey("Given a limiter of rows", func() {
+			limiter := newResultLimiter(2, defaultByteLimit)
+
+			So(limiter.add(tsdb.RowValues{int64(1)}), ShouldBeTrue)
+			So(limiter.add(tsdb.RowValues{int64(2)}), ShouldBeTrue)
+			So(limiter.truncated(), ShouldBeFalse)
+			So(limiter.add(tsdb.RowValues{int64(3)}), ShouldBeFalse)
+			So(limiter.truncated(), ShouldBeTrue)
+			So(limiter.rows, ShouldEqual, 2)
+
+			Convey("Should mark the result as truncated", func() {
+				meta := simplejson.New()
+				limiter.setMeta(meta)
+
+				So(meta.Get("truncated").MustBool(), ShouldBeTrue)
+				notice := meta.Get("notices").GetIndex(0)
+				So(notice.Get("severity").MustString(), ShouldEqual, "warning")
+				So(notice.Get("text").MustString(), ShouldEqual, "Query result was truncated after 2 rows at the row limit of 2 rows of the data source")
+			})
+		})
+
+		Convey("Given a limiter of bytes", func() {
+			limiter := newResultLimiter(defaultRowLimit, 20)
+
+			So(limiter.add(tsdb.RowValues{"0123456789", int64(1)}), ShouldBeTrue)
+			So(limiter.add(tsdb.RowValues{"0123", nil}), ShouldBeFalse)
+			So(limiter.notice(), ShouldContainSubstring, "size limit of 20 bytes")
+		})
+
+		Convey("Given a result within the limits", func() {
+			limiter := newResultLimiter(defaultRowLimit, defaultByteLimit)
+			So(limiter.add(tsdb.RowValues{"abc"}), ShouldBeTrue)
+
+			meta := simplejson.New()
+			limiter.setMeta(meta)
+			So(meta.Get("truncated").Interface(), ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,28 @@
+package sqleng
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// TableRows returns the rows of the data frame of a table query, with the
+// values of nullable fields dereferenced. It is used by the tests of the SQL
+// data sources.
+func TableRows(queryResult *tsdb.QueryResult) [][]interface{} {
+	frames, err := queryResult.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+	So(frames, ShouldHaveLength, 1)
+
+	frame := frames[0]
+	rows := make([][]interface{}, frame.Rows())
+	for i := range rows {
+		rows[i] = make([]interface{}, len(frame.Fields))
+		for j, field := range frame.Fields {
+			if v, ok := field.ConcreteAt(i); ok {
+				rows[i][j] = v
+			}
+		}
+	}
+	return rows
+}
@@ -28,6 +28,20 @@ type TimeRange struct {
 	From string
 	To   string
 	now  time.Time
+	// intervalDuration is the duration intervals are calculated from when
+	// it differs from the duration of the range, as for the tail of an
+	// incremental query.
+	intervalDuration time.Duration
+}
+
+// IntervalDuration returns the duration intervals are calculated from,
+// which is the duration of the range unless the range is the tail of an
+// incremental query.
+func (tr *TimeRange) IntervalDuration() time.Duration {
+	if tr.intervalDuration > 0 {
+		return tr.intervalDuration
+	}
+	return tr.MustGetTo().Sub(tr.MustGetFrom())
 }
 
 func (tr *TimeRange) GetFromAsMsEpoch() int64 {
@@ -0,0 +1,34 @@
+package tsdb
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type basicAuthTransport struct {
+	Transport http.RoundTripper
+
+	username string
+	password string
+}
+
+func (bat basicAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.SetBasicAuth(bat.username, bat.password)
+	return bat.Transport.RoundTrip(req)
+}
+
+// BasicAuthTransport returns a transport adding the basic auth credentials of
+// the data source to requests, or the transport itself when the data source
+// does not use basic auth.
+func BasicAuthTransport(dsInfo *models.DataSource, transport http.RoundTripper) http.RoundTripper {
+	if !dsInfo.BasicAuth {
+		return transport
+	}
+
+	return basicAuthTransport{
+		Transport: transport,
+		username:  dsInfo.BasicAuthUser,
+		password:  dsInfo.DecryptedBasicAuthPassword(),
+	}
+}
@@ -0,0 +1,38 @@
+package zipkin
+
+import "time"
+
+type ZipkinQuery struct {
+	RefId   string
+	TraceID string
+
+	// Search queries find the traces of a service which match the other
+	// criteria, instead of fetching a trace by its id.
+	Search      bool
+	Service     string
+	SpanName    string
+	Tags        map[string]string
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	Limit       int64
+	Start       time.Time
+	End         time.Time
+}
+
+// span is a span of the v2 API, whose timestamp and duration are in
+// microseconds.
+type span struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	Kind          string            `json:"kind"`
+	LocalEndpoint *endpoint         `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags"`
+}
+
+type endpoint struct {
+	ServiceName string `json:"serviceName"`
+}
@@ -0,0 +1,283 @@
+package zipkin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+const (
+	searchQueryType = "search"
+	// defaultLimit is the number of traces of a search query which sets none.
+	defaultLimit = 10
+)
+
+type ZipkinExecutor struct {
+}
+
+func NewZipkinExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &ZipkinExecutor{}, nil
+}
+
+var plog log.Logger
+
+func init() {
+	plog = log.New("tsdb.zipkin")
+	tsdb.RegisterTsdbQueryEndpoint("zipkin", NewZipkinExecutor)
+}
+
+// Query fetches a trace by its id, or searches the traces of a service, and
+// returns a frame of spans per trace.
+func (e *ZipkinExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	result := &tsdb.Response{
+		Results: map[string]*tsdb.QueryResult{},
+	}
+
+	queries, err := parseQuery(tsdbQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, query := range queries {
+		queryRes := &tsdb.QueryResult{RefId: query.RefId}
+		result.Results[query.RefId] = queryRes
+
+		if !query.Search && query.TraceID == "" {
+			queryRes.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{})
+			continue
+		}
+
+		req, err := createRequest(dsInfo, query)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := ctxhttp.Do(ctx, httpClient, req)
+		if err != nil {
+			return nil, err
+		}
+
+		traces, err := parseResponse(res, query)
+		if err != nil {
+			queryRes.Error = err
+			queryRes.ErrorString = err.Error()
+			continue
+		}
+
+		frames := make(data.Frames, 0, len(traces))
+		for _, spans := range traces {
+			frame, err := traceToFrame(spans)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		}
+		queryRes.Dataframes = tsdb.NewDecodedDataFrames(frames)
+	}
+
+	return result, nil
+}
+
+func parseQuery(tsdbQuery *tsdb.TsdbQuery) ([]*ZipkinQuery, error) {
+	qs := []*ZipkinQuery{}
+	for _, queryModel := range tsdbQuery.Queries {
+		model := queryModel.Model
+		query := &ZipkinQuery{
+			RefId:   queryModel.RefId,
+			TraceID: strings.TrimSpace(model.Get("query").MustString("")),
+			Search:  model.Get("queryType").MustString("") == searchQueryType,
+		}
+
+		if query.Search {
+			query.Service = model.Get("service").MustString("")
+			if query.Service == "" {
+				return nil, fmt.Errorf("search query %s has no service", query.RefId)
+			}
+			query.SpanName = model.Get("operation").MustString("")
+			query.Limit = model.Get("limit").MustInt64(defaultLimit)
+
+			query.Tags = map[string]string{}
+			for k, v := range model.Get("tags").MustMap() {
+				query.Tags[k] = fmt.Sprint(v)
+			}
+
+			var err error
+			if query.MinDuration, err = parseDuration(model.Get("minDuration").MustString("")); err != nil {
+				return nil, err
+			}
+			if query.MaxDuration, err = parseDuration(model.Get("maxDuration").MustString("")); err != nil {
+				return nil, err
+			}
+
+			if query.Start, err = tsdbQuery.TimeRange.ParseFrom(); err != nil {
+				return nil, err
+			}
+			if query.End, err = tsdbQuery.TimeRange.ParseTo(); err != nil {
+				return nil, err
+			}
+		}
+
+		qs = append(qs, query)
+	}
+
+	return qs, nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	return d, nil
+}
+
+func createRequest(dsInfo *models.DataSource, query *ZipkinQuery) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Search {
+		u.Path = path.Join(u.Path, "api/v2/traces")
+
+		params := url.Values{}
+		params.Set("serviceName", query.Service)
+		if query.SpanName != "" {
+			params.Set("spanName", query.SpanName)
+		}
+		if len(query.Tags) > 0 {
+			params.Set("annotationQuery", annotationQuery(query.Tags))
+		}
+		if query.MinDuration > 0 {
+			params.Set("minDuration", strconv.FormatInt(query.MinDuration.Microseconds(), 10))
+		}
+		if query.MaxDuration > 0 {
+			params.Set("maxDuration", strconv.FormatInt(query.MaxDuration.Microseconds(), 10))
+		}
+		// Zipkin searches the lookback before the end of the range
+		params.Set("endTs", strconv.FormatInt(query.End.UnixNano()/int64(time.Millisecond), 10))
+		params.Set("lookback", strconv.FormatInt(query.End.Sub(query.Start).Milliseconds(), 10))
+		params.Set("limit", strconv.FormatInt(query.Limit, 10))
+		u.RawQuery = params.Encode()
+	} else {
+		u.Path = path.Join(u.Path, "api/v2/trace", url.PathEscape(query.TraceID))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	return req, nil
+}
+
+// annotationQuery returns the tags as a query of Zipkin, such as
+// error and http.status_code=500.
+func annotationQuery(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	terms := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if tags[k] == "" {
+			terms = append(terms, k)
+			continue
+		}
+		terms = append(terms, k+"="+tags[k])
+	}
+
+	return strings.Join(terms, " and ")
+}
+
+// parseResponse returns the spans of each trace of the response, which is a
+// list of spans for a trace by id and a list of traces for a search.
+func parseResponse(res *http.Response, query *ZipkinQuery) ([][]span, error) {
+	body, err := ioutil.ReadAll(res.Body)
+	defer res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		plog.Info("Request failed", "status", res.Status, "body", string(body))
+		if res.StatusCode == http.StatusNotFound && !query.Search {
+			return nil, fmt.Errorf("trace %s not found", query.TraceID)
+		}
+		return nil, fmt.Errorf("Request failed status: %v", res.Status)
+	}
+
+	if !query.Search {
+		var spans []span
+		if err := json.Unmarshal(body, &spans); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Zipkin response: %w", err)
+		}
+		return [][]span{spans}, nil
+	}
+
+	var traces [][]span
+	if err := json.Unmarshal(body, &traces); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Zipkin response: %w", err)
+	}
+	return traces, nil
+}
+
+func traceToFrame(spans []span) (*data.Frame, error) {
+	traceSpans := make([]*tsdb.TraceSpan, 0, len(spans))
+	traceID := ""
+	for _, s := range spans {
+		traceID = s.TraceID
+
+		tags := make(map[string]interface{}, len(s.Tags))
+		for k, v := range s.Tags {
+			tags[k] = v
+		}
+
+		serviceName := ""
+		if s.LocalEndpoint != nil {
+			serviceName = s.LocalEndpoint.ServiceName
+		}
+
+		traceSpans = append(traceSpans, &tsdb.TraceSpan{
+			TraceID:       s.TraceID,
+			SpanID:        s.ID,
+			ParentSpanID:  s.ParentID,
+			OperationName: s.Name,
+			ServiceName:   serviceName,
+			StartTime:     time.Unix(0, s.Timestamp*int64(time.Microsecond)),
+			Duration:      time.Duration(s.Duration) * time.Microsecond,
+			Tags:          tags,
+		})
+	}
+
+	return tsdb.SpansToFrame(traceID, traceSpans)
+}
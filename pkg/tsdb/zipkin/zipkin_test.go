@@ -0,0 +1,116 @@
+package zipkin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const traceResponse = `[
+	{"traceId":"abc","id":"2","parentId":"1","name":"query","timestamp":1600000000001000,"duration":1500,
+	 "localEndpoint":{"serviceName":"db"},"tags":{"error":"true"}},
+	{"traceId":"abc","id":"1","name":"get /api","timestamp":1600000000000000,"duration":5000,
+	 "localEndpoint":{"serviceName":"api"}}
+]`
+
+func TestZipkin(t *testing.T) {
+	Convey("Zipkin", t, func() {
+		var requestPath, username string
+		var params url.Values
+		body := traceResponse
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestPath = r.URL.Path
+			params = r.URL.Query()
+			username, _, _ = r.BasicAuth()
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		defer server.Close()
+
+		dsInfo := &models.DataSource{
+			Id:            1,
+			Url:           server.URL,
+			BasicAuth:     true,
+			BasicAuthUser: "user",
+			JsonData:      simplejson.New(),
+		}
+		executor, err := NewZipkinExecutor(dsInfo)
+		So(err, ShouldBeNil)
+
+		tsdbQuery := &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1600000000000", "1600003600000"),
+			Queries: []*tsdb.Query{{
+				RefId: "A",
+				Model: simplejson.NewFromAny(map[string]interface{}{"query": "abc"}),
+			}},
+		}
+
+		Convey("fetches trace by id", func() {
+			res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+			So(err, ShouldBeNil)
+			So(requestPath, ShouldEqual, "/api/v2/trace/abc")
+			So(username, ShouldEqual, "user")
+
+			frames, err := res.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+
+			frame := frames[0]
+			So(frame.Name, ShouldEqual, "abc")
+			So(frame.Rows(), ShouldEqual, 2)
+			So(frame.Fields[1].At(0), ShouldEqual, "1")
+			So(frame.Fields[2].At(1), ShouldEqual, "1")
+			So(frame.Fields[3].At(1), ShouldEqual, "query")
+			So(frame.Fields[4].At(0), ShouldEqual, "api")
+			So(frame.Fields[5].At(0), ShouldEqual, time.Unix(1600000000, 0).UTC())
+			So(frame.Fields[6].At(1), ShouldEqual, 1.5)
+			So(frame.Fields[7].At(1), ShouldEqual, `{"error":"true"}`)
+		})
+
+		Convey("searches traces", func() {
+			body = "[" + traceResponse + "]"
+			tsdbQuery.Queries[0].Model = simplejson.NewFromAny(map[string]interface{}{
+				"queryType":   "search",
+				"service":     "api",
+				"operation":   "get /api",
+				"tags":        map[string]interface{}{"http.status_code": 500, "error": ""},
+				"minDuration": "100ms",
+				"limit":       5,
+			})
+
+			res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+			So(err, ShouldBeNil)
+			So(requestPath, ShouldEqual, "/api/v2/traces")
+			So(params.Get("serviceName"), ShouldEqual, "api")
+			So(params.Get("spanName"), ShouldEqual, "get /api")
+			So(params.Get("annotationQuery"), ShouldEqual, "error and http.status_code=500")
+			So(params.Get("minDuration"), ShouldEqual, "100000")
+			So(params.Get("endTs"), ShouldEqual, "1600003600000")
+			So(params.Get("lookback"), ShouldEqual, "3600000")
+			So(params.Get("limit"), ShouldEqual, "5")
+
+			frames, err := res.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			So(frames[0].Rows(), ShouldEqual, 2)
+		})
+
+		Convey("returns error of trace not found", func() {
+			status = http.StatusNotFound
+			body = "Trace not found"
+
+			res, err := executor.Query(context.Background(), dsInfo, tsdbQuery)
+			So(err, ShouldBeNil)
+			So(res.Results["A"].ErrorString, ShouldEqual, "trace abc not found")
+		})
+	})
+}
@@ -1,4 +1,25 @@
 import _ from 'lodash';
+import { arrowTableToDataFrame, base64StringToArrowTable, TableData } from '@grafana/data';
+
+// table queries return a data frame, which is read as a table
+function toTables(queryRes: any): TableData[] {
+  const tables: TableData[] = queryRes.tables ? [...queryRes.tables] : [];
+
+  for (const encoded of queryRes.dataframes || []) {
+    const frame = arrowTableToDataFrame(base64StringToArrowTable(encoded));
+    const table: TableData = {
+      columns: frame.fields.map(field => ({ text: field.name })),
+      rows: [],
+    };
+
+    for (let i = 0; i < frame.length; i++) {
+      table.rows.push(frame.fields.map(field => field.values.get(i)));
+    }
+    tables.push(table);
+  }
+
+  return tables;
+}
 
 export default class ResponseParser {
   processQueryResult(res: any) {
@@ -22,13 +43,11 @@ export default class ResponseParser {
         }
       }
 
-      if (queryRes.tables) {
-        for (const table of queryRes.tables) {
-          table.type = 'table';
-          table.refId = queryRes.refId;
-          table.meta = queryRes.meta;
-          data.push(table);
-        }
+      for (const table of toTables(queryRes) as any[]) {
+        table.type = 'table';
+        table.refId = queryRes.refId;
+        table.meta = queryRes.meta;
+        data.push(table);
       }
     }
 
@@ -40,8 +59,9 @@ export default class ResponseParser {
       return [];
     }
 
-    const columns = results.data.results[refId].tables[0].columns;
-    const rows = results.data.results[refId].tables[0].rows;
+    const table = toTables(results.data.results[refId])[0];
+    const columns = table.columns;
+    const rows = table.rows;
     const textColIndex = this.findColIndex(columns, '__text');
     const valueColIndex = this.findColIndex(columns, '__value');
 
@@ -101,7 +121,7 @@ export default class ResponseParser {
   }
 
   transformAnnotationResponse(options: any, data: any) {
-    const table = data.data.results[options.annotation.name].tables[0];
+    const table = toTables(data.data.results[options.annotation.name])[0];
 
     let timeColumnIndex = -1;
     let timeEndColumnIndex = -1;